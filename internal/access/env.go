@@ -6,6 +6,10 @@ import (
 	"time"
 )
 
+// tokenCacheTTL is kept short so that a deactivated token
+// stops working within a minute.
+const tokenCacheTTL = time.Minute
+
 type Env struct {
 	dbs   db.ReadWriteMyDBs
 	cache *cache.Cache
@@ -13,9 +17,8 @@ type Env struct {
 
 func NewEnv(dbs db.ReadWriteMyDBs) Env {
 	return Env{
-		dbs: dbs,
-		// Default expiration 24 hours, and purges the expired items every hour.
-		cache: cache.New(24*time.Hour, 1*time.Hour),
+		dbs:   dbs,
+		cache: cache.New(tokenCacheTTL, 10*time.Minute),
 	}
 }
 
@@ -62,3 +65,18 @@ func (env Env) retrieveFromDB(token string) (OAuth, error) {
 func (env Env) cacheToken(token string, access OAuth) {
 	env.cache.Set(token, access, cache.DefaultExpiration)
 }
+
+// Forget removes a token from cache so that next request
+// reads it from db.
+func (env Env) Forget(token string) {
+	env.cache.Delete(token)
+}
+
+func (env Env) SaveUsage(u Usage) error {
+	_, err := env.dbs.Write.NamedExec(StmtSaveUsage, u)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -35,11 +35,45 @@ func (g Guard) RequireToken(next echo.HandlerFunc) echo.HandlerFunc {
 			return render.NewDBError(err)
 		}
 
+		o.Fingerprint = Fingerprint(token)
+
 		if o.Expired() || !o.Active {
-			log.Printf("Token %s is either expired or not active", token)
+			// Do not keep it in cache in case it is reactivated.
+			g.repo.Forget(token)
+			log.Printf("Token %s is either expired or not active", o.Fingerprint)
 			return render.NewForbidden("The access token is expired or no longer active")
 		}
 
+		o = o.WithOperator(c.Request().Header.Get(HeaderOperator))
+		c.Set(xhttp.KeyCtxOAuth, o)
+
+		// Context is recycled after request ends.
+		u := NewUsage(o, c.Request().Method, c.Path())
+		go func() {
+			err := g.repo.SaveUsage(u)
+			if err != nil {
+				log.Print(err)
+			}
+		}()
+
 		return next(c)
 	}
 }
+
+// RequireScope must be used after RequireToken.
+func (g Guard) RequireScope(scope Scope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			o, ok := c.Get(xhttp.KeyCtxOAuth).(OAuth)
+			if !ok {
+				return render.NewForbidden("Invalid access token")
+			}
+
+			if !o.HasScope(scope) {
+				return render.NewForbidden("Access token does not have scope " + string(scope))
+			}
+
+			return next(c)
+		}
+	}
+}
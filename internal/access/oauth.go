@@ -1,15 +1,31 @@
 package access
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"github.com/FTChinese/go-rest/chrono"
 	"github.com/guregu/null"
+	"strings"
 	"time"
 )
 
+// StmtOAuth loads a token. Tokens created before scopes were
+// introduced have NULL scope and are read-only. Those used to
+// confirm payments or change data must be granted the scopes
+// explicitly, one client at a time:
+//
+//	UPDATE oauth.access
+//	SET scope = 'admins:read teams:read teams:write orders:read orders:confirm webhooks:read webhooks:write audit:read reports:read outbox:read outbox:write'
+//	WHERE scope IS NULL
+//		AND is_active = 1
+//		AND client_id = ?;
 const StmtOAuth = `
 SELECT access_token,
 	is_active,
 	expires_in,
+	client_id,
+	created_by,
+	scope,
 	created_utc
 FROM oauth.access
 WHERE access_token = UNHEX(?)
@@ -21,7 +37,54 @@ type OAuth struct {
 	Token     string      `db:"access_token"`
 	Active    bool        `db:"is_active"`
 	ExpiresIn null.Int    `db:"expires_in"` // seconds
+	ClientID  null.String `db:"client_id"`  // The app this token is issued to.
+	CreatedBy null.String `db:"created_by"` // Staff who created the token.
+	// Space separated scopes. NULL for tokens created
+	// before scopes were introduced, which are read-only.
+	Scope     null.String `db:"scope"`
 	CreatedAt chrono.Time `db:"created_utc"`
+	// Fingerprint identifies the token in logs without
+	// revealing it.
+	Fingerprint string `db:"-"`
+	// Operator is the staff using the token in this request.
+	Operator string `db:"-"`
+}
+
+// Fingerprint is the first 12 hex chars of sha256 of token.
+func Fingerprint(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])[:12]
+}
+
+// HasScope checks whether the token is allowed to use s.
+func (a OAuth) HasScope(s Scope) bool {
+	if !a.Scope.Valid {
+		for _, v := range readOnlyScopes {
+			if v == s {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, v := range strings.Fields(a.Scope.String) {
+		if Scope(v) == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithOperator sets the staff using the token. It falls back
+// to the creator of the token if the client does not tell.
+func (a OAuth) WithOperator(name string) OAuth {
+	if name == "" {
+		name = a.CreatedBy.String
+	}
+	a.Operator = name
+
+	return a
 }
 
 func (a OAuth) Expired() bool {
@@ -0,0 +1,78 @@
+package access
+
+import (
+	"github.com/guregu/null"
+	"testing"
+)
+
+func TestOAuth_HasScope(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope null.String
+		check Scope
+		want  bool
+	}{
+		{
+			name:  "Legacy token could not confirm orders",
+			scope: null.String{},
+			check: ScopeOrdersConfirm,
+			want:  false,
+		},
+		{
+			name:  "Legacy token could not write",
+			scope: null.String{},
+			check: ScopeTeamsWrite,
+			want:  false,
+		},
+		{
+			name:  "Legacy token could read",
+			scope: null.String{},
+			check: ScopeOrdersRead,
+			want:  true,
+		},
+		{
+			name:  "Legacy token could not read outbox",
+			scope: null.String{},
+			check: ScopeOutboxRead,
+			want:  false,
+		},
+		{
+			name:  "Read-only token",
+			scope: null.StringFrom("orders:read teams:read"),
+			check: ScopeOrdersConfirm,
+			want:  false,
+		},
+		{
+			name:  "Granted scope",
+			scope: null.StringFrom("orders:read orders:confirm"),
+			check: ScopeOrdersConfirm,
+			want:  true,
+		},
+		{
+			name:  "Empty scope has no access",
+			scope: null.StringFrom(""),
+			check: ScopeTeamsRead,
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := OAuth{Scope: tt.scope}
+			if got := o.HasScope(tt.check); got != tt.want {
+				t.Errorf("HasScope() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOAuth_WithOperator(t *testing.T) {
+	o := OAuth{CreatedBy: null.StringFrom("alice")}
+
+	if got := o.WithOperator("").Operator; got != "alice" {
+		t.Errorf("WithOperator() fallback = %s, want alice", got)
+	}
+
+	if got := o.WithOperator("bob").Operator; got != "bob" {
+		t.Errorf("WithOperator() = %s, want bob", got)
+	}
+}
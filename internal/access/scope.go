@@ -0,0 +1,31 @@
+package access
+
+// Scope limits what a CMS access token could do.
+type Scope string
+
+const (
+	ScopeAdminsRead    Scope = "admins:read"
+	ScopeTeamsRead     Scope = "teams:read"
+	ScopeTeamsWrite    Scope = "teams:write" // Change team data, or act on behalf of it.
+	ScopeOrdersRead    Scope = "orders:read"
+	ScopeOrdersConfirm Scope = "orders:confirm"
+	ScopeWebhooksRead  Scope = "webhooks:read"
+	ScopeWebhooksWrite Scope = "webhooks:write"
+	ScopeAuditRead     Scope = "audit:read"
+	ScopeReportsRead   Scope = "reports:read"
+	ScopeOutboxRead    Scope = "outbox:read"
+	ScopeOutboxWrite   Scope = "outbox:write" // Resend dead letters.
+)
+
+// readOnlyScopes are granted to tokens without scope. They
+// could view data but never change it.
+// ScopeOutboxRead is left out since letters contain live
+// links; it must be granted explicitly.
+var readOnlyScopes = []Scope{
+	ScopeAdminsRead,
+	ScopeTeamsRead,
+	ScopeOrdersRead,
+	ScopeWebhooksRead,
+	ScopeAuditRead,
+	ScopeReportsRead,
+}
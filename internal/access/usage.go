@@ -0,0 +1,40 @@
+package access
+
+import (
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/guregu/null"
+)
+
+// HeaderOperator is sent by CMS to tell which staff is
+// using a shared token.
+const HeaderOperator = "X-Operator"
+
+// Usage records which app and staff used a token.
+type Usage struct {
+	Fingerprint string      `db:"token_fingerprint"`
+	ClientID    null.String `db:"client_id"`
+	Operator    null.String `db:"operator"`
+	Method      string      `db:"http_method"`
+	Path        string      `db:"request_path"`
+	UsedUTC     chrono.Time `db:"used_utc"`
+}
+
+func NewUsage(o OAuth, method, path string) Usage {
+	return Usage{
+		Fingerprint: o.Fingerprint,
+		ClientID:    o.ClientID,
+		Operator:    null.NewString(o.Operator, o.Operator != ""),
+		Method:      method,
+		Path:        path,
+		UsedUTC:     chrono.TimeNow(),
+	}
+}
+
+const StmtSaveUsage = `
+INSERT INTO b2b.cms_token_usage
+SET token_fingerprint = :token_fingerprint,
+	client_id = :client_id,
+	operator = :operator,
+	http_method = :http_method,
+	request_path = :request_path,
+	used_utc = :used_utc`
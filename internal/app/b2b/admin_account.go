@@ -1,6 +1,7 @@
 package b2b
 
 import (
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
 	"github.com/FTChinese/ftacademy/internal/pkg/input"
 	"github.com/FTChinese/go-rest/render"
 	"github.com/labstack/echo/v4"
@@ -16,7 +17,7 @@ func (router AdminRouter) RefreshJWT(c echo.Context) error {
 		return render.NewDBError(err)
 	}
 
-	bearer, err := router.guard.CreatePassport(baseAccount)
+	bearer, err := router.guard.CreatePassport(baseAccount, claims.SessionID())
 	if err != nil {
 		return render.NewDBError(err)
 	}
@@ -134,5 +135,14 @@ func (router AdminRouter) ChangePassword(c echo.Context) error {
 		return render.NewDBError(err)
 	}
 
+	// Log out other devices in case the old password leaked.
+	if err := router.revokeSessions(claims.AdminID, claims.SessionID()); err != nil {
+		return render.NewDBError(err)
+	}
+
+	router.audit.record(c, audit.NewEntry(claimsActor(claims), audit.ActionPasswordChange).
+		WithTeam(claims.TeamID.String).
+		WithTarget(claims.AdminID))
+
 	return c.NoContent(http.StatusNoContent)
 }
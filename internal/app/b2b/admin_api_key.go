@@ -0,0 +1,187 @@
+package b2b
+
+import (
+	"database/sql"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/pkg/xhttp"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// RequireTeamOrKey accepts either a logged-in admin's JWT
+// or a team's API key having the specified scope.
+// In both cases the context carries PassportClaims so that
+// the same handlers could be shared.
+func (router AdminRouter) RequireTeamOrKey(scope admin.APIScope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		jwtNext := router.RequireTeamSet(router.RequireRole(scope.Area())(next))
+
+		return func(c echo.Context) error {
+			defer router.logger.Sync()
+			sugar := router.logger.Sugar()
+
+			cred, err := xhttp.GetBearerAuth(c.Request().Header)
+			if err != nil || !admin.IsAPIKey(cred) {
+				return jwtNext(c)
+			}
+
+			key, err := router.repo.APIKeyByPlain(cred)
+			if err != nil {
+				sugar.Error(err)
+				if err == sql.ErrNoRows {
+					return render.NewUnauthorized("Invalid API key")
+				}
+				return render.NewDBError(err)
+			}
+
+			if key.IsRevoked() {
+				return render.NewUnauthorized("API key is revoked")
+			}
+
+			if !key.Scopes.Has(scope) {
+				return render.NewForbidden("API key does not have scope " + string(scope))
+			}
+
+			// A key cannot do more than its creator.
+			ms, err := router.repo.MemberScope(key.TeamID, key.AdminID)
+			if err != nil {
+				sugar.Error(err)
+				return render.NewDBError(err)
+			}
+			if ms.Role == admin.RoleNull {
+				return render.NewUnauthorized("Creator of this API key is no longer an admin of the team")
+			}
+			if !ms.CanRequest(scope.Area(), c.Request().Method) {
+				return render.NewForbidden("Creator of this API key is not allowed this action")
+			}
+
+			claims := key.Claims()
+			claims.MemberScope = ms
+
+			go func() {
+				if err := router.repo.TouchAPIKey(key.ID); err != nil {
+					sugar.Error(err)
+				}
+			}()
+
+			c.Set(xhttp.KeyCtxClaims, claims)
+			return next(c)
+		}
+	}
+}
+
+func (router AdminRouter) ListAPIKeys(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	keys, err := router.repo.ListAPIKeys(claims.TeamID.String)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey generates a new key. The plain key is only
+// returned in this response.
+// Input:
+// name: string;
+// scopes: string[];
+func (router AdminRouter) CreateAPIKey(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.APIKeyParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	if ve := params.Validate(admin.IsValidScope); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	key, plain, err := admin.NewAPIKey(params, admin.Creator{
+		AdminID: claims.AdminID,
+		TeamID:  claims.TeamID.String,
+	})
+	if err != nil {
+		sugar.Error(err)
+		return render.NewInternalError(err.Error())
+	}
+
+	err = router.repo.CreateAPIKey(key)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, admin.APIKeyCreated{
+		APIKey: key,
+		Key:    plain,
+	})
+}
+
+// UpdateAPIKey changes a key's name or scopes.
+// Input: the same as CreateAPIKey.
+func (router AdminRouter) UpdateAPIKey(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+	id := c.Param("id")
+
+	var params input.APIKeyParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	if ve := params.Validate(admin.IsValidScope); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	key, err := router.repo.LoadAPIKey(id, claims.TeamID.String)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	if key.IsRevoked() {
+		return render.NewBadRequest("API key is already revoked")
+	}
+
+	key = key.Update(params)
+	err = router.repo.UpdateAPIKey(key)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, key)
+}
+
+// RevokeAPIKey disables a key permanently.
+func (router AdminRouter) RevokeAPIKey(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+	id := c.Param("id")
+
+	key, err := router.repo.LoadAPIKey(id, claims.TeamID.String)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	if key.IsRevoked() {
+		return c.JSON(http.StatusOK, key)
+	}
+
+	key = key.Revoked()
+	err = router.repo.UpdateAPIKey(key)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, key)
+}
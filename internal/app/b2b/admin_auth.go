@@ -1,10 +1,14 @@
 package b2b
 
 import (
+	"database/sql"
 	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
 	"github.com/FTChinese/ftacademy/internal/pkg/input"
 	"github.com/FTChinese/ftacademy/internal/pkg/letter"
 	"github.com/FTChinese/ftacademy/pkg/db"
+	"github.com/FTChinese/ftacademy/pkg/throttle"
+	"github.com/FTChinese/ftacademy/pkg/xhttp"
 	"github.com/FTChinese/go-rest/render"
 	"github.com/labstack/echo/v4"
 	"net/http"
@@ -27,9 +31,13 @@ func (router AdminRouter) SignUp(c echo.Context) error {
 		return render.NewUnprocessable(ve)
 	}
 
-	adminAccount := admin.NewAccount(params)
+	adminAccount, err := admin.NewAccount(params)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewInternalError(err.Error())
+	}
 
-	err := router.repo.SignUp(adminAccount)
+	err = router.repo.SignUp(adminAccount)
 	if err != nil {
 		if db.IsAlreadyExists(err) {
 			return render.NewUnprocessable(render.NewVEAlreadyExists("email"))
@@ -38,14 +46,13 @@ func (router AdminRouter) SignUp(c echo.Context) error {
 		return render.NewDBError(err)
 	}
 
-	go func() {
-		_ = router.sendEmailVerification(adminAccount.BaseAccount)
-	}()
+	// Signing up succeeds even if the letter failed; it could be
+	// requested again.
+	_ = router.sendEmailVerification(adminAccount.BaseAccount)
 
-	jwtBearer, err := router.guard.CreatePassport(adminAccount.BaseAccount)
+	jwtBearer, err := router.startSession(c, adminAccount.BaseAccount)
 	if err != nil {
-		sugar.Error(err)
-		return render.NewInternalError(err.Error())
+		return err
 	}
 
 	// `200 OK`
@@ -133,7 +140,16 @@ func (router AdminRouter) VerifyEmail(c echo.Context) error {
 // {error: {field: "email", code: "invalid"}}
 // * if password is missing:
 // {error: { field: "password", code: "missing_field"}}
+// 423 Locked if the account is locked after too many failed logins.
+// 429 Too Many Requests if the IP or email failed too often.
+//
+// If the admin enabled 2FA, the response is
+// {twoFactorRequired: true, challengeToken: string, expiresIn: number}
+// which should be sent to LoginTwoFactor together with a code.
 func (router AdminRouter) Login(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
 	var params input.Credentials
 	if err := c.Bind(&params); err != nil {
 		return err
@@ -143,23 +159,66 @@ func (router AdminRouter) Login(c echo.Context) error {
 		return render.NewUnprocessable(ve)
 	}
 
+	limitKeys := []string{
+		throttle.KeyIP(c.RealIP()),
+		throttle.KeyEmail(params.Email),
+	}
+	d, err := router.loginLimiter.Check(limitKeys...)
+	if err != nil {
+		sugar.Error(err)
+	} else if !d.Allowed {
+		return xhttp.TooManyRequests(c, d)
+	}
+
 	authResult, err := router.repo.Authenticate(params)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			_ = router.loginLimiter.Hit(limitKeys...)
+		}
 		return render.NewDBError(err)
 	}
+
+	// A locked account cannot log in even with correct
+	// password.
+	if router.isLocked(authResult.AdminID) {
+		return errAccountLocked()
+	}
+
 	if !authResult.PasswordMatched {
-		return render.NewForbidden("Incorrect credentials")
+		err = router.loginLimiter.Hit(limitKeys...)
+		if err != nil {
+			sugar.Error(err)
+		}
+		return router.recordLoginFailure(c, authResult.AdminID)
 	}
 
+	_ = router.loginLimiter.Reset(throttle.KeyEmail(params.Email))
+
+	// With 2FA enabled, passport is issued only after a
+	// code is verified against the challenge. Failures are
+	// not forgiven until then.
+	challenge, err := router.challengeTwoFactor(authResult.AdminID)
+	if err != nil {
+		return err
+	}
+	if challenge != nil {
+		return c.JSON(http.StatusOK, challenge)
+	}
+
+	_ = router.lockoutLimiter.Reset(authResult.AdminID)
+
 	baseAccount, err := router.repo.BaseAccountByID(authResult.AdminID)
 	if err != nil {
 		return render.NewDBError(err)
 	}
 
-	jwtBearer, err := router.guard.CreatePassport(baseAccount)
+	jwtBearer, err := router.startSession(c, baseAccount)
 	if err != nil {
-		return render.NewInternalError(err.Error())
+		return err
 	}
+
+	router.audit.record(c, audit.NewEntry(audit.AdminActor(baseAccount.ID), audit.ActionLogin).
+		WithTeam(baseAccount.TeamID.String))
 	// `200 OK`
 	return c.JSON(http.StatusOK, jwtBearer)
 }
@@ -182,6 +241,22 @@ func (router AdminRouter) ForgotPassword(c echo.Context) error {
 		return render.NewUnprocessable(ve)
 	}
 
+	// Every request is counted since each one sends an email.
+	limitKeys := []string{
+		throttle.KeyIP(c.RealIP()),
+		throttle.KeyEmail(params.Email),
+	}
+	d, err := router.pwResetLimiter.Check(limitKeys...)
+	if err != nil {
+		sugar.Error(err)
+	} else if !d.Allowed {
+		return xhttp.TooManyRequests(c, d)
+	}
+	err = router.pwResetLimiter.Hit(limitKeys...)
+	if err != nil {
+		sugar.Error(err)
+	}
+
 	// Find account by email
 	account, err := router.repo.BaseAccountByEmail(params.Email)
 	if err != nil {
@@ -284,5 +359,14 @@ func (router AdminRouter) ResetPassword(c echo.Context) error {
 		_ = router.repo.DisablePasswordReset(session.WithUsed())
 	}()
 
+	// Log out all devices since the password might be stolen.
+	if err := router.revokeSessions(baseAccount.ID, ""); err != nil {
+		return render.NewDBError(err)
+	}
+
+	router.audit.record(c, audit.NewEntry(audit.AdminActor(baseAccount.ID), audit.ActionPasswordReset).
+		WithTeam(baseAccount.TeamID.String).
+		WithTarget(baseAccount.ID))
+
 	return c.NoContent(http.StatusNoContent)
 }
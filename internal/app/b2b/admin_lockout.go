@@ -0,0 +1,186 @@
+package b2b
+
+import (
+	"database/sql"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/letter"
+	"github.com/FTChinese/ftacademy/pkg/throttle"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+)
+
+var (
+	// Failed logins per IP and email. An office might share
+	// one IP so the limit is loose while delays grow after
+	// a few failures.
+	loginRule = throttle.Rule{
+		Limit:     30,
+		Window:    15 * time.Minute,
+		Free:      3,
+		BaseDelay: time.Second,
+		MaxDelay:  30 * time.Second,
+	}
+	// Password reset letters per IP and email.
+	pwResetRule = throttle.Rule{
+		Limit:     5,
+		Window:    time.Hour,
+		Free:      1,
+		BaseDelay: 30 * time.Second,
+		MaxDelay:  10 * time.Minute,
+	}
+	// Failed logins per account. Reaching the threshold
+	// locks the account instead of delaying.
+	lockoutRule = throttle.Rule{
+		Window: admin.LockoutWindow,
+	}
+)
+
+func errAccountLocked() error {
+	return render.NewResponseError(
+		http.StatusLocked,
+		"Account is temporarily locked after too many failed logins. Please check your email to unlock it")
+}
+
+// isLocked checks whether an account has a lock in effect.
+// Database errors are only logged so that login is not
+// blocked by them.
+func (router AdminRouter) isLocked(adminID string) bool {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	_, err := router.repo.ActiveLockout(adminID)
+	if err == nil {
+		return true
+	}
+
+	if err != sql.ErrNoRows {
+		sugar.Error(err)
+	}
+
+	return false
+}
+
+// recordLoginFailure counts a wrong password against the
+// account. Returns the error to respond.
+func (router AdminRouter) recordLoginFailure(c echo.Context, adminID string) error {
+	if router.countLoginFailure(c, adminID) {
+		return errAccountLocked()
+	}
+
+	return render.NewForbidden("Incorrect credentials")
+}
+
+// countLoginFailure counts a wrong password or a wrong
+// second-factor code against the account and locks it once
+// admin.LockoutThreshold is reached. An email with unlock
+// link is sent to the admin.
+// Returns whether the account is locked.
+func (router AdminRouter) countLoginFailure(c echo.Context, adminID string) bool {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	err := router.lockoutLimiter.Hit(adminID)
+	if err != nil {
+		sugar.Error(err)
+		return false
+	}
+
+	n, err := router.lockoutLimiter.Count(adminID)
+	if err != nil {
+		sugar.Error(err)
+		return false
+	}
+	if n < admin.LockoutThreshold {
+		return false
+	}
+
+	lockout, err := admin.NewLockout(adminID, c.RealIP(), n)
+	if err != nil {
+		sugar.Error(err)
+		return false
+	}
+
+	err = router.repo.CreateLockout(lockout)
+	if err != nil {
+		sugar.Error(err)
+		return false
+	}
+
+	// Start counting again after the lock.
+	err = router.lockoutLimiter.Reset(adminID)
+	if err != nil {
+		sugar.Error(err)
+	}
+
+	sugar.Infof("Admin %s locked after %d failed logins from %s", adminID, n, lockout.ClientIP)
+
+	router.sendAccountLocked(adminID, lockout)
+
+	return true
+}
+
+// sendAccountLocked queues the letter with unlock link.
+// Mailer sends it in background so that the response is
+// not held by SMTP.
+func (router AdminRouter) sendAccountLocked(adminID string, lockout admin.Lockout) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	account, err := router.repo.BaseAccountByID(adminID)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	parcel, err := letter.AccountLockedParcel(account, lockout)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	err = router.post.Deliver(parcel)
+	if err != nil {
+		sugar.Error(err)
+	}
+}
+
+// UnlockAccount removes the lock with the token sent in
+// the email.
+//
+//	POST /api/b2b/auth/unlock/:token/
+//
+// Status code:
+// 204 No Content
+// 404 Not Found if the token does not exist, or the lock
+// already expired or removed.
+func (router AdminRouter) UnlockAccount(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	token := c.Param("token")
+
+	lockout, err := router.repo.LockoutByToken(token)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	if !lockout.IsActive() {
+		return render.NewNotFound("Account already unlocked")
+	}
+
+	err = router.repo.UnlockAccount(lockout.WithUnlocked())
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	err = router.lockoutLimiter.Reset(lockout.AdminID)
+	if err != nil {
+		sugar.Error(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,273 @@
+package b2b
+
+import (
+	"database/sql"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/letter"
+	"github.com/FTChinese/ftacademy/internal/repository/adminrepo"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// ListTeamMembers shows owner and co-admins of a team.
+func (router AdminRouter) ListTeamMembers(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	list, err := router.repo.ListTeamMembers(claims.TeamID.String)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// UpdateTeamMember changes a co-admin's role and unit.
+// Input:
+// role: 'billing' | 'licence_manager' | 'viewer';
+// unitId?: string;
+func (router AdminRouter) UpdateTeamMember(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+	id := c.Param("id")
+
+	var params input.TeamMemberParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	if ve := params.ValidateRole(admin.IsValidMemberRole); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+	if err := ensureUnit(router.repo, params.UnitID, claims.TeamID.String); err != nil {
+		return err
+	}
+
+	// Owner is not found here, so it cannot be changed.
+	m, err := router.repo.LoadTeamMember(claims.TeamID.String, id)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	m = m.WithRole(admin.Role(params.Role), params.UnitID)
+	err = router.repo.UpdateMemberRole(m)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, m)
+}
+
+// RemoveTeamMember removes a co-admin from team.
+func (router AdminRouter) RemoveTeamMember(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+	id := c.Param("id")
+
+	m, err := router.repo.LoadTeamMember(claims.TeamID.String, id)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	err = router.repo.RemoveTeamMember(m)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListAdminInvitations shows pending invitations to co-admins.
+func (router AdminRouter) ListAdminInvitations(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	list, err := router.repo.ListAdminInvitations(claims.TeamID.String)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// InviteTeamMember sends an email to invite a co-admin.
+// Input:
+// email: string;
+// role: 'billing' | 'licence_manager' | 'viewer';
+// unitId?: string;
+func (router AdminRouter) InviteTeamMember(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.TeamMemberParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	if ve := params.ValidateInvitation(admin.IsValidMemberRole); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+	if err := ensureUnit(router.repo, params.UnitID, claims.TeamID.String); err != nil {
+		return err
+	}
+
+	// An admin could only belong to one team.
+	existing, err := router.repo.BaseAccountByEmail(params.Email)
+	if err != nil && err != sql.ErrNoRows {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+	if existing.TeamID.Valid {
+		return render.NewUnprocessable(&render.ValidationError{
+			Message: "This email already belongs to a team",
+			Field:   "email",
+			Code:    render.CodeAlreadyExists,
+		})
+	}
+
+	inv, err := admin.NewAdminInvitation(params, admin.Creator{
+		AdminID: claims.AdminID,
+		TeamID:  claims.TeamID.String,
+	})
+	if err != nil {
+		sugar.Error(err)
+		return render.NewInternalError(err.Error())
+	}
+
+	err = router.repo.CreateAdminInvitation(inv)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	router.sendAdminInvitation(inv, claims.AdminID)
+
+	return c.JSON(http.StatusOK, inv)
+}
+
+// sendAdminInvitation queues the letter inviting a person to
+// join the team as an admin.
+func (router AdminRouter) sendAdminInvitation(inv admin.AdminInvitation, inviterID string) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	profile, err := router.repo.LoadB2BAdminProfile(inviterID)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	parcel, err := letter.AdminInvitationParcel(inv, profile)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	err = router.post.Deliver(parcel)
+	if err != nil {
+		sugar.Error(err)
+	}
+}
+
+// RevokeAdminInvitation cancels a pending invitation.
+func (router AdminRouter) RevokeAdminInvitation(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+	id := c.Param("id")
+
+	inv, err := router.repo.LoadAdminInvitation(id, claims.TeamID.String)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	if inv.Status != admin.AdminInvitationPending {
+		return render.NewBadRequest("Only pending invitation could be revoked")
+	}
+
+	inv = inv.WithStatus(admin.AdminInvitationRevoked)
+	err = router.repo.UpdateAdminInvitation(inv)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, inv)
+}
+
+// VerifyAdminInvitation shows which team is inviting so that
+// the invitee could sign up or log in before accepting it.
+func (router AdminRouter) VerifyAdminInvitation(c echo.Context) error {
+	token := c.Param("token")
+
+	inv, err := router.repo.AdminInvitationByToken(token)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	if !inv.IsAcceptable() {
+		return render.NewNotFound("Invitation is expired or no longer valid")
+	}
+
+	team, err := router.repo.RetrieveTeam(inv.TeamID)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, admin.AdminInvitationVerified{
+		AdminInvitation: inv,
+		TeamName:        team.OrgName,
+	})
+}
+
+// AcceptAdminInvitation adds the logged-in admin to the
+// inviting team. A new JWT is returned containing the team.
+func (router AdminRouter) AcceptAdminInvitation(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+	token := c.Param("token")
+
+	inv, err := router.repo.AdminInvitationByToken(token)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	account, err := router.repo.BaseAccountByID(claims.AdminID)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	_, err = router.repo.AcceptAdminInvitation(inv, account)
+	if err != nil {
+		sugar.Error(err)
+		switch err {
+		case adminrepo.ErrNotInvitee:
+			return render.NewForbidden(err.Error())
+		case adminrepo.ErrAlreadyInTeam, adminrepo.ErrInvitationInvalid:
+			return render.NewBadRequest(err.Error())
+		default:
+			return render.NewDBError(err)
+		}
+	}
+
+	account, err = router.repo.BaseAccountByID(claims.AdminID)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	bearer, err := router.guard.CreatePassport(account, claims.SessionID())
+	if err != nil {
+		return render.NewInternalError(err.Error())
+	}
+
+	return c.JSON(http.StatusOK, bearer)
+}
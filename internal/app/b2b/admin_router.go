@@ -6,11 +6,13 @@ import (
 	"github.com/FTChinese/ftacademy/pkg/config"
 	"github.com/FTChinese/ftacademy/pkg/db"
 	"github.com/FTChinese/ftacademy/pkg/postman"
+	"github.com/FTChinese/ftacademy/pkg/throttle"
 	"github.com/FTChinese/ftacademy/pkg/xhttp"
 	"github.com/FTChinese/go-rest/render"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 	"log"
+	"net/http"
 )
 
 // AdminRouter defines what an admin can do before login.
@@ -19,19 +21,36 @@ type AdminRouter struct {
 	repo   adminrepo.Env
 	post   postman.Postman
 	logger *zap.Logger
+	// Limits login and password reset requests per IP and email.
+	loginLimiter   throttle.Limiter
+	pwResetLimiter throttle.Limiter
+	// Counts failed logins per account to lock it.
+	lockoutLimiter throttle.Limiter
+	// Revoked sessions are rejected by guard.
+	revocation adminrepo.RevocationList
+	audit      auditTrail
 }
 
 // NewAdminRouter creates a new instance of AdminRouter.
-func NewAdminRouter(dbs db.ReadWriteMyDBs, p postman.Postman, logger *zap.Logger) AdminRouter {
+func NewAdminRouter(dbs db.ReadWriteMyDBs, p postman.Postman, store throttle.Store, logger *zap.Logger) AdminRouter {
+	repo := adminrepo.NewEnv(dbs, logger)
+	revocation := adminrepo.NewRevocationList(repo)
+
 	return AdminRouter{
 		guard: admin.NewJWTGuard(
 			config.
 				MustGetB2BAppKey().
-				GetJWTKey(),
+				MustGetJWTKeyring(admin.AccessTokenTTL),
+			revocation,
 		),
-		repo:   adminrepo.NewEnv(dbs, logger),
-		post:   p,
-		logger: logger,
+		repo:           repo,
+		post:           p,
+		logger:         logger,
+		loginLimiter:   throttle.NewLimiter("admin_login", store, loginRule),
+		pwResetLimiter: throttle.NewLimiter("admin_pw_reset", store, pwResetRule),
+		lockoutLimiter: throttle.NewLimiter("admin_lockout", store, lockoutRule),
+		revocation:     revocation,
+		audit:          newAuditTrail(dbs, logger),
 	}
 }
 
@@ -63,7 +82,68 @@ func (router AdminRouter) RequireTeamSet(next echo.HandlerFunc) echo.HandlerFunc
 			return render.NewUnauthorized("Organization team is required")
 		}
 
+		// Role might be changed or removed by team owner
+		// after JWT is issued.
+		scope, err := router.repo.MemberScope(claims.TeamID.String, claims.AdminID)
+		if err != nil {
+			log.Printf("Error retrieving team role %v", err)
+			return render.NewDBError(err)
+		}
+
+		if scope.Role == admin.RoleNull {
+			return render.NewForbidden("You are not an admin of this team")
+		}
+
+		// Admin could still use account routes to enable it.
+		if scope.LacksTwoFactor() {
+			return render.NewForbidden("Your team requires two-factor authentication. Please enable it in account settings")
+		}
+
+		claims.MemberScope = scope
 		c.Set(xhttp.KeyCtxClaims, claims)
 		return next(c)
 	}
 }
+
+// RequireRole checks whether the admin's role could access
+// an area. GET requests need read permission while others
+// need write permission. Unit managers are only allowed
+// into licences area.
+// It must be used after RequireTeamSet.
+func (router AdminRouter) RequireRole(area admin.Area) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims := getAdminClaims(c)
+
+			if !claims.MemberScope.CanRequest(area, c.Request().Method) {
+				return render.NewForbidden("Your role in this team does not allow this action")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequireTeamScope rejects unit managers from tools that
+// work on the whole team's licence pool, like join links
+// and redeem codes.
+// It must be used after RequireTeamSet.
+func (router AdminRouter) RequireTeamScope(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		claims := getAdminClaims(c)
+
+		if claims.IsUnitScoped() {
+			return render.NewForbidden("This is only available to team-wide admins")
+		}
+
+		return next(c)
+	}
+}
+
+// JWKS publishes public keys so that other services could
+// verify admin JWT without the secret.
+//
+//	GET /api/b2b/auth/jwks/
+func (router AdminRouter) JWKS(c echo.Context) error {
+	return c.JSON(http.StatusOK, router.guard.JWKS())
+}
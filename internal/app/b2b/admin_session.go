@@ -0,0 +1,231 @@
+package b2b
+
+import (
+	"database/sql"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// startSession creates a server-side session upon login and
+// issues both access and refresh token.
+func (router AdminRouter) startSession(c echo.Context, a admin.BaseAccount) (admin.Passport, error) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	s, refreshToken, err := admin.NewSession(a.ID, c.Request().UserAgent(), c.RealIP())
+	if err != nil {
+		sugar.Error(err)
+		return admin.Passport{}, render.NewInternalError(err.Error())
+	}
+
+	err = router.repo.CreateSession(s)
+	if err != nil {
+		sugar.Error(err)
+		return admin.Passport{}, render.NewDBError(err)
+	}
+
+	pp, err := router.guard.CreatePassport(a, s.ID)
+	if err != nil {
+		sugar.Error(err)
+		return admin.Passport{}, render.NewInternalError(err.Error())
+	}
+
+	return pp.WithRefresh(refreshToken, s), nil
+}
+
+// revokeSessions revokes all sessions of an admin except
+// the one specified, which could be empty.
+func (router AdminRouter) revokeSessions(adminID string, except string) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	list, err := router.repo.ListActiveSessions(adminID)
+	if err != nil {
+		sugar.Error(err)
+		return err
+	}
+
+	for _, s := range list {
+		if s.ID == except {
+			continue
+		}
+
+		err := router.repo.RevokeSession(adminID, s.ID)
+		if err != nil && err != sql.ErrNoRows {
+			sugar.Error(err)
+			return err
+		}
+		router.revocation.Revoked(s.ID)
+	}
+
+	return nil
+}
+
+// RefreshSession exchanges a refresh token for a new pair
+// of tokens. The refresh token can only be used once.
+//
+//	POST /api/b2b/auth/refresh/
+//
+// Input: {refreshToken: string}
+//
+// Status code:
+// 401 if the token is invalid, or the session is revoked or
+// expired. Replaying a used token revokes the session.
+func (router AdminRouter) RefreshSession(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	var params input.RefreshTokenParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	invalid := render.NewUnauthorized(admin.ErrSessionRevoked.Error())
+
+	id, ok := admin.SessionIDOfRefresh(params.RefreshToken)
+	if !ok {
+		return invalid
+	}
+
+	s, err := router.repo.LoadSession(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return invalid
+		}
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	if !s.IsActive() {
+		return invalid
+	}
+
+	if !s.Matches(params.RefreshToken) {
+		// A token rotated out is used again: either client or
+		// attacker holds a stolen copy. Kill the session.
+		if s.IsReplayed(params.RefreshToken) {
+			router.killReplayedSession(s)
+		}
+		return invalid
+	}
+
+	s, refreshToken, err := s.Rotate(c.RealIP())
+	if err != nil {
+		sugar.Error(err)
+		return render.NewInternalError(err.Error())
+	}
+
+	err = router.repo.RotateSession(s)
+	if err != nil {
+		// Another request rotated the same token first.
+		if err == sql.ErrNoRows {
+			router.killReplayedSession(s)
+			return invalid
+		}
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	account, err := router.repo.BaseAccountByID(s.AdminID)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	pp, err := router.guard.CreatePassport(account, s.ID)
+	if err != nil {
+		return render.NewInternalError(err.Error())
+	}
+
+	return c.JSON(http.StatusOK, pp.WithRefresh(refreshToken, s))
+}
+
+// killReplayedSession revokes a session whose refresh token
+// is used more than once.
+func (router AdminRouter) killReplayedSession(s admin.Session) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	sugar.Infof("Refresh token replayed for session %s", s.ID)
+	if err := router.repo.RevokeSession(s.AdminID, s.ID); err != nil {
+		sugar.Error(err)
+	}
+	router.revocation.Revoked(s.ID)
+}
+
+// Logout revokes current session.
+//
+//	POST /api/b2b/auth/logout/
+func (router AdminRouter) Logout(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	err := router.repo.RevokeSession(claims.AdminID, claims.SessionID())
+	if err != nil && err != sql.ErrNoRows {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+	router.revocation.Revoked(claims.SessionID())
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListSessions shows devices the admin is logged in.
+//
+//	GET /api/b2b/account/sessions/
+func (router AdminRouter) ListSessions(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	list, err := router.repo.ListActiveSessions(claims.AdminID)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	for i, s := range list {
+		list[i] = s.WithCurrent(claims.SessionID())
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// RevokeSession logs out a device.
+//
+//	DELETE /api/b2b/account/sessions/:id/
+func (router AdminRouter) RevokeSession(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+	id := c.Param("id")
+
+	err := router.repo.RevokeSession(claims.AdminID, id)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+	router.revocation.Revoked(id)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RevokeOtherSessions logs out all devices except current
+// one.
+//
+//	POST /api/b2b/account/sessions/revoke-others/
+func (router AdminRouter) RevokeOtherSessions(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	err := router.revokeSessions(claims.AdminID, claims.SessionID())
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
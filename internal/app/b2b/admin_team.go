@@ -2,6 +2,7 @@ package b2b
 
 import (
 	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
 	"github.com/FTChinese/ftacademy/internal/pkg/input"
 	"github.com/FTChinese/go-rest/render"
 	"github.com/labstack/echo/v4"
@@ -20,6 +21,11 @@ func (router AdminRouter) CreateTeam(c echo.Context) error {
 
 	claims := getAdminClaims(c)
 
+	// Owner or co-admin of a team cannot create another one.
+	if claims.TeamID.Valid {
+		return render.NewBadRequest("You already belong to a team")
+	}
+
 	var params input.TeamParams
 	if err := c.Bind(&params); err != nil {
 		sugar.Error(err)
@@ -91,5 +97,10 @@ func (router AdminRouter) UpdateTeam(c echo.Context) error {
 		return render.NewDBError(err)
 	}
 
+	router.audit.record(c, audit.NewEntry(claimsActor(claims), audit.ActionTeamUpdate).
+		WithTeam(teamUpdated.ID).
+		WithTarget(teamUpdated.ID).
+		WithChange(currentTeam, teamUpdated))
+
 	return c.JSON(http.StatusOK, teamUpdated)
 }
@@ -0,0 +1,430 @@
+package b2b
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/repository/adminrepo"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/labstack/echo/v4"
+	"github.com/skip2/go-qrcode"
+	"net/http"
+	"time"
+)
+
+// challengeTwoFactor creates a login challenge if the admin
+// enabled 2FA. Nil is returned if 2FA is not enabled.
+func (router AdminRouter) challengeTwoFactor(adminID string) (*admin.LoginChallengeIssued, error) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	f, err := router.repo.LoadTwoFactor(adminID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		sugar.Error(err)
+		return nil, render.NewDBError(err)
+	}
+
+	if !f.Enabled {
+		return nil, nil
+	}
+
+	ch, err := admin.NewLoginChallenge(adminID)
+	if err != nil {
+		sugar.Error(err)
+		return nil, render.NewInternalError(err.Error())
+	}
+
+	err = router.repo.CreateLoginChallenge(ch)
+	if err != nil {
+		sugar.Error(err)
+		return nil, render.NewDBError(err)
+	}
+
+	issued := ch.Issued()
+	return &issued, nil
+}
+
+// verifySecondFactor checks a TOTP code, or a recovery code
+// if allowRecovery is true. The step of an accepted TOTP
+// code is saved so that it cannot be replayed.
+func (router AdminRouter) verifySecondFactor(f admin.TwoFactor, code string, allowRecovery bool) (bool, error) {
+	updated, ok := f.Verify(code, time.Now())
+	if ok {
+		err := router.repo.UpdateTwoFactor(updated)
+		switch err {
+		case nil:
+			return true, nil
+		case adminrepo.ErrTwoFactorStepUsed:
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+
+	if !allowRecovery || !f.Enabled {
+		return false, nil
+	}
+
+	return router.repo.UseRecoveryCode(f.AdminID, code)
+}
+
+func invalidCodeError() error {
+	return render.NewUnprocessable(&render.ValidationError{
+		Message: "Verification code is incorrect",
+		Field:   "code",
+		Code:    render.CodeInvalid,
+	})
+}
+
+// LoginTwoFactor is the second step of login for admins
+// with 2FA enabled.
+// Input:
+// challengeToken: string;
+// code: string; TOTP code or a recovery code.
+func (router AdminRouter) LoginTwoFactor(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	var params input.TwoFactorLoginParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	ch, err := router.repo.LoginChallenge(params.ChallengeToken)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return render.NewUnauthorized("Login session expired. Please log in again")
+		}
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+	if !ch.IsUsable() {
+		return render.NewUnauthorized("Login session expired. Please log in again")
+	}
+
+	// Account might be locked by failures of other challenges.
+	if router.isLocked(ch.AdminID) {
+		return errAccountLocked()
+	}
+
+	// Take an attempt before checking the code so that
+	// concurrent requests cannot try more than allowed.
+	ok, err := router.repo.TakeChallengeAttempt(ch.Token)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+	if !ok {
+		return render.NewUnauthorized("Login session expired. Please log in again")
+	}
+
+	f, err := router.repo.LoadTwoFactor(ch.AdminID)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	ok, err = router.verifySecondFactor(f, params.Code, true)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+	if !ok {
+		// Wrong codes count towards lockout the same as
+		// wrong passwords, across challenges.
+		if router.countLoginFailure(c, ch.AdminID) {
+			return errAccountLocked()
+		}
+		return invalidCodeError()
+	}
+
+	if err := router.repo.DeleteLoginChallenge(ch.Token); err != nil {
+		sugar.Error(err)
+	}
+
+	if err := router.lockoutLimiter.Reset(ch.AdminID); err != nil {
+		sugar.Error(err)
+	}
+
+	account, err := router.repo.BaseAccountByID(ch.AdminID)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	bearer, err := router.startSession(c, account)
+	if err != nil {
+		return err
+	}
+
+	router.audit.record(c, audit.NewEntry(audit.AdminActor(account.ID), audit.ActionLogin).
+		WithTeam(account.TeamID.String))
+
+	return c.JSON(http.StatusOK, bearer)
+}
+
+// TwoFactorStatus shows whether 2FA is enabled for current
+// admin.
+func (router AdminRouter) TwoFactorStatus(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	s, err := router.repo.TwoFactorStatus(claims.AdminID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.JSON(http.StatusOK, admin.TwoFactorStatus{})
+		}
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, s)
+}
+
+// SetupTwoFactor generates a new secret and returns it as a
+// QR code to be scanned by authenticator app.
+// 2FA is not enabled until EnableTwoFactor is called.
+func (router AdminRouter) SetupTwoFactor(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	current, err := router.repo.LoadTwoFactor(claims.AdminID)
+	if err != nil && err != sql.ErrNoRows {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+	if current.Enabled {
+		return render.NewBadRequest("Two-factor authentication is already enabled")
+	}
+
+	account, err := router.repo.BaseAccountByID(claims.AdminID)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	f, err := admin.NewTwoFactor(claims.AdminID)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewInternalError(err.Error())
+	}
+
+	uri := f.URI(account.Email)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewInternalError(err.Error())
+	}
+
+	err = router.repo.SaveTwoFactor(f)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, admin.TwoFactorSetup{
+		Secret: f.Secret,
+		URI:    uri,
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	})
+}
+
+// EnableTwoFactor verifies the first code from
+// authenticator app and returns recovery codes which are
+// shown only once.
+// Input:
+// code: string;
+func (router AdminRouter) EnableTwoFactor(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.TwoFactorCodeParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	f, err := router.repo.LoadTwoFactor(claims.AdminID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return render.NewBadRequest("Set up two-factor authentication first")
+		}
+		return render.NewDBError(err)
+	}
+	if f.Enabled {
+		return render.NewBadRequest("Two-factor authentication is already enabled")
+	}
+
+	f, ok := f.Verify(params.Code, time.Now())
+	if !ok {
+		return invalidCodeError()
+	}
+
+	plain, codes, err := admin.NewRecoveryCodes(claims.AdminID)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewInternalError(err.Error())
+	}
+
+	err = router.repo.EnableTwoFactor(f.WithEnabled(), codes)
+	if err != nil {
+		if err == adminrepo.ErrTwoFactorStepUsed {
+			return invalidCodeError()
+		}
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, admin.RecoveryCodes{
+		Codes: plain,
+	})
+}
+
+// DisableTwoFactor turns off 2FA after verifying a code.
+// It is not allowed if admin's team requires 2FA.
+// Input:
+// code: string; TOTP code or a recovery code.
+func (router AdminRouter) DisableTwoFactor(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.TwoFactorCodeParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	if claims.TeamID.Valid {
+		team, err := router.repo.RetrieveTeam(claims.TeamID.String)
+		if err != nil {
+			return render.NewDBError(err)
+		}
+		if team.RequireTwoFactor {
+			return render.NewForbidden("Your team requires two-factor authentication")
+		}
+	}
+
+	f, err := router.repo.LoadTwoFactor(claims.AdminID)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	ok, err := router.verifySecondFactor(f, params.Code, true)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+	if !ok {
+		return invalidCodeError()
+	}
+
+	err = router.repo.DisableTwoFactor(claims.AdminID)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes invalidates existing recovery codes
+// and returns a new set.
+// Input:
+// code: string; TOTP code.
+func (router AdminRouter) RegenerateRecoveryCodes(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.TwoFactorCodeParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	f, err := router.repo.LoadTwoFactor(claims.AdminID)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+	if !f.Enabled {
+		return render.NewBadRequest("Two-factor authentication is not enabled")
+	}
+
+	f, ok := f.Verify(params.Code, time.Now())
+	if !ok {
+		return invalidCodeError()
+	}
+
+	plain, codes, err := admin.NewRecoveryCodes(claims.AdminID)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewInternalError(err.Error())
+	}
+
+	err = router.repo.EnableTwoFactor(f, codes)
+	if err != nil {
+		if err == adminrepo.ErrTwoFactorStepUsed {
+			return invalidCodeError()
+		}
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, admin.RecoveryCodes{
+		Codes: plain,
+	})
+}
+
+// UpdateTwoFactorPolicy lets team owner require 2FA for all
+// admins. Owner must have 2FA enabled to turn it on.
+// Input:
+// required: boolean;
+func (router AdminRouter) UpdateTwoFactorPolicy(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+	if claims.Role != admin.RoleOwner {
+		return render.NewForbidden("Only team owner could change this setting")
+	}
+
+	var params input.TwoFactorPolicyParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	if params.Required && !claims.TwoFactorEnabled {
+		return render.NewBadRequest("Enable two-factor authentication for yourself first")
+	}
+
+	team, err := router.repo.RetrieveTeam(claims.TeamID.String)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	team = team.WithTwoFactorRequired(params.Required)
+	err = router.repo.UpdateTwoFactorPolicy(team)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, team)
+}
@@ -0,0 +1,147 @@
+package b2b
+
+import (
+	"database/sql"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/guregu/null"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type unitLoader interface {
+	LoadUnit(id, teamID string) (admin.Unit, error)
+}
+
+// ensureUnit checks an optional unit id belongs to the team.
+func ensureUnit(repo unitLoader, unitID null.String, teamID string) error {
+	if !unitID.Valid {
+		return nil
+	}
+
+	_, err := repo.LoadUnit(unitID.String, teamID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return render.NewUnprocessable(&render.ValidationError{
+				Message: "Unit not found",
+				Field:   "unitId",
+				Code:    render.CodeInvalid,
+			})
+		}
+		return render.NewDBError(err)
+	}
+
+	return nil
+}
+
+// ListUnits shows departments of a team.
+func (router AdminRouter) ListUnits(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	list, err := router.repo.ListUnits(claims.TeamID.String)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// CreateUnit adds a department to team.
+// Input:
+// name: string;
+// budget?: number;
+func (router AdminRouter) CreateUnit(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.UnitParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	u := admin.NewUnit(claims.TeamID.String, params)
+	err := router.repo.CreateUnit(u)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, u)
+}
+
+// UpdateUnit changes name or budget of a unit.
+func (router AdminRouter) UpdateUnit(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+	id := c.Param("id")
+
+	var params input.UnitParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	u, err := router.repo.LoadUnit(id, claims.TeamID.String)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	u = u.Update(params)
+	err = router.repo.UpdateUnit(u)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, u)
+}
+
+// DeleteUnit removes a unit and moves everything under it
+// back to team level.
+func (router AdminRouter) DeleteUnit(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+	id := c.Param("id")
+
+	u, err := router.repo.LoadUnit(id, claims.TeamID.String)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	err = router.repo.DeleteUnit(u)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// UnitReport shows licence utilisation and spend of each
+// unit against its budget.
+func (router AdminRouter) UnitReport(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	report, err := router.repo.UnitReport(claims.TeamID.String)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,118 @@
+package b2b
+
+import (
+	"github.com/FTChinese/ftacademy/internal/access"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
+	"github.com/FTChinese/ftacademy/internal/repository/auditrepo"
+	"github.com/FTChinese/ftacademy/pkg/db"
+	"github.com/FTChinese/ftacademy/pkg/xhttp"
+	gorest "github.com/FTChinese/go-rest"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/guregu/null"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// auditTrail appends entries to the audit log.
+// Failure to record is only logged so that it never fails
+// the action being audited.
+type auditTrail struct {
+	repo   auditrepo.Env
+	logger *zap.Logger
+}
+
+func newAuditTrail(dbs db.ReadWriteMyDBs, logger *zap.Logger) auditTrail {
+	return auditTrail{
+		repo:   auditrepo.NewEnv(dbs, logger),
+		logger: logger,
+	}
+}
+
+// record saves e in background with IP of the request.
+func (a auditTrail) record(c echo.Context, e audit.Entry) {
+	go a.save(e.WithIP(c.RealIP()))
+}
+
+// save is used directly by background jobs, which have
+// no request.
+func (a auditTrail) save(e audit.Entry) {
+	defer a.logger.Sync()
+	sugar := a.logger.Sugar()
+
+	if err := a.repo.CreateEntry(e); err != nil {
+		sugar.Error(err)
+	}
+}
+
+// claimsActor tells whether an admin or an API key made
+// the request.
+func claimsActor(claims admin.PassportClaims) audit.Actor {
+	if claims.APIKeyID != "" {
+		return audit.APIKeyActor(claims.APIKeyID)
+	}
+
+	return audit.AdminActor(claims.AdminID)
+}
+
+// cmsEntry is used by endpoints guarded by access.Guard.
+// Besides the token, it records the staff using it.
+func cmsEntry(c echo.Context, action audit.Action) audit.Entry {
+	o, _ := c.Get(xhttp.KeyCtxOAuth).(access.OAuth)
+
+	return audit.NewEntry(audit.CMSActor(o.Fingerprint), action).
+		WithOperator(admin.Operator{
+			Name: null.NewString(o.Operator, o.Operator != ""),
+		})
+}
+
+// listEntries is shared by team owners and CMS.
+// teamID is null in CMS so that the log of all teams,
+// optionally filtered by the team_id query, is shown.
+//
+// Query: ?action=<string>&actor_id=<string>&target_id=<string>&page=<int>&per_page=<int>
+func (a auditTrail) listEntries(c echo.Context, teamID null.String) error {
+	var page gorest.Pagination
+	if err := c.Bind(&page); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	query := func(name string) null.String {
+		v := c.QueryParam(name)
+		return null.NewString(v, v != "")
+	}
+
+	if !teamID.Valid {
+		teamID = query("team_id")
+	}
+
+	list, err := a.repo.ListEntries(audit.Filter{
+		TeamID:   teamID,
+		Action:   query("action"),
+		ActorID:  query("actor_id"),
+		TargetID: query("target_id"),
+	}, page)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// ListAuditLog shows actions taken on the team of current owner.
+// Query: ?action=<string>&actor_id=<string>&target_id=<string>&page=<int>&per_page=<int>
+func (router AdminRouter) ListAuditLog(c echo.Context) error {
+	claims := getAdminClaims(c)
+	if claims.Role != admin.RoleOwner {
+		return render.NewForbidden("Only team owner could view the audit log")
+	}
+
+	return router.audit.listEntries(c, claims.TeamID)
+}
+
+// ListAuditLog shows actions across all teams.
+// Query: ?team_id=<string>&action=<string>&actor_id=<string>&target_id=<string>&page=<int>&per_page=<int>
+func (router CMSRouter) ListAuditLog(c echo.Context) error {
+	return router.audit.listEntries(c, null.String{})
+}
@@ -0,0 +1,147 @@
+package b2b
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/checkout"
+	"github.com/FTChinese/ftacademy/internal/pkg/export"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// Export endpoints download all rows of a list as a
+// spreadsheet. They accept:
+// ?format=csv|xlsx&lang=zh|en
+// lang falls back to Accept-Language header.
+
+// exporter writes response headers lazily upon the first
+// row so that an error from db query could still be
+// responded as JSON.
+type exporter struct {
+	c      echo.Context
+	name   string
+	format export.Format
+	lang   export.Lang
+	header export.Header
+	w      export.Writer
+}
+
+func newExporter(c echo.Context, name string, header export.Header) *exporter {
+	lang := c.QueryParam("lang")
+	if lang == "" {
+		lang = c.Request().Header.Get("Accept-Language")
+	}
+
+	return &exporter{
+		c:      c,
+		name:   name,
+		format: export.ParseFormat(c.QueryParam("format")),
+		lang:   export.ParseLang(lang),
+		header: header,
+	}
+}
+
+func (e *exporter) started() bool {
+	return e.w != nil
+}
+
+func (e *exporter) start() error {
+	resp := e.c.Response()
+	resp.Header().Set(echo.HeaderContentType, e.format.ContentType())
+	resp.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+e.format.FileName(e.name)+`"`)
+	resp.WriteHeader(http.StatusOK)
+
+	w, err := export.NewWriter(resp, e.format, e.name)
+	if err != nil {
+		return err
+	}
+	e.w = w
+
+	return e.w.Write(e.header.Localize(e.lang))
+}
+
+func (e *exporter) Write(row []string) error {
+	if !e.started() {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	return e.w.Write(row)
+}
+
+// finish closes the file, or turns err into a response if
+// nothing is sent yet.
+func (e *exporter) finish(err error) error {
+	if err != nil {
+		if !e.started() {
+			return render.NewDBError(err)
+		}
+		// Response is already on the way. The client will
+		// get a truncated file.
+		return err
+	}
+
+	if !e.started() {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	return e.w.Close()
+}
+
+// ExportOrders downloads all orders of the team.
+func (router SubsRouter) ExportOrders(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+	ex := newExporter(c, "orders", checkout.OrderExportHeader)
+
+	err := router.repo.StreamOrders(claims.TeamID.String, func(o checkout.Order) error {
+		return ex.Write(o.ExportRow(ex.lang))
+	})
+	if err != nil {
+		sugar.Error(err)
+	}
+
+	return ex.finish(err)
+}
+
+// ExportLicences downloads licences visible to the admin,
+// with assignee's email, tier and expiration time.
+func (router SubsRouter) ExportLicences(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+	ex := newExporter(c, "licences", licence.LicenceExportHeader)
+
+	err := router.repo.StreamLicences(claims.TeamID.String, claims.UnitID, func(l licence.ExpandedLicence) error {
+		return ex.Write(l.ExportRow(ex.lang))
+	})
+	if err != nil {
+		sugar.Error(err)
+	}
+
+	return ex.finish(err)
+}
+
+// ExportInvitations downloads invitations visible to the admin.
+func (router SubsRouter) ExportInvitations(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+	ex := newExporter(c, "invitations", licence.InvitationExportHeader)
+
+	err := router.repo.StreamInvitations(claims.TeamID.String, claims.UnitID, func(i licence.Invitation) error {
+		return ex.Write(i.ExportRow(ex.lang))
+	})
+	if err != nil {
+		sugar.Error(err)
+	}
+
+	return ex.finish(err)
+}
@@ -3,10 +3,12 @@ package b2b
 import (
 	"database/sql"
 	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
 	"github.com/FTChinese/ftacademy/internal/pkg/input"
 	"github.com/FTChinese/ftacademy/internal/pkg/letter"
 	"github.com/FTChinese/ftacademy/internal/pkg/licence"
 	"github.com/FTChinese/ftacademy/internal/pkg/reader"
+	"github.com/FTChinese/ftacademy/internal/pkg/webhook"
 	"github.com/FTChinese/ftacademy/internal/repository/subsrepo"
 	"github.com/FTChinese/ftacademy/pkg/validator"
 	gorest "github.com/FTChinese/go-rest"
@@ -41,6 +43,36 @@ func (router SubsRouter) FindMembership(c echo.Context) error {
 	return c.JSON(http.StatusOK, m)
 }
 
+// invitationError converts errors of creating an invitation
+// into responses.
+func invitationError(err error) error {
+	switch err {
+	case subsrepo.ErrLicenceUnavailable:
+		return &render.ValidationError{
+			Message: "The licence is already taken",
+			Field:   "licence",
+			Code:    "already_taken",
+		}
+
+	case subsrepo.ErrInviteeMismatch:
+		return &render.ValidationError{
+			Message: err.Error(),
+			Field:   "invitee",
+			Code:    render.CodeAlreadyExists,
+		}
+
+	case subsrepo.ErrAlreadyMember:
+		return &render.ValidationError{
+			Message: "The email to accept the invitation is already a valid member",
+			Field:   "membership",
+			Code:    render.CodeAlreadyExists,
+		}
+
+	default:
+		return render.NewDBError(err)
+	}
+}
+
 // CreateInvitation creates an invitation for a licence and send it to a user.
 // Input:
 // email: string,
@@ -49,9 +81,6 @@ func (router SubsRouter) FindMembership(c echo.Context) error {
 // Returns a licence.ExpandedLicence instance with its LatestInvitation
 // field populated with the invitation created here.
 func (router SubsRouter) CreateInvitation(c echo.Context) error {
-	defer router.logger.Sync()
-	sugar := router.logger.Sugar()
-
 	claims := getAdminClaims(c)
 
 	var params input.InvitationParams
@@ -66,68 +95,19 @@ func (router SubsRouter) CreateInvitation(c echo.Context) error {
 	// Create invitation and get the update licence.
 	lic, err := router.repo.CreateInvitation(params, claims)
 	if err != nil {
-		switch err {
-		case subsrepo.ErrLicenceUnavailable:
-			return &render.ValidationError{
-				Message: "The licence is already taken",
-				Field:   "licence",
-				Code:    "already_taken",
-			}
-
-		case subsrepo.ErrInviteeMismatch:
-			return &render.ValidationError{
-				Message: err.Error(),
-				Field:   "invitee",
-				Code:    render.CodeAlreadyExists,
-			}
-
-		case subsrepo.ErrAlreadyMember:
-			return &render.ValidationError{
-				Message: "The email to accept the invitation is already a valid member",
-				Field:   "membership",
-				Code:    render.CodeAlreadyExists,
-			}
-
-		default:
-			return render.NewDBError(err)
-		}
+		return invitationError(err)
 	}
 
-	// Send invitation letter
-	go func() {
-		sugar.Info("Start sending invitation email")
-		assignee, err := router.repo.FindAssignee(params.Email)
-		if err != nil {
-			sugar.Error(err)
-			return
-		}
-		// Find admin so that we could tell user who send the invitation.
-		sugar.Info("Start retrieving b2b admin profile")
-		adminProfile, err := router.repo.LoadB2BAdminProfile(claims.AdminID)
-		if err != nil {
-			sugar.Error(err)
-			return
-		}
+	go router.hooks.Emit(claims.TeamID.String, webhook.EventInvitationCreated, lic.LatestInvitation)
 
-		parcel, err := letter.InvitationParcel(
-			assignee,
-			lic,
-			adminProfile)
+	router.audit.record(c, audit.NewEntry(claimsActor(claims), audit.ActionInvitationCreate).
+		WithTeam(claims.TeamID.String).
+		WithTarget(lic.LatestInvitation.ID).
+		WithChange(nil, lic.LatestInvitation))
 
-		sugar.Infof("Invitation parcel %v", parcel)
-
-		if err != nil {
-			sugar.Error(err)
-			return
-		}
-
-		err = router.post.Deliver(parcel)
-		if err != nil {
-			sugar.Error(err)
-		} else {
-			sugar.Infof("Invitation letter sent to %s", assignee.Email.String)
-		}
-	}()
+	// Queued to outbox before responding so that the letter
+	// is not lost if sending fails.
+	router.sendInvitation(lic, params.Email, claims.AdminID)
 
 	return c.JSON(http.StatusOK, licence.ExpandedLicence{
 		Licence:  lic,
@@ -143,12 +123,27 @@ func (router SubsRouter) RevokeInvitation(c echo.Context) error {
 	invID := c.Param("id") // the invitation id
 	claims := getAdminClaims(c)
 
+	// Unit managers cannot touch invitations of other units.
+	if claims.IsUnitScoped() {
+		if _, err := router.repo.InvitationByID(claims.AccessRight(invID)); err != nil {
+			return render.NewDBError(err)
+		}
+	}
+
 	result, err := router.repo.RevokeInvitation(invID, claims.TeamID.String)
 	// TODO: handle different errors
 	if err != nil {
 		return render.NewDBError(err)
 	}
 
+	router.audit.record(c, audit.NewEntry(claimsActor(claims), audit.ActionInvitationRevoke).
+		WithTeam(claims.TeamID.String).
+		WithTarget(invID).
+		WithChange(nil, result.Invitation))
+
+	// The freed licence might go to someone on waitlist.
+	go router.waitlist.Fill(claims.TeamID.String)
+
 	return c.JSON(http.StatusOK, result)
 }
 
@@ -162,7 +157,7 @@ func (router SubsRouter) ListInvitations(c echo.Context) error {
 		return render.NewBadRequest(err.Error())
 	}
 
-	invs, err := router.repo.ListInvitations(claims.TeamID.String, page)
+	invs, err := router.repo.ListInvitations(claims.TeamID.String, claims.UnitID, page)
 	if err != nil {
 		return render.NewDBError(err)
 	}
@@ -233,3 +228,35 @@ func (router SubsRouter) VerifyInvitation(c echo.Context) error {
 		Membership: m,
 	})
 }
+
+// sendInvitation delivers the invitation letter of a licence
+// in the name of the admin who created it.
+func (router SubsRouter) sendInvitation(lic licence.Licence, email string, adminID string) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	assignee, err := router.repo.FindAssignee(email)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+	// Find admin so that we could tell user who send the invitation.
+	adminProfile, err := router.repo.LoadB2BAdminProfile(adminID)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	parcel, err := letter.InvitationParcel(assignee, lic, adminProfile)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	err = router.post.Deliver(parcel)
+	if err != nil {
+		sugar.Error(err)
+	} else {
+		sugar.Infof("Invitation letter queued for %s", assignee.Email.String)
+	}
+}
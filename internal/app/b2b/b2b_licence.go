@@ -2,8 +2,11 @@ package b2b
 
 import (
 	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
 	"github.com/FTChinese/ftacademy/internal/pkg/input"
 	"github.com/FTChinese/ftacademy/internal/pkg/letter"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/FTChinese/ftacademy/internal/pkg/webhook"
 	"github.com/FTChinese/ftacademy/internal/repository/subsrepo"
 	gorest "github.com/FTChinese/go-rest"
 	"github.com/FTChinese/go-rest/render"
@@ -16,10 +19,7 @@ func (router SubsRouter) LoadLicence(c echo.Context) error {
 	// TODO: ensure teamId actually exist before hitting this endpoint.
 	claims := getAdminClaims(c)
 
-	lic, err := router.repo.LoadLicence(admin.AccessRight{
-		RowID:  licID,
-		TeamID: claims.TeamID.String,
-	})
+	lic, err := router.repo.LoadLicence(claims.AccessRight(licID))
 	if err != nil {
 		return render.NewDBError(err)
 	}
@@ -35,7 +35,7 @@ func (router SubsRouter) ListLicence(c echo.Context) error {
 		return render.NewBadRequest(err.Error())
 	}
 
-	licences, err := router.repo.ListLicence(claims.TeamID.String, page)
+	licences, err := router.repo.ListLicence(claims.TeamID.String, claims.UnitID, page)
 	if err != nil {
 		return render.NewDBError(err)
 	}
@@ -53,6 +53,8 @@ func (router SubsRouter) ListLicence(c echo.Context) error {
 // or licence is not found or cannot be granted,
 // or account is not found.
 // 403 Forbidden if reader already has valid membership.
+// 202 Accepted if reader has a valid auto-renewal subscription
+// and the licence is reserved for deferred grant.
 func (router SubsRouter) GrantLicence(c echo.Context) error {
 	defer router.logger.Sync()
 	sugar := router.logger.Sugar()
@@ -89,41 +91,35 @@ func (router SubsRouter) GrantLicence(c echo.Context) error {
 		}
 	}
 
-	// Send a notification letter to admin.
-	go func() {
-		err := router.repo.SaveVersionedLicence(result.LicenceVersion)
-		if err != nil {
-			sugar.Error()
-		}
+	go router.archiveGrant(result)
 
-		err = router.repo.ArchiveMembership(result.MemberModified.MembershipVersion)
-		if err != nil {
-			sugar.Error(err)
-		}
+	// Licence is reserved until reader's auto-renewal
+	// subscription ended.
+	if result.Deferred {
+		router.notifyReserved(result, assignee)
 
-		// TODO: send email to this user.
-
-		// Find the admin by licence creator id.
-		profile, err := router.repo.LoadB2BAdminProfile(result.LicenceVersion.PostChange.AdminID)
-		if err != nil {
-			sugar.Infof("Error retreiving admin profile %s of licence %s", result.LicenceVersion.PostChange.AdminID, result.LicenceVersion.PostChange.ID)
-			sugar.Error(err)
-			return
-		}
+		return c.JSON(http.StatusAccepted, result)
+	}
 
-		parcel, err := letter.LicenceGrantedParcel(result.LicenceVersion.PostChange.Licence, assignee, profile)
-		if err != nil {
-			sugar.Infof("Error creating granted parcel for licence %s", result.LicenceVersion.PostChange.ID)
-			sugar.Error(err)
-			return
-		}
+	go router.hooks.Emit(
+		result.LicenceVersion.PostChange.TeamID,
+		webhook.EventLicenceGranted,
+		result.LicenceVersion.PostChange.Licence)
 
-		err = router.post.Deliver(parcel)
+	// Keep team roster in sync with granted readers.
+	go func() {
+		err := router.repo.AddGrantedStaffer(assignee, params.TeamID)
 		if err != nil {
 			sugar.Error(err)
 		}
 	}()
 
+	// Send a notification letter to admin.
+	router.sendLicenceGranted(
+		result.LicenceVersion.PostChange.Licence,
+		assignee,
+		result.LicenceVersion.PostChange.AdminID)
+
 	// Send back user's membership.
 	return c.JSON(http.StatusOK, result)
 }
@@ -137,10 +133,7 @@ func (router SubsRouter) RevokeLicence(c echo.Context) error {
 
 	claims := getAdminClaims(c)
 
-	result, err := router.repo.RevokeLicence(admin.AccessRight{
-		RowID:  id,
-		TeamID: claims.TeamID.String,
-	})
+	result, err := router.repo.RevokeLicence(claims.AccessRight(id))
 
 	// TODO: handle various error response
 	if err != nil {
@@ -148,6 +141,11 @@ func (router SubsRouter) RevokeLicence(c echo.Context) error {
 		return render.NewDBError(err)
 	}
 
+	router.audit.record(c, audit.NewEntry(claimsActor(claims), audit.ActionLicenceRevoke).
+		WithTeam(claims.TeamID.String).
+		WithTarget(id).
+		WithChange(result.LicenceVersion.AnteChange.Licence, result.LicenceVersion.PostChange.Licence))
+
 	go func() {
 		err := router.repo.SaveVersionedLicence(result.LicenceVersion)
 		if err != nil {
@@ -159,9 +157,132 @@ func (router SubsRouter) RevokeLicence(c echo.Context) error {
 			sugar.Error(err)
 		}
 
-		// TODO: if membership has addon, send a request to API to re-enable it.
+		// TODO: if membership still has addon after carry-over restored, send a request to API to re-enable it.
 		// TODO: send email to this user.
+
+		router.hooks.Emit(claims.TeamID.String, webhook.EventLicenceRevoked, result.LicenceVersion.PostChange.Licence)
+
+		router.waitlist.Fill(claims.TeamID.String)
 	}()
 
 	return c.JSON(http.StatusOK, result)
 }
+
+// BulkRevokeLicences revokes multiple licences either by
+// ids or by the domain of assignees' email.
+// Each licence is revoked separately so that the response
+// contains the result of every one of them.
+// Input:
+// licenceIds?: string[];
+// emailDomain?: string;
+func (router SubsRouter) BulkRevokeLicences(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.BulkRevokeParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	result, err := router.repo.BulkRevokeLicence(claims, params)
+	if err != nil {
+		if err == subsrepo.ErrTooManyRevokes {
+			return render.NewUnprocessable(&render.ValidationError{
+				Message: err.Error(),
+				Field:   "emailDomain",
+				Code:    render.CodeInvalid,
+			})
+		}
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	for _, item := range result.Items {
+		if !item.Ok {
+			continue
+		}
+		router.audit.record(c, audit.NewEntry(claimsActor(claims), audit.ActionLicenceRevoke).
+			WithTeam(claims.TeamID.String).
+			WithTarget(item.LicenceID).
+			WithChange(item.LicenceVersion.AnteChange.Licence, item.LicenceVersion.PostChange.Licence))
+	}
+
+	if result.Total == 0 {
+		return c.JSON(http.StatusOK, result)
+	}
+
+	for _, item := range result.Items {
+		if item.Ok {
+			go router.hooks.Emit(claims.TeamID.String, webhook.EventLicenceRevoked, item.LicenceVersion.PostChange.Licence)
+		}
+	}
+
+	if result.Succeeded > 0 {
+		go router.waitlist.Fill(claims.TeamID.String)
+	}
+
+	// Send a single summary letter to admin.
+	router.sendLicencesRevoked(claims.AdminID, result)
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (router SubsRouter) sendLicencesRevoked(adminID string, result licence.BulkRevokeResult) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	adminProfile, err := router.repo.LoadB2BAdminProfile(adminID)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	parcel, err := letter.LicencesRevokedParcel(adminProfile, result)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	err = router.post.Deliver(parcel)
+	if err != nil {
+		sugar.Error(err)
+	}
+}
+
+// AllocateLicences moves licences into a unit.
+// Input:
+// licenceIds: string[];
+// unitId: string | null; Null to move them back to team level.
+func (router SubsRouter) AllocateLicences(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.LicenceAllocationParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	if err := ensureUnit(router.repo, params.UnitID, claims.TeamID.String); err != nil {
+		return err
+	}
+
+	result, err := router.repo.AllocateLicences(claims.TeamID.String, params)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
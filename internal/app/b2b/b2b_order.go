@@ -2,8 +2,10 @@ package b2b
 
 import (
 	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
 	"github.com/FTChinese/ftacademy/internal/pkg/checkout"
 	"github.com/FTChinese/ftacademy/internal/pkg/letter"
+	"github.com/FTChinese/ftacademy/internal/pkg/webhook"
 	"github.com/FTChinese/go-rest"
 	"github.com/FTChinese/go-rest/render"
 	"github.com/labstack/echo/v4"
@@ -13,9 +15,6 @@ import (
 // CreateOrders creates orders an org purchased.
 // input: input.ShoppingCart.
 func (router SubsRouter) CreateOrders(c echo.Context) error {
-	defer router.logger.Sync()
-	sugar := router.logger.Sugar()
-
 	claims := getAdminClaims(c)
 
 	var cart checkout.ShoppingCart
@@ -30,28 +29,42 @@ func (router SubsRouter) CreateOrders(c echo.Context) error {
 		return render.NewDBError(err)
 	}
 
-	go func() {
-		profile, err := router.repo.LoadB2BAdminProfile(claims.AdminID)
-		if err != nil {
-			sugar.Error(err)
-			return
-		}
-
-		parcel, err := letter.OrderCreatedParcel(profile, schema.OrderRow)
-		if err != nil {
-			sugar.Error(err)
-			return
-		}
-
-		err = router.post.Deliver(parcel)
-		if err != nil {
-			sugar.Error(err)
-		}
-	}()
+	go router.hooks.Emit(claims.TeamID.String, webhook.EventOrderCreated, schema.OrderRow)
+
+	router.audit.record(c, audit.NewEntry(claimsActor(claims), audit.ActionOrderCreate).
+		WithTeam(claims.TeamID.String).
+		WithTarget(schema.OrderRow.ID).
+		WithChange(nil, schema.OrderRow))
+
+	// Queued to outbox before responding so that the letter
+	// is not lost if sending fails.
+	router.sendOrderCreated(claims.AdminID, schema.OrderRow)
 
 	return c.JSON(http.StatusOK, schema.OrderRow)
 }
 
+func (router SubsRouter) sendOrderCreated(adminID string, order checkout.Order) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	profile, err := router.repo.LoadB2BAdminProfile(adminID)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	parcel, err := letter.OrderCreatedParcel(profile, order)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	err = router.post.Deliver(parcel)
+	if err != nil {
+		sugar.Error(err)
+	}
+}
+
 func (router SubsRouter) ListOrders(c echo.Context) error {
 	claims := getAdminClaims(c)
 
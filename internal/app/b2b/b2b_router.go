@@ -4,19 +4,52 @@ import (
 	"github.com/FTChinese/ftacademy/internal/repository/subsrepo"
 	"github.com/FTChinese/ftacademy/pkg/db"
 	"github.com/FTChinese/ftacademy/pkg/postman"
+	"github.com/FTChinese/ftacademy/pkg/throttle"
 	"go.uber.org/zap"
+	"time"
 )
 
 type SubsRouter struct {
-	repo   subsrepo.Env
-	post   postman.Postman
-	logger *zap.Logger
+	repo     subsrepo.Env
+	post     postman.Postman
+	logger   *zap.Logger
+	waitlist waitlistFiller
+	hooks    hookDispatcher
+	// Limits failed attempts to redeem a code per reader and per IP.
+	redeemLimiter throttle.Limiter
+	// Limits licence requests, each of which sends a letter,
+	// per reader, per IP and per email.
+	requestLimiter throttle.Limiter
+	audit          auditTrail
 }
 
-func NewSubsRouter(myDBs db.ReadWriteMyDBs, pm postman.Postman, logger *zap.Logger) SubsRouter {
+// Failed attempts to redeem a code per reader and per IP.
+// Codes have about 80 bits of entropy so a loose limit is
+// enough to stop guessing.
+var redeemRule = throttle.Rule{
+	Limit:  10,
+	Window: time.Hour,
+}
+
+// Licence requests created per reader, IP and email.
+var licenceRequestRule = throttle.Rule{
+	Limit:  5,
+	Window: 24 * time.Hour,
+}
+
+func NewSubsRouter(myDBs db.ReadWriteMyDBs, pm postman.Postman, store throttle.Store, logger *zap.Logger) SubsRouter {
+	repo := subsrepo.NewEnv(myDBs, logger)
+	hooks := newHookDispatcher(myDBs, logger)
+	trail := newAuditTrail(myDBs, logger)
+
 	return SubsRouter{
-		repo:   subsrepo.NewEnv(myDBs, logger),
-		post:   pm,
-		logger: logger,
+		repo:           repo,
+		post:           pm,
+		logger:         logger,
+		waitlist:       newWaitlistFiller(repo, hooks, pm, trail, logger),
+		hooks:          hooks,
+		redeemLimiter:  throttle.NewLimiter("reader_redeem", store, redeemRule),
+		requestLimiter: throttle.NewLimiter("licence_request", store, licenceRequestRule),
+		audit:          trail,
 	}
 }
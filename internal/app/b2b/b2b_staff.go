@@ -0,0 +1,337 @@
+package b2b
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/letter"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/FTChinese/ftacademy/internal/pkg/webhook"
+	"github.com/FTChinese/ftacademy/internal/repository/subsrepo"
+	gorest "github.com/FTChinese/go-rest"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+func staffExistsError() error {
+	return render.NewUnprocessable(&render.ValidationError{
+		Message: subsrepo.ErrStafferExists.Error(),
+		Field:   "email",
+		Code:    render.CodeAlreadyExists,
+	})
+}
+
+// ListStaff shows team roster with each staffer's
+// licence and membership.
+func (router SubsRouter) ListStaff(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	var page gorest.Pagination
+	if err := c.Bind(&page); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	list, err := router.repo.ListStaff(claims.TeamID.String, claims.UnitID, page)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// CreateStaffer adds a member to roster.
+// Input:
+// email: string;
+// name?: string;
+// unitId?: string; Ignored for unit managers.
+func (router SubsRouter) CreateStaffer(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.StafferParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	if err := router.scopeStafferUnit(&params, claims); err != nil {
+		return err
+	}
+
+	a, err := router.repo.FindAssignee(params.Email)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	s, err := router.repo.CreateStaffer(licence.NewStaffer(params, claims.TeamID.String, a))
+	if err != nil {
+		sugar.Error(err)
+		if err == subsrepo.ErrStafferExists {
+			return staffExistsError()
+		}
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, s)
+}
+
+// ImportStaff adds staff from a csv file uploaded in
+// the `file` field of a multipart form.
+// Each row contains email and an optional name.
+func (router SubsRouter) ImportStaff(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	defer f.Close()
+
+	params, invalid, err := licence.ParseStaffCSV(f)
+	if err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	// Staff imported by a unit manager belong to its unit.
+	for i := range params {
+		params[i].UnitID = claims.UnitID
+	}
+
+	result, err := router.repo.ImportStaff(claims.TeamID.String, params)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+	result.Invalid = invalid
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// scopeStafferUnit puts staff created by a unit manager into
+// its own unit, or ensures the unit chosen by a team-wide
+// admin exists.
+func (router SubsRouter) scopeStafferUnit(params *input.StafferParams, claims admin.PassportClaims) error {
+	if claims.IsUnitScoped() {
+		params.UnitID = claims.UnitID
+		return nil
+	}
+
+	return ensureUnit(router.repo, params.UnitID, claims.TeamID.String)
+}
+
+func (router SubsRouter) loadStaffer(c echo.Context) (licence.Staffer, error) {
+	claims := getAdminClaims(c)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return licence.Staffer{}, render.NewBadRequest(err.Error())
+	}
+
+	s, err := router.repo.LoadStaffer(claims.TeamID.String, id)
+	if err != nil {
+		return licence.Staffer{}, render.NewDBError(err)
+	}
+
+	if !claims.Covers(s.UnitID) {
+		return licence.Staffer{}, render.NewNotFound("Staffer not found")
+	}
+
+	return s, nil
+}
+
+// LoadStaffer shows a staffer with licence and membership.
+func (router SubsRouter) LoadStaffer(c echo.Context) error {
+	s, err := router.loadStaffer(c)
+	if err != nil {
+		return err
+	}
+
+	es, err := router.repo.ExpandStaffer(s, getAdminClaims(c).UnitID)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, es)
+}
+
+// UpdateStaffer changes email or name of a staffer.
+// Input:
+// email: string;
+// name?: string;
+// unitId?: string; Ignored for unit managers.
+func (router SubsRouter) UpdateStaffer(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	var params input.StafferParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	if err := router.scopeStafferUnit(&params, getAdminClaims(c)); err != nil {
+		return err
+	}
+
+	current, err := router.loadStaffer(c)
+	if err != nil {
+		return err
+	}
+
+	s, err := router.repo.EditStaffer(current, params)
+	if err != nil {
+		sugar.Error(err)
+		if err == subsrepo.ErrStafferExists {
+			return staffExistsError()
+		}
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, s)
+}
+
+// RemoveStaffer deletes a staffer from roster.
+// Query parameter:
+// ?revoke=true to also revoke the licence held or invited.
+// Without it, the licence is returned in response so that
+// client could offer to revoke it.
+func (router SubsRouter) RemoveStaffer(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+	revoke, _ := strconv.ParseBool(c.QueryParam("revoke"))
+
+	s, err := router.loadStaffer(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := router.repo.RemoveStaffer(s, revoke, claims.UnitID)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	go router.stafferLicenceReleased(claimsActor(claims), claims.TeamID.String, result)
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// stafferLicenceReleased notifies subscribers if a granted
+// licence is revoked when a staffer is removed or
+// deactivated, then hands the freed seat to waitlist.
+// It should be run in a goroutine.
+func (router SubsRouter) stafferLicenceReleased(actor audit.Actor, teamID string, result licence.StafferRemoved) {
+	if !result.Revoked {
+		return
+	}
+
+	router.audit.save(audit.NewEntry(actor, audit.ActionLicenceRevoke).
+		WithTeam(teamID).
+		WithTarget(result.Licence.ID).
+		WithChange(result.PriorLicence, result.Licence))
+
+	if result.GrantRevoked {
+		router.hooks.Emit(teamID, webhook.EventLicenceRevoked, *result.Licence)
+	}
+
+	router.waitlist.Fill(teamID)
+}
+
+// InviteStaff creates invitations for selected staffers
+// using available licences and sends the letters.
+// Input:
+// staffIds: number[];
+// description?: string;
+func (router SubsRouter) InviteStaff(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.StaffInvitationParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	invited, err := router.repo.InviteStaff(params, claims)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	for _, lic := range invited {
+		router.audit.record(c, audit.NewEntry(claimsActor(claims), audit.ActionInvitationCreate).
+			WithTeam(claims.TeamID.String).
+			WithTarget(lic.LatestInvitation.ID).
+			WithChange(nil, lic.LatestInvitation))
+	}
+
+	router.sendInvitations(invited, claims.AdminID)
+
+	return c.JSON(http.StatusOK, invited)
+}
+
+// sendInvitations delivers invitation letters for licences
+// invited on behalf of an admin.
+func (router SubsRouter) sendInvitations(invited []licence.Licence, adminID string) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	if len(invited) == 0 {
+		return
+	}
+
+	for _, lic := range invited {
+		go router.hooks.Emit(lic.TeamID, webhook.EventInvitationCreated, lic.LatestInvitation)
+	}
+
+	profile, err := router.repo.LoadB2BAdminProfile(adminID)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	for _, lic := range invited {
+		assignee, err := router.repo.FindAssignee(lic.LatestInvitation.Email)
+		if err != nil {
+			sugar.Error(err)
+			continue
+		}
+
+		parcel, err := letter.InvitationParcel(assignee, lic, profile)
+		if err != nil {
+			sugar.Error(err)
+			continue
+		}
+
+		err = router.post.Deliver(parcel)
+		if err != nil {
+			sugar.Error(err)
+		}
+	}
+}
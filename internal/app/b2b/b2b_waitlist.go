@@ -0,0 +1,219 @@
+package b2b
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	gorest "github.com/FTChinese/go-rest"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+)
+
+// ListWaitlist shows people waiting for a licence,
+// in the order they will be invited.
+func (router SubsRouter) ListWaitlist(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	var page gorest.Pagination
+	if err := c.Bind(&page); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	list, err := router.repo.ListWaitlist(claims.TeamID.String, page)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// CreateWaitlistEntry adds an email to waitlist.
+// Input:
+// email: string;
+// priority: number; 0 - 100, larger comes first.
+// note?: string;
+func (router SubsRouter) CreateWaitlistEntry(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.WaitlistParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	entry := licence.NewWaitlistEntry(params, admin.Creator{
+		AdminID: claims.AdminID,
+		TeamID:  claims.TeamID.String,
+	})
+
+	err := router.repo.CreateWaitlistEntry(entry)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	// A licence might already be available.
+	go router.waitlist.Fill(claims.TeamID.String)
+
+	return c.JSON(http.StatusOK, entry)
+}
+
+// UpdateWaitlistEntry changes the priority or note of an entry.
+// Input:
+// email: string; Not changeable but required for validation.
+// priority: number;
+// note?: string;
+func (router SubsRouter) UpdateWaitlistEntry(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.WaitlistParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	entry, err := router.repo.LoadWaitlistEntry(admin.AccessRight{
+		RowID:  c.Param("id"),
+		TeamID: claims.TeamID.String,
+	})
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	params.Email = entry.Email
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	if !entry.IsWaiting() {
+		return render.NewBadRequest("Only waiting entries could be changed")
+	}
+
+	entry = entry.Update(params)
+	err = router.repo.UpdateWaitlistEntry(entry)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, entry)
+}
+
+// RemoveWaitlistEntry takes an entry off waitlist.
+func (router SubsRouter) RemoveWaitlistEntry(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	entry, err := router.repo.LoadWaitlistEntry(admin.AccessRight{
+		RowID:  c.Param("id"),
+		TeamID: claims.TeamID.String,
+	})
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	if !entry.IsWaiting() {
+		return render.NewBadRequest("Only waiting entries could be removed")
+	}
+
+	err = router.repo.UpdateWaitlistEntry(entry.Removed())
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// UpdateWaitlistSetting turns on/off auto-fill for a team.
+// Input:
+// autoFill: boolean;
+func (router SubsRouter) UpdateWaitlistSetting(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.WaitlistSettingParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	team, err := router.repo.LoadTeam(claims.TeamID.String)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	team = team.WithAutoFill(params.AutoFill)
+	err = router.repo.UpdateWaitlistAutoFill(team)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	if team.AutoFillWaitlist {
+		go router.waitlist.Fill(team.ID)
+	}
+
+	return c.JSON(http.StatusOK, team)
+}
+
+// ExpireInvitations releases licences whose invitation is
+// not accepted in time and fills them from waitlist.
+func (router SubsRouter) ExpireInvitations() {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	list, err := router.repo.ListExpiredInvitations()
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	var teams = map[string]bool{}
+	for _, ie := range list {
+		err := router.repo.ExpireInvitation(ie)
+		if err != nil {
+			sugar.Errorf("Expiring invitation %s failed: %v", ie.InvitationID, err)
+			continue
+		}
+
+		router.audit.save(audit.NewEntry(audit.SystemActor(audit.JobInvitationExpiry), audit.ActionInvitationRevoke).
+			WithTeam(ie.TeamID).
+			WithTarget(ie.InvitationID))
+		teams[ie.TeamID] = true
+	}
+
+	for teamID := range teams {
+		router.waitlist.Fill(teamID)
+	}
+}
+
+// WatchExpiredInvitations runs ExpireInvitations and
+// ExpireRedeemCodes periodically.
+// It blocks and should be run in a goroutine.
+func (router SubsRouter) WatchExpiredInvitations(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		router.ExpireInvitations()
+		router.ExpireRedeemCodes()
+	}
+}
@@ -0,0 +1,375 @@
+package b2b
+
+import (
+	"github.com/FTChinese/ftacademy/internal/access"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
+	"github.com/FTChinese/ftacademy/internal/pkg/checkout"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/letter"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/FTChinese/ftacademy/internal/pkg/webhook"
+	"github.com/FTChinese/ftacademy/pkg/xhttp"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// Endpoints in this file let CMS staff act on behalf of a
+// team specified by the id path parameter. The staff is
+// identified by the X-Operator header, the same as every
+// other CMS request. Each request carries the reason in
+// addition to what the b2b counterpart accepts:
+// reason: string;
+
+// loadOnBehalf finds the team in path and builds owner's
+// claims to access its data. The claims carry the operator
+// so that rows created with them tell who made them.
+func (router CMSRouter) loadOnBehalf(c echo.Context, op input.OperatorParams) (admin.Team, admin.PassportClaims, error) {
+	o, _ := c.Get(xhttp.KeyCtxOAuth).(access.OAuth)
+	if o.Operator == "" {
+		return admin.Team{}, admin.PassportClaims{}, render.NewBadRequest("Missing " + access.HeaderOperator + " header")
+	}
+
+	t, err := router.repo.LoadTeam(c.Param("id"))
+	if err != nil {
+		return admin.Team{}, admin.PassportClaims{}, render.NewDBError(err)
+	}
+
+	return t, admin.OnBehalfOf(t, admin.NewOperator(o.Operator, op)), nil
+}
+
+// auditOnBehalf creates an audit entry attributed to the
+// operator of claims.
+func auditOnBehalf(c echo.Context, action audit.Action, claims admin.PassportClaims) audit.Entry {
+	return cmsEntry(c, action).
+		WithTeam(claims.TeamID.String).
+		WithOperator(claims.Operator)
+}
+
+// bindOperator is used by endpoints whose b2b version
+// has no request body.
+func bindOperator(c echo.Context) (input.OperatorParams, error) {
+	var op input.OperatorParams
+	if err := c.Bind(&op); err != nil {
+		return op, render.NewBadRequest(err.Error())
+	}
+
+	if ve := op.Validate(); ve != nil {
+		return op, render.NewUnprocessable(ve)
+	}
+
+	return op, nil
+}
+
+// UpdateTeamOnBehalf changes name and invoice title of a team.
+// Input: {orgName: string, invoiceTitle?: string, reason: string}
+func (router CMSRouter) UpdateTeamOnBehalf(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	var params struct {
+		input.TeamParams
+		input.OperatorParams
+	}
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	if ve := params.TeamParams.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+	if ve := params.OperatorParams.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	currentTeam, claims, err := router.loadOnBehalf(c, params.OperatorParams)
+	if err != nil {
+		return err
+	}
+
+	if currentTeam.IsEqual(params.TeamParams) {
+		return c.JSON(http.StatusOK, currentTeam)
+	}
+
+	teamUpdated := currentTeam.Update(params.TeamParams)
+
+	err = router.repo.UpdateTeam(teamUpdated)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	router.audit.record(c, auditOnBehalf(c, audit.ActionTeamUpdate, claims).
+		WithTarget(teamUpdated.ID).
+		WithChange(currentTeam, teamUpdated))
+
+	return c.JSON(http.StatusOK, teamUpdated)
+}
+
+// CreateOrderOnBehalf creates an order for a team, usually
+// after it sent a purchase request by email.
+// Input: checkout.ShoppingCart plus reason.
+func (router CMSRouter) CreateOrderOnBehalf(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	var params struct {
+		checkout.ShoppingCart
+		input.OperatorParams
+	}
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	if ve := params.OperatorParams.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	t, claims, err := router.loadOnBehalf(c, params.OperatorParams)
+	if err != nil {
+		return err
+	}
+
+	schema := checkout.NewOrderSchemaBuilder(params.ShoppingCart, claims).
+		Build()
+	err = router.subs.CreateOrder(schema)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	go router.hooks.Emit(t.ID, webhook.EventOrderCreated, schema.OrderRow)
+
+	router.audit.record(c, auditOnBehalf(c, audit.ActionOrderCreate, claims).
+		WithTarget(schema.OrderRow.ID).
+		WithChange(nil, schema.OrderRow))
+
+	// Owner is notified as if the order is placed by itself.
+	router.sendOrderCreated(t.AdminID, schema.OrderRow)
+
+	return c.JSON(http.StatusOK, schema.OrderRow)
+}
+
+func (router CMSRouter) sendOrderCreated(ownerID string, order checkout.Order) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	profile, err := router.repo.LoadB2BAdminProfile(ownerID)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	parcel, err := letter.OrderCreatedParcel(profile, order)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	err = router.post.Deliver(parcel)
+	if err != nil {
+		sugar.Error(err)
+	}
+}
+
+// CreateInvitationOnBehalf invites a reader to a licence of
+// the team.
+// Input: input.InvitationParams plus reason.
+func (router CMSRouter) CreateInvitationOnBehalf(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	var params struct {
+		input.InvitationParams
+		input.OperatorParams
+	}
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	if ve := params.InvitationParams.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+	if ve := params.OperatorParams.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	t, claims, err := router.loadOnBehalf(c, params.OperatorParams)
+	if err != nil {
+		return err
+	}
+
+	lic, err := router.subs.CreateInvitation(params.InvitationParams, claims)
+	if err != nil {
+		sugar.Error(err)
+		return invitationError(err)
+	}
+
+	go router.hooks.Emit(t.ID, webhook.EventInvitationCreated, lic.LatestInvitation)
+
+	router.audit.record(c, auditOnBehalf(c, audit.ActionInvitationCreate, claims).
+		WithTarget(lic.LatestInvitation.ID).
+		WithChange(nil, lic.LatestInvitation))
+
+	router.sendInvitation(lic, t.AdminID)
+
+	return c.JSON(http.StatusOK, licence.ExpandedLicence{
+		Licence:  lic,
+		Assignee: licence.AssigneeJSON{},
+	})
+}
+
+// RevokeInvitationOnBehalf revokes an invitation of the team.
+func (router CMSRouter) RevokeInvitationOnBehalf(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	op, err := bindOperator(c)
+	if err != nil {
+		return err
+	}
+
+	t, claims, err := router.loadOnBehalf(c, op)
+	if err != nil {
+		return err
+	}
+
+	invID := c.Param("invId")
+
+	result, err := router.subs.RevokeInvitation(invID, t.ID)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	router.audit.record(c, auditOnBehalf(c, audit.ActionInvitationRevoke, claims).
+		WithTarget(invID).
+		WithChange(nil, result.Invitation))
+
+	// The freed licence might go to someone on waitlist.
+	go router.waitlist.Fill(t.ID)
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// ResendInvitationOnBehalf sends the invitation letter again
+// in case reader lost it. Only invitations still acceptable
+// could be resent; otherwise revoke it and create a new one.
+func (router CMSRouter) ResendInvitationOnBehalf(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	op, err := bindOperator(c)
+	if err != nil {
+		return err
+	}
+
+	t, claims, err := router.loadOnBehalf(c, op)
+	if err != nil {
+		return err
+	}
+
+	invID := c.Param("invId")
+
+	inv, err := router.subs.InvitationByID(claims.AccessRight(invID))
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	if !inv.IsAcceptable() {
+		return render.NewUnprocessable(&render.ValidationError{
+			Message: "Invitation is no longer valid. Revoke it and create a new one.",
+			Field:   "invitation",
+			Code:    render.CodeInvalid,
+		})
+	}
+
+	lic, err := router.subs.LoadLicence(claims.AccessRight(inv.LicenceID))
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	router.audit.record(c, auditOnBehalf(c, audit.ActionInvitationResend, claims).
+		WithTarget(invID))
+
+	router.sendInvitation(lic.Licence, t.AdminID)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// sendInvitation delivers the invitation letter of a licence
+// in the name of the team owner.
+func (router CMSRouter) sendInvitation(lic licence.Licence, ownerID string) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	assignee, err := router.subs.FindAssignee(lic.LatestInvitation.Email)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	adminProfile, err := router.repo.LoadB2BAdminProfile(ownerID)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	parcel, err := letter.InvitationParcel(assignee, lic, adminProfile)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	err = router.post.Deliver(parcel)
+	if err != nil {
+		sugar.Error(err)
+	}
+}
+
+// RevokeLicenceOnBehalf unlinks a reader from a licence of
+// the team.
+func (router CMSRouter) RevokeLicenceOnBehalf(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	op, err := bindOperator(c)
+	if err != nil {
+		return err
+	}
+
+	t, claims, err := router.loadOnBehalf(c, op)
+	if err != nil {
+		return err
+	}
+
+	licID := c.Param("licId")
+
+	result, err := router.subs.RevokeLicence(claims.AccessRight(licID))
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	router.audit.record(c, auditOnBehalf(c, audit.ActionLicenceRevoke, claims).
+		WithTarget(licID).
+		WithChange(result.LicenceVersion.AnteChange.Licence, result.LicenceVersion.PostChange.Licence))
+
+	go func() {
+		err := router.subs.SaveVersionedLicence(result.LicenceVersion)
+		if err != nil {
+			sugar.Error(err)
+		}
+
+		err = router.subs.ArchiveMembership(result.MembershipVersioned)
+		if err != nil {
+			sugar.Error(err)
+		}
+
+		router.hooks.Emit(t.ID, webhook.EventLicenceRevoked, result.LicenceVersion.PostChange.Licence)
+
+		router.waitlist.Fill(t.ID)
+	}()
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -1,8 +1,10 @@
 package b2b
 
 import (
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
 	"github.com/FTChinese/ftacademy/internal/pkg/checkout"
 	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/webhook"
 	gorest "github.com/FTChinese/go-rest"
 	"github.com/FTChinese/go-rest/render"
 	"github.com/labstack/echo/v4"
@@ -110,11 +112,25 @@ func (router CMSRouter) ConfirmPayment(c echo.Context) error {
 	}
 
 	// TODO: run without blocking.
-	err = router.repo.ConfirmPayment(order)
+	err = router.repo.ConfirmPayment(order, func(g checkout.LicenceGenerated) {
+		if g.IsRenewed() {
+			go router.hooks.Emit(order.TeamID, webhook.EventLicenceRenewed, g.LicenceVersion.PostChange.Licence)
+		}
+	})
 	if err != nil {
 		sugar.Error(err)
 		return render.NewDBError(err)
 	}
 
+	go router.hooks.Emit(order.TeamID, webhook.EventOrderPaid, order.ChangeStatus(checkout.StatusPaid))
+
+	router.audit.record(c, cmsEntry(c, audit.ActionPaymentConfirm).
+		WithTeam(order.TeamID).
+		WithTarget(order.ID).
+		WithChange(nil, payResult))
+
+	// New licences might be given to people on waitlist.
+	go router.waitlist.Fill(order.TeamID)
+
 	return c.JSON(http.StatusOK, payResult)
 }
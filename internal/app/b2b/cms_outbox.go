@@ -0,0 +1,56 @@
+package b2b
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
+	"github.com/FTChinese/ftacademy/internal/pkg/outbox"
+	"github.com/FTChinese/ftacademy/internal/repository/outboxrepo"
+	gorest "github.com/FTChinese/go-rest"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// ListOutbox shows dead letters so that they could be resent.
+// Query: ?page=<int>&per_page=<int>
+// Letter body is never shown since it contains live links.
+func (router CMSRouter) ListOutbox(c echo.Context) error {
+	var page gorest.Pagination
+	if err := c.Bind(&page); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	list, err := router.mailer.repo.ListMessages(outbox.StatusDead, page)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// ResendLetter queues a dead letter again and sends it.
+// The returned message is pending; check it later for
+// the outcome.
+func (router CMSRouter) ResendLetter(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	id := c.Param("id")
+
+	msg, err := router.mailer.Resend(id)
+	if err != nil {
+		if err == outboxrepo.ErrNotDead {
+			return render.NewUnprocessable(&render.ValidationError{
+				Message: err.Error(),
+				Field:   "status",
+				Code:    render.CodeInvalid,
+			})
+		}
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	router.audit.record(c, cmsEntry(c, audit.ActionLetterResend).
+		WithTarget(msg.ID))
+
+	return c.JSON(http.StatusOK, msg)
+}
@@ -0,0 +1,166 @@
+package b2b
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/export"
+	"github.com/FTChinese/ftacademy/internal/pkg/report"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Finance reports. Every endpoint accepts:
+// ?start=YYYY-MM-DD&end=YYYY-MM-DD&format=json|csv
+// Both dates are inclusive. Defaults to the last 12 months.
+
+type csvRower interface {
+	CSVRow() []string
+}
+
+func getReportPeriod(c echo.Context) (report.Period, error) {
+	p, err := report.ParsePeriod(c.QueryParam("start"), c.QueryParam("end"), time.Now())
+	if err != nil {
+		return report.Period{}, render.NewBadRequest(err.Error())
+	}
+
+	return p, nil
+}
+
+func wantCSV(c echo.Context) bool {
+	return c.QueryParam("format") == "csv"
+}
+
+// writeReportCSV downloads rows as a csv file.
+func writeReportCSV(c echo.Context, fileName string, header []string, rows []csvRower) error {
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+fileName+`"`)
+	c.Response().WriteHeader(http.StatusOK)
+
+	w := export.NewCSVWriter(c.Response())
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := w.Write(r.CSVRow()); err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
+}
+
+// RevenueReport shows revenue by month, tier and cycle,
+// together with discount given.
+func (router CMSRouter) RevenueReport(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	p, err := getReportPeriod(c)
+	if err != nil {
+		return err
+	}
+
+	r, err := router.repo.RevenueReport(p)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	if wantCSV(c) {
+		var rows = make([]csvRower, 0, len(r.Data))
+		for _, v := range r.Data {
+			rows = append(rows, v)
+		}
+		return writeReportCSV(c, p.FileName("revenue"), report.RevenueCSVHeader, rows)
+	}
+
+	return c.JSON(http.StatusOK, r)
+}
+
+// SeatReport shows new versus renewal seats by month.
+func (router CMSRouter) SeatReport(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	p, err := getReportPeriod(c)
+	if err != nil {
+		return err
+	}
+
+	list, err := router.repo.SeatReport(p)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	if wantCSV(c) {
+		var rows = make([]csvRower, 0, len(list))
+		for _, v := range list {
+			rows = append(rows, v)
+		}
+		return writeReportCSV(c, p.FileName("seats"), report.SeatCSVHeader, rows)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// ActiveSeatReport shows granted seats at the end of each month.
+func (router CMSRouter) ActiveSeatReport(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	p, err := getReportPeriod(c)
+	if err != nil {
+		return err
+	}
+
+	list, err := router.repo.ActiveSeatReport(p)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	if wantCSV(c) {
+		var rows = make([]csvRower, 0, len(list))
+		for _, v := range list {
+			rows = append(rows, v)
+		}
+		return writeReportCSV(c, p.FileName("active-seats"), report.ActiveSeatCSVHeader, rows)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// TopTeamsReport ranks teams by amount paid.
+// Query: ?limit=<int> Defaults to 20, at most 100.
+func (router CMSRouter) TopTeamsReport(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	p, err := getReportPeriod(c)
+	if err != nil {
+		return err
+	}
+
+	limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	list, err := router.repo.TopTeamsReport(p, limit)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	if wantCSV(c) {
+		var rows = make([]csvRower, 0, len(list))
+		for _, v := range list {
+			rows = append(rows, v)
+		}
+		return writeReportCSV(c, p.FileName("top-teams"), report.TopTeamCSVHeader, rows)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
@@ -2,21 +2,36 @@ package b2b
 
 import (
 	"github.com/FTChinese/ftacademy/internal/repository/cmsrepo"
+	"github.com/FTChinese/ftacademy/internal/repository/subsrepo"
 	"github.com/FTChinese/ftacademy/pkg/db"
 	"github.com/FTChinese/ftacademy/pkg/postman"
 	"go.uber.org/zap"
 )
 
 type CMSRouter struct {
-	repo   cmsrepo.Env
-	post   postman.Postman
-	logger *zap.Logger
+	repo     cmsrepo.Env
+	subs     subsrepo.Env // Orders and licences of a specific team.
+	post     postman.Postman
+	logger   *zap.Logger
+	waitlist waitlistFiller
+	hooks    hookDispatcher
+	audit    auditTrail
+	mailer   Mailer
 }
 
-func NewCMSRouter(dbs db.ReadWriteMyDBs, p postman.Postman, logger *zap.Logger) CMSRouter {
+func NewCMSRouter(dbs db.ReadWriteMyDBs, m Mailer, logger *zap.Logger) CMSRouter {
+	hooks := newHookDispatcher(dbs, logger)
+	subs := subsrepo.NewEnv(dbs, logger)
+	trail := newAuditTrail(dbs, logger)
+
 	return CMSRouter{
-		repo:   cmsrepo.NewEnv(dbs, logger),
-		post:   p,
-		logger: logger,
+		repo:     cmsrepo.NewEnv(dbs, logger),
+		subs:     subs,
+		post:     m,
+		logger:   logger,
+		waitlist: newWaitlistFiller(subs, hooks, m, trail, logger),
+		hooks:    hooks,
+		audit:    trail,
+		mailer:   m,
 	}
 }
@@ -1,26 +1,95 @@
 package b2b
 
 import (
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/checkout"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/FTChinese/ftacademy/pkg/db"
+	gorest "github.com/FTChinese/go-rest"
 	"github.com/FTChinese/go-rest/render"
+	"github.com/guregu/null"
 	"github.com/labstack/echo/v4"
 	"net/http"
 )
 
+// cmsTeamDetail shows everything about a team in one shot.
+// Orders and licences only contain the first page.
+type cmsTeamDetail struct {
+	admin.TeamSummary
+	Owner    admin.BaseAccount        `json:"owner"`
+	Orders   checkout.OrderList       `json:"orders"`
+	Licences licence.PagedLicenceList `json:"licences"`
+}
+
+// ListTeams shows the team directory.
+// Query: ?q=<team id | org name | owner email>&pending_orders=true&expiring=true&sort=created|spend|seats&page=<int>&per_page=<int>
+func (router CMSRouter) ListTeams(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	var page gorest.Pagination
+	if err := c.Bind(&page); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	filter := admin.TeamDirectoryFilter{
+		Query:             c.QueryParam("q"),
+		PendingOrders:     c.QueryParam("pending_orders") == "true",
+		ExpiringThisMonth: c.QueryParam("expiring") == "true",
+		Sort:              admin.ParseTeamSort(c.QueryParam("sort")),
+	}
+
+	list, err := router.repo.ListTeams(filter, page)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
 // LoadTeam retrieves a b2b team by the id specified
-// in url path parameters.
+// in url path parameters, together with its owner,
+// aggregated stats, recent orders and licences.
 func (router CMSRouter) LoadTeam(c echo.Context) error {
 	defer router.logger.Sync()
 	sugar := router.logger.Sugar()
 
 	teamID := c.Param("id")
 
-	t, err := router.repo.LoadTeam(teamID)
+	t, err := router.repo.LoadTeamSummary(teamID)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	owner, err := router.repo.LoadAdmin(t.AdminID)
 	if err != nil {
 		sugar.Error(err)
 		return render.NewDBError(err)
 	}
 
-	return c.JSON(http.StatusOK, t)
+	page := gorest.NewPagination(1, 10)
+
+	orders, err := router.subs.ListOrders(teamID, page)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	licences, err := router.subs.ListLicence(teamID, null.String{}, page)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, cmsTeamDetail{
+		TeamSummary: t,
+		Owner:       owner,
+		Orders:      orders,
+		Licences:    licences,
+	})
 }
 
 func (router CMSRouter) LoadingAdminProfile(c echo.Context) error {
@@ -37,3 +106,89 @@ func (router CMSRouter) LoadingAdminProfile(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, profile)
 }
+
+// ListTeamDomains shows the email domains used to match
+// readers' self-service licence requests to a team.
+func (router CMSRouter) ListTeamDomains(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	list, err := router.repo.ListTeamDomains(c.Param("id"))
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// CreateTeamDomain registers an email domain for a team.
+// Input:
+// domain: string;
+func (router CMSRouter) CreateTeamDomain(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	teamID := c.Param("id")
+
+	var params input.TeamDomainParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	if _, err := router.repo.LoadTeam(teamID); err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	d := admin.NewTeamDomain(teamID, params)
+	err := router.repo.CreateTeamDomain(d)
+	if err != nil {
+		if db.IsAlreadyExists(err) {
+			return render.NewUnprocessable(render.NewVEAlreadyExists("domain"))
+		}
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, d)
+}
+
+// DeleteTeamDomain removes an email domain from a team.
+func (router CMSRouter) DeleteTeamDomain(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	err := router.repo.DeleteTeamDomain(c.Param("id"), c.Param("domain"))
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListLegacyPasswords shows admins whose password is still
+// hashed with SHA-256. They are upgraded upon next login.
+// Query: ?page=1&per_page=10
+func (router CMSRouter) ListLegacyPasswords(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	var page gorest.Pagination
+	if err := c.Bind(&page); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	list, err := router.repo.ListLegacyPassword(page)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
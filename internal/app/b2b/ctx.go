@@ -2,6 +2,7 @@ package b2b
 
 import (
 	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/reader"
 	"github.com/FTChinese/ftacademy/pkg/xhttp"
 	"github.com/labstack/echo/v4"
 )
@@ -9,3 +10,14 @@ import (
 func getAdminClaims(c echo.Context) admin.PassportClaims {
 	return c.Get(xhttp.KeyCtxClaims).(admin.PassportClaims)
 }
+
+// getReaderClaims is used by endpoints guarded by reader's
+// RequireLoggedIn middleware.
+func getReaderClaims(c echo.Context) reader.PassportClaims {
+	return c.Get(xhttp.KeyCtxClaims).(reader.PassportClaims)
+}
+
+// getScimSetting is used by endpoints guarded by RequireScimToken.
+func getScimSetting(c echo.Context) admin.ScimSetting {
+	return c.Get(xhttp.KeyCtxScim).(admin.ScimSetting)
+}
@@ -0,0 +1,178 @@
+package b2b
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
+	"github.com/FTChinese/ftacademy/internal/pkg/letter"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/FTChinese/ftacademy/internal/pkg/webhook"
+	"github.com/FTChinese/ftacademy/internal/repository/subsrepo"
+	"time"
+)
+
+// archiveGrant saves the licence version of a grant, or a
+// reservation, and the membership replaced by it.
+// It should be run in a goroutine.
+func (router SubsRouter) archiveGrant(result licence.GrantResult) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	err := router.repo.SaveVersionedLicence(result.LicenceVersion)
+	if err != nil {
+		sugar.Error(err)
+	}
+
+	// Membership is not touched until a reserved licence is
+	// actually granted.
+	if result.Deferred {
+		return
+	}
+
+	err = router.repo.ArchiveMembership(result.MembershipVersion)
+	if err != nil {
+		sugar.Error(err)
+	}
+}
+
+// sendLicenceGranted queues the letter of a granted licence
+// in the name of the admin.
+func (router SubsRouter) sendLicenceGranted(lic licence.Licence, assignee licence.Assignee, adminID string) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	profile, err := router.repo.LoadB2BAdminProfile(adminID)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	parcel, err := letter.LicenceGrantedParcel(lic, assignee, profile)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	err = router.post.Deliver(parcel)
+	if err != nil {
+		sugar.Error(err)
+	}
+}
+
+// notifyReserved tells both admin and reader that the grant
+// is pending.
+func (router SubsRouter) notifyReserved(result licence.GrantResult, assignee licence.Assignee) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	profile, err := router.repo.LoadB2BAdminProfile(result.LicenceVersion.PostChange.AdminID)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	parcel, err := letter.LicenceReservedParcel(result, assignee, profile)
+	if err != nil {
+		sugar.Error(err)
+	} else if err := router.post.Deliver(parcel); err != nil {
+		sugar.Error(err)
+	}
+
+	parcel, err = letter.ReaderReservedParcel(result, assignee, profile)
+	if err != nil {
+		sugar.Error(err)
+	} else if err := router.post.Deliver(parcel); err != nil {
+		sugar.Error(err)
+	}
+}
+
+// ApplyDeferredGrants checks every reserved licence and
+// grants it if the assignee's auto-renewal subscription ended.
+func (router SubsRouter) ApplyDeferredGrants() {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	list, err := router.repo.ListDeferredGrants()
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	for _, d := range list {
+		result, err := router.repo.ApplyDeferredGrant(d)
+		if err != nil {
+			switch err {
+			case subsrepo.ErrGrantNotReady:
+				// Checked again in next round.
+			case subsrepo.ErrSwitchingLicence:
+				router.releaseReservation(d)
+			default:
+				sugar.Errorf("Deferred grant of licence %s failed: %v", d.LicenceID, err)
+			}
+			continue
+		}
+
+		sugar.Infof("Deferred grant of licence %s applied", d.LicenceID)
+
+		go router.hooks.Emit(
+			result.LicenceVersion.PostChange.TeamID,
+			webhook.EventLicenceGranted,
+			result.LicenceVersion.PostChange.Licence)
+
+		router.audit.save(audit.NewEntry(audit.SystemActor(audit.JobDeferredGrant), audit.ActionLicenceGrant).
+			WithTeam(d.TeamID).
+			WithTarget(d.LicenceID).
+			WithChange(result.LicenceVersion.AnteChange.Licence, result.LicenceVersion.PostChange.Licence))
+
+		router.archiveGrant(result)
+
+		assignee, err := router.repo.RetrieveAssignee(d.AssigneeID)
+		if err != nil {
+			sugar.Error(err)
+			continue
+		}
+
+		router.sendLicenceGranted(
+			result.LicenceVersion.PostChange.Licence,
+			assignee,
+			result.LicenceVersion.PostChange.AdminID)
+	}
+}
+
+// releaseReservation gives up a deferred grant which could
+// never be applied since the reader is using another licence,
+// so that the seat goes to someone else rather than being
+// retried forever.
+func (router SubsRouter) releaseReservation(d licence.DeferredGrant) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	v, err := router.repo.ReleaseReservation(d)
+	if err != nil {
+		sugar.Errorf("Releasing reserved licence %s failed: %v", d.LicenceID, err)
+		return
+	}
+
+	sugar.Infof("Reserved licence %s released since reader %s is using another licence", d.LicenceID, d.AssigneeID)
+
+	router.audit.save(audit.NewEntry(audit.SystemActor(audit.JobDeferredGrant), audit.ActionLicenceRevoke).
+		WithTeam(d.TeamID).
+		WithTarget(d.LicenceID).
+		WithChange(v.AnteChange.Licence, v.PostChange.Licence))
+
+	err = router.repo.SaveVersionedLicence(v)
+	if err != nil {
+		sugar.Error(err)
+	}
+
+	router.waitlist.Fill(d.TeamID)
+}
+
+// WatchDeferredGrants runs ApplyDeferredGrants periodically.
+// It blocks and should be run in a goroutine.
+func (router SubsRouter) WatchDeferredGrants(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		router.ApplyDeferredGrants()
+	}
+}
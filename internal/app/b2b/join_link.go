@@ -0,0 +1,196 @@
+package b2b
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/FTChinese/ftacademy/internal/pkg/webhook"
+	"github.com/FTChinese/ftacademy/internal/repository/subsrepo"
+	gorest "github.com/FTChinese/go-rest"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+func (router SubsRouter) ListJoinLinks(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	var page gorest.Pagination
+	if err := c.Bind(&page); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	list, err := router.repo.ListJoinLinks(claims.TeamID.String, page)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// CreateJoinLink creates a shareable link.
+// Input:
+// tier: standard | premium;
+// cycle: month | year;
+// seatCap: number; 1 - 1000
+// domains: string[]; Allowed email domains.
+// expirationDays?: number; Default 30.
+// description?: string;
+func (router SubsRouter) CreateJoinLink(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.JoinLinkParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	link, err := licence.NewJoinLink(params, admin.Creator{
+		AdminID: claims.AdminID,
+		TeamID:  claims.TeamID.String,
+	})
+	if err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	err = router.repo.CreateJoinLink(link)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, link)
+}
+
+// RevokeJoinLink stops a link from granting more licences.
+func (router SubsRouter) RevokeJoinLink(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	link, err := router.repo.RevokeJoinLink(admin.AccessRight{
+		RowID:  c.Param("id"),
+		TeamID: claims.TeamID.String,
+	})
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, link)
+}
+
+// VerifyJoinLink is used when a reader opens a join link
+// to show what the link offers.
+//
+// Status code:
+// 404 if link not found, or is no longer joinable.
+func (router SubsRouter) VerifyJoinLink(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	link, err := router.repo.JoinLinkByToken(c.Param("token"))
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+	if !link.IsJoinable() {
+		return render.NewNotFound(subsrepo.ErrInvalidJoinLink.Error())
+	}
+
+	team, err := router.repo.LoadTeam(link.TeamID)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, licence.JoinLinkVerified{
+		Link:     link,
+		TeamName: team.OrgName,
+	})
+}
+
+// JoinTeam grants a licence via a join link to a logged-in
+// reader whose email is under the allowed domains.
+//
+// Status code:
+// 404 if link is not joinable;
+// 422 if email not verified or not allowed, already joined,
+// or no licence left;
+// 202 Accepted if reader has a valid auto-renewal subscription
+// and the licence is reserved for deferred grant.
+func (router SubsRouter) JoinTeam(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getReaderClaims(c)
+
+	assignee, err := router.repo.RetrieveAssignee(claims.FtcID)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+	if !assignee.Email.Valid {
+		return render.NewUnprocessable(&render.ValidationError{
+			Message: "An email is required to join a team",
+			Field:   "email",
+			Code:    render.CodeMissingField,
+		})
+	}
+
+	result, err := router.repo.JoinTeam(c.Param("token"), assignee)
+	if err != nil {
+		sugar.Error(err)
+		switch err {
+		case subsrepo.ErrInvalidJoinLink:
+			return render.NewNotFound(err.Error())
+
+		case subsrepo.ErrEmailNotVerified, subsrepo.ErrDomainNotAllowed:
+			return render.NewUnprocessable(&render.ValidationError{
+				Message: err.Error(),
+				Field:   "email",
+				Code:    render.CodeInvalid,
+			})
+
+		case subsrepo.ErrAlreadyJoined:
+			return render.NewUnprocessable(&render.ValidationError{
+				Message: err.Error(),
+				Field:   "link",
+				Code:    render.CodeAlreadyExists,
+			})
+
+		case subsrepo.ErrNoAvailableLicence:
+			return render.NewUnprocessable(&render.ValidationError{
+				Message: err.Error(),
+				Field:   "licence",
+				Code:    render.CodeInvalid,
+			})
+
+		default:
+			return render.NewDBError(err)
+		}
+	}
+
+	go router.archiveGrant(result.GrantResult)
+
+	if result.Deferred {
+		router.notifyReserved(result.GrantResult, assignee)
+
+		return c.JSON(http.StatusAccepted, result)
+	}
+
+	go router.hooks.Emit(
+		result.LicenceVersion.PostChange.TeamID,
+		webhook.EventLicenceGranted,
+		result.LicenceVersion.PostChange.Licence)
+
+	router.sendLicenceGranted(
+		result.LicenceVersion.PostChange.Licence,
+		assignee,
+		result.LicenceVersion.PostChange.AdminID)
+
+	return c.JSON(http.StatusOK, result)
+}
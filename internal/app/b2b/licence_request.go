@@ -0,0 +1,294 @@
+package b2b
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/letter"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/FTChinese/ftacademy/internal/pkg/webhook"
+	"github.com/FTChinese/ftacademy/internal/repository/subsrepo"
+	"github.com/FTChinese/ftacademy/pkg/throttle"
+	"github.com/FTChinese/ftacademy/pkg/xhttp"
+	gorest "github.com/FTChinese/go-rest"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// CreateLicenceRequest is used by a logged-in reader to ask
+// for a licence with company email.
+// The team is found by the email's domain registered in CMS.
+// A letter is sent to the email to verify it.
+// Input:
+// email: string;
+//
+// Status code:
+// 422 if no team registered the domain, or a request
+// is already waiting for verification or approval;
+// 429 if too many requests are created.
+func (router SubsRouter) CreateLicenceRequest(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getReaderClaims(c)
+
+	var params input.LicenceRequestParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	limitKeys := []string{
+		throttle.KeyFtcID(claims.FtcID),
+		throttle.KeyIP(c.RealIP()),
+		throttle.KeyEmail(params.Email),
+	}
+	d, err := router.requestLimiter.Check(limitKeys...)
+	if err != nil {
+		sugar.Error(err)
+	} else if !d.Allowed {
+		return xhttp.TooManyRequests(c, d)
+	}
+
+	lr, err := router.repo.CreateLicenceRequest(claims.FtcID, params)
+	if err != nil {
+		sugar.Error(err)
+		switch err {
+		case subsrepo.ErrNoTeamForDomain:
+			return render.NewUnprocessable(&render.ValidationError{
+				Message: err.Error(),
+				Field:   "email",
+				Code:    render.CodeInvalid,
+			})
+
+		case subsrepo.ErrRequestExists:
+			return render.NewUnprocessable(&render.ValidationError{
+				Message: err.Error(),
+				Field:   "request",
+				Code:    render.CodeAlreadyExists,
+			})
+
+		default:
+			return render.NewDBError(err)
+		}
+	}
+
+	// Every request sends a letter.
+	if err := router.requestLimiter.Hit(limitKeys...); err != nil {
+		sugar.Error(err)
+	}
+
+	router.sendLicenceRequestVrf(lr, params.EmailDomain())
+
+	return c.JSON(http.StatusOK, lr)
+}
+
+// sendLicenceRequestVrf queues the letter to verify the
+// company email of a request.
+func (router SubsRouter) sendLicenceRequestVrf(lr licence.LicenceRequest, domain string) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	assignee, err := router.repo.RetrieveAssignee(lr.FtcID)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	team, err := router.repo.TeamByDomain(domain)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	parcel, err := letter.LicenceRequestVrfParcel(lr, assignee, team)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	err = router.post.Deliver(parcel)
+	if err != nil {
+		sugar.Error(err)
+	}
+}
+
+// ListReaderLicenceRequests shows a reader's own requests.
+func (router SubsRouter) ListReaderLicenceRequests(c echo.Context) error {
+	claims := getReaderClaims(c)
+
+	list, err := router.repo.ListReaderLicenceRequests(claims.FtcID)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// VerifyLicenceRequest verifies the token sent to company
+// email and notifies admin that a request is waiting.
+func (router SubsRouter) VerifyLicenceRequest(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getReaderClaims(c)
+	token := c.Param("token")
+
+	lr, err := router.repo.VerifyLicenceRequest(token, claims.FtcID)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	router.sendLicenceRequested(lr)
+
+	return c.JSON(http.StatusOK, lr)
+}
+
+// sendLicenceRequested queues the letter telling team owner
+// that a verified request is waiting for approval.
+func (router SubsRouter) sendLicenceRequested(lr licence.LicenceRequest) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	team, err := router.repo.LoadTeam(lr.TeamID)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	profile, err := router.repo.LoadB2BAdminProfile(team.AdminID)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	parcel, err := letter.LicenceRequestedParcel(lr, profile)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	err = router.post.Deliver(parcel)
+	if err != nil {
+		sugar.Error(err)
+	}
+}
+
+// ListLicenceRequests shows the approval queue of a team.
+// Query parameters:
+// ?status=unverified | pending | approved | rejected
+// Default to pending.
+func (router SubsRouter) ListLicenceRequests(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	var page gorest.Pagination
+	if err := c.Bind(&page); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	status, err := licence.ParseRequestStatus(c.QueryParam("status"))
+	if err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	list, err := router.repo.ListLicenceRequests(claims.TeamID.String, status, page)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// ApproveLicenceRequest grants a licence to the reader
+// without sending an invitation email.
+// Input:
+// licenceId?: string; Pick one if omitted.
+//
+// Status code:
+// 404 if request is not found or not pending;
+// 422 if no licence available.
+func (router SubsRouter) ApproveLicenceRequest(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.ApproveRequestParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	result, err := router.repo.ApproveLicenceRequest(
+		admin.AccessRight{
+			RowID:  c.Param("id"),
+			TeamID: claims.TeamID.String,
+		},
+		params,
+		claims)
+	if err != nil {
+		sugar.Error(err)
+		switch err {
+		case subsrepo.ErrNoAvailableLicence, subsrepo.ErrLicenceUnavailable:
+			return render.NewUnprocessable(&render.ValidationError{
+				Message: err.Error(),
+				Field:   "licenceId",
+				Code:    render.CodeInvalid,
+			})
+
+		default:
+			return render.NewDBError(err)
+		}
+	}
+
+	go router.archiveGrant(result.GrantResult)
+
+	// The invitation is created and accepted on behalf of the
+	// reader in one step.
+	inv := result.LicenceVersion.PostChange.Licence.LatestInvitation
+	router.audit.record(c, audit.NewEntry(claimsActor(claims), audit.ActionInvitationCreate).
+		WithTeam(claims.TeamID.String).
+		WithTarget(inv.ID).
+		WithChange(nil, inv))
+
+	if result.Deferred {
+		assignee, err := router.repo.RetrieveAssignee(result.Request.FtcID)
+		if err != nil {
+			sugar.Error(err)
+		} else {
+			router.notifyReserved(result.GrantResult, assignee)
+		}
+
+		return c.JSON(http.StatusAccepted, result)
+	}
+
+	go router.hooks.Emit(
+		claims.TeamID.String,
+		webhook.EventLicenceGranted,
+		result.LicenceVersion.PostChange.Licence)
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// RejectLicenceRequest removes a request from approval queue.
+func (router SubsRouter) RejectLicenceRequest(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	lr, err := router.repo.RejectLicenceRequest(admin.AccessRight{
+		RowID:  c.Param("id"),
+		TeamID: claims.TeamID.String,
+	})
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, lr)
+}
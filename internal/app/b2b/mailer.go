@@ -0,0 +1,99 @@
+package b2b
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/outbox"
+	"github.com/FTChinese/ftacademy/internal/repository/outboxrepo"
+	"github.com/FTChinese/ftacademy/pkg/db"
+	"github.com/FTChinese/ftacademy/pkg/postman"
+	"go.uber.org/zap"
+	"time"
+)
+
+// Mailer is a postman.Postman which saves each parcel to
+// the outbox before handing it to the transport. Letters
+// failed to send are retried by Watch with exponential
+// backoff and moved to dead letters after outbox.MaxAttempts.
+type Mailer struct {
+	repo      outboxrepo.Env
+	transport postman.Postman
+	logger    *zap.Logger
+}
+
+func NewMailer(dbs db.ReadWriteMyDBs, transport postman.Postman, logger *zap.Logger) Mailer {
+	return Mailer{
+		repo:      outboxrepo.NewEnv(dbs, logger),
+		transport: transport,
+		logger:    logger,
+	}
+}
+
+// Deliver queues a parcel and sends it in background.
+// An error is returned only if the parcel is not saved.
+func (m Mailer) Deliver(p postman.Parcel) error {
+	msg := outbox.NewMessage(p, time.Now())
+
+	err := m.repo.CreateMessage(msg)
+	if err != nil {
+		return err
+	}
+
+	go m.attempt(msg)
+
+	return nil
+}
+
+func (m Mailer) attempt(msg outbox.Message) {
+	defer m.logger.Sync()
+	sugar := m.logger.Sugar()
+
+	err := m.transport.Deliver(msg.Parcel())
+	msg = msg.Attempted(err, time.Now())
+
+	if err != nil {
+		sugar.Infof("Letter %s to %s failed after %d attempts: %v", msg.ID, msg.ToAddress, msg.Attempts, err)
+	}
+
+	err = m.repo.UpdateMessage(msg)
+	if err != nil {
+		sugar.Error(err)
+	}
+}
+
+// DeliverDue resends pending letters whose backoff expired.
+func (m Mailer) DeliverDue() {
+	defer m.logger.Sync()
+	sugar := m.logger.Sugar()
+
+	list, err := m.repo.ClaimDueMessages(100)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	for _, msg := range list {
+		m.attempt(msg)
+	}
+}
+
+// Resend puts a dead letter back to queue and sends it.
+func (m Mailer) Resend(id string) (outbox.Message, error) {
+	msg, err := m.repo.ResendMessage(id)
+	if err != nil {
+		return outbox.Message{}, err
+	}
+
+	go m.attempt(msg)
+
+	return msg, nil
+}
+
+// Watch runs DeliverDue periodically.
+// It blocks and should be run in a goroutine.
+func (m Mailer) Watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		m.DeliverDue()
+	}
+}
@@ -0,0 +1,281 @@
+package b2b
+
+import (
+	"fmt"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/export"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/FTChinese/ftacademy/internal/pkg/webhook"
+	"github.com/FTChinese/ftacademy/internal/repository/subsrepo"
+	"github.com/FTChinese/ftacademy/pkg/qr"
+	"github.com/FTChinese/ftacademy/pkg/throttle"
+	"github.com/FTChinese/ftacademy/pkg/xhttp"
+	gorest "github.com/FTChinese/go-rest"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// ListRedeemCodes shows redemption codes of a team.
+// Query parameters:
+// ?status=active | redeemed | revoked | expired
+// Default to active.
+func (router SubsRouter) ListRedeemCodes(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	var page gorest.Pagination
+	if err := c.Bind(&page); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	status, err := licence.ParseRedeemStatus(c.QueryParam("status"))
+	if err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	list, err := router.repo.ListRedeemCodes(claims.TeamID.String, status, page)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// CreateRedeemCodes converts available licences into codes.
+// Input:
+// count: number; 1 - 200
+// expirationDays?: number; Default 30.
+// description?: string;
+//
+// Status code:
+// 422 if there are not enough available licences.
+func (router SubsRouter) CreateRedeemCodes(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.RedeemCodeParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	codes, err := router.repo.CreateRedeemCodes(params, admin.Creator{
+		AdminID: claims.AdminID,
+		TeamID:  claims.TeamID.String,
+	})
+	if err != nil {
+		sugar.Error(err)
+		switch err {
+		case subsrepo.ErrNoAvailableLicence:
+			return render.NewUnprocessable(&render.ValidationError{
+				Message: "Not enough licences available",
+				Field:   "count",
+				Code:    render.CodeInvalid,
+			})
+
+		default:
+			return render.NewDBError(err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, codes)
+}
+
+// ExportRedeemCodes downloads all active codes as a csv file.
+func (router SubsRouter) ExportRedeemCodes(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	codes, err := router.repo.ListActiveRedeemCodes(claims.TeamID.String)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="redeem-codes.csv"`)
+	c.Response().WriteHeader(http.StatusOK)
+
+	w := export.NewCSVWriter(c.Response())
+	if err := w.Write(licence.RedeemCodeCSVHeader); err != nil {
+		return err
+	}
+	for _, rc := range codes {
+		if err := w.Write(rc.CSVRow()); err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
+}
+
+// RedeemCodeQR downloads the QR image of an active code,
+// which encodes the url to redeem it.
+func (router SubsRouter) RedeemCodeQR(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	rc, err := router.repo.LoadRedeemCode(admin.AccessRight{
+		RowID:  c.Param("id"),
+		TeamID: claims.TeamID.String,
+	})
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	if !rc.IsRedeemable() {
+		return render.NewNotFound("The code is no longer redeemable")
+	}
+
+	png, err := qr.PNG(rc.BuildURL())
+	if err != nil {
+		return render.NewInternalError(err.Error())
+	}
+
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="%s"`, rc.FileName("png")))
+
+	return c.Blob(http.StatusOK, "image/png", png)
+}
+
+// RevokeRedeemCode invalidates an active code and
+// its licence becomes available again.
+func (router SubsRouter) RevokeRedeemCode(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	result, err := router.repo.RevokeRedeemCode(admin.AccessRight{
+		RowID:  c.Param("id"),
+		TeamID: claims.TeamID.String,
+	})
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	go router.waitlist.Fill(claims.TeamID.String)
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// Redeem is used by a logged-in reader to claim the licence
+// held by a code.
+// Input:
+// code: string;
+//
+// Status code:
+// 422 if code is invalid, or reader has no email;
+// 429 if too many failed attempts;
+// 202 Accepted if reader has a valid auto-renewal subscription
+// and the licence is reserved for deferred grant.
+func (router SubsRouter) Redeem(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getReaderClaims(c)
+
+	limitKeys := []string{
+		throttle.KeyFtcID(claims.FtcID),
+		throttle.KeyIP(c.RealIP()),
+	}
+	d, err := router.redeemLimiter.Check(limitKeys...)
+	if err != nil {
+		sugar.Error(err)
+	} else if !d.Allowed {
+		return xhttp.TooManyRequests(c, d)
+	}
+
+	var params input.RedeemParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	if ve := params.Validate(); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	assignee, err := router.repo.RetrieveAssignee(claims.FtcID)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+	if !assignee.Email.Valid {
+		return render.NewUnprocessable(&render.ValidationError{
+			Message: "An email is required to redeem a licence",
+			Field:   "email",
+			Code:    render.CodeMissingField,
+		})
+	}
+
+	result, err := router.repo.RedeemCode(params.Code, assignee)
+	if err != nil {
+		sugar.Error(err)
+		switch err {
+		case subsrepo.ErrInvalidRedeemCode:
+			if err := router.redeemLimiter.Hit(limitKeys...); err != nil {
+				sugar.Error(err)
+			}
+			return render.NewUnprocessable(&render.ValidationError{
+				Message: err.Error(),
+				Field:   "code",
+				Code:    render.CodeInvalid,
+			})
+
+		default:
+			return render.NewDBError(err)
+		}
+	}
+
+	go router.archiveGrant(result.GrantResult)
+
+	if result.Deferred {
+		router.notifyReserved(result.GrantResult, assignee)
+
+		return c.JSON(http.StatusAccepted, result)
+	}
+
+	go router.hooks.Emit(
+		result.LicenceVersion.PostChange.TeamID,
+		webhook.EventLicenceGranted,
+		result.LicenceVersion.PostChange.Licence)
+
+	router.sendLicenceGranted(
+		result.LicenceVersion.PostChange.Licence,
+		assignee,
+		result.Code.AdminID)
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// ExpireRedeemCodes releases licences held by expired codes
+// and fills them from waitlist.
+func (router SubsRouter) ExpireRedeemCodes() {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	list, err := router.repo.ListExpiredRedeemCodes()
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	var teams = map[string]bool{}
+	for _, rc := range list {
+		_, err := router.repo.ExpireRedeemCode(rc)
+		if err != nil {
+			sugar.Errorf("Expiring redeem code %s failed: %v", rc.ID, err)
+			continue
+		}
+		teams[rc.TeamID] = true
+	}
+
+	for teamID := range teams {
+		router.waitlist.Fill(teamID)
+	}
+}
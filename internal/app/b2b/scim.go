@@ -0,0 +1,366 @@
+package b2b
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/FTChinese/ftacademy/internal/pkg/scim"
+	"github.com/FTChinese/ftacademy/internal/repository/subsrepo"
+	"github.com/FTChinese/ftacademy/pkg/xhttp"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/guregu/null"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+// scimJSON writes a SCIM response.
+func scimJSON(c echo.Context, code int, v interface{}) error {
+	c.Response().Header().Set(echo.HeaderContentType, scim.ContentType)
+	return c.JSON(code, v)
+}
+
+func scimError(c echo.Context, code int, scimType string, detail string) error {
+	return scimJSON(c, code, scim.NewError(code, scimType, detail))
+}
+
+// bindScim decodes request body regardless of content type
+// since identity providers send application/scim+json
+// which echo's binder does not recognize.
+func bindScim(c echo.Context, v interface{}) error {
+	return json.NewDecoder(c.Request().Body).Decode(v)
+}
+
+func scimUsersURL(c echo.Context) string {
+	return c.Scheme() + "://" + c.Request().Host + "/scim/v2/Users"
+}
+
+// RequireScimToken authenticates identity provider with the
+// bearer token generated by team admin.
+// Subsequent handlers act on behalf of that admin.
+func (router SubsRouter) RequireScimToken(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token, err := xhttp.GetBearerAuth(c.Request().Header)
+		if err != nil {
+			return scimError(c, http.StatusUnauthorized, "", err.Error())
+		}
+
+		setting, err := router.repo.ScimSettingByToken(token)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return scimError(c, http.StatusUnauthorized, "", "Invalid bearer token")
+			}
+			return scimError(c, http.StatusInternalServerError, "", err.Error())
+		}
+
+		// The token cannot do more than its creator, whose
+		// role might be changed after token generated.
+		ms, err := router.repo.MemberScope(setting.TeamID, setting.AdminID)
+		if err != nil {
+			return scimError(c, http.StatusInternalServerError, "", err.Error())
+		}
+		if ms.Role == admin.RoleNull {
+			return scimError(c, http.StatusUnauthorized, "", "Creator of this token is no longer an admin of the team")
+		}
+		if ms.LacksTwoFactor() {
+			return scimError(c, http.StatusForbidden, "", "Team requires two-factor authentication for creator of this token")
+		}
+		// Provisioning works on the whole roster.
+		if ms.IsUnitScoped() || !ms.CanRequest(admin.AreaLicences, c.Request().Method) {
+			return scimError(c, http.StatusForbidden, "", "Creator of this token is not allowed this action")
+		}
+
+		claims := setting.Claims()
+		claims.MemberScope = ms
+
+		c.Set(xhttp.KeyCtxScim, setting)
+		c.Set(xhttp.KeyCtxClaims, claims)
+		return next(c)
+	}
+}
+
+func (router SubsRouter) loadScimStaffer(c echo.Context) (licence.Staffer, error) {
+	setting := getScimSetting(c)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return licence.Staffer{}, scimError(c, http.StatusNotFound, "", "User not found")
+	}
+
+	s, err := router.repo.LoadStaffer(setting.TeamID, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return licence.Staffer{}, scimError(c, http.StatusNotFound, "", "User not found")
+		}
+		return licence.Staffer{}, scimError(c, http.StatusInternalServerError, "", err.Error())
+	}
+
+	return s, nil
+}
+
+// ScimListUsers lists staff of the team.
+// Query parameters:
+// filter=userName eq "email"
+// startIndex=1
+// count=100
+func (router SubsRouter) ScimListUsers(c echo.Context) error {
+	setting := getScimSetting(c)
+	base := scimUsersURL(c)
+
+	if f := c.QueryParam("filter"); f != "" {
+		email, err := scim.ParseFilter(f)
+		if err != nil {
+			return scimError(c, http.StatusBadRequest, scim.ErrTypeInvalidFilter, err.Error())
+		}
+
+		s, err := router.repo.StafferByEmail(setting.TeamID, email)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return scimJSON(c, http.StatusOK, scim.NewListResponse(nil, 0, 1))
+			}
+			return scimError(c, http.StatusInternalServerError, "", err.Error())
+		}
+
+		return scimJSON(c, http.StatusOK, scim.NewListResponse(
+			[]scim.User{scim.NewUser(s, base)},
+			1,
+			1))
+	}
+
+	paging := scim.ParsePaging(c.QueryParam("startIndex"), c.QueryParam("count"))
+
+	staff, total, err := router.repo.ListStaffFrom(setting.TeamID, paging.Offset(), paging.Count)
+	if err != nil {
+		return scimError(c, http.StatusInternalServerError, "", err.Error())
+	}
+
+	var users = make([]scim.User, 0, len(staff))
+	for _, s := range staff {
+		users = append(users, scim.NewUser(s, base))
+	}
+
+	return scimJSON(c, http.StatusOK, scim.NewListResponse(users, total, paging.StartIndex))
+}
+
+func (router SubsRouter) ScimGetUser(c echo.Context) error {
+	s, err := router.loadScimStaffer(c)
+	if err != nil {
+		return err
+	}
+
+	return scimJSON(c, http.StatusOK, scim.NewUser(s, scimUsersURL(c)))
+}
+
+// ScimCreateUser adds a staffer. If the team turned on
+// auto-invite, an invitation is sent with an available licence.
+func (router SubsRouter) ScimCreateUser(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	setting := getScimSetting(c)
+
+	var u scim.User
+	if err := bindScim(c, &u); err != nil {
+		return scimError(c, http.StatusBadRequest, scim.ErrTypeInvalidValue, err.Error())
+	}
+
+	params := u.StafferParams()
+	if ve := params.Validate(); ve != nil {
+		return scimError(c, http.StatusBadRequest, scim.ErrTypeInvalidValue, ve.Message)
+	}
+
+	a, err := router.repo.FindAssignee(params.Email)
+	if err != nil {
+		sugar.Error(err)
+		return scimError(c, http.StatusInternalServerError, "", err.Error())
+	}
+
+	staffer := licence.NewStaffer(params, setting.TeamID, a).
+		WithActive(u.IsActive())
+
+	s, err := router.repo.CreateStaffer(staffer)
+	if err != nil {
+		if err == subsrepo.ErrStafferExists {
+			return scimError(c, http.StatusConflict, scim.ErrTypeUniqueness, err.Error())
+		}
+		sugar.Error(err)
+		return scimError(c, http.StatusInternalServerError, "", err.Error())
+	}
+
+	if setting.AutoInvite && s.Active {
+		go router.scimInvite(setting, s)
+	}
+
+	return scimJSON(c, http.StatusCreated, scim.NewUser(s, scimUsersURL(c)))
+}
+
+// ScimPatchUser supports changing the active attribute.
+// Deactivating revokes the licence of the staffer.
+func (router SubsRouter) ScimPatchUser(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	setting := getScimSetting(c)
+
+	var op scim.PatchOp
+	if err := bindScim(c, &op); err != nil {
+		return scimError(c, http.StatusBadRequest, scim.ErrTypeInvalidValue, err.Error())
+	}
+
+	active, found, err := op.Active()
+	if err != nil {
+		return scimError(c, http.StatusBadRequest, scim.ErrTypeInvalidValue, err.Error())
+	}
+
+	s, err := router.loadScimStaffer(c)
+	if err != nil {
+		return err
+	}
+
+	if !found || active == s.Active {
+		return scimJSON(c, http.StatusOK, scim.NewUser(s, scimUsersURL(c)))
+	}
+
+	result, err := router.repo.SetStafferActive(s, active)
+	if err != nil {
+		sugar.Error(err)
+		return scimError(c, http.StatusInternalServerError, "", err.Error())
+	}
+
+	go router.stafferLicenceReleased(audit.ScimActor(setting.TeamID), setting.TeamID, result)
+
+	if active && setting.AutoInvite {
+		go router.scimInvite(setting, result.Staffer)
+	}
+
+	return scimJSON(c, http.StatusOK, scim.NewUser(result.Staffer, scimUsersURL(c)))
+}
+
+// ScimDeleteUser removes a staffer and revokes the licence.
+func (router SubsRouter) ScimDeleteUser(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	setting := getScimSetting(c)
+
+	s, err := router.loadScimStaffer(c)
+	if err != nil {
+		return err
+	}
+
+	// Identity provider manages the whole team.
+	result, err := router.repo.RemoveStaffer(s, true, null.String{})
+	if err != nil {
+		sugar.Error(err)
+		return scimError(c, http.StatusInternalServerError, "", err.Error())
+	}
+
+	go router.stafferLicenceReleased(audit.ScimActor(setting.TeamID), setting.TeamID, result)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// scimInvite invites a provisioned staffer if any licence
+// is available.
+func (router SubsRouter) scimInvite(setting admin.ScimSetting, s licence.Staffer) {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	invited, err := router.repo.InviteStaff(input.StaffInvitationParams{
+		StaffIDs:    []int64{s.ID},
+		Description: null.StringFrom("Provisioned by SCIM"),
+	}, setting.Claims())
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	router.sendInvitations(invited, setting.AdminID)
+}
+
+// LoadScimSetting shows whether SCIM is enabled for the team.
+func (router SubsRouter) LoadScimSetting(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	s, err := router.repo.LoadScimSetting(claims.TeamID.String)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, s)
+}
+
+// CreateScimToken generates a new bearer token, replacing
+// the previous one. The token is only shown once.
+// Input:
+// autoInvite: boolean;
+func (router SubsRouter) CreateScimToken(c echo.Context) error {
+	defer router.logger.Sync()
+	sugar := router.logger.Sugar()
+
+	claims := getAdminClaims(c)
+
+	var params input.ScimSettingParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	s, token, err := admin.NewScimSetting(admin.Creator{
+		AdminID: claims.AdminID,
+		TeamID:  claims.TeamID.String,
+	}, params.AutoInvite)
+	if err != nil {
+		return render.NewInternalError(err.Error())
+	}
+
+	err = router.repo.SaveScimSetting(s)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, admin.ScimTokenCreated{
+		ScimSetting: s,
+		Token:       token,
+	})
+}
+
+// UpdateScimSetting turns on/off auto-invite.
+// Input:
+// autoInvite: boolean;
+func (router SubsRouter) UpdateScimSetting(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	var params input.ScimSettingParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	s, err := router.repo.LoadScimSetting(claims.TeamID.String)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	s = s.WithAutoInvite(params.AutoInvite)
+	err = router.repo.UpdateScimAutoInvite(s)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, s)
+}
+
+// DeleteScimSetting disables SCIM and invalidates the token.
+func (router SubsRouter) DeleteScimSetting(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	err := router.repo.DeleteScimSetting(claims.TeamID.String)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
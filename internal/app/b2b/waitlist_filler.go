@@ -0,0 +1,104 @@
+package b2b
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
+	"github.com/FTChinese/ftacademy/internal/pkg/letter"
+	"github.com/FTChinese/ftacademy/internal/pkg/webhook"
+	"github.com/FTChinese/ftacademy/internal/repository/subsrepo"
+	"github.com/FTChinese/ftacademy/pkg/postman"
+	"go.uber.org/zap"
+)
+
+// waitlistFiller invites people on a team's waitlist, or
+// notifies admin, after licences become available.
+// It is shared by routers where licences are released:
+// revoking licence, invitation expiry and payment confirmation.
+type waitlistFiller struct {
+	repo   subsrepo.Env
+	hooks  hookDispatcher
+	post   postman.Postman
+	audit  auditTrail
+	logger *zap.Logger
+}
+
+func newWaitlistFiller(repo subsrepo.Env, hooks hookDispatcher, pm postman.Postman, trail auditTrail, logger *zap.Logger) waitlistFiller {
+	return waitlistFiller{
+		repo:   repo,
+		hooks:  hooks,
+		post:   pm,
+		audit:  trail,
+		logger: logger,
+	}
+}
+
+// Fill should be run in a goroutine.
+func (f waitlistFiller) Fill(teamID string) {
+	defer f.logger.Sync()
+	sugar := f.logger.Sugar()
+
+	result, err := f.repo.FillWaitlist(teamID)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	for _, lic := range result.Invited {
+		go f.hooks.Emit(teamID, webhook.EventInvitationCreated, lic.LatestInvitation)
+
+		f.audit.save(audit.NewEntry(audit.SystemActor(audit.JobWaitlist), audit.ActionInvitationCreate).
+			WithTeam(teamID).
+			WithTarget(lic.LatestInvitation.ID).
+			WithChange(nil, lic.LatestInvitation))
+	}
+
+	if len(result.Invited) == 0 && !result.ShouldNotifyAdmin() {
+		return
+	}
+
+	team, err := f.repo.LoadTeam(teamID)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	profile, err := f.repo.LoadB2BAdminProfile(team.AdminID)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	if result.ShouldNotifyAdmin() {
+		parcel, err := letter.SeatsAvailableParcel(result, profile)
+		if err != nil {
+			sugar.Error(err)
+			return
+		}
+
+		err = f.post.Deliver(parcel)
+		if err != nil {
+			sugar.Error(err)
+		}
+		return
+	}
+
+	for _, lic := range result.Invited {
+		assignee, err := f.repo.FindAssignee(lic.LatestInvitation.Email)
+		if err != nil {
+			sugar.Error(err)
+			continue
+		}
+
+		parcel, err := letter.InvitationParcel(assignee, lic, profile)
+		if err != nil {
+			sugar.Error(err)
+			continue
+		}
+
+		err = f.post.Deliver(parcel)
+		if err != nil {
+			sugar.Error(err)
+		} else {
+			sugar.Infof("Waitlist invitation sent to %s", lic.LatestInvitation.Email)
+		}
+	}
+}
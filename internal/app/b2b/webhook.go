@@ -0,0 +1,183 @@
+package b2b
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/webhook"
+	gorest "github.com/FTChinese/go-rest"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/guregu/null"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+)
+
+// The following handlers are shared by team admins and CMS.
+// teamID is null when endpoints are managed in CMS.
+
+func (h hookDispatcher) listEndpoints(c echo.Context, teamID null.String) error {
+	list, err := h.repo.ListEndpoints(teamID)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+func (h hookDispatcher) createEndpoint(c echo.Context, teamID, adminID null.String) error {
+	defer h.logger.Sync()
+	sugar := h.logger.Sugar()
+
+	var params input.WebhookParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	if ve := params.Validate(webhook.IsValidEvent); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	ep, err := webhook.NewEndpoint(params, teamID, adminID)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewInternalError(err.Error())
+	}
+
+	err = h.repo.CreateEndpoint(ep)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, webhook.EndpointCreated{
+		Endpoint: ep,
+		Secret:   ep.Secret,
+	})
+}
+
+func (h hookDispatcher) updateEndpoint(c echo.Context, teamID null.String) error {
+	defer h.logger.Sync()
+	sugar := h.logger.Sugar()
+
+	id := c.Param("id")
+
+	var params input.WebhookParams
+	if err := c.Bind(&params); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	if ve := params.Validate(webhook.IsValidEvent); ve != nil {
+		return render.NewUnprocessable(ve)
+	}
+
+	ep, err := h.repo.LoadEndpoint(id, teamID)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	ep = ep.Update(params)
+	err = h.repo.UpdateEndpoint(ep)
+	if err != nil {
+		sugar.Error(err)
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, ep)
+}
+
+func (h hookDispatcher) deleteEndpoint(c echo.Context, teamID null.String) error {
+	id := c.Param("id")
+
+	err := h.repo.DeleteEndpoint(id, teamID)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h hookDispatcher) listDeliveries(c echo.Context, teamID null.String) error {
+	id := c.Param("id")
+
+	var page gorest.Pagination
+	if err := c.Bind(&page); err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+
+	// Ensure endpoint is owned by the caller.
+	_, err := h.repo.LoadEndpoint(id, teamID)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	list, err := h.repo.ListDeliveries(id, page)
+	if err != nil {
+		return render.NewDBError(err)
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+func (router SubsRouter) ListWebhooks(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	return router.hooks.listEndpoints(c, claims.TeamID)
+}
+
+// CreateWebhook registers an endpoint of a team.
+// The signing secret is only shown in this response.
+// Input:
+// url: string; Must be https.
+// events: string[];
+// description?: string;
+func (router SubsRouter) CreateWebhook(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	return router.hooks.createEndpoint(c, claims.TeamID, null.StringFrom(claims.AdminID))
+}
+
+// UpdateWebhook changes url, events or active state.
+// Input: the same as CreateWebhook plus active: boolean.
+func (router SubsRouter) UpdateWebhook(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	return router.hooks.updateEndpoint(c, claims.TeamID)
+}
+
+func (router SubsRouter) DeleteWebhook(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	return router.hooks.deleteEndpoint(c, claims.TeamID)
+}
+
+// ListWebhookDeliveries shows the delivery log of an endpoint.
+func (router SubsRouter) ListWebhookDeliveries(c echo.Context) error {
+	claims := getAdminClaims(c)
+
+	return router.hooks.listDeliveries(c, claims.TeamID)
+}
+
+// WatchWebhookDeliveries retries failed deliveries.
+// It blocks and should be run in a goroutine.
+func (router SubsRouter) WatchWebhookDeliveries(interval time.Duration) {
+	router.hooks.Watch(interval)
+}
+
+// Endpoints registered in CMS receive events of all teams.
+
+func (router CMSRouter) ListWebhooks(c echo.Context) error {
+	return router.hooks.listEndpoints(c, null.String{})
+}
+
+func (router CMSRouter) CreateWebhook(c echo.Context) error {
+	return router.hooks.createEndpoint(c, null.String{}, null.String{})
+}
+
+func (router CMSRouter) UpdateWebhook(c echo.Context) error {
+	return router.hooks.updateEndpoint(c, null.String{})
+}
+
+func (router CMSRouter) DeleteWebhook(c echo.Context) error {
+	return router.hooks.deleteEndpoint(c, null.String{})
+}
+
+func (router CMSRouter) ListWebhookDeliveries(c echo.Context) error {
+	return router.hooks.listDeliveries(c, null.String{})
+}
@@ -0,0 +1,123 @@
+package b2b
+
+import (
+	"database/sql"
+	"github.com/FTChinese/ftacademy/internal/pkg/webhook"
+	"github.com/FTChinese/ftacademy/internal/repository/webhookrepo"
+	"github.com/FTChinese/ftacademy/pkg/db"
+	"go.uber.org/zap"
+	"time"
+)
+
+// hookDispatcher sends events to webhook endpoints
+// subscribing to them. Failed deliveries are saved and
+// retried by Watch with exponential backoff.
+type hookDispatcher struct {
+	repo   webhookrepo.Env
+	client webhook.Client
+	logger *zap.Logger
+}
+
+func newHookDispatcher(dbs db.ReadWriteMyDBs, logger *zap.Logger) hookDispatcher {
+	return hookDispatcher{
+		repo:   webhookrepo.NewEnv(dbs, logger),
+		client: webhook.NewClient(10 * time.Second),
+		logger: logger,
+	}
+}
+
+// Emit should be run in a goroutine.
+func (h hookDispatcher) Emit(teamID string, t webhook.EventType, data interface{}) {
+	defer h.logger.Sync()
+	sugar := h.logger.Sugar()
+
+	endpoints, err := h.repo.SubscribedEndpoints(teamID, t)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	if len(endpoints) == 0 {
+		return
+	}
+
+	evt := webhook.NewEvent(t, teamID, data)
+
+	for _, ep := range endpoints {
+		d, err := webhook.NewDelivery(ep, evt)
+		if err != nil {
+			sugar.Error(err)
+			continue
+		}
+
+		// Save before sending so that it could be retried
+		// even if the process stopped.
+		err = h.repo.CreateDelivery(d)
+		if err != nil {
+			sugar.Error(err)
+			continue
+		}
+
+		h.attempt(ep, d)
+	}
+}
+
+func (h hookDispatcher) attempt(ep webhook.Endpoint, d webhook.Delivery) {
+	defer h.logger.Sync()
+	sugar := h.logger.Sugar()
+
+	result := h.client.Send(ep, d)
+	d = d.Attempted(result, time.Now())
+
+	if !result.Ok() {
+		sugar.Infof("Webhook delivery %s to %s failed with status %d: %v", d.ID, ep.URL, result.StatusCode, result.Err)
+	}
+
+	err := h.repo.UpdateDelivery(d)
+	if err != nil {
+		sugar.Error(err)
+	}
+}
+
+// RetryDue resends pending deliveries whose backoff or
+// lease expired.
+func (h hookDispatcher) RetryDue() {
+	defer h.logger.Sync()
+	sugar := h.logger.Sugar()
+
+	list, err := h.repo.ClaimDueDeliveries(100)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	for _, d := range list {
+		ep, err := h.repo.RetrieveEndpoint(d.EndpointID)
+		if err != nil && err != sql.ErrNoRows {
+			sugar.Error(err)
+			continue
+		}
+
+		// Stop retrying if endpoint is removed or disabled.
+		if err == sql.ErrNoRows || !ep.Active {
+			err = h.repo.UpdateDelivery(d.Abandoned("Endpoint removed or disabled", time.Now()))
+			if err != nil {
+				sugar.Error(err)
+			}
+			continue
+		}
+
+		h.attempt(ep, d)
+	}
+}
+
+// Watch runs RetryDue periodically.
+// It blocks and should be run in a goroutine.
+func (h hookDispatcher) Watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		h.RetryDue()
+	}
+}
@@ -2,6 +2,8 @@ package reader
 
 import (
 	"github.com/FTChinese/ftacademy/pkg/fetch"
+	"github.com/FTChinese/ftacademy/pkg/throttle"
+	"github.com/FTChinese/ftacademy/pkg/xhttp"
 	"github.com/FTChinese/go-rest/render"
 	"github.com/labstack/echo/v4"
 	"log"
@@ -30,12 +32,34 @@ func (router Router) EmailLogin(c echo.Context) error {
 
 	log.Printf("Real IP: %v", header)
 
+	k, err := peekLimitKeys(c)
+	if err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	limitKeys := []string{
+		throttle.KeyIP(c.RealIP()),
+		throttle.KeyEmail(k.Email),
+	}
+	if d := checkLimit(router.loginLimiter, limitKeys...); !d.Allowed {
+		return xhttp.TooManyRequests(c, d)
+	}
+
 	resp, err := router.clients.Select(true).
 		EmailLogin(c.Request().Body, header)
 	if err != nil {
 		return render.NewInternalError(err.Error())
 	}
 
+	// Only wrong credentials are counted.
+	switch resp.StatusCode {
+	case 200:
+		_ = router.loginLimiter.Reset(throttle.KeyEmail(k.Email))
+	case 403, 404:
+		if err := router.loginLimiter.Hit(limitKeys...); err != nil {
+			log.Print(err)
+		}
+	}
+
 	return router.handlePassport(c, resp)
 }
 
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"github.com/FTChinese/ftacademy/internal/pkg/reader"
 	"github.com/FTChinese/ftacademy/pkg/fetch"
+	"github.com/FTChinese/ftacademy/pkg/throttle"
+	"github.com/FTChinese/ftacademy/pkg/xhttp"
 	"github.com/FTChinese/go-rest/render"
 	"github.com/labstack/echo/v4"
 	"log"
@@ -13,6 +15,22 @@ import (
 // Input:
 // * mobile: string
 func (router Router) RequestMobileLoginSMS(c echo.Context) error {
+	k, err := peekLimitKeys(c)
+	if err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	// Every request is counted since each one sends an SMS.
+	limitKeys := []string{
+		throttle.KeyIP(c.RealIP()),
+		throttle.KeyMobile(k.Mobile),
+	}
+	if d := checkLimit(router.smsLimiter, limitKeys...); !d.Allowed {
+		return xhttp.TooManyRequests(c, d)
+	}
+	if err := router.smsLimiter.Hit(limitKeys...); err != nil {
+		log.Print(err)
+	}
+
 	resp, err := router.clients.Select(true).RequestLoginSMS(c.Request().Body)
 
 	if err != nil {
@@ -2,8 +2,11 @@ package reader
 
 import (
 	"github.com/FTChinese/ftacademy/pkg/fetch"
+	"github.com/FTChinese/ftacademy/pkg/throttle"
+	"github.com/FTChinese/ftacademy/pkg/xhttp"
 	"github.com/FTChinese/go-rest/render"
 	"github.com/labstack/echo/v4"
+	"log"
 )
 
 // ResetPassword allow user to change password.
@@ -36,6 +39,22 @@ func (router Router) ResetPassword(c echo.Context) error {
 //
 // The footprint.Client headers are required.
 func (router Router) RequestPwResetLetter(c echo.Context) error {
+	k, err := peekLimitKeys(c)
+	if err != nil {
+		return render.NewBadRequest(err.Error())
+	}
+	// Every request is counted since each one sends an email.
+	limitKeys := []string{
+		throttle.KeyIP(c.RealIP()),
+		throttle.KeyEmail(k.Email),
+	}
+	if d := checkLimit(router.pwResetLimiter, limitKeys...); !d.Allowed {
+		return xhttp.TooManyRequests(c, d)
+	}
+	if err := router.pwResetLimiter.Hit(limitKeys...); err != nil {
+		log.Print(err)
+	}
+
 	resp, err := router.clients.Select(true).
 		RequestPasswordResetLetter(c.Request().Body)
 
@@ -6,6 +6,7 @@ import (
 	"github.com/FTChinese/ftacademy/internal/pkg/reader"
 	"github.com/FTChinese/ftacademy/pkg/config"
 	"github.com/FTChinese/ftacademy/pkg/fetch"
+	"github.com/FTChinese/ftacademy/pkg/throttle"
 	"github.com/FTChinese/ftacademy/pkg/xhttp"
 	"github.com/FTChinese/go-rest/render"
 	"github.com/labstack/echo/v4"
@@ -18,18 +19,26 @@ type Router struct {
 	clients api.Clients
 	wxApp   config.WechatApp
 	version string
+	// Limits login, SMS and password reset requests per IP,
+	// email and mobile.
+	loginLimiter   throttle.Limiter
+	smsLimiter     throttle.Limiter
+	pwResetLimiter throttle.Limiter
 }
 
-func NewReaderRouter(clients api.Clients, version string) Router {
+func NewReaderRouter(clients api.Clients, store throttle.Store, version string) Router {
 	return Router{
 		guard: reader.NewJWTGuard(
 			config.
 				MustGetReaderAppKey().
-				GetJWTKey(),
+				MustGetJWTKeyring(reader.PassportTTL),
 		),
-		clients: clients,
-		wxApp:   config.MustWxWebApp(),
-		version: version,
+		clients:        clients,
+		wxApp:          config.MustWxWebApp(),
+		version:        version,
+		loginLimiter:   throttle.NewLimiter("reader_login", store, loginRule),
+		smsLimiter:     throttle.NewLimiter("reader_sms", store, smsRule),
+		pwResetLimiter: throttle.NewLimiter("reader_pw_reset", store, pwResetRule),
 	}
 }
 
@@ -94,3 +103,11 @@ func (router Router) OptionalLoggedIn(next echo.HandlerFunc) echo.HandlerFunc {
 		return next(c)
 	}
 }
+
+// JWKS publishes public keys so that other services could
+// verify reader JWT without the secret.
+//
+//	GET /api/reader/auth/jwks/
+func (router Router) JWKS(c echo.Context) error {
+	return c.JSON(http.StatusOK, router.guard.JWKS())
+}
@@ -0,0 +1,76 @@
+package reader
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/FTChinese/ftacademy/pkg/throttle"
+	"github.com/labstack/echo/v4"
+	"io"
+	"log"
+	"time"
+)
+
+var (
+	// Failed logins per IP and email.
+	loginRule = throttle.Rule{
+		Limit:     30,
+		Window:    15 * time.Minute,
+		Free:      3,
+		BaseDelay: time.Second,
+		MaxDelay:  30 * time.Second,
+	}
+	// SMS codes sent per IP and mobile.
+	smsRule = throttle.Rule{
+		Limit:     10,
+		Window:    time.Hour,
+		Free:      1,
+		BaseDelay: time.Minute,
+		MaxDelay:  10 * time.Minute,
+	}
+	// Password reset letters per IP and email.
+	pwResetRule = throttle.Rule{
+		Limit:     5,
+		Window:    time.Hour,
+		Free:      1,
+		BaseDelay: 30 * time.Second,
+		MaxDelay:  10 * time.Minute,
+	}
+)
+
+// limitKeys is used to peek the fields used as throttle key
+// in the request body forwarded to API.
+type limitKeys struct {
+	Email  string `json:"email"`
+	Mobile string `json:"mobile"`
+}
+
+// peekLimitKeys reads the request body and replaces it
+// with a copy so that it could still be forwarded.
+func peekLimitKeys(c echo.Context) (limitKeys, error) {
+	var k limitKeys
+
+	b, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return k, err
+	}
+	_ = c.Request().Body.Close()
+	c.Request().Body = io.NopCloser(bytes.NewReader(b))
+
+	// Malformed body is left to API to reject.
+	_ = json.Unmarshal(b, &k)
+
+	return k, nil
+}
+
+// checkLimit tests keys against a limiter. Errors of the
+// store are only logged so that readers are not blocked by
+// them.
+func checkLimit(l throttle.Limiter, keys ...string) throttle.Decision {
+	d, err := l.Check(keys...)
+	if err != nil {
+		log.Print(err)
+		return throttle.Decision{Allowed: true}
+	}
+
+	return d
+}
@@ -1,17 +1,16 @@
 package reader
 
 import (
+	"github.com/FTChinese/ftacademy/pkg/qr"
 	"github.com/FTChinese/go-rest/render"
 	"github.com/labstack/echo/v4"
-	"github.com/skip2/go-qrcode"
 	"net/http"
 )
 
 func GenerateQRImage(c echo.Context) error {
 	v := c.QueryParam("url")
 
-	var png []byte
-	png, err := qrcode.Encode(v, qrcode.Medium, 256)
+	png, err := qr.PNG(v)
 	if err != nil {
 		return render.NewInternalError(err.Error())
 	}
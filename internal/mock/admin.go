@@ -7,6 +7,7 @@ import (
 	"github.com/FTChinese/ftacademy/internal/pkg/ids"
 	"github.com/FTChinese/ftacademy/internal/pkg/input"
 	"github.com/FTChinese/ftacademy/pkg/faker"
+	"github.com/FTChinese/ftacademy/pkg/pwhash"
 	"github.com/FTChinese/go-rest/chrono"
 	"github.com/brianvoe/gofakeit/v5"
 	"github.com/google/uuid"
@@ -16,10 +17,15 @@ import (
 
 type Admin struct {
 	admin.Account
+	Password string // Plain password to test login.
 }
 
 func NewAdmin() Admin {
+	pw := faker.SimplePassword()
+	h, _ := pwhash.Hash(pw)
+
 	return Admin{
+		Password: pw,
 		Account: admin.Account{
 			BaseAccount: admin.BaseAccount{
 				ID:          uuid.New().String(),
@@ -29,8 +35,8 @@ func NewAdmin() Admin {
 				Active:      true,
 				Verified:    false,
 			},
-			Password:   faker.SimplePassword(),
-			CreatedUTC: chrono.TimeNow(),
+			PasswordHash: h,
+			CreatedUTC:   chrono.TimeNow(),
 		},
 	}
 }
@@ -1,5 +1,7 @@
 package admin
 
+import "github.com/guregu/null"
+
 // AccessRight is used to limit admin's right to data.
 // Image what happens when data is only retrieved by row id?
 // Admin could visit a specific row using url like:
@@ -7,7 +9,10 @@ package admin
 // Just by simply change the id an admin could access the row,
 // even if the row is not created by him/her.
 // Ideally we should only allow a team access its data created by it.
+// UnitID further limits a unit manager to rows of its own
+// unit. Null means the whole team is accessible.
 type AccessRight struct {
 	RowID  string
 	TeamID string
+	UnitID null.String
 }
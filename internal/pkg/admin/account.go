@@ -2,6 +2,7 @@ package admin
 
 import (
 	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/pkg/pwhash"
 	"github.com/FTChinese/go-rest/chrono"
 	"github.com/google/uuid"
 	"github.com/guregu/null"
@@ -12,6 +13,7 @@ import (
 type BaseAccount struct {
 	ID          string      `json:"id" db:"admin_id"`
 	TeamID      null.String `json:"teamId" db:"team_id"`
+	TeamRole    Role        `json:"teamRole" db:"team_role"` // Owner if team is created by this admin, otherwise the role as co-admin.
 	Email       string      `json:"email" db:"email"`
 	DisplayName null.String `json:"displayName" db:"display_name"`
 	Active      bool        `json:"active" db:"active"`
@@ -32,15 +34,22 @@ func (a BaseAccount) UpdateName(name string) BaseAccount {
 }
 
 // Account is used to created an admin.
+// Password is hashed in application so that plain text
+// never reaches db.
 type Account struct {
 	BaseAccount
-	Password   string      `json:"-" db:"password"`
-	CreatedUTC chrono.Time `json:"createdUtc" db:"created_utc"`
-	UpdatedUTC chrono.Time `json:"updatedUtc" db:"updated_utc"`
+	PasswordHash string      `json:"-" db:"password_hash"`
+	CreatedUTC   chrono.Time `json:"createdUtc" db:"created_utc"`
+	UpdatedUTC   chrono.Time `json:"updatedUtc" db:"updated_utc"`
 }
 
 // NewAccount creates a new instance from signup parameters.
-func NewAccount(p input.SignupParams) Account {
+func NewAccount(p input.SignupParams) (Account, error) {
+	h, err := pwhash.Hash(p.Password)
+	if err != nil {
+		return Account{}, err
+	}
+
 	return Account{
 		BaseAccount: BaseAccount{
 			ID:          uuid.New().String(),
@@ -50,8 +59,8 @@ func NewAccount(p input.SignupParams) Account {
 			Active:      true,
 			Verified:    false,
 		},
-		Password:   p.Password,
-		CreatedUTC: chrono.TimeNow(),
-		UpdatedUTC: chrono.Time{},
-	}
+		PasswordHash: h,
+		CreatedUTC:   chrono.TimeNow(),
+		UpdatedUTC:   chrono.Time{},
+	}, nil
 }
@@ -4,24 +4,30 @@ const StmtCreateAdmin = `
 INSERT INTO b2b.admin
 SET id = :admin_id,
 	email = :email,
-	password_sha2 = UNHEX(SHA2(:password, 256)),
+	password_hash = :password_hash,
 	created_utc = :created_utc`
 
 const colBaseAccount = `
 SELECT a.id AS admin_id,
 	t.id AS team_id,
+	IF(t.admin_id = a.id, 'owner', m.member_role) AS team_role,
 	a.email AS email,
 	a.display_name AS display_name,
 	a.is_active AS active,
 	a.verified AS verified
 `
 
-const selectBaseAccount = colBaseAccount + `
+// An admin either owns a team or is a co-admin of it.
+const fromAdminTeam = `
 FROM b2b.admin AS a
+	LEFT JOIN b2b.team_member AS m
+	ON a.id = m.admin_id
 	LEFT JOIN b2b.team AS t
-	ON a.id = t.admin_id
+	ON a.id = t.admin_id OR m.team_id = t.id
 `
 
+const selectBaseAccount = colBaseAccount + fromAdminTeam
+
 const StmtBaseAccountByID = selectBaseAccount + `
 WHERE a.id = ?
 LIMIT 1`
@@ -37,9 +43,12 @@ SET display_name = :display_name,
 WHERE id = :admin_id
 LIMIT 1`
 
+// StmtUpdatePassword saves argon2id hash and removes the
+// legacy SHA-256 one.
 const StmtUpdatePassword = `
 UPDATE b2b.admin
-SET password_sha2 = UNHEX(SHA2(:password, 256)),
+SET password_hash = :password_hash,
+	password_sha2 = NULL,
 	updated_utc = UTC_TIMESTAMP()
 WHERE id = :admin_id
 LIMIT 1`
@@ -0,0 +1,172 @@
+package admin
+
+import (
+	"database/sql/driver"
+	"errors"
+	"github.com/FTChinese/ftacademy/internal/pkg/ids"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/FTChinese/go-rest/rand"
+	"github.com/guregu/null"
+	"strings"
+)
+
+// APIKeyPrefix tells an API key from a JWT in
+// Authorization header.
+const APIKeyPrefix = "ftak_"
+
+// IsAPIKey checks whether a bearer credential is an API key.
+func IsAPIKey(s string) bool {
+	return strings.HasPrefix(s, APIKeyPrefix)
+}
+
+type APIScope string
+
+// Area is the resources a scope gives access to.
+func (s APIScope) Area() Area {
+	switch s {
+	case ScopeOrdersRead:
+		return AreaOrders
+	default:
+		return AreaLicences
+	}
+}
+
+const (
+	ScopeLicencesRead     APIScope = "licences:read"
+	ScopeInvitationsRead  APIScope = "invitations:read"
+	ScopeInvitationsWrite APIScope = "invitations:write"
+	ScopeOrdersRead       APIScope = "orders:read"
+)
+
+var apiScopes = map[APIScope]bool{
+	ScopeLicencesRead:     true,
+	ScopeInvitationsRead:  true,
+	ScopeInvitationsWrite: true,
+	ScopeOrdersRead:       true,
+}
+
+func IsValidScope(s string) bool {
+	return apiScopes[APIScope(s)]
+}
+
+// ScopeList is saved as space separated string, the same
+// as OAuth's scope parameter.
+type ScopeList []APIScope
+
+func NewScopeList(s []string) ScopeList {
+	var l = make(ScopeList, 0, len(s))
+	for _, v := range s {
+		l = append(l, APIScope(v))
+	}
+
+	return l
+}
+
+func (l ScopeList) Has(s APIScope) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l ScopeList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+
+	var s = make([]string, 0, len(l))
+	for _, v := range l {
+		s = append(s, string(v))
+	}
+
+	return strings.Join(s, " "), nil
+}
+
+func (l *ScopeList) Scan(src interface{}) error {
+	if src == nil {
+		*l = ScopeList{}
+		return nil
+	}
+
+	switch s := src.(type) {
+	case []byte:
+		*l = NewScopeList(strings.Fields(string(s)))
+		return nil
+
+	default:
+		return errors.New("incompatible type to scan")
+	}
+}
+
+// APIKey lets team's internal systems call b2b api
+// on behalf of the admin who created it.
+type APIKey struct {
+	ID string `json:"id" db:"key_id"`
+	Creator
+	Name        string      `json:"name" db:"key_name"`
+	Hint        string      `json:"hint" db:"key_hint"` // The first few characters to help admin identify a key.
+	KeyHash     string      `json:"-" db:"key_hash"`
+	Scopes      ScopeList   `json:"scopes" db:"scopes"`
+	LastUsedUTC chrono.Time `json:"lastUsedUtc" db:"last_used_utc"`
+	RevokedUTC  chrono.Time `json:"revokedUtc" db:"revoked_utc"`
+	RowTime
+}
+
+// NewAPIKey generates a key. The plain key is only returned
+// here and should be shown to admin once.
+func NewAPIKey(params input.APIKeyParams, by Creator) (APIKey, string, error) {
+	secret, err := rand.Hex(32)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	key := APIKeyPrefix + secret
+
+	return APIKey{
+		ID:      ids.APIKeyID(),
+		Creator: by,
+		Name:    params.Name,
+		Hint:    key[:len(APIKeyPrefix)+6],
+		KeyHash: HashToken(key),
+		Scopes:  NewScopeList(params.Scopes),
+		RowTime: NewRowTime(),
+	}, key, nil
+}
+
+func (k APIKey) IsRevoked() bool {
+	return !k.RevokedUTC.IsZero()
+}
+
+func (k APIKey) Update(params input.APIKeyParams) APIKey {
+	k.Name = params.Name
+	k.Scopes = NewScopeList(params.Scopes)
+	k.UpdatedUTC = chrono.TimeNow()
+
+	return k
+}
+
+func (k APIKey) Revoked() APIKey {
+	k.RevokedUTC = chrono.TimeNow()
+	k.UpdatedUTC = chrono.TimeNow()
+
+	return k
+}
+
+// Claims builds the passport equivalent of a JWT so that
+// handlers work the same for keys and logged-in admins.
+func (k APIKey) Claims() PassportClaims {
+	return PassportClaims{
+		AdminID:  k.AdminID,
+		TeamID:   null.StringFrom(k.TeamID),
+		APIKeyID: k.ID,
+	}
+}
+
+// APIKeyCreated is returned once after key generated.
+type APIKeyCreated struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,57 @@
+package admin
+
+const StmtCreateAPIKey = `
+INSERT INTO b2b.api_key
+SET key_id = :key_id,
+	team_id = :team_id,
+	admin_id = :admin_id,
+	key_name = :key_name,
+	key_hint = :key_hint,
+	key_hash = UNHEX(:key_hash),
+	scopes = :scopes,
+	created_utc = :created_utc,
+	updated_utc = :updated_utc`
+
+const colAPIKey = `
+SELECT key_id,
+	team_id,
+	admin_id,
+	key_name,
+	key_hint,
+	LOWER(HEX(key_hash)) AS key_hash,
+	scopes,
+	last_used_utc,
+	revoked_utc,
+	created_utc,
+	updated_utc
+FROM b2b.api_key
+`
+
+const StmtListAPIKeys = colAPIKey + `
+WHERE team_id = ?
+ORDER BY created_utc DESC`
+
+const StmtAPIKey = colAPIKey + `
+WHERE key_id = ?
+	AND team_id = ?
+LIMIT 1`
+
+const StmtAPIKeyByHash = colAPIKey + `
+WHERE key_hash = UNHEX(?)
+LIMIT 1`
+
+const StmtUpdateAPIKey = `
+UPDATE b2b.api_key
+SET key_name = :key_name,
+	scopes = :scopes,
+	revoked_utc = :revoked_utc,
+	updated_utc = :updated_utc
+WHERE key_id = :key_id
+	AND team_id = :team_id
+LIMIT 1`
+
+const StmtTouchAPIKey = `
+UPDATE b2b.api_key
+SET last_used_utc = UTC_TIMESTAMP()
+WHERE key_id = ?
+LIMIT 1`
@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+)
+
+func TestScopeList_Scan(t *testing.T) {
+	tests := []struct {
+		name string
+		src  interface{}
+		want ScopeList
+	}{
+		{
+			name: "Null",
+			src:  nil,
+			want: ScopeList{},
+		},
+		{
+			name: "Multiple scopes",
+			src:  []byte("licences:read orders:read"),
+			want: ScopeList{ScopeLicencesRead, ScopeOrdersRead},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ScopeList
+			if err := got.Scan(tt.src); err != nil {
+				t.Error(err)
+				return
+			}
+
+			if len(got) != len(tt.want) {
+				t.Errorf("Scan() = %v, want %v", got, tt.want)
+				return
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Scan() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewAPIKey(t *testing.T) {
+	key, plain, err := NewAPIKey(input.APIKeyParams{
+		Name:   "HR system",
+		Scopes: []string{"invitations:write"},
+	}, Creator{
+		AdminID: "admin",
+		TeamID:  "team",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsAPIKey(plain) {
+		t.Errorf("expected prefix %s, got %s", APIKeyPrefix, plain)
+	}
+
+	if key.KeyHash != HashToken(plain) {
+		t.Error("key hash mismatched")
+	}
+
+	if !key.Scopes.Has(ScopeInvitationsWrite) || key.Scopes.Has(ScopeOrdersRead) {
+		t.Errorf("unexpected scopes %v", key.Scopes)
+	}
+}
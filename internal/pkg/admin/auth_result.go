@@ -1,5 +1,12 @@
 package admin
 
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/pkg/pwhash"
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/guregu/null"
+)
+
 // AuthResult contains the data retrieved from db after
 // authenticating password for the specified email.
 // If db returns ErrNoRows, it indicates the specified email does not
@@ -7,6 +14,63 @@ package admin
 // The the PasswordMatched field is false, it indicates the account
 // exists but password is not correct.
 type AuthResult struct {
-	AdminID         string `db:"id"`
-	PasswordMatched bool   `db:"password_matched"`
+	AdminID         string      `db:"id"`
+	PasswordHash    null.String `db:"password_hash"` // argon2id in PHC format.
+	LegacyHash      null.String `db:"password_sha2"` // Unsalted SHA-256 in hex, used before argon2id.
+	PasswordMatched bool        `db:"-"`
+	NeedsUpgrade    bool        `db:"-"` // Password matched but hash should be replaced.
+}
+
+// Verify compares password against hash retrieved from db.
+// Legacy hash is only checked if the account has no argon2id
+// hash yet.
+func (r AuthResult) Verify(password string) (AuthResult, error) {
+	if r.PasswordHash.Valid {
+		ok, err := pwhash.Verify(password, r.PasswordHash.String)
+		if err != nil {
+			return r, err
+		}
+		r.PasswordMatched = ok
+		r.NeedsUpgrade = ok && pwhash.NeedsRehash(r.PasswordHash.String)
+
+		return r, nil
+	}
+
+	r.PasswordMatched = r.LegacyHash.Valid && pwhash.VerifyLegacy(password, r.LegacyHash.String)
+	r.NeedsUpgrade = r.PasswordMatched
+
+	return r, nil
+}
+
+// PasswordHashed is used to save a new password.
+type PasswordHashed struct {
+	ID   string `db:"admin_id"`
+	Hash string `db:"password_hash"`
+}
+
+func NewPasswordHashed(id, password string) (PasswordHashed, error) {
+	h, err := pwhash.Hash(password)
+	if err != nil {
+		return PasswordHashed{}, err
+	}
+
+	return PasswordHashed{
+		ID:   id,
+		Hash: h,
+	}, nil
+}
+
+// LegacyPasswordAccount is an admin whose password is still
+// hashed with SHA-256, i.e., not logged in since migration.
+type LegacyPasswordAccount struct {
+	ID         string      `json:"id" db:"admin_id"`
+	Email      string      `json:"email" db:"email"`
+	TeamID     null.String `json:"teamId" db:"team_id"`
+	CreatedUTC chrono.Time `json:"createdUtc" db:"created_utc"`
+	UpdatedUTC chrono.Time `json:"updatedUtc" db:"updated_utc"`
+}
+
+type LegacyPasswordList struct {
+	pkg.PagedList
+	Data []LegacyPasswordAccount `json:"data"`
 }
@@ -0,0 +1,65 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/FTChinese/ftacademy/pkg/pwhash"
+	"github.com/guregu/null"
+)
+
+func TestAuthResult_Verify(t *testing.T) {
+	h, _ := pwhash.Hash("12345678")
+	// SHA-256 of 12345678
+	legacy := "ef797c8118f02dfb649607dd5d3f8c7623048c9c063d532cc95c5ed7a898a64f"
+
+	tests := []struct {
+		name        string
+		result      AuthResult
+		password    string
+		wantMatched bool
+		wantUpgrade bool
+	}{
+		{
+			name:        "Argon2id hash",
+			result:      AuthResult{PasswordHash: null.StringFrom(h)},
+			password:    "12345678",
+			wantMatched: true,
+			wantUpgrade: false,
+		},
+		{
+			name:        "Legacy hash is upgraded",
+			result:      AuthResult{LegacyHash: null.StringFrom(legacy)},
+			password:    "12345678",
+			wantMatched: true,
+			wantUpgrade: true,
+		},
+		{
+			name:        "Wrong password against legacy hash",
+			result:      AuthResult{LegacyHash: null.StringFrom(legacy)},
+			password:    "wrong",
+			wantMatched: false,
+			wantUpgrade: false,
+		},
+		{
+			name: "Legacy hash ignored once migrated",
+			result: AuthResult{
+				PasswordHash: null.StringFrom(h),
+				LegacyHash:   null.StringFrom(legacy),
+			},
+			password:    "wrong",
+			wantMatched: false,
+			wantUpgrade: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.result.Verify(tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.PasswordMatched != tt.wantMatched || got.NeedsUpgrade != tt.wantUpgrade {
+				t.Errorf("Verify() = %v, %v; want %v, %v", got.PasswordMatched, got.NeedsUpgrade, tt.wantMatched, tt.wantUpgrade)
+			}
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package admin
 
+// colAuthResult retrieves password hashes so that they are
+// verified in application.
 const colAuthResult = `
 SELECT id,
-	password_sha2 = UNHEX(SHA2(?, 256)) AS password_matched
+	password_hash,
+	LOWER(HEX(password_sha2)) AS password_sha2
 FROM b2b.admin
 `
 
@@ -13,3 +16,25 @@ LIMIT 1`
 const StmtVerifyPasswordByID = colAuthResult + `
 WHERE id = ?
 LIMIT 1`
+
+// StmtCountLegacyPassword counts admins whose password is
+// still hashed by SHA-256.
+const StmtCountLegacyPassword = `
+SELECT COUNT(*) AS row_count
+FROM b2b.admin
+WHERE password_hash IS NULL`
+
+const StmtListLegacyPassword = `
+SELECT a.id AS admin_id,
+	a.email,
+	t.id AS team_id,
+	a.created_utc,
+	a.updated_utc
+FROM b2b.admin AS a
+	LEFT JOIN b2b.team_member AS m
+	ON a.id = m.admin_id
+	LEFT JOIN b2b.team AS t
+	ON a.id = t.admin_id OR m.team_id = t.id
+WHERE a.password_hash IS NULL
+ORDER BY a.created_utc ASC
+LIMIT ? OFFSET ?`
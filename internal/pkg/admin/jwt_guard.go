@@ -1,27 +1,41 @@
 package admin
 
 import (
+	"errors"
+	"github.com/FTChinese/ftacademy/pkg/keyring"
 	"github.com/FTChinese/ftacademy/pkg/xhttp"
 	"log"
 	"net/http"
 )
 
+var ErrSessionRevoked = errors.New("session is revoked or expired, please log in again")
+
+// RevocationList tells whether the session of a token is
+// revoked.
+type RevocationList interface {
+	IsRevoked(sessionID string) bool
+}
+
 type JWTGuard struct {
-	signingKey []byte
+	keyring keyring.Keyring
+	revoked RevocationList
 }
 
-func NewJWTGuard(key []byte) JWTGuard {
+func NewJWTGuard(kr keyring.Keyring, revoked RevocationList) JWTGuard {
 	return JWTGuard{
-		signingKey: key,
+		keyring: kr,
+		revoked: revoked,
 	}
 }
 
-func (g JWTGuard) GetKey() []byte {
-	return g.signingKey
+// JWKS publishes public keys for other services to verify
+// tokens.
+func (g JWTGuard) JWKS() keyring.JWKSet {
+	return g.keyring.JWKS()
 }
 
-func (g JWTGuard) CreatePassport(a BaseAccount) (Passport, error) {
-	return NewPassport(a, g.signingKey)
+func (g JWTGuard) CreatePassport(a BaseAccount, sessionID string) (Passport, error) {
+	return NewPassport(a, sessionID, g.keyring)
 }
 
 func (g JWTGuard) RetrievePassportClaims(req *http.Request) (PassportClaims, error) {
@@ -31,11 +45,17 @@ func (g JWTGuard) RetrievePassportClaims(req *http.Request) (PassportClaims, err
 		return PassportClaims{}, err
 	}
 
-	claims, err := ParsePassportClaims(ss, g.signingKey)
+	claims, err := ParsePassportClaims(ss, g.keyring)
 	if err != nil {
 		log.Printf("Error parsing JWT %v", err)
 		return PassportClaims{}, err
 	}
 
+	// Tokens issued before sessions were introduced carry no
+	// jti and cannot be revoked, thus rejected.
+	if claims.SessionID() == "" || g.revoked.IsRevoked(claims.SessionID()) {
+		return PassportClaims{}, ErrSessionRevoked
+	}
+
 	return claims, nil
 }
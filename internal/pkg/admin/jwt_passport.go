@@ -2,6 +2,7 @@ package admin
 
 import (
 	"errors"
+	"github.com/FTChinese/ftacademy/pkg/keyring"
 	"github.com/golang-jwt/jwt"
 	"github.com/guregu/null"
 	"log"
@@ -27,6 +28,14 @@ func NewStandardClaims(expiresAt int64) jwt.StandardClaims {
 type PassportClaims struct {
 	AdminID string      `json:"aid"`
 	TeamID  null.String `json:"tid"`
+	// Role and unit are not signed into token so that changes
+	// take effect immediately. They are retrieved by guard.
+	MemberScope `json:"-"`
+	// APIKeyID is set when the request is authenticated by
+	// an API key instead of JWT.
+	APIKeyID string `json:"-"`
+	// Operator is set when CMS acts on behalf of a team.
+	Operator Operator `json:"-"`
 	jwt.StandardClaims
 }
 
@@ -37,18 +46,33 @@ type Passport struct {
 	BaseAccount
 	ExpiresAt int64  `json:"expiresAt"`
 	Token     string `json:"token"`
+	// Refresh token is only present when a session starts or
+	// is refreshed.
+	RefreshToken     string `json:"refreshToken,omitempty"`
+	RefreshExpiresAt int64  `json:"refreshExpiresAt,omitempty"`
 }
 
-func NewPassport(a BaseAccount, signingKey []byte) (Passport, error) {
+// AccessRight limits access to a row to the team, and the
+// unit if the admin is a unit manager.
+func (c PassportClaims) AccessRight(rowID string) AccessRight {
+	return AccessRight{
+		RowID:  rowID,
+		TeamID: c.TeamID.String,
+		UnitID: c.UnitID,
+	}
+}
+
+// NewPassport issues a short-lived access token bound to a
+// session.
+func NewPassport(a BaseAccount, sessionID string, kr keyring.Keyring) (Passport, error) {
 	claims := PassportClaims{
 		AdminID:        a.ID,
 		TeamID:         a.TeamID,
-		StandardClaims: NewStandardClaims(time.Now().Unix() + 86400*7),
+		StandardClaims: NewStandardClaims(time.Now().Add(AccessTokenTTL).Unix()),
 	}
+	claims.Id = sessionID
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	ss, err := token.SignedString(signingKey)
+	ss, err := kr.Sign(claims)
 
 	if err != nil {
 		return Passport{}, err
@@ -61,13 +85,21 @@ func NewPassport(a BaseAccount, signingKey []byte) (Passport, error) {
 	}, nil
 }
 
-func ParsePassportClaims(ss string, key []byte) (PassportClaims, error) {
-	token, err := jwt.ParseWithClaims(
-		ss,
-		&PassportClaims{},
-		func(token *jwt.Token) (i interface{}, err error) {
-			return key, nil
-		})
+// WithRefresh attaches the refresh token of the session.
+func (p Passport) WithRefresh(token string, s Session) Passport {
+	p.RefreshToken = token
+	p.RefreshExpiresAt = s.ExpiresUTC.Unix()
+
+	return p
+}
+
+// SessionID is the jti of the token.
+func (c PassportClaims) SessionID() string {
+	return c.Id
+}
+
+func ParsePassportClaims(ss string, kr keyring.Keyring) (PassportClaims, error) {
+	token, err := kr.ParseWithClaims(ss, &PassportClaims{})
 
 	if err != nil {
 		log.Printf("Parsing JWT error: %v", err)
@@ -0,0 +1,69 @@
+package admin
+
+import (
+	"fmt"
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/go-rest"
+	"github.com/FTChinese/go-rest/chrono"
+	"time"
+)
+
+const (
+	// LockoutThreshold is the number of failed logins within
+	// LockoutWindow that locks an account.
+	LockoutThreshold = 5
+	LockoutWindow    = 15 * time.Minute
+	lockoutDuration  = 30 * 60
+)
+
+// Lockout is recorded when an account is locked after
+// repeated failed logins. The account is unlocked when the
+// lock expires, or the admin clicked the link in the email
+// sent upon locking.
+type Lockout struct {
+	Token       string      `json:"-" db:"token"`
+	AdminID     string      `json:"adminId" db:"admin_id"`
+	ClientIP    string      `json:"clientIp" db:"client_ip"`
+	Failures    int         `json:"failures" db:"failures"`
+	ExpiresIn   int64       `json:"expiresIn" db:"expires_in"`
+	LockedUTC   chrono.Time `json:"lockedUtc" db:"locked_utc"`
+	UnlockedUTC chrono.Time `json:"unlockedUtc" db:"unlocked_utc"`
+}
+
+func NewLockout(adminID, ip string, failures int) (Lockout, error) {
+	token, err := gorest.RandomHex(32)
+	if err != nil {
+		return Lockout{}, err
+	}
+
+	return Lockout{
+		Token:     token,
+		AdminID:   adminID,
+		ClientIP:  ip,
+		Failures:  failures,
+		ExpiresIn: lockoutDuration,
+		LockedUTC: chrono.TimeNow(),
+	}, nil
+}
+
+func (l Lockout) LockedUntil() time.Time {
+	return l.LockedUTC.Add(time.Duration(l.ExpiresIn) * time.Second)
+}
+
+// IsActive checks whether the account is still locked.
+func (l Lockout) IsActive() bool {
+	return l.UnlockedUTC.IsZero() && l.LockedUntil().After(time.Now())
+}
+
+func (l Lockout) WithUnlocked() Lockout {
+	l.UnlockedUTC = chrono.TimeNow()
+	return l
+}
+
+func (l Lockout) BuildURL() string {
+	return pkg.B2BUnlockAccountURL(l.Token)
+}
+
+func (l Lockout) FormatDuration() string {
+	return fmt.Sprintf("%d分钟", l.ExpiresIn/60)
+}
@@ -0,0 +1,40 @@
+package admin
+
+const StmtCreateLockout = `
+INSERT INTO b2b.admin_lockout
+SET token = UNHEX(:token),
+	admin_id = :admin_id,
+	client_ip = :client_ip,
+	failures = :failures,
+	expires_in = :expires_in,
+	locked_utc = :locked_utc`
+
+const colLockout = `
+SELECT LOWER(HEX(token)) AS token,
+	admin_id,
+	client_ip,
+	failures,
+	expires_in,
+	locked_utc,
+	unlocked_utc
+FROM b2b.admin_lockout
+`
+
+// StmtActiveLockout retrieves the lock of an admin not yet
+// expired or unlocked.
+const StmtActiveLockout = colLockout + `
+WHERE admin_id = ?
+	AND unlocked_utc IS NULL
+	AND DATE_ADD(locked_utc, INTERVAL expires_in SECOND) > UTC_TIMESTAMP()
+ORDER BY locked_utc DESC
+LIMIT 1`
+
+const StmtLockoutByToken = colLockout + `
+WHERE token = UNHEX(?)
+LIMIT 1`
+
+const StmtUnlockAccount = `
+UPDATE b2b.admin_lockout
+SET unlocked_utc = :unlocked_utc
+WHERE token = UNHEX(:token)
+LIMIT 1`
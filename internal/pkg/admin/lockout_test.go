@@ -0,0 +1,46 @@
+package admin
+
+import (
+	"github.com/FTChinese/go-rest/chrono"
+	"testing"
+	"time"
+)
+
+func TestLockout_IsActive(t *testing.T) {
+	l, err := NewLockout("admin-id", "127.0.0.1", LockoutThreshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		l    Lockout
+		want bool
+	}{
+		{
+			name: "Newly locked",
+			l:    l,
+			want: true,
+		},
+		{
+			name: "Unlocked by email",
+			l:    l.WithUnlocked(),
+			want: false,
+		},
+		{
+			name: "Expired",
+			l: Lockout{
+				ExpiresIn: lockoutDuration,
+				LockedUTC: chrono.TimeFrom(time.Now().Add(-time.Hour)),
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.l.IsActive(); got != tt.want {
+				t.Errorf("IsActive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package admin
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/guregu/null"
+)
+
+// Operator is a CMS staff changing data on behalf of a team.
+// It tells who did it and why, the way Creator does for admins.
+type Operator struct {
+	Name   null.String `json:"operator" db:"operator_name"`
+	Reason null.String `json:"reason" db:"operator_reason"`
+}
+
+// NewOperator uses name of the staff using the CMS token and
+// the reason sent in request body.
+func NewOperator(name string, params input.OperatorParams) Operator {
+	return Operator{
+		Name:   null.NewString(name, name != ""),
+		Reason: null.NewString(params.Reason, params.Reason != ""),
+	}
+}
+
+// OnBehalfOf creates claims of a team's owner so that CMS
+// could reuse what is built for admins. The claims have
+// access to the whole team and carry the operator, which is
+// saved on rows created with them.
+func OnBehalfOf(t Team, o Operator) PassportClaims {
+	return PassportClaims{
+		AdminID: t.AdminID,
+		TeamID:  null.StringFrom(t.ID),
+		MemberScope: MemberScope{
+			Role: RoleOwner,
+		},
+		Operator: o,
+	}
+}
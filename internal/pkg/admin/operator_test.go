@@ -0,0 +1,34 @@
+package admin
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"testing"
+)
+
+func TestOnBehalfOf(t *testing.T) {
+	team := Team{
+		ID:      "t_abc",
+		AdminID: "a_owner",
+	}
+
+	claims := OnBehalfOf(team, NewOperator("alice", input.OperatorParams{
+		Reason: "Requested by email",
+	}))
+
+	if claims.AdminID != team.AdminID {
+		t.Errorf("AdminID = %s, want %s", claims.AdminID, team.AdminID)
+	}
+
+	r := claims.AccessRight("lic_1")
+	if r.TeamID != team.ID || r.UnitID.Valid {
+		t.Errorf("AccessRight() = %+v, want whole team %s", r, team.ID)
+	}
+
+	if claims.Role != RoleOwner {
+		t.Errorf("Role = %v, want owner", claims.Role)
+	}
+
+	if claims.Operator.Name.String != "alice" || claims.Operator.Reason.String != "Requested by email" {
+		t.Errorf("Operator = %+v, want alice with reason", claims.Operator)
+	}
+}
@@ -7,10 +7,8 @@ SELECT a.id AS admin_id,
 	a.is_active AS active,
 	a.verified AS verified,
 	t.id AS team_id,
+	IF(t.admin_id = a.id, 'owner', m.member_role) AS team_role,
 	IFNULL(t.org_name, '') AS org_name,
-	t.invoice_title
-FROM b2b.admin AS a
-	LEFT JOIN b2b.team AS t
-	ON a.id = t.admin_id
+	t.invoice_title` + fromAdminTeam + `
 WHERE a.id = ?
 LIMIT 1`
@@ -0,0 +1,55 @@
+package admin
+
+import (
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/FTChinese/go-rest/rand"
+	"github.com/guregu/null"
+)
+
+// ScimSetting enables SCIM provisioning for a team.
+// Requests from identity provider act on behalf of the
+// admin who created the token.
+type ScimSetting struct {
+	Creator
+	TokenHash  string `json:"-" db:"token_hash"`
+	AutoInvite bool   `json:"autoInvite" db:"auto_invite"` // Invite users created by identity provider with available licences.
+	RowTime
+}
+
+// NewScimSetting generates a new token. The plain token is
+// only returned here and should be shown to admin once.
+func NewScimSetting(by Creator, autoInvite bool) (ScimSetting, string, error) {
+	token, err := rand.Hex(32)
+	if err != nil {
+		return ScimSetting{}, "", err
+	}
+
+	return ScimSetting{
+		Creator:    by,
+		TokenHash:  HashToken(token),
+		AutoInvite: autoInvite,
+		RowTime:    NewRowTime(),
+	}, token, nil
+}
+
+func (s ScimSetting) WithAutoInvite(on bool) ScimSetting {
+	s.AutoInvite = on
+	s.UpdatedUTC = chrono.TimeNow()
+
+	return s
+}
+
+// Claims builds the passport of the admin on whose behalf
+// identity provider is acting.
+func (s ScimSetting) Claims() PassportClaims {
+	return PassportClaims{
+		AdminID: s.AdminID,
+		TeamID:  null.StringFrom(s.TeamID),
+	}
+}
+
+// ScimTokenCreated is returned once after token generated.
+type ScimTokenCreated struct {
+	ScimSetting
+	Token string `json:"token"`
+}
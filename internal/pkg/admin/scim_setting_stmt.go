@@ -0,0 +1,47 @@
+package admin
+
+// StmtUpsertScimSetting creates or rotates the token of a team.
+const StmtUpsertScimSetting = `
+INSERT INTO b2b.scim_setting
+SET team_id = :team_id,
+	admin_id = :admin_id,
+	token_hash = UNHEX(:token_hash),
+	auto_invite = :auto_invite,
+	created_utc = :created_utc,
+	updated_utc = :updated_utc
+ON DUPLICATE KEY UPDATE
+	admin_id = :admin_id,
+	token_hash = UNHEX(:token_hash),
+	auto_invite = :auto_invite,
+	created_utc = :created_utc,
+	updated_utc = :updated_utc`
+
+const colScimSetting = `
+SELECT team_id,
+	admin_id,
+	LOWER(HEX(token_hash)) AS token_hash,
+	auto_invite,
+	created_utc,
+	updated_utc
+FROM b2b.scim_setting
+`
+
+const StmtScimSetting = colScimSetting + `
+WHERE team_id = ?
+LIMIT 1`
+
+const StmtScimSettingByToken = colScimSetting + `
+WHERE token_hash = UNHEX(?)
+LIMIT 1`
+
+const StmtUpdateScimAutoInvite = `
+UPDATE b2b.scim_setting
+SET auto_invite = :auto_invite,
+	updated_utc = :updated_utc
+WHERE team_id = :team_id
+LIMIT 1`
+
+const StmtDeleteScimSetting = `
+DELETE FROM b2b.scim_setting
+WHERE team_id = ?
+LIMIT 1`
@@ -0,0 +1,111 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"github.com/FTChinese/ftacademy/internal/pkg/ids"
+	"github.com/FTChinese/go-rest"
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/guregu/null"
+	"strings"
+	"time"
+)
+
+const (
+	// AccessTokenTTL is the lifetime of JWT.
+	AccessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// Session is created upon login and kept server-side so
+// that it could be revoked.
+// Access tokens are short-lived and carry the session id
+// as jti. The refresh token is rotated on every use; the
+// previous one is remembered so that a replayed token,
+// likely stolen, revokes the session.
+type Session struct {
+	ID           string      `json:"id" db:"session_id"`
+	AdminID      string      `json:"-" db:"admin_id"`
+	RefreshHash  string      `json:"-" db:"refresh_hash"`
+	PrevHash     null.String `json:"-" db:"prev_refresh_hash"`
+	UserAgent    string      `json:"userAgent" db:"user_agent"`
+	ClientIP     string      `json:"clientIp" db:"client_ip"`
+	CreatedUTC   chrono.Time `json:"createdUtc" db:"created_utc"`
+	RefreshedUTC chrono.Time `json:"refreshedUtc" db:"refreshed_utc"`
+	ExpiresUTC   chrono.Time `json:"expiresUtc" db:"expires_utc"`
+	RevokedUTC   chrono.Time `json:"-" db:"revoked_utc"`
+	// Current marks the session making the request.
+	Current bool `json:"current" db:"-"`
+}
+
+// NewSession starts a session and returns the refresh token
+// which is only saved as hash.
+func NewSession(adminID, userAgent, ip string) (Session, string, error) {
+	s := Session{
+		ID:         ids.SessionID(),
+		AdminID:    adminID,
+		UserAgent:  userAgent,
+		ClientIP:   ip,
+		CreatedUTC: chrono.TimeNow(),
+	}
+
+	return s.rotated()
+}
+
+// Rotate issues a new refresh token and extends expiration.
+func (s Session) Rotate(ip string) (Session, string, error) {
+	s.PrevHash = null.StringFrom(s.RefreshHash)
+	s.ClientIP = ip
+	s.RefreshedUTC = chrono.TimeNow()
+
+	return s.rotated()
+}
+
+func (s Session) rotated() (Session, string, error) {
+	secret, err := gorest.RandomHex(32)
+	if err != nil {
+		return Session{}, "", err
+	}
+
+	token := s.ID + "." + secret
+
+	s.RefreshHash = HashToken(token)
+	s.ExpiresUTC = chrono.TimeFrom(time.Now().Add(refreshTokenTTL))
+
+	return s, token, nil
+}
+
+// SessionIDOfRefresh extracts the session id from a refresh
+// token.
+func SessionIDOfRefresh(token string) (string, bool) {
+	i := strings.LastIndex(token, ".")
+	if i <= 0 {
+		return "", false
+	}
+
+	return token[:i], true
+}
+
+func (s Session) IsActive() bool {
+	return s.RevokedUTC.IsZero() && s.ExpiresUTC.After(time.Now())
+}
+
+func (s Session) Matches(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(s.RefreshHash), []byte(HashToken(token))) == 1
+}
+
+// IsReplayed checks whether token is the one already
+// rotated out.
+func (s Session) IsReplayed(token string) bool {
+	return s.PrevHash.Valid &&
+		subtle.ConstantTimeCompare([]byte(s.PrevHash.String), []byte(HashToken(token))) == 1
+}
+
+func (s Session) WithRevoked() Session {
+	s.RevokedUTC = chrono.TimeNow()
+	return s
+}
+
+func (s Session) WithCurrent(id string) Session {
+	s.Current = s.ID == id
+	return s
+}
@@ -0,0 +1,66 @@
+package admin
+
+const StmtCreateSession = `
+INSERT INTO b2b.admin_session
+SET session_id = :session_id,
+	admin_id = :admin_id,
+	refresh_hash = UNHEX(:refresh_hash),
+	user_agent = :user_agent,
+	client_ip = :client_ip,
+	created_utc = :created_utc,
+	expires_utc = :expires_utc`
+
+const colSession = `
+SELECT session_id,
+	admin_id,
+	LOWER(HEX(refresh_hash)) AS refresh_hash,
+	LOWER(HEX(prev_refresh_hash)) AS prev_refresh_hash,
+	user_agent,
+	client_ip,
+	created_utc,
+	refreshed_utc,
+	expires_utc,
+	revoked_utc
+FROM b2b.admin_session
+`
+
+const StmtSession = colSession + `
+WHERE session_id = ?
+LIMIT 1`
+
+// StmtActiveSessions lists sessions of an admin not revoked
+// or expired, most recently used first.
+const StmtActiveSessions = colSession + `
+WHERE admin_id = ?
+	AND revoked_utc IS NULL
+	AND expires_utc > UTC_TIMESTAMP()
+ORDER BY COALESCE(refreshed_utc, created_utc) DESC`
+
+// StmtRotateSession replaces the refresh token only if the
+// one presented is still current. No row is affected if a
+// concurrent request already rotated it.
+const StmtRotateSession = `
+UPDATE b2b.admin_session
+SET refresh_hash = UNHEX(:refresh_hash),
+	prev_refresh_hash = UNHEX(:prev_refresh_hash),
+	client_ip = :client_ip,
+	refreshed_utc = :refreshed_utc,
+	expires_utc = :expires_utc
+WHERE session_id = :session_id
+	AND refresh_hash = UNHEX(:prev_refresh_hash)
+	AND revoked_utc IS NULL
+LIMIT 1`
+
+const StmtRevokeSession = `
+UPDATE b2b.admin_session
+SET revoked_utc = UTC_TIMESTAMP()
+WHERE session_id = ?
+	AND admin_id = ?
+	AND revoked_utc IS NULL
+LIMIT 1`
+
+const StmtSessionRevoked = `
+SELECT revoked_utc IS NOT NULL OR expires_utc <= UTC_TIMESTAMP()
+FROM b2b.admin_session
+WHERE session_id = ?
+LIMIT 1`
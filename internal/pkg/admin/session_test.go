@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"github.com/FTChinese/ftacademy/pkg/keyring"
+	"testing"
+)
+
+func TestSession_Rotate(t *testing.T) {
+	s, token, err := NewSession("admin-id", "Mozilla/5.0", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, ok := SessionIDOfRefresh(token)
+	if !ok || id != s.ID {
+		t.Errorf("SessionIDOfRefresh() = %s, want %s", id, s.ID)
+	}
+
+	if !s.IsActive() || !s.Matches(token) {
+		t.Error("new session should match its token")
+	}
+
+	rotated, next, err := s.Rotate("127.0.0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rotated.Matches(token) {
+		t.Error("old token should not match after rotation")
+	}
+	if !rotated.IsReplayed(token) {
+		t.Error("old token should be detected as replayed")
+	}
+	if !rotated.Matches(next) || rotated.IsReplayed(next) {
+		t.Error("new token should match")
+	}
+
+	if rotated.WithRevoked().IsActive() {
+		t.Error("revoked session should not be active")
+	}
+}
+
+func TestNewPassport(t *testing.T) {
+	key, _ := keyring.NewHMAC("secret", AccessTokenTTL)
+	pp, err := NewPassport(BaseAccount{ID: "admin-id"}, "ses_abc", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := ParsePassportClaims(pp.Token, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.SessionID() != "ses_abc" {
+		t.Errorf("SessionID() = %s", claims.SessionID())
+	}
+}
@@ -13,8 +13,10 @@ type Team struct {
 	ID      string `json:"id" db:"team_id"`
 	AdminID string `json:"adminId" db:"admin_id"`
 	input.TeamParams
-	CreatedUTC chrono.Time `json:"createdUtc" db:"created_utc"`
-	UpdatedUTC chrono.Time `json:"updatedUtc" db:"updated_utc"`
+	AutoFillWaitlist bool        `json:"autoFillWaitlist" db:"waitlist_auto_fill"` // Invite people on waitlist automatically when licences become available.
+	RequireTwoFactor bool        `json:"requireTwoFactor" db:"require_two_factor"` // Every admin must enable 2FA before accessing team data.
+	CreatedUTC       chrono.Time `json:"createdUtc" db:"created_utc"`
+	UpdatedUTC       chrono.Time `json:"updatedUtc" db:"updated_utc"`
 }
 
 func NewTeam(adminID string, params input.TeamParams) Team {
@@ -35,3 +37,21 @@ func (t Team) Update(newVal input.TeamParams) Team {
 
 	return t
 }
+
+// WithAutoFill turns on/off inviting people on waitlist
+// automatically.
+func (t Team) WithAutoFill(on bool) Team {
+	t.AutoFillWaitlist = on
+	t.UpdatedUTC = chrono.TimeNow()
+
+	return t
+}
+
+// WithTwoFactorRequired turns on/off 2FA requirement for
+// all admins of the team.
+func (t Team) WithTwoFactorRequired(on bool) Team {
+	t.RequireTwoFactor = on
+	t.UpdatedUTC = chrono.TimeNow()
+
+	return t
+}
@@ -0,0 +1,100 @@
+package admin
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"strings"
+)
+
+// TeamSort decides the order of rows in team directory.
+// Rows are always in descending order.
+type TeamSort string
+
+const (
+	TeamSortCreated TeamSort = "created"
+	TeamSortSpend   TeamSort = "spend"
+	TeamSortSeats   TeamSort = "seats"
+)
+
+// ParseTeamSort falls back to TeamSortCreated for unknown values.
+func ParseTeamSort(s string) TeamSort {
+	switch TeamSort(s) {
+	case TeamSortSpend, TeamSortSeats:
+		return TeamSort(s)
+	default:
+		return TeamSortCreated
+	}
+}
+
+// OrderBy returns the ORDER BY clause.
+func (s TeamSort) OrderBy() string {
+	switch s {
+	case TeamSortSpend:
+		return "ORDER BY total_spend DESC, t.created_utc DESC"
+	case TeamSortSeats:
+		return "ORDER BY seat_count DESC, t.created_utc DESC"
+	default:
+		return "ORDER BY t.created_utc DESC"
+	}
+}
+
+// TeamStats is aggregated from a team's orders and licences.
+type TeamStats struct {
+	OrderCount        int64   `json:"orderCount" db:"order_count"`
+	PendingOrderCount int64   `json:"pendingOrderCount" db:"pending_order_count"` // Orders waiting for payment confirmation.
+	TotalSpend        float64 `json:"totalSpend" db:"total_spend"`                // Sum of paid orders.
+	SeatCount         int64   `json:"seatCount" db:"seat_count"`
+	GrantedSeatCount  int64   `json:"grantedSeatCount" db:"granted_seat_count"`
+	ExpiringSeatCount int64   `json:"expiringSeatCount" db:"expiring_seat_count"` // Licences whose current period ends this month.
+}
+
+// TeamSummary is a row in CMS team directory.
+type TeamSummary struct {
+	Team
+	OwnerEmail string `json:"ownerEmail" db:"owner_email"`
+	TeamStats
+}
+
+type TeamSummaryList struct {
+	pkg.PagedList
+	Data []TeamSummary `json:"data"`
+}
+
+// TeamDirectoryFilter narrows down teams listed in CMS.
+type TeamDirectoryFilter struct {
+	// Matches team id exactly, or part of org name or owner's email.
+	Query             string
+	PendingOrders     bool
+	ExpiringThisMonth bool
+	Sort              TeamSort
+}
+
+func (f TeamDirectoryFilter) SQLWhere() pkg.SQLWhere {
+	var cond = make([]string, 0)
+	var args = make([]interface{}, 0)
+
+	if q := strings.TrimSpace(f.Query); q != "" {
+		cond = append(cond, "(t.id = ? OR t.org_name LIKE ? OR a.email LIKE ?)")
+		like := "%" + pkg.EscapeLike(q) + "%"
+		args = append(args, q, like, like)
+	}
+
+	if f.PendingOrders {
+		cond = append(cond, "o.pending_order_count > 0")
+	}
+
+	if f.ExpiringThisMonth {
+		cond = append(cond, "l.expiring_seat_count > 0")
+	}
+
+	if len(cond) == 0 {
+		return pkg.SQLWhere{
+			Clause: "",
+			Values: args,
+		}
+	}
+
+	return pkg.SQLWhere{
+		Clause: "WHERE " + strings.Join(cond, " AND "),
+		Values: args,
+	}
+}
@@ -0,0 +1,63 @@
+package admin
+
+// fromTeamDirectory joins a team with its owner and
+// aggregated orders and licences.
+const fromTeamDirectory = `
+FROM b2b.team AS t
+	LEFT JOIN b2b.admin AS a
+	ON t.admin_id = a.id
+	LEFT JOIN (
+		SELECT team_id,
+			COUNT(*) AS order_count,
+			SUM(current_status IN ('pending_payment', 'processing')) AS pending_order_count,
+			SUM(IF(current_status = 'paid', amount_payable, 0)) AS total_spend
+		FROM b2b.order
+		GROUP BY team_id
+	) AS o
+	ON t.id = o.team_id
+	LEFT JOIN (
+		SELECT team_id,
+			COUNT(*) AS seat_count,
+			SUM(current_status = 'granted') AS granted_seat_count,
+			SUM(DATE_FORMAT(current_period_end_utc, '%Y-%m') = DATE_FORMAT(UTC_TIMESTAMP(), '%Y-%m')) AS expiring_seat_count
+		FROM b2b.licence
+		GROUP BY team_id
+	) AS l
+	ON t.id = l.team_id
+`
+
+const colTeamSummary = `
+SELECT t.id AS team_id,
+	t.admin_id AS admin_id,
+	t.org_name AS org_name,
+	t.phone AS phone,
+	t.invoice_title AS invoice_title,
+	IFNULL(t.waitlist_auto_fill, FALSE) AS waitlist_auto_fill,
+	IFNULL(t.require_two_factor, FALSE) AS require_two_factor,
+	t.created_utc AS created_utc,
+	IFNULL(a.email, '') AS owner_email,
+	IFNULL(o.order_count, 0) AS order_count,
+	IFNULL(o.pending_order_count, 0) AS pending_order_count,
+	IFNULL(o.total_spend, 0) AS total_spend,
+	IFNULL(l.seat_count, 0) AS seat_count,
+	IFNULL(l.granted_seat_count, 0) AS granted_seat_count,
+	IFNULL(l.expiring_seat_count, 0) AS expiring_seat_count
+` + fromTeamDirectory
+
+// BuildStmtListTeamSummary retrieves a page of team directory.
+// where is built from TeamDirectoryFilter.
+func BuildStmtListTeamSummary(where string, sort TeamSort) string {
+	return colTeamSummary + where + `
+` + sort.OrderBy() + `
+LIMIT ? OFFSET ?`
+}
+
+func BuildStmtCountTeamSummary(where string) string {
+	return `
+SELECT COUNT(*)` + fromTeamDirectory + where
+}
+
+// StmtTeamSummary retrieves a team with aggregated stats for CMS.
+const StmtTeamSummary = colTeamSummary + `
+WHERE t.id = ?
+LIMIT 1`
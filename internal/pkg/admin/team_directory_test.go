@@ -0,0 +1,67 @@
+package admin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTeamDirectoryFilter_SQLWhere(t *testing.T) {
+	tests := []struct {
+		name       string
+		filter     TeamDirectoryFilter
+		wantClause string
+		wantValues []interface{}
+	}{
+		{
+			name:       "No filter",
+			filter:     TeamDirectoryFilter{},
+			wantClause: "",
+			wantValues: []interface{}{},
+		},
+		{
+			name: "Search with wildcards escaped",
+			filter: TeamDirectoryFilter{
+				Query: " 50%_off ",
+			},
+			wantClause: "WHERE (t.id = ? OR t.org_name LIKE ? OR a.email LIKE ?)",
+			wantValues: []interface{}{"50%_off", `%50\%\_off%`, `%50\%\_off%`},
+		},
+		{
+			name: "Pending orders and expiring licences",
+			filter: TeamDirectoryFilter{
+				PendingOrders:     true,
+				ExpiringThisMonth: true,
+			},
+			wantClause: "WHERE o.pending_order_count > 0 AND l.expiring_seat_count > 0",
+			wantValues: []interface{}{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.filter.SQLWhere()
+			if got.Clause != tt.wantClause {
+				t.Errorf("SQLWhere() clause = %q, want %q", got.Clause, tt.wantClause)
+			}
+			if !reflect.DeepEqual(got.Values, tt.wantValues) {
+				t.Errorf("SQLWhere() values = %v, want %v", got.Values, tt.wantValues)
+			}
+		})
+	}
+}
+
+func TestParseTeamSort(t *testing.T) {
+	tests := []struct {
+		in   string
+		want TeamSort
+	}{
+		{"spend", TeamSortSpend},
+		{"seats", TeamSortSeats},
+		{"", TeamSortCreated},
+		{"name", TeamSortCreated},
+	}
+	for _, tt := range tests {
+		if got := ParseTeamSort(tt.in); got != tt.want {
+			t.Errorf("ParseTeamSort(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package admin
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/go-rest/chrono"
+)
+
+// TeamDomain is an email domain registered by CMS for a team
+// so that readers using company email under it could request
+// licences by themselves.
+// A domain could only belong to one team.
+type TeamDomain struct {
+	Domain     string      `json:"domain" db:"domain"`
+	TeamID     string      `json:"teamId" db:"team_id"`
+	CreatedUTC chrono.Time `json:"createdUtc" db:"created_utc"`
+}
+
+func NewTeamDomain(teamID string, params input.TeamDomainParams) TeamDomain {
+	return TeamDomain{
+		Domain:     params.Domain,
+		TeamID:     teamID,
+		CreatedUTC: chrono.TimeNow(),
+	}
+}
@@ -0,0 +1,36 @@
+package admin
+
+// StmtCreateTeamDomain registers a domain for a team.
+// The table must carry a unique key on domain so that a domain
+// never resolves to more than one team:
+//
+//	ALTER TABLE b2b.team_domain ADD UNIQUE KEY uk_domain (domain);
+const StmtCreateTeamDomain = `
+INSERT INTO b2b.team_domain
+SET domain = :domain,
+	team_id = :team_id,
+	created_utc = :created_utc`
+
+const StmtListTeamDomains = `
+SELECT domain,
+	team_id,
+	created_utc
+FROM b2b.team_domain
+WHERE team_id = ?
+ORDER BY created_utc ASC`
+
+const StmtDeleteTeamDomain = `
+DELETE FROM b2b.team_domain
+WHERE domain = ?
+	AND team_id = ?
+LIMIT 1`
+
+// StmtTeamByDomain finds the team owning an email domain.
+const StmtTeamByDomain = colTeam + `
+WHERE id = (
+	SELECT team_id
+	FROM b2b.team_domain
+	WHERE domain = ?
+	LIMIT 1
+)
+LIMIT 1`
@@ -0,0 +1,123 @@
+package admin
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/ids"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/go-rest"
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/guregu/null"
+	"strings"
+	"time"
+)
+
+// TeamMember is an admin of a team. Owner is not saved as
+// a member since it is the admin_id of a team.
+type TeamMember struct {
+	TeamID      string      `json:"teamId" db:"team_id"`
+	AdminID     string      `json:"adminId" db:"admin_id"`
+	Email       string      `json:"email" db:"email"`
+	DisplayName null.String `json:"displayName" db:"display_name"`
+	Role        Role        `json:"role" db:"member_role"`
+	UnitID      null.String `json:"unitId" db:"unit_id"` // Limit the co-admin to a unit. Null for the whole team.
+	RowTime
+}
+
+func (m TeamMember) IsOwner() bool {
+	return m.Role == RoleOwner
+}
+
+func (m TeamMember) WithRole(r Role, unitID null.String) TeamMember {
+	m.Role = r
+	m.UnitID = unitID
+	m.UpdatedUTC = chrono.TimeNow()
+
+	return m
+}
+
+type AdminInvitationStatus string
+
+const (
+	AdminInvitationPending  AdminInvitationStatus = "pending"
+	AdminInvitationAccepted AdminInvitationStatus = "accepted"
+	AdminInvitationRevoked  AdminInvitationStatus = "revoked"
+)
+
+// AdminInvitation is sent by team owner to an email to
+// become a co-admin.
+type AdminInvitation struct {
+	ID        string                `json:"id" db:"invitation_id"`
+	TeamID    string                `json:"teamId" db:"team_id"`
+	InviterID string                `json:"inviterId" db:"inviter_id"`
+	Email     string                `json:"email" db:"email"`
+	Role      Role                  `json:"role" db:"member_role"`
+	UnitID    null.String           `json:"unitId" db:"unit_id"`
+	Token     string                `json:"-" db:"token"`
+	Status    AdminInvitationStatus `json:"status" db:"invitation_status"`
+	ExpiresIn int64                 `json:"expiresIn" db:"expires_in"`
+	RowTime
+}
+
+func NewAdminInvitation(params input.TeamMemberParams, by Creator) (AdminInvitation, error) {
+	token, err := gorest.RandomHex(32)
+	if err != nil {
+		return AdminInvitation{}, err
+	}
+
+	return AdminInvitation{
+		ID:        ids.AdminInvitationID(),
+		TeamID:    by.TeamID,
+		InviterID: by.AdminID,
+		Email:     params.Email,
+		Role:      Role(params.Role),
+		UnitID:    params.UnitID,
+		Token:     token,
+		Status:    AdminInvitationPending,
+		ExpiresIn: 7 * 24 * 60 * 60,
+		RowTime:   NewRowTime(),
+	}, nil
+}
+
+func (i AdminInvitation) IsExpired() bool {
+	return i.CreatedUTC.Add(time.Duration(i.ExpiresIn) * time.Second).Before(time.Now())
+}
+
+func (i AdminInvitation) IsAcceptable() bool {
+	return i.Status == AdminInvitationPending && !i.IsExpired()
+}
+
+// IsInvitee checks whether the logged-in account is the
+// one invited.
+func (i AdminInvitation) IsInvitee(a BaseAccount) bool {
+	return strings.EqualFold(i.Email, a.Email)
+}
+
+func (i AdminInvitation) BuildURL() string {
+	return pkg.B2BAdminInvitationURL(i.Token)
+}
+
+func (i AdminInvitation) WithStatus(s AdminInvitationStatus) AdminInvitation {
+	i.Status = s
+	i.UpdatedUTC = chrono.TimeNow()
+
+	return i
+}
+
+// Accepted creates the membership upon acceptance.
+func (i AdminInvitation) Accepted(a BaseAccount) (AdminInvitation, TeamMember) {
+	return i.WithStatus(AdminInvitationAccepted), TeamMember{
+		TeamID:      i.TeamID,
+		AdminID:     a.ID,
+		Email:       a.Email,
+		DisplayName: a.DisplayName,
+		Role:        i.Role,
+		UnitID:      i.UnitID,
+		RowTime:     NewRowTime(),
+	}
+}
+
+// AdminInvitationVerified shows invitee which team is inviting.
+type AdminInvitationVerified struct {
+	AdminInvitation
+	TeamName string `json:"teamName"`
+}
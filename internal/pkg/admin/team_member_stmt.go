@@ -0,0 +1,140 @@
+package admin
+
+// StmtMemberScope finds the role of an admin in a team, the
+// unit it is limited to, and whether it satisfies team's
+// 2FA policy.
+// Role is NULL if the admin is neither owner nor member.
+const StmtMemberScope = `
+SELECT IF(t.admin_id = ?, 'owner', m.member_role) AS team_role,
+	IF(t.admin_id = ?, NULL, m.unit_id) AS unit_id,
+	IFNULL(t.require_two_factor, FALSE) AS require_two_factor,
+	IFNULL(f.is_enabled, FALSE) AS two_factor_enabled
+FROM b2b.team AS t
+	LEFT JOIN b2b.team_member AS m
+	ON t.id = m.team_id
+	AND m.admin_id = ?
+	LEFT JOIN b2b.admin_totp AS f
+	ON f.admin_id = ?
+WHERE t.id = ?
+LIMIT 1`
+
+// StmtListTeamMembers retrieves owner together with co-admins.
+const StmtListTeamMembers = `
+SELECT t.id AS team_id,
+	a.id AS admin_id,
+	a.email,
+	a.display_name,
+	'owner' AS member_role,
+	NULL AS unit_id,
+	t.created_utc,
+	NULL AS updated_utc
+FROM b2b.team AS t
+	INNER JOIN b2b.admin AS a
+	ON t.admin_id = a.id
+WHERE t.id = ?
+UNION ALL
+SELECT m.team_id,
+	a.id AS admin_id,
+	a.email,
+	a.display_name,
+	m.member_role,
+	m.unit_id,
+	m.created_utc,
+	m.updated_utc
+FROM b2b.team_member AS m
+	INNER JOIN b2b.admin AS a
+	ON m.admin_id = a.id
+WHERE m.team_id = ?`
+
+const StmtTeamMember = `
+SELECT m.team_id,
+	a.id AS admin_id,
+	a.email,
+	a.display_name,
+	m.member_role,
+	m.unit_id,
+	m.created_utc,
+	m.updated_utc
+FROM b2b.team_member AS m
+	INNER JOIN b2b.admin AS a
+	ON m.admin_id = a.id
+WHERE m.team_id = ?
+	AND m.admin_id = ?
+LIMIT 1`
+
+const StmtCreateTeamMember = `
+INSERT INTO b2b.team_member
+SET team_id = :team_id,
+	admin_id = :admin_id,
+	member_role = :member_role,
+	unit_id = :unit_id,
+	created_utc = :created_utc`
+
+const StmtUpdateMemberRole = `
+UPDATE b2b.team_member
+SET member_role = :member_role,
+	unit_id = :unit_id,
+	updated_utc = :updated_utc
+WHERE team_id = :team_id
+	AND admin_id = :admin_id
+LIMIT 1`
+
+const StmtDeleteTeamMember = `
+DELETE FROM b2b.team_member
+WHERE team_id = ?
+	AND admin_id = ?
+LIMIT 1`
+
+const StmtCreateAdminInvitation = `
+INSERT INTO b2b.admin_invitation
+SET invitation_id = :invitation_id,
+	team_id = :team_id,
+	inviter_id = :inviter_id,
+	email = :email,
+	member_role = :member_role,
+	unit_id = :unit_id,
+	token = UNHEX(:token),
+	invitation_status = :invitation_status,
+	expires_in = :expires_in,
+	created_utc = :created_utc`
+
+const colAdminInvitation = `
+SELECT invitation_id,
+	team_id,
+	inviter_id,
+	email,
+	member_role,
+	unit_id,
+	LOWER(HEX(token)) AS token,
+	invitation_status,
+	expires_in,
+	created_utc,
+	updated_utc
+FROM b2b.admin_invitation
+`
+
+const StmtListAdminInvitations = colAdminInvitation + `
+WHERE team_id = ?
+	AND invitation_status = 'pending'
+ORDER BY created_utc DESC`
+
+const StmtAdminInvitation = colAdminInvitation + `
+WHERE invitation_id = ?
+	AND team_id = ?
+LIMIT 1`
+
+const StmtAdminInvitationByToken = colAdminInvitation + `
+WHERE token = UNHEX(?)
+LIMIT 1`
+
+const StmtLockAdminInvitation = colAdminInvitation + `
+WHERE invitation_id = ?
+LIMIT 1
+FOR UPDATE`
+
+const StmtUpdateAdminInvitation = `
+UPDATE b2b.admin_invitation
+SET invitation_status = :invitation_status,
+	updated_utc = :updated_utc
+WHERE invitation_id = :invitation_id
+LIMIT 1`
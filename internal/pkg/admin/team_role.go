@@ -0,0 +1,149 @@
+package admin
+
+import (
+	"database/sql/driver"
+	"errors"
+	"github.com/guregu/null"
+	"net/http"
+)
+
+// Role is what an admin could do in a team.
+type Role string
+
+const (
+	RoleNull           Role = ""
+	RoleOwner          Role = "owner"           // Team creator. Everything.
+	RoleBilling        Role = "billing"         // Orders only.
+	RoleLicenceManager Role = "licence_manager" // Invitations and licences.
+	RoleViewer         Role = "viewer"          // Read everything.
+)
+
+var memberRoles = map[Role]bool{
+	RoleBilling:        true,
+	RoleLicenceManager: true,
+	RoleViewer:         true,
+}
+
+// IsValidMemberRole checks roles that could be assigned to
+// a co-admin. Owner cannot be assigned.
+func IsValidMemberRole(s string) bool {
+	return memberRoles[Role(s)]
+}
+
+func (r Role) Value() (driver.Value, error) {
+	if r == RoleNull {
+		return nil, nil
+	}
+
+	return string(r), nil
+}
+
+func (r *Role) Scan(src interface{}) error {
+	if src == nil {
+		*r = RoleNull
+		return nil
+	}
+
+	switch s := src.(type) {
+	case []byte:
+		*r = Role(s)
+		return nil
+
+	case string:
+		*r = Role(s)
+		return nil
+
+	default:
+		return errors.New("incompatible type to scan")
+	}
+}
+
+// Area groups b2b resources under the same permission.
+type Area int
+
+const (
+	AreaOrders   Area = iota // Orders and payment.
+	AreaLicences             // Licences, invitations, staff roster and other ways to grant licences.
+	AreaTeam                 // Team settings, co-admins, and integrations.
+)
+
+type grant struct {
+	read  bool
+	write bool
+}
+
+var rolePermissions = map[Role]map[Area]grant{
+	RoleOwner: {
+		AreaOrders:   {true, true},
+		AreaLicences: {true, true},
+		AreaTeam:     {true, true},
+	},
+	RoleBilling: {
+		AreaOrders: {true, true},
+	},
+	RoleLicenceManager: {
+		AreaLicences: {true, true},
+	},
+	RoleViewer: {
+		AreaOrders:   {true, false},
+		AreaLicences: {true, false},
+		AreaTeam:     {true, false},
+	},
+}
+
+// Can checks whether a role is allowed to read or write
+// an area.
+func (r Role) Can(a Area, write bool) bool {
+	g := rolePermissions[r][a]
+	if write {
+		return g.write
+	}
+
+	return g.read
+}
+
+// CanRequest checks permission by HTTP method: GET and HEAD
+// are reading while others are writing.
+func (r Role) CanRequest(a Area, method string) bool {
+	write := method != http.MethodGet && method != http.MethodHead
+
+	return r.Can(a, write)
+}
+
+// MemberScope is an admin's role in a team together with the
+// unit it is limited to. UnitID is always null for owner.
+type MemberScope struct {
+	Role              Role        `db:"team_role"`
+	UnitID            null.String `db:"unit_id"`
+	TwoFactorRequired bool        `db:"require_two_factor"` // Team policy.
+	TwoFactorEnabled  bool        `db:"two_factor_enabled"` // Whether this admin turned on 2FA.
+}
+
+// LacksTwoFactor checks whether the admin should enable 2FA
+// before accessing team data.
+func (s MemberScope) LacksTwoFactor() bool {
+	return s.TwoFactorRequired && !s.TwoFactorEnabled
+}
+
+// IsUnitScoped checks whether the admin only manages a unit.
+func (s MemberScope) IsUnitScoped() bool {
+	return s.UnitID.Valid
+}
+
+// Covers checks whether a row of a unit is visible. Team-wide
+// admins see rows of every unit.
+func (s MemberScope) Covers(unitID null.String) bool {
+	return !s.IsUnitScoped() || s.UnitID == unitID
+}
+
+// CanRequest adds unit restriction to role permission:
+// a unit manager could only touch licences of its unit, and
+// nothing else since orders and team settings are
+// shared by the whole team.
+func (s MemberScope) CanRequest(a Area, method string) bool {
+	if s.IsUnitScoped() && a != AreaLicences {
+		return false
+	}
+
+	return s.Role.CanRequest(a, method)
+}
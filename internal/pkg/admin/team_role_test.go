@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"github.com/guregu/null"
+	"net/http"
+	"testing"
+)
+
+func TestRole_CanRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		role   Role
+		area   Area
+		method string
+		want   bool
+	}{
+		{"Owner writes team", RoleOwner, AreaTeam, http.MethodPatch, true},
+		{"Billing creates order", RoleBilling, AreaOrders, http.MethodPost, true},
+		{"Billing reads licences", RoleBilling, AreaLicences, http.MethodGet, false},
+		{"Licence manager invites", RoleLicenceManager, AreaLicences, http.MethodPost, true},
+		{"Licence manager reads orders", RoleLicenceManager, AreaOrders, http.MethodGet, false},
+		{"Viewer reads orders", RoleViewer, AreaOrders, http.MethodGet, true},
+		{"Viewer revokes licence", RoleViewer, AreaLicences, http.MethodPost, false},
+		{"No role", RoleNull, AreaLicences, http.MethodGet, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.role.CanRequest(tt.area, tt.method); got != tt.want {
+				t.Errorf("CanRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemberScope_CanRequest(t *testing.T) {
+	unit := null.StringFrom("unit_sales")
+
+	tests := []struct {
+		name  string
+		scope MemberScope
+		area  Area
+		want  bool
+	}{
+		{"Team viewer reads team", MemberScope{Role: RoleViewer}, AreaTeam, true},
+		{"Unit viewer reads licences", MemberScope{Role: RoleViewer, UnitID: unit}, AreaLicences, true},
+		{"Unit viewer reads orders", MemberScope{Role: RoleViewer, UnitID: unit}, AreaOrders, false},
+		{"Unit viewer reads team", MemberScope{Role: RoleViewer, UnitID: unit}, AreaTeam, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scope.CanRequest(tt.area, http.MethodGet); got != tt.want {
+				t.Errorf("CanRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemberScope_Covers(t *testing.T) {
+	team := MemberScope{Role: RoleOwner}
+	sales := MemberScope{Role: RoleLicenceManager, UnitID: null.StringFrom("unit_sales")}
+
+	if !team.Covers(null.StringFrom("unit_legal")) {
+		t.Error("Team-wide admin should cover every unit")
+	}
+	if !sales.Covers(null.StringFrom("unit_sales")) {
+		t.Error("Unit manager should cover own unit")
+	}
+	if sales.Covers(null.String{}) {
+		t.Error("Unit manager should not cover team-level rows")
+	}
+}
@@ -17,14 +17,16 @@ SELECT id AS team_id,
 	org_name,
 	phone,
 	invoice_title,
+	IFNULL(waitlist_auto_fill, FALSE) AS waitlist_auto_fill,
+	IFNULL(require_two_factor, FALSE) AS require_two_factor,
 	created_utc
 FROM b2b.team
 `
 
 // BuildStmtLoadTeam build the sql statement to load a team.
 // if adminOnly is true, this intends to be used by a loggedin
-// admin only:
-// WHERE id = ? AND admin_id = ? LIMIT 1`
+// admin, either owner or co-admin, only:
+// WHERE id = ? AND (admin_id = ? OR id IN (...)) LIMIT 1`
 //
 // For CMS you should not add the admin_id condition in WHERE:
 // WHERE id = ? LIMIT 1`
@@ -34,7 +36,7 @@ func BuildStmtLoadTeam(adminOnly bool) string {
 	buf.WriteString(colTeam)
 	buf.WriteString("WHERE id = ?")
 	if adminOnly {
-		buf.WriteString(" AND admin_id = ?")
+		buf.WriteString(" AND (admin_id = ? OR id IN (SELECT team_id FROM b2b.team_member WHERE admin_id = ?))")
 	}
 	buf.WriteString(" LIMIT 1")
 
@@ -50,3 +52,13 @@ SET org_name = :org_name,
 WHERE id = :team_id
 	AND admin_id = :admin_id
 LIMIT 1`
+
+// StmtUpdateWaitlistAutoFill turns on/off inviting people on
+// waitlist automatically.
+const StmtUpdateWaitlistAutoFill = `
+UPDATE b2b.team
+SET waitlist_auto_fill = :waitlist_auto_fill,
+	updated_utc = :updated_utc
+WHERE id = :team_id
+	AND admin_id = :admin_id
+LIMIT 1`
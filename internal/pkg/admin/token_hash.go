@@ -0,0 +1,15 @@
+package admin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashToken hashes a machine credential, like SCIM token or
+// API key, so that the plain value is never saved.
+// These credentials are random with enough entropy, thus a
+// plain sha256 is sufficient.
+func HashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
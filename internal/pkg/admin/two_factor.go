@@ -0,0 +1,184 @@
+package admin
+
+import (
+	"fmt"
+	"github.com/FTChinese/ftacademy/pkg/totp"
+	"github.com/FTChinese/go-rest"
+	"github.com/FTChinese/go-rest/chrono"
+	"strings"
+	"time"
+)
+
+const (
+	totpIssuer        = "FT中文网企业订阅"
+	recoveryCodeCount = 10
+)
+
+// TwoFactor is the TOTP setting of an admin.
+// It is saved but not enabled until admin verifies a code
+// generated by authenticator app.
+type TwoFactor struct {
+	AdminID    string      `json:"-" db:"admin_id"`
+	Secret     string      `json:"-" db:"totp_secret"`
+	Enabled    bool        `json:"enabled" db:"is_enabled"`
+	LastStep   int64       `json:"-" db:"last_step"` // Step of last accepted code so that it cannot be used again.
+	EnabledUTC chrono.Time `json:"enabledUtc" db:"enabled_utc"`
+	RowTime
+}
+
+func NewTwoFactor(adminID string) (TwoFactor, error) {
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return TwoFactor{}, err
+	}
+
+	return TwoFactor{
+		AdminID: adminID,
+		Secret:  secret,
+		Enabled: false,
+		RowTime: NewRowTime(),
+	}, nil
+}
+
+// URI is encoded into QR code for authenticator app.
+func (f TwoFactor) URI(email string) string {
+	return totp.URI(f.Secret, totpIssuer, email)
+}
+
+// Verify checks a TOTP code. The returned value has the
+// matched step recorded and should be saved.
+func (f TwoFactor) Verify(code string, now time.Time) (TwoFactor, bool) {
+	step, ok := totp.Validate(f.Secret, code, now)
+	if !ok || step <= f.LastStep {
+		return f, false
+	}
+
+	f.LastStep = step
+	f.UpdatedUTC = chrono.TimeUTCFrom(now)
+
+	return f, true
+}
+
+func (f TwoFactor) WithEnabled() TwoFactor {
+	f.Enabled = true
+	f.EnabledUTC = chrono.TimeNow()
+	f.UpdatedUTC = chrono.TimeNow()
+
+	return f
+}
+
+// TwoFactorSetup is returned when admin starts enrollment.
+// Secret is shown in case the QR code cannot be scanned.
+type TwoFactorSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	QRCode string `json:"qrCode"` // PNG image as data url.
+}
+
+// TwoFactorStatus tells whether 2FA is turned on for an
+// admin and how many recovery codes are left.
+type TwoFactorStatus struct {
+	Enabled           bool        `json:"enabled" db:"is_enabled"`
+	EnabledUTC        chrono.Time `json:"enabledUtc" db:"enabled_utc"`
+	RecoveryCodesLeft int64       `json:"recoveryCodesLeft" db:"codes_left"`
+}
+
+// RecoveryCode could be used once in place of a TOTP code
+// when authenticator app is lost. Only hash is saved.
+type RecoveryCode struct {
+	AdminID    string      `db:"admin_id"`
+	CodeHash   string      `db:"code_hash"`
+	CreatedUTC chrono.Time `db:"created_utc"`
+}
+
+// NormalizeRecoveryCode removes separators and spaces users
+// might type in.
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
+
+// NewRecoveryCodes generates a set of codes in the format of
+// xxxxx-xxxxx. The plain codes are shown to admin only once.
+func NewRecoveryCodes(adminID string) ([]string, []RecoveryCode, error) {
+	plain := make([]string, 0, recoveryCodeCount)
+	hashed := make([]RecoveryCode, 0, recoveryCodeCount)
+	now := chrono.TimeNow()
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		h, err := gorest.RandomHex(5)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		plain = append(plain, fmt.Sprintf("%s-%s", h[:5], h[5:]))
+		hashed = append(hashed, RecoveryCode{
+			AdminID:    adminID,
+			CodeHash:   HashToken(h),
+			CreatedUTC: now,
+		})
+	}
+
+	return plain, hashed, nil
+}
+
+// RecoveryCodes is the response after 2FA is enabled or
+// codes are regenerated.
+type RecoveryCodes struct {
+	Codes []string `json:"recoveryCodes"`
+}
+
+// MaxChallengeAttempts limits how many codes could be tried
+// against a login challenge.
+const MaxChallengeAttempts = 5
+
+// LoginChallenge is created after password is verified for
+// an admin with 2FA enabled. The token is exchanged for a
+// Passport together with a valid code.
+type LoginChallenge struct {
+	Token      string      `db:"token"`
+	AdminID    string      `db:"admin_id"`
+	Attempts   int64       `db:"attempts"`
+	ExpiresIn  int64       `db:"expires_in"`
+	CreatedUTC chrono.Time `db:"created_utc"`
+}
+
+func NewLoginChallenge(adminID string) (LoginChallenge, error) {
+	token, err := gorest.RandomHex(32)
+	if err != nil {
+		return LoginChallenge{}, err
+	}
+
+	return LoginChallenge{
+		Token:      token,
+		AdminID:    adminID,
+		Attempts:   0,
+		ExpiresIn:  5 * 60,
+		CreatedUTC: chrono.TimeNow(),
+	}, nil
+}
+
+func (c LoginChallenge) IsExpired() bool {
+	return c.CreatedUTC.Add(time.Second * time.Duration(c.ExpiresIn)).Before(time.Now())
+}
+
+// IsUsable checks whether more codes could be tried.
+func (c LoginChallenge) IsUsable() bool {
+	return !c.IsExpired() && c.Attempts < MaxChallengeAttempts
+}
+
+// LoginChallengeIssued is returned from login step one
+// instead of Passport.
+type LoginChallengeIssued struct {
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	ChallengeToken    string `json:"challengeToken"`
+	ExpiresIn         int64  `json:"expiresIn"`
+}
+
+func (c LoginChallenge) Issued() LoginChallengeIssued {
+	return LoginChallengeIssued{
+		TwoFactorRequired: true,
+		ChallengeToken:    c.Token,
+		ExpiresIn:         c.ExpiresIn,
+	}
+}
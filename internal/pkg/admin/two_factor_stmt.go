@@ -0,0 +1,123 @@
+package admin
+
+// StmtSaveTwoFactor starts or restarts enrollment.
+// An enabled setting must be disabled before this.
+const StmtSaveTwoFactor = `
+INSERT INTO b2b.admin_totp
+SET admin_id = :admin_id,
+	totp_secret = :totp_secret,
+	is_enabled = FALSE,
+	last_step = 0,
+	created_utc = :created_utc
+ON DUPLICATE KEY UPDATE
+	totp_secret = :totp_secret,
+	is_enabled = FALSE,
+	last_step = 0,
+	enabled_utc = NULL,
+	updated_utc = :created_utc`
+
+const StmtTwoFactor = `
+SELECT admin_id,
+	totp_secret,
+	is_enabled,
+	last_step,
+	enabled_utc,
+	created_utc,
+	updated_utc
+FROM b2b.admin_totp
+WHERE admin_id = ?
+LIMIT 1`
+
+// StmtUpdateTwoFactor saves the step of an accepted code.
+// No row is affected if the step is already used by a
+// concurrent request so that a code is accepted only once.
+const StmtUpdateTwoFactor = `
+UPDATE b2b.admin_totp
+SET is_enabled = :is_enabled,
+	last_step = :last_step,
+	enabled_utc = :enabled_utc,
+	updated_utc = :updated_utc
+WHERE admin_id = :admin_id
+	AND last_step < :last_step
+LIMIT 1`
+
+const StmtDeleteTwoFactor = `
+DELETE FROM b2b.admin_totp
+WHERE admin_id = ?
+LIMIT 1`
+
+const StmtTwoFactorStatus = `
+SELECT f.is_enabled,
+	f.enabled_utc,
+	(
+		SELECT COUNT(*)
+		FROM b2b.admin_recovery_code AS c
+		WHERE c.admin_id = f.admin_id
+			AND c.used_utc IS NULL
+	) AS codes_left
+FROM b2b.admin_totp AS f
+WHERE f.admin_id = ?
+LIMIT 1`
+
+const StmtInsertRecoveryCode = `
+INSERT INTO b2b.admin_recovery_code
+SET admin_id = :admin_id,
+	code_hash = UNHEX(:code_hash),
+	created_utc = :created_utc`
+
+const StmtDeleteRecoveryCodes = `
+DELETE FROM b2b.admin_recovery_code
+WHERE admin_id = ?`
+
+// StmtUseRecoveryCode marks a code as used. No row is
+// affected if the code is wrong or used.
+const StmtUseRecoveryCode = `
+UPDATE b2b.admin_recovery_code
+SET used_utc = UTC_TIMESTAMP()
+WHERE admin_id = ?
+	AND code_hash = UNHEX(?)
+	AND used_utc IS NULL
+LIMIT 1`
+
+const StmtCreateLoginChallenge = `
+INSERT INTO b2b.admin_login_challenge
+SET token = UNHEX(:token),
+	admin_id = :admin_id,
+	attempts = :attempts,
+	expires_in = :expires_in,
+	created_utc = :created_utc`
+
+const StmtLoginChallenge = `
+SELECT LOWER(HEX(token)) AS token,
+	admin_id,
+	attempts,
+	expires_in,
+	created_utc
+FROM b2b.admin_login_challenge
+WHERE token = UNHEX(?)
+LIMIT 1`
+
+// StmtIncrChallengeAttempts takes an attempt before a code
+// is checked. No row is affected once attempts reach the
+// limit, even under concurrent requests.
+const StmtIncrChallengeAttempts = `
+UPDATE b2b.admin_login_challenge
+SET attempts = attempts + 1
+WHERE token = UNHEX(?)
+	AND attempts < ?
+LIMIT 1`
+
+const StmtDeleteLoginChallenge = `
+DELETE FROM b2b.admin_login_challenge
+WHERE token = UNHEX(?)
+LIMIT 1`
+
+// StmtUpdateTwoFactorPolicy lets owner require 2FA for every
+// admin of the team.
+const StmtUpdateTwoFactorPolicy = `
+UPDATE b2b.team
+SET require_two_factor = :require_two_factor,
+	updated_utc = :updated_utc
+WHERE id = :team_id
+	AND admin_id = :admin_id
+LIMIT 1`
@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FTChinese/ftacademy/pkg/totp"
+)
+
+func TestTwoFactor_Verify(t *testing.T) {
+	f, err := NewTwoFactor("admin-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	code, _ := totp.CodeAt(f.Secret, totp.Step(now))
+
+	f, ok := f.Verify(code, now)
+	if !ok {
+		t.Fatal("Valid code should be accepted")
+	}
+
+	if _, ok := f.Verify(code, now); ok {
+		t.Error("The same code should not be accepted twice")
+	}
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	plain, hashed, err := NewRecoveryCodes("admin-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plain) != recoveryCodeCount || len(hashed) != recoveryCodeCount {
+		t.Fatalf("Expected %d codes", recoveryCodeCount)
+	}
+
+	// Users might copy the code with spaces around.
+	typed := " " + plain[0] + " "
+	if HashToken(NormalizeRecoveryCode(typed)) != hashed[0].CodeHash {
+		t.Error("Normalized code should match its hash")
+	}
+}
+
+func TestLoginChallenge_IsUsable(t *testing.T) {
+	c, err := NewLoginChallenge("admin-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.IsUsable() {
+		t.Error("New challenge should be usable")
+	}
+
+	c.Attempts = MaxChallengeAttempts
+	if c.IsUsable() {
+		t.Error("Challenge should be unusable after too many attempts")
+	}
+}
@@ -0,0 +1,130 @@
+package admin
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/ids"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/guregu/null"
+)
+
+// Unit is a department within a team. Licences, staff and
+// co-admins could be assigned to a unit so that unit
+// managers only see their own part.
+type Unit struct {
+	ID     string `json:"id" db:"unit_id"`
+	TeamID string `json:"teamId" db:"team_id"`
+	input.UnitParams
+	RowTime
+}
+
+func NewUnit(teamID string, params input.UnitParams) Unit {
+	return Unit{
+		ID:         ids.UnitID(),
+		TeamID:     teamID,
+		UnitParams: params,
+		RowTime:    NewRowTime(),
+	}
+}
+
+func (u Unit) Update(params input.UnitParams) Unit {
+	u.UnitParams = params
+	u.UpdatedUTC = chrono.TimeNow()
+
+	return u
+}
+
+// UnitUsage is the licence statistics of a unit.
+// UnitID is null for licences not allocated to any unit.
+type UnitUsage struct {
+	UnitID    null.String `json:"unitId" db:"unit_id"`
+	Total     int64       `json:"total" db:"licence_count"`
+	Available int64       `json:"available" db:"available_count"`
+	Invited   int64       `json:"invited" db:"invited_count"`
+	Granted   int64       `json:"granted" db:"granted_count"`
+	Spend     float64     `json:"spend" db:"spend"`
+}
+
+// Utilisation is the ratio of granted licences.
+func (u UnitUsage) Utilisation() float64 {
+	if u.Total == 0 {
+		return 0
+	}
+
+	return float64(u.Granted) / float64(u.Total)
+}
+
+// UnitReportRow combines a unit with its usage.
+type UnitReportRow struct {
+	UnitID      null.String `json:"unitId"`
+	Name        string      `json:"name"`
+	Budget      null.Float  `json:"budget"`
+	Total       int64       `json:"total"`
+	Available   int64       `json:"available"`
+	Invited     int64       `json:"invited"`
+	Granted     int64       `json:"granted"`
+	Utilisation float64     `json:"utilisation"`
+	Spend       float64     `json:"spend"`
+}
+
+// UnitReport breaks down a team's licences by unit.
+type UnitReport struct {
+	Rows  []UnitReportRow `json:"rows"`
+	Total UnitReportRow   `json:"total"`
+}
+
+// NewUnitReport joins units with usage. Every unit has a
+// row even if it has no licence; licences without unit
+// are put under a row with null unit id.
+func NewUnitReport(units []Unit, usages []UnitUsage) UnitReport {
+	var byUnit = make(map[string]UnitUsage)
+	var unallocated UnitUsage
+	for _, u := range usages {
+		if u.UnitID.Valid {
+			byUnit[u.UnitID.String] = u
+		} else {
+			unallocated = u
+		}
+	}
+
+	var report = UnitReport{
+		Rows: make([]UnitReportRow, 0, len(units)+1),
+	}
+
+	add := func(row UnitReportRow, u UnitUsage) {
+		row.Total = u.Total
+		row.Available = u.Available
+		row.Invited = u.Invited
+		row.Granted = u.Granted
+		row.Utilisation = u.Utilisation()
+		row.Spend = u.Spend
+		report.Rows = append(report.Rows, row)
+
+		report.Total.Total += u.Total
+		report.Total.Available += u.Available
+		report.Total.Invited += u.Invited
+		report.Total.Granted += u.Granted
+		report.Total.Spend += u.Spend
+	}
+
+	for _, unit := range units {
+		add(UnitReportRow{
+			UnitID: null.StringFrom(unit.ID),
+			Name:   unit.Name,
+			Budget: unit.Budget,
+		}, byUnit[unit.ID])
+	}
+
+	if unallocated.Total > 0 {
+		add(UnitReportRow{
+			Name: "Unallocated",
+		}, unallocated)
+	}
+
+	report.Total.Name = "Total"
+	report.Total.Utilisation = UnitUsage{
+		Total:   report.Total.Total,
+		Granted: report.Total.Granted,
+	}.Utilisation()
+
+	return report
+}
@@ -0,0 +1,77 @@
+package admin
+
+const StmtCreateUnit = `
+INSERT INTO b2b.team_unit
+SET unit_id = :unit_id,
+	team_id = :team_id,
+	unit_name = :unit_name,
+	budget = :budget,
+	created_utc = :created_utc`
+
+const colUnit = `
+SELECT unit_id,
+	team_id,
+	unit_name,
+	budget,
+	created_utc,
+	updated_utc
+FROM b2b.team_unit
+`
+
+const StmtListUnits = colUnit + `
+WHERE team_id = ?
+ORDER BY unit_name ASC`
+
+const StmtUnit = colUnit + `
+WHERE unit_id = ?
+	AND team_id = ?
+LIMIT 1`
+
+const StmtUpdateUnit = `
+UPDATE b2b.team_unit
+SET unit_name = :unit_name,
+	budget = :budget,
+	updated_utc = :updated_utc
+WHERE unit_id = :unit_id
+	AND team_id = :team_id
+LIMIT 1`
+
+const StmtDeleteUnit = `
+DELETE FROM b2b.team_unit
+WHERE unit_id = ?
+	AND team_id = ?
+LIMIT 1`
+
+// The following release everything of a deleted unit back
+// to team level.
+
+const StmtReleaseUnitLicences = `
+UPDATE b2b.licence
+SET unit_id = NULL
+WHERE team_id = ?
+	AND unit_id = ?`
+
+const StmtReleaseUnitStaff = `
+UPDATE b2b.staff
+SET unit_id = NULL
+WHERE team_id = ?
+	AND unit_id = ?`
+
+const StmtReleaseUnitMembers = `
+UPDATE b2b.team_member
+SET unit_id = NULL
+WHERE team_id = ?
+	AND unit_id = ?`
+
+// StmtUnitUsage counts licences of a team by unit.
+// Spend is the sum of each licence's latest price.
+const StmtUnitUsage = `
+SELECT unit_id,
+	COUNT(*) AS licence_count,
+	SUM(current_status = 'available') AS available_count,
+	SUM(current_status = 'invited') AS invited_count,
+	SUM(current_status = 'granted') AS granted_count,
+	IFNULL(SUM(JSON_EXTRACT(latest_price, '$.unitAmount')), 0) AS spend
+FROM b2b.licence
+WHERE team_id = ?
+GROUP BY unit_id`
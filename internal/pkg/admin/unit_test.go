@@ -0,0 +1,64 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/guregu/null"
+)
+
+func TestNewUnitReport(t *testing.T) {
+	sales := Unit{
+		ID: "unit_sales",
+		UnitParams: input.UnitParams{
+			Name:   "Sales",
+			Budget: null.FloatFrom(10000),
+		},
+	}
+	legal := Unit{
+		ID: "unit_legal",
+		UnitParams: input.UnitParams{
+			Name: "Legal",
+		},
+	}
+
+	report := NewUnitReport([]Unit{sales, legal}, []UnitUsage{
+		{
+			UnitID:    null.StringFrom("unit_sales"),
+			Total:     4,
+			Available: 1,
+			Granted:   3,
+			Spend:     1000,
+		},
+		{
+			Total:   2,
+			Invited: 1,
+			Granted: 1,
+			Spend:   500,
+		},
+	})
+
+	if len(report.Rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(report.Rows))
+	}
+
+	if report.Rows[0].Utilisation != 0.75 {
+		t.Errorf("Sales utilisation = %f, want 0.75", report.Rows[0].Utilisation)
+	}
+
+	if report.Rows[1].Total != 0 {
+		t.Errorf("Legal should have no licence, got %d", report.Rows[1].Total)
+	}
+
+	if report.Rows[2].UnitID.Valid {
+		t.Error("Last row should be unallocated licences")
+	}
+
+	if report.Total.Total != 6 || report.Total.Spend != 1500 {
+		t.Errorf("Unexpected total %+v", report.Total)
+	}
+
+	if report.Total.Utilisation != float64(4)/6 {
+		t.Errorf("Total utilisation = %f", report.Total.Utilisation)
+	}
+}
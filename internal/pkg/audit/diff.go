@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// Change is the value of a field before and after an action.
+type Change struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Diff keeps only the JSON fields changed, keyed by field
+// name.
+type Diff map[string]Change
+
+func toMap(v interface{}) map[string]interface{} {
+	m := map[string]interface{}{}
+	if v == nil {
+		return m
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return m
+	}
+
+	// A non-object value is kept under an empty key.
+	if err := json.Unmarshal(b, &m); err != nil {
+		var x interface{}
+		_ = json.Unmarshal(b, &x)
+		return map[string]interface{}{"": x}
+	}
+
+	return m
+}
+
+// NewDiff compares the JSON representation of before and
+// after.
+func NewDiff(before, after interface{}) Diff {
+	b, a := toMap(before), toMap(after)
+
+	d := Diff{}
+	for k, v := range b {
+		if !reflect.DeepEqual(v, a[k]) {
+			d[k] = Change{From: v, To: a[k]}
+		}
+	}
+	for k, v := range a {
+		if _, ok := b[k]; !ok {
+			d[k] = Change{From: nil, To: v}
+		}
+	}
+
+	return d
+}
+
+func (d Diff) Value() (driver.Value, error) {
+	if len(d) == 0 {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func (d *Diff) Scan(src interface{}) error {
+	if src == nil {
+		*d = nil
+		return nil
+	}
+
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, d)
+	case string:
+		return json.Unmarshal([]byte(s), d)
+	default:
+		return errors.New("incompatible type to scan to audit.Diff")
+	}
+}
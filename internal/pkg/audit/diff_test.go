@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewDiff(t *testing.T) {
+	type team struct {
+		Name    string `json:"name"`
+		Invoice string `json:"invoiceTitle"`
+	}
+
+	tests := []struct {
+		name   string
+		before interface{}
+		after  interface{}
+		want   Diff
+	}{
+		{
+			name:   "Update",
+			before: team{Name: "FT", Invoice: "FT"},
+			after:  team{Name: "FT", Invoice: "FT中文网"},
+			want: Diff{
+				"invoiceTitle": {From: "FT", To: "FT中文网"},
+			},
+		},
+		{
+			name:   "Create",
+			before: nil,
+			after:  team{Name: "FT"},
+			want: Diff{
+				"name":         {From: nil, To: "FT"},
+				"invoiceTitle": {From: nil, To: ""},
+			},
+		},
+		{
+			name:   "Unchanged",
+			before: team{Name: "FT"},
+			after:  team{Name: "FT"},
+			want:   Diff{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewDiff(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewDiff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package audit
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/ids"
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/guregu/null"
+)
+
+// ActorKind tells who performed an action.
+type ActorKind string
+
+const (
+	ActorAdmin  ActorKind = "admin"
+	ActorAPIKey ActorKind = "api_key"
+	ActorCMS    ActorKind = "cms"
+	ActorScim   ActorKind = "scim"
+	ActorSystem ActorKind = "system"
+)
+
+type Action string
+
+const (
+	ActionLogin            Action = "admin.login"
+	ActionPasswordChange   Action = "admin.password_change"
+	ActionPasswordReset    Action = "admin.password_reset"
+	ActionTeamUpdate       Action = "team.update"
+	ActionOrderCreate      Action = "order.create"
+	ActionPaymentConfirm   Action = "order.payment_confirm"
+	ActionInvitationCreate Action = "invitation.create"
+	ActionInvitationRevoke Action = "invitation.revoke"
+	ActionInvitationResend Action = "invitation.resend"
+	ActionLicenceGrant     Action = "licence.grant"
+	ActionLicenceRevoke    Action = "licence.revoke"
+	ActionLetterResend     Action = "letter.resend"
+)
+
+// Actor is admin id, API key id, fingerprint of CMS token,
+// or name of a background job, depending on Kind.
+type Actor struct {
+	Kind ActorKind `json:"actorKind" db:"actor_kind"`
+	ID   string    `json:"actorId" db:"actor_id"`
+}
+
+func AdminActor(adminID string) Actor {
+	return Actor{
+		Kind: ActorAdmin,
+		ID:   adminID,
+	}
+}
+
+func APIKeyActor(keyID string) Actor {
+	return Actor{
+		Kind: ActorAPIKey,
+		ID:   keyID,
+	}
+}
+
+// CMSActor never saves the token itself.
+func CMSActor(fingerprint string) Actor {
+	return Actor{
+		Kind: ActorCMS,
+		ID:   fingerprint,
+	}
+}
+
+// ScimActor is the identity provider of a team. A team has
+// only one SCIM token, thus it is identified by team id.
+func ScimActor(teamID string) Actor {
+	return Actor{
+		Kind: ActorScim,
+		ID:   teamID,
+	}
+}
+
+// Background jobs recorded as SystemActor.
+const (
+	JobDeferredGrant    = "deferred_grant"
+	JobWaitlist         = "waitlist"
+	JobInvitationExpiry = "invitation_expiry"
+)
+
+func SystemActor(job string) Actor {
+	return Actor{
+		Kind: ActorSystem,
+		ID:   job,
+	}
+}
+
+// Entry is a row of the audit log. It is append-only: rows
+// are never updated or deleted.
+type Entry struct {
+	ID string `json:"id" db:"audit_id"`
+	Actor
+	TeamID   null.String `json:"teamId" db:"team_id"`
+	Action   Action      `json:"action" db:"action"`
+	TargetID null.String `json:"targetId" db:"target_id"`
+	ClientIP null.String `json:"clientIp" db:"client_ip"`
+	Diff     Diff        `json:"diff" db:"payload"`
+	// Only present when CMS acts on behalf of a team.
+	admin.Operator
+	CreatedUTC chrono.Time `json:"createdUtc" db:"created_utc"`
+}
+
+func NewEntry(actor Actor, action Action) Entry {
+	return Entry{
+		ID:         ids.AuditID(),
+		Actor:      actor,
+		Action:     action,
+		CreatedUTC: chrono.TimeNow(),
+	}
+}
+
+func (e Entry) WithTeam(teamID string) Entry {
+	e.TeamID = null.NewString(teamID, teamID != "")
+	return e
+}
+
+func (e Entry) WithTarget(id string) Entry {
+	e.TargetID = null.NewString(id, id != "")
+	return e
+}
+
+func (e Entry) WithIP(ip string) Entry {
+	e.ClientIP = null.NewString(ip, ip != "")
+	return e
+}
+
+// WithOperator records the CMS staff and the reason.
+func (e Entry) WithOperator(o admin.Operator) Entry {
+	e.Operator = o
+	return e
+}
+
+// WithChange records the difference between states before
+// and after the action. Either could be nil.
+func (e Entry) WithChange(before, after interface{}) Entry {
+	e.Diff = NewDiff(before, after)
+	return e
+}
+
+// Filter narrows down the log. TeamID is always set for
+// team owners.
+type Filter struct {
+	TeamID   null.String
+	Action   null.String
+	ActorID  null.String
+	TargetID null.String
+}
+
+func (f Filter) Args() []interface{} {
+	return []interface{}{
+		f.TeamID, f.TeamID,
+		f.Action, f.Action,
+		f.ActorID, f.ActorID,
+		f.TargetID, f.TargetID,
+	}
+}
+
+type List struct {
+	pkg.PagedList
+	Data []Entry `json:"data"`
+}
@@ -0,0 +1,44 @@
+package audit
+
+// There is deliberately no UPDATE or DELETE statement for
+// the audit log.
+
+const StmtCreateEntry = `
+INSERT INTO b2b.audit_log
+SET audit_id = :audit_id,
+	actor_kind = :actor_kind,
+	actor_id = :actor_id,
+	team_id = :team_id,
+	action = :action,
+	target_id = :target_id,
+	client_ip = :client_ip,
+	payload = :payload,
+	operator_name = :operator_name,
+	operator_reason = :operator_reason,
+	created_utc = :created_utc`
+
+const whereEntries = `
+WHERE (? IS NULL OR team_id = ?)
+	AND (? IS NULL OR action = ?)
+	AND (? IS NULL OR actor_id = ?)
+	AND (? IS NULL OR target_id = ?)`
+
+const StmtListEntries = `
+SELECT audit_id,
+	actor_kind,
+	actor_id,
+	team_id,
+	action,
+	target_id,
+	client_ip,
+	payload,
+	operator_name,
+	operator_reason,
+	created_utc
+FROM b2b.audit_log` + whereEntries + `
+ORDER BY created_utc DESC
+LIMIT ? OFFSET ?`
+
+const StmtCountEntries = `
+SELECT COUNT(*) AS row_count
+FROM b2b.audit_log` + whereEntries
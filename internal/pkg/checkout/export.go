@@ -0,0 +1,24 @@
+package checkout
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/export"
+)
+
+// OrderExportHeader is the header row of Order.ExportRow.
+var OrderExportHeader = export.Header{
+	{En: "Order ID", Zh: "订单号"},
+	{En: "Status", Zh: "状态"},
+	{En: "Amount Payable", Zh: "应付金额"},
+	{En: "Items", Zh: "数量"},
+	{En: "Created (UTC)", Zh: "创建时间 (UTC)"},
+}
+
+func (o Order) ExportRow(lang export.Lang) []string {
+	return []string{
+		o.ID,
+		o.Status.String(),
+		export.Amount(o.AmountPayable),
+		export.Int(o.ItemCount),
+		export.Time(o.CreatedUTC),
+	}
+}
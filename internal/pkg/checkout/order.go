@@ -24,6 +24,7 @@ type Order struct {
 	ItemCount     int64             `json:"itemCount" db:"item_count"`
 	ItemList      OrderItemListJSON `json:"itemList" db:"item_list"`
 	Status        Status            `json:"status" db:"current_status"`
+	admin.Operator
 }
 
 func (o Order) ChangeStatus(s Status) Order {
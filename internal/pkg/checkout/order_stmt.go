@@ -3,6 +3,12 @@ package checkout
 import "strings"
 
 // StmtCreateOrder is used to save a row of Order.
+// The operator columns are only set when CMS creates the
+// order on behalf of a team:
+//
+//	ALTER TABLE b2b.order
+//		ADD COLUMN operator_name VARCHAR(64) NULL,
+//		ADD COLUMN operator_reason VARCHAR(512) NULL;
 const StmtCreateOrder = `
 INSERT INTO b2b.order
 SET id = :order_id,
@@ -12,7 +18,9 @@ SET id = :order_id,
 	created_utc = :created_utc,
 	current_status = :current_status,
 	item_count = :item_count,
-	item_list = :item_list
+	item_list = :item_list,
+	operator_name = :operator_name,
+	operator_reason = :operator_reason
 `
 
 // Shared columns used both when retrieving a list orders,
@@ -25,7 +33,9 @@ SELECT o.id AS order_id,
 	o.created_utc AS created_utc,
 	o.item_count AS item_count,
 	o.item_list AS item_list,
-	o.current_status AS current_status
+	o.current_status AS current_status,
+	o.operator_name AS operator_name,
+	o.operator_reason AS operator_reason
 `
 
 // BuildStmtOrder retrieve a row from order table
@@ -93,3 +103,10 @@ SET current_status = :current_status
 WHERE id = :order_id
 LIMIT 1
 `
+
+// StmtExportOrders retrieves all orders of a team without
+// pagination. Rows should be streamed.
+const StmtExportOrders = colOrder + `
+FROM b2b.order AS o
+WHERE o.team_id = ?
+ORDER BY o.created_utc DESC`
@@ -34,6 +34,8 @@ type OrderSchemaBuilder struct {
 	orderID string
 	cart    ShoppingCart
 	creator admin.Creator
+	// Only set when CMS creates the order for a team.
+	operator admin.Operator
 }
 
 func NewOrderSchemaBuilder(cart ShoppingCart, pp admin.PassportClaims) OrderSchemaBuilder {
@@ -44,6 +46,7 @@ func NewOrderSchemaBuilder(cart ShoppingCart, pp admin.PassportClaims) OrderSche
 			AdminID: pp.AdminID,
 			TeamID:  pp.TeamID.String,
 		},
+		operator: pp.Operator,
 	}
 }
 
@@ -56,6 +59,7 @@ func (b OrderSchemaBuilder) Order() Order {
 		ItemList:      b.cart.OrderItemList(),
 		ItemCount:     b.cart.ItemCount,
 		Status:        StatusPending,
+		Operator:      b.operator,
 	}
 }
 
@@ -0,0 +1,133 @@
+// Package export defines the shared pieces to download a
+// table as csv or xlsx with localized headers.
+package export
+
+import (
+	"encoding/csv"
+	"github.com/FTChinese/ftacademy/pkg/xlsx"
+	"io"
+	"strings"
+)
+
+type Lang string
+
+const (
+	LangZh Lang = "zh"
+	LangEn Lang = "en"
+)
+
+// ParseLang accepts a query value or Accept-Language header.
+// Chinese is the default.
+func ParseLang(s string) Lang {
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(s)), "en") {
+		return LangEn
+	}
+
+	return LangZh
+}
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// ParseFormat falls back to csv.
+func ParseFormat(s string) Format {
+	if Format(s) == FormatXLSX {
+		return FormatXLSX
+	}
+
+	return FormatCSV
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+func (f Format) FileName(name string) string {
+	return name + "." + string(f)
+}
+
+// Column is the header of a column in both languages.
+type Column struct {
+	En string
+	Zh string
+}
+
+type Header []Column
+
+func (h Header) Localize(l Lang) []string {
+	var s = make([]string, 0, len(h))
+	for _, c := range h {
+		if l == LangEn {
+			s = append(s, c.En)
+		} else {
+			s = append(s, c.Zh)
+		}
+	}
+
+	return s
+}
+
+// Writer writes rows in the chosen format.
+type Writer interface {
+	Write(record []string) error
+	Close() error
+}
+
+// NewWriter creates a Writer for format f. sheet is only
+// used by xlsx.
+func NewWriter(w io.Writer, f Format, sheet string) (Writer, error) {
+	if f == FormatXLSX {
+		return xlsx.NewWriter(w, sheet)
+	}
+
+	// BOM so that Excel recognizes UTF-8 when opening csv.
+	if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+		return nil, err
+	}
+
+	return NewCSVWriter(w), nil
+}
+
+// NewCSVWriter writes csv without BOM, escaping every cell
+// by EscapeCell.
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{csv.NewWriter(w)}
+}
+
+type csvWriter struct {
+	*csv.Writer
+}
+
+func (w *csvWriter) Write(record []string) error {
+	var escaped = make([]string, 0, len(record))
+	for _, v := range record {
+		escaped = append(escaped, EscapeCell(v))
+	}
+
+	return w.Writer.Write(escaped)
+}
+
+func (w *csvWriter) Close() error {
+	w.Flush()
+	return w.Error()
+}
+
+// EscapeCell prefixes a quote to cells that spreadsheet apps
+// would evaluate as a formula, so that data entered by users
+// is never run when a csv is opened.
+func EscapeCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
@@ -0,0 +1,73 @@
+package export
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestParseLang(t *testing.T) {
+	tests := map[string]Lang{
+		"":               LangZh,
+		"zh-CN,zh;q=0.9": LangZh,
+		"en":             LangEn,
+		"en-US,en;q=0.8": LangEn,
+	}
+	for in, want := range tests {
+		if got := ParseLang(in); got != want {
+			t.Errorf("ParseLang(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestHeader_Localize(t *testing.T) {
+	h := Header{
+		{En: "Email", Zh: "邮箱"},
+		{En: "Tier", Zh: "会员类型"},
+	}
+
+	if got := h.Localize(LangEn); !reflect.DeepEqual(got, []string{"Email", "Tier"}) {
+		t.Errorf("Localize(en) = %v", got)
+	}
+
+	if got := h.Localize(LangZh); !reflect.DeepEqual(got, []string{"邮箱", "会员类型"}) {
+		t.Errorf("Localize(zh) = %v", got)
+	}
+}
+
+func TestNewWriter_CSV(t *testing.T) {
+	var buf bytes.Buffer
+
+	w, err := NewWriter(&buf, FormatCSV, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Write([]string{"a", "b,c"})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := buf.String(); got != "\uFEFFa,\"b,c\"\n" {
+		t.Errorf("csv output = %q", got)
+	}
+}
+
+func TestEscapeCell(t *testing.T) {
+	tests := map[string]string{
+		"":              "",
+		"alice":         "alice",
+		"=SUM(A1:A2)":   "'=SUM(A1:A2)",
+		"+1":            "'+1",
+		"-2+3":          "'-2+3",
+		"@cmd":          "'@cmd",
+		"\t=1":          "'\t=1",
+		"\r=1":          "'\r=1",
+		"a=1":           "a=1",
+		"alice@example": "alice@example",
+	}
+	for in, want := range tests {
+		if got := EscapeCell(in); got != want {
+			t.Errorf("EscapeCell(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package export
+
+import (
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/FTChinese/go-rest/enum"
+	"strconv"
+)
+
+// layoutTime is used for all time cells, in UTC.
+const layoutTime = "2006-01-02 15:04:05"
+
+// Time formats a cell of time. Zero time is left empty.
+func Time(t chrono.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(layoutTime)
+}
+
+func Int(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+func Amount(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+func Tier(t enum.Tier, l Lang) string {
+	if l == LangEn {
+		return t.StringEN()
+	}
+
+	return t.StringCN()
+}
+
+func Cycle(c enum.Cycle, l Lang) string {
+	if l == LangEn {
+		return c.StringEN()
+	}
+
+	return c.StringCN()
+}
@@ -30,3 +30,67 @@ func SnapshotID() string {
 func InvoiceID() string {
 	return "inv_" + rand.String(12)
 }
+
+// LicenceRequestID creates an id for reader's self-service
+// licence request.
+func LicenceRequestID() string {
+	return "lreq_" + rand.String(12)
+}
+
+// WaitlistID creates an id for team waitlist entry.
+func WaitlistID() string {
+	return "wait_" + rand.String(12)
+}
+
+// RedeemCodeID creates an id for licence redemption code.
+func RedeemCodeID() string {
+	return "rdm_" + rand.String(12)
+}
+
+// JoinLinkID creates an id for team join link.
+func JoinLinkID() string {
+	return "join_" + rand.String(12)
+}
+
+// APIKeyID creates an id for team's API key.
+func APIKeyID() string {
+	return "key_" + rand.String(12)
+}
+
+// WebhookEndpointID creates an id for a registered webhook url.
+func WebhookEndpointID() string {
+	return "whk_" + rand.String(12)
+}
+
+// WebhookEventID identifies an event shared by all its deliveries.
+func WebhookEventID() string {
+	return "evt_" + rand.String(12)
+}
+
+func WebhookDeliveryID() string {
+	return "dlv_" + rand.String(12)
+}
+
+// AdminInvitationID identifies an invitation to become a
+// team's co-admin.
+func AdminInvitationID() string {
+	return "adinv_" + rand.String(12)
+}
+
+// UnitID identifies a department within a team.
+func UnitID() string {
+	return "unit_" + rand.String(12)
+}
+
+func SessionID() string {
+	return "ses_" + rand.String(16)
+}
+
+func AuditID() string {
+	return "aud_" + rand.String(16)
+}
+
+// OutboxID identifies a letter queued for sending.
+func OutboxID() string {
+	return "mail_" + rand.String(16)
+}
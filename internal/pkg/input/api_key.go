@@ -0,0 +1,44 @@
+package input
+
+import (
+	"github.com/FTChinese/ftacademy/pkg/validator"
+	"github.com/FTChinese/go-rest/render"
+	"strings"
+)
+
+// APIKeyParams is used to create or edit a team's API key.
+type APIKeyParams struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// Validate checks name and scopes. validScope is provided
+// by caller since scopes are defined in admin package.
+func (p *APIKeyParams) Validate(validScope func(string) bool) *render.ValidationError {
+	p.Name = strings.TrimSpace(p.Name)
+
+	ve := validator.New("name").Required().MaxLen(64).Validate(p.Name)
+	if ve != nil {
+		return ve
+	}
+
+	if len(p.Scopes) == 0 {
+		return &render.ValidationError{
+			Message: "At least one scope is required",
+			Field:   "scopes",
+			Code:    render.CodeMissingField,
+		}
+	}
+
+	for _, s := range p.Scopes {
+		if !validScope(s) {
+			return &render.ValidationError{
+				Message: "Unknown scope " + s,
+				Field:   "scopes",
+				Code:    render.CodeInvalid,
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package input
+
+import (
+	"github.com/FTChinese/ftacademy/pkg/price"
+	"github.com/FTChinese/ftacademy/pkg/validator"
+	"github.com/FTChinese/go-rest/enum"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/guregu/null"
+	"strings"
+)
+
+const (
+	maxJoinLinkSeats    = 1000
+	maxJoinLinkDomains  = 20
+	defaultJoinLinkDays = 30
+)
+
+// JoinLinkParams is used by admin to create a shareable link
+// granting licences of an edition to anyone with an email
+// under the allowed domains.
+type JoinLinkParams struct {
+	price.Edition
+	SeatCap        int64       `json:"seatCap"`
+	Domains        []string    `json:"domains"`
+	ExpirationDays int64       `json:"expirationDays"` // Default 30 days.
+	Description    null.String `json:"description"`
+}
+
+func (p *JoinLinkParams) Validate() *render.ValidationError {
+	desc := strings.TrimSpace(p.Description.String)
+	p.Description = null.NewString(desc, desc != "")
+
+	if p.ExpirationDays == 0 {
+		p.ExpirationDays = defaultJoinLinkDays
+	}
+
+	if p.Tier == enum.TierNull || p.Cycle == enum.CycleNull {
+		return &render.ValidationError{
+			Message: "Tier and cycle are required",
+			Field:   "edition",
+			Code:    render.CodeMissingField,
+		}
+	}
+
+	if p.SeatCap < 1 || p.SeatCap > maxJoinLinkSeats {
+		return &render.ValidationError{
+			Message: "Seat cap must be within 1 to 1000",
+			Field:   "seatCap",
+			Code:    render.CodeInvalid,
+		}
+	}
+
+	if p.ExpirationDays < 1 || p.ExpirationDays > 366 {
+		return &render.ValidationError{
+			Message: "Expiration days must be within 1 to 366",
+			Field:   "expirationDays",
+			Code:    render.CodeInvalid,
+		}
+	}
+
+	if len(p.Domains) == 0 || len(p.Domains) > maxJoinLinkDomains {
+		return &render.ValidationError{
+			Message: "Provide 1 to 20 email domains",
+			Field:   "domains",
+			Code:    render.CodeInvalid,
+		}
+	}
+
+	for i, d := range p.Domains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+		ve := validator.New("domains").Required().MaxLen(128).Domain().Validate(d)
+		if ve != nil {
+			return ve
+		}
+		p.Domains[i] = d
+	}
+
+	return validator.New("description").MaxLen(256).Validate(p.Description.String)
+}
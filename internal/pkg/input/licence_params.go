@@ -1,7 +1,66 @@
 package input
 
+import (
+	"github.com/FTChinese/ftacademy/pkg/validator"
+	"github.com/FTChinese/go-rest/render"
+	"strings"
+)
+
 type GrantParams struct {
 	LicenceID string `json:"licenceId"`
 	TeamID    string `json:"teamId"`
 	FtcID     string `json:"ftcId"`
 }
+
+// MaxBulkRevoke limits how many licences could be revoked
+// in a single request, including those found by email domain.
+const MaxBulkRevoke = 500
+
+// BulkRevokeParams selects licences to revoke in one shot.
+// Client should provide either a list of licence ids,
+// or an email domain so that all licences granted to
+// emails under that domain are revoked.
+// If both exist, they are merged.
+type BulkRevokeParams struct {
+	LicenceIDs  []string `json:"licenceIds"`
+	EmailDomain string   `json:"emailDomain"` // Without the @ sign, e.g., ftchinese.com
+}
+
+func (p *BulkRevokeParams) Validate() *render.ValidationError {
+	p.EmailDomain = strings.ToLower(
+		strings.TrimPrefix(strings.TrimSpace(p.EmailDomain), "@"))
+
+	// Remove empty and duplicate ids.
+	var ids = make([]string, 0)
+	var seen = map[string]bool{}
+	for _, v := range p.LicenceIDs {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		ids = append(ids, v)
+	}
+	p.LicenceIDs = ids
+
+	if len(p.LicenceIDs) == 0 && p.EmailDomain == "" {
+		return &render.ValidationError{
+			Message: "Either licence ids or email domain is required",
+			Field:   "licenceIds",
+			Code:    render.CodeMissingField,
+		}
+	}
+
+	if len(p.LicenceIDs) > MaxBulkRevoke {
+		return &render.ValidationError{
+			Message: "Too many licences to revoke in one request",
+			Field:   "licenceIds",
+			Code:    render.CodeInvalid,
+		}
+	}
+
+	return validator.New("emailDomain").
+		MaxLen(128).
+		Domain().
+		Validate(p.EmailDomain)
+}
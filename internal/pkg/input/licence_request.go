@@ -0,0 +1,48 @@
+package input
+
+import (
+	"github.com/FTChinese/ftacademy/pkg/validator"
+	"github.com/FTChinese/go-rest/render"
+	"strings"
+)
+
+// LicenceRequestParams is submitted by a reader to request
+// a licence with company email.
+type LicenceRequestParams struct {
+	Email string `json:"email"`
+}
+
+func (p *LicenceRequestParams) Validate() *render.ValidationError {
+	p.Email = strings.ToLower(strings.TrimSpace(p.Email))
+
+	return validator.New("email").
+		Required().
+		MaxLen(64).
+		Email().
+		Validate(p.Email)
+}
+
+// EmailDomain extracts the domain part of the email.
+func (p LicenceRequestParams) EmailDomain() string {
+	i := strings.LastIndex(p.Email, "@")
+	if i < 0 {
+		return ""
+	}
+
+	return p.Email[i+1:]
+}
+
+// ApproveRequestParams is used by admin to approve a
+// licence request. If licence id is missing, an available
+// one will be picked.
+type ApproveRequestParams struct {
+	LicenceID string `json:"licenceId"`
+}
+
+func (p *ApproveRequestParams) Validate() *render.ValidationError {
+	p.LicenceID = strings.TrimSpace(p.LicenceID)
+
+	return validator.New("licenceId").
+		MaxLen(32).
+		Validate(p.LicenceID)
+}
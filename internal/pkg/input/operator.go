@@ -0,0 +1,20 @@
+package input
+
+import (
+	"github.com/FTChinese/ftacademy/pkg/validator"
+	"github.com/FTChinese/go-rest/render"
+	"strings"
+)
+
+// OperatorParams is sent by CMS together with the payload of
+// an action taken on behalf of a team. Name of the staff is
+// not part of it: it always comes from the X-Operator header.
+type OperatorParams struct {
+	Reason string `json:"reason"`
+}
+
+func (p *OperatorParams) Validate() *render.ValidationError {
+	p.Reason = strings.TrimSpace(p.Reason)
+
+	return validator.New("reason").Required().MaxLen(512).Validate(p.Reason)
+}
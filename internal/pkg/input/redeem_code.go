@@ -0,0 +1,65 @@
+package input
+
+import (
+	"github.com/FTChinese/ftacademy/pkg/validator"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/guregu/null"
+	"strings"
+)
+
+const (
+	maxRedeemCodes        = 200
+	defaultRedeemCodeDays = 30
+)
+
+// RedeemCodeParams is used by admin to convert available
+// licences into redemption codes.
+type RedeemCodeParams struct {
+	Count          int64       `json:"count"`          // How many licences to convert.
+	ExpirationDays int64       `json:"expirationDays"` // Default 30 days.
+	Description    null.String `json:"description"`
+}
+
+func (p *RedeemCodeParams) Validate() *render.ValidationError {
+	desc := strings.TrimSpace(p.Description.String)
+	p.Description = null.NewString(desc, desc != "")
+
+	if p.ExpirationDays == 0 {
+		p.ExpirationDays = defaultRedeemCodeDays
+	}
+
+	if p.Count < 1 || p.Count > maxRedeemCodes {
+		return &render.ValidationError{
+			Message: "Count must be within 1 to 200",
+			Field:   "count",
+			Code:    render.CodeInvalid,
+		}
+	}
+
+	if p.ExpirationDays < 1 || p.ExpirationDays > 366 {
+		return &render.ValidationError{
+			Message: "Expiration days must be within 1 to 366",
+			Field:   "expirationDays",
+			Code:    render.CodeInvalid,
+		}
+	}
+
+	return validator.New("description").MaxLen(256).Validate(p.Description.String)
+}
+
+// RedeemParams is submitted by a reader to redeem a code.
+type RedeemParams struct {
+	Code string `json:"code"`
+}
+
+// Validate normalizes the code so that reader could type it
+// in lower case, with spaces or hyphens.
+func (p *RedeemParams) Validate() *render.ValidationError {
+	p.Code = strings.NewReplacer(" ", "", "-", "").
+		Replace(strings.ToUpper(strings.TrimSpace(p.Code)))
+
+	return validator.New("code").
+		Required().
+		MaxLen(32).
+		Validate(p.Code)
+}
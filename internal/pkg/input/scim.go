@@ -0,0 +1,6 @@
+package input
+
+// ScimSettingParams configures SCIM provisioning of a team.
+type ScimSettingParams struct {
+	AutoInvite bool `json:"autoInvite"`
+}
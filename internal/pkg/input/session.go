@@ -0,0 +1,26 @@
+package input
+
+import (
+	"github.com/FTChinese/go-rest/render"
+	"strings"
+)
+
+// RefreshTokenParams exchanges a refresh token for a new
+// access token.
+type RefreshTokenParams struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+func (p *RefreshTokenParams) Validate() *render.ValidationError {
+	p.RefreshToken = strings.TrimSpace(p.RefreshToken)
+
+	if p.RefreshToken == "" {
+		return &render.ValidationError{
+			Message: "Refresh token is required",
+			Field:   "refreshToken",
+			Code:    render.CodeMissingField,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package input
+
+import (
+	"github.com/FTChinese/ftacademy/pkg/validator"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/guregu/null"
+	"strings"
+)
+
+// MaxStaffImport limits the rows of a csv file.
+const MaxStaffImport = 1000
+
+// StafferParams is used to add or edit a team member.
+type StafferParams struct {
+	Email  string      `json:"email"`
+	Name   null.String `json:"name"`
+	UnitID null.String `json:"unitId"` // Null to put the staffer at team level.
+}
+
+func (p *StafferParams) Validate() *render.ValidationError {
+	p.Email = strings.ToLower(strings.TrimSpace(p.Email))
+	name := strings.TrimSpace(p.Name.String)
+	p.Name = null.NewString(name, name != "")
+
+	ve := validator.New("email").Required().MaxLen(64).Email().Validate(p.Email)
+	if ve != nil {
+		return ve
+	}
+
+	return validator.New("name").MaxLen(64).Validate(p.Name.String)
+}
+
+// StaffInvitationParams creates invitations for selected staff.
+type StaffInvitationParams struct {
+	StaffIDs    []int64     `json:"staffIds"`
+	Description null.String `json:"description"`
+}
+
+func (p *StaffInvitationParams) Validate() *render.ValidationError {
+	desc := strings.TrimSpace(p.Description.String)
+	p.Description = null.NewString(desc, desc != "")
+
+	if len(p.StaffIDs) == 0 || len(p.StaffIDs) > 100 {
+		return &render.ValidationError{
+			Message: "Select 1 to 100 staff",
+			Field:   "staffIds",
+			Code:    render.CodeInvalid,
+		}
+	}
+
+	return validator.New("description").MaxLen(256).Validate(p.Description.String)
+}
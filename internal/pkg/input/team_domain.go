@@ -0,0 +1,24 @@
+package input
+
+import (
+	"github.com/FTChinese/ftacademy/pkg/validator"
+	"github.com/FTChinese/go-rest/render"
+	"strings"
+)
+
+// TeamDomainParams is used by CMS to register an email domain
+// for a team.
+type TeamDomainParams struct {
+	Domain string `json:"domain"`
+}
+
+func (p *TeamDomainParams) Validate() *render.ValidationError {
+	p.Domain = strings.ToLower(
+		strings.TrimPrefix(strings.TrimSpace(p.Domain), "@"))
+
+	return validator.New("domain").
+		Required().
+		MaxLen(128).
+		Domain().
+		Validate(p.Domain)
+}
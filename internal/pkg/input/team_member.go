@@ -0,0 +1,49 @@
+package input
+
+import (
+	"github.com/FTChinese/ftacademy/pkg/validator"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/guregu/null"
+	"strings"
+)
+
+// TeamMemberParams is used by team owner to invite a
+// co-admin or change a co-admin's role.
+type TeamMemberParams struct {
+	Email  string      `json:"email"` // Only required when inviting.
+	Role   string      `json:"role"`
+	UnitID null.String `json:"unitId"` // Limit the co-admin to a unit.
+}
+
+// ValidateRole checks role. validRole is provided by caller
+// since roles are defined in admin package.
+func (p *TeamMemberParams) ValidateRole(validRole func(string) bool) *render.ValidationError {
+	if !validRole(p.Role) {
+		return &render.ValidationError{
+			Message: "Role must be one of billing, licence_manager or viewer",
+			Field:   "role",
+			Code:    render.CodeInvalid,
+		}
+	}
+
+	// Unit has no orders of its own.
+	if p.UnitID.Valid && p.Role == "billing" {
+		return &render.ValidationError{
+			Message: "Billing admin cannot be limited to a unit",
+			Field:   "unitId",
+			Code:    render.CodeInvalid,
+		}
+	}
+
+	return nil
+}
+
+func (p *TeamMemberParams) ValidateInvitation(validRole func(string) bool) *render.ValidationError {
+	p.Email = strings.ToLower(strings.TrimSpace(p.Email))
+
+	if ve := validator.EnsureEmail(p.Email); ve != nil {
+		return ve
+	}
+
+	return p.ValidateRole(validRole)
+}
@@ -0,0 +1,52 @@
+package input
+
+import (
+	"github.com/FTChinese/go-rest/render"
+	"strings"
+)
+
+// TwoFactorCodeParams carries a TOTP code, or a recovery
+// code where allowed.
+type TwoFactorCodeParams struct {
+	Code string `json:"code"`
+}
+
+func (p *TwoFactorCodeParams) Validate() *render.ValidationError {
+	p.Code = strings.TrimSpace(p.Code)
+
+	if p.Code == "" {
+		return &render.ValidationError{
+			Message: "Verification code is required",
+			Field:   "code",
+			Code:    render.CodeMissingField,
+		}
+	}
+
+	return nil
+}
+
+// TwoFactorLoginParams is the second step of login.
+type TwoFactorLoginParams struct {
+	ChallengeToken string `json:"challengeToken"`
+	TwoFactorCodeParams
+}
+
+func (p *TwoFactorLoginParams) Validate() *render.ValidationError {
+	p.ChallengeToken = strings.TrimSpace(p.ChallengeToken)
+
+	if p.ChallengeToken == "" {
+		return &render.ValidationError{
+			Message: "Challenge token is required",
+			Field:   "challengeToken",
+			Code:    render.CodeMissingField,
+		}
+	}
+
+	return p.TwoFactorCodeParams.Validate()
+}
+
+// TwoFactorPolicyParams is used by team owner to require
+// 2FA for all admins.
+type TwoFactorPolicyParams struct {
+	Required bool `json:"required"`
+}
@@ -0,0 +1,60 @@
+package input
+
+import (
+	"github.com/FTChinese/ftacademy/pkg/validator"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/guregu/null"
+	"strings"
+)
+
+// UnitParams creates or edits a department of a team.
+type UnitParams struct {
+	Name   string     `json:"name" db:"unit_name"`
+	Budget null.Float `json:"budget" db:"budget"` // Optional spending budget.
+}
+
+func (p *UnitParams) Validate() *render.ValidationError {
+	p.Name = strings.TrimSpace(p.Name)
+
+	ve := validator.New("name").Required().MaxLen(64).Validate(p.Name)
+	if ve != nil {
+		return ve
+	}
+
+	if p.Budget.Valid && p.Budget.Float64 < 0 {
+		return &render.ValidationError{
+			Message: "Budget cannot be negative",
+			Field:   "budget",
+			Code:    render.CodeInvalid,
+		}
+	}
+
+	return nil
+}
+
+// LicenceAllocationParams moves licences into a unit, or
+// back to team level if unitId is null.
+type LicenceAllocationParams struct {
+	LicenceIDs []string    `json:"licenceIds"`
+	UnitID     null.String `json:"unitId"`
+}
+
+func (p *LicenceAllocationParams) Validate() *render.ValidationError {
+	if len(p.LicenceIDs) == 0 {
+		return &render.ValidationError{
+			Message: "At least one licence is required",
+			Field:   "licenceIds",
+			Code:    render.CodeMissingField,
+		}
+	}
+
+	if len(p.LicenceIDs) > 500 {
+		return &render.ValidationError{
+			Message: "Allocate at most 500 licences at once",
+			Field:   "licenceIds",
+			Code:    render.CodeInvalid,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package input
+
+import (
+	"github.com/FTChinese/ftacademy/pkg/validator"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/guregu/null"
+	"strings"
+)
+
+// WaitlistParams is used to add a person to a team's waitlist,
+// or update an existing one.
+type WaitlistParams struct {
+	Email    string      `json:"email"`
+	Priority int64       `json:"priority"` // Larger number comes first.
+	Note     null.String `json:"note"`
+}
+
+func (p *WaitlistParams) Validate() *render.ValidationError {
+	p.Email = strings.ToLower(strings.TrimSpace(p.Email))
+	note := strings.TrimSpace(p.Note.String)
+	p.Note = null.NewString(note, note != "")
+
+	ve := validator.New("email").Required().MaxLen(64).Email().Validate(p.Email)
+	if ve != nil {
+		return ve
+	}
+
+	if p.Priority < 0 || p.Priority > 100 {
+		return &render.ValidationError{
+			Message: "Priority must be within 0 to 100",
+			Field:   "priority",
+			Code:    render.CodeInvalid,
+		}
+	}
+
+	return validator.New("note").MaxLen(256).Validate(p.Note.String)
+}
+
+// WaitlistSettingParams toggles whether licences should be
+// filled from waitlist automatically.
+type WaitlistSettingParams struct {
+	AutoFill bool `json:"autoFill"`
+}
@@ -0,0 +1,78 @@
+package input
+
+import (
+	"github.com/FTChinese/ftacademy/pkg/xhttp"
+	"github.com/FTChinese/go-rest/render"
+	"net/url"
+	"strings"
+)
+
+// WebhookParams registers or edits a webhook endpoint.
+type WebhookParams struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	Active      bool     `json:"active"`
+}
+
+// Validate checks url and events. validEvent is provided
+// by caller since events are defined in webhook package.
+func (p *WebhookParams) Validate(validEvent func(string) bool) *render.ValidationError {
+	p.URL = strings.TrimSpace(p.URL)
+	p.Description = strings.TrimSpace(p.Description)
+
+	u, err := url.Parse(p.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return &render.ValidationError{
+			Message: "A valid https url is required",
+			Field:   "url",
+			Code:    render.CodeInvalid,
+		}
+	}
+
+	// Endpoints are called from inside our network. Do not
+	// let them point to internal services.
+	if err := xhttp.ResolvesPublic(u.Hostname()); err != nil {
+		return &render.ValidationError{
+			Message: "URL should resolve to a public address",
+			Field:   "url",
+			Code:    render.CodeInvalid,
+		}
+	}
+
+	if len(p.URL) > 1024 {
+		return &render.ValidationError{
+			Message: "URL is too long",
+			Field:   "url",
+			Code:    render.CodeInvalid,
+		}
+	}
+
+	if len(p.Events) == 0 {
+		return &render.ValidationError{
+			Message: "At least one event is required",
+			Field:   "events",
+			Code:    render.CodeMissingField,
+		}
+	}
+
+	for _, e := range p.Events {
+		if !validEvent(e) {
+			return &render.ValidationError{
+				Message: "Unknown event " + e,
+				Field:   "events",
+				Code:    render.CodeInvalid,
+			}
+		}
+	}
+
+	if len(p.Description) > 256 {
+		return &render.ValidationError{
+			Message: "Description should not exceed 256 characters",
+			Field:   "description",
+			Code:    render.CodeInvalid,
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,9 @@
 package letter
 
-import "github.com/FTChinese/ftacademy/internal/pkg/checkout"
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/checkout"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+)
 
 // CtxVerification holds data to render a letter upon signup.
 type CtxVerification struct {
@@ -70,3 +73,108 @@ type CtxLicenceGranted struct {
 func (ctx CtxLicenceGranted) Render() (string, error) {
 	return Render(keyLicenceGranted, ctx)
 }
+
+// CtxLicencesRevoked is used to send admin a summary
+// after licences are revoked in bulk.
+type CtxLicencesRevoked struct {
+	AdminName string
+	licence.BulkRevokeResult
+}
+
+func (ctx CtxLicencesRevoked) Render() (string, error) {
+	return Render(keyLicencesRevoked, ctx)
+}
+
+// CtxLicenceReserved is used to tell admin that a licence
+// is reserved for a reader with auto-renewal subscription.
+type CtxLicenceReserved struct {
+	AdminName         string
+	AssigneeEmail     string
+	Tier              string
+	CurrentExpiration string
+}
+
+func (ctx CtxLicenceReserved) Render() (string, error) {
+	return Render(keyLicenceReserved, ctx)
+}
+
+// CtxReaderReserved is used to tell reader that the licence
+// will be granted after auto-renewal subscription ended.
+type CtxReaderReserved struct {
+	ReaderName        string
+	AdminEmail        string
+	TeamName          string
+	Tier              string
+	CurrentExpiration string
+}
+
+func (ctx CtxReaderReserved) Render() (string, error) {
+	return Render(keyReaderReserved, ctx)
+}
+
+// CtxLicenceRequestVrf is used to verify the company email
+// a reader used to request a licence.
+type CtxLicenceRequestVrf struct {
+	ReaderName string
+	Email      string
+	TeamName   string
+	Link       string
+	Duration   string
+}
+
+func (ctx CtxLicenceRequestVrf) Render() (string, error) {
+	return Render(keyLicenceRequestVrf, ctx)
+}
+
+// CtxLicenceRequested tells admin a licence request is
+// waiting for approval.
+type CtxLicenceRequested struct {
+	AdminName string
+	Email     string
+}
+
+func (ctx CtxLicenceRequested) Render() (string, error) {
+	return Render(keyLicenceRequested, ctx)
+}
+
+// CtxSeatsAvailable tells admin licences are available
+// while people are on waitlist.
+type CtxSeatsAvailable struct {
+	AdminName string
+	Available int
+	Waiting   int
+}
+
+func (ctx CtxSeatsAvailable) Render() (string, error) {
+	return Render(keySeatsAvailable, ctx)
+}
+
+// CtxAdminInvitation invites an email to become co-admin of
+// a team.
+type CtxAdminInvitation struct {
+	InviterName string
+	TeamName    string
+	Role        string
+	Email       string
+	Link        string
+	Duration    string
+}
+
+func (ctx CtxAdminInvitation) Render() (string, error) {
+	return Render(keyAdminInvitation, ctx)
+}
+
+// CtxAccountLocked tells an admin the account is locked
+// after repeated failed logins.
+type CtxAccountLocked struct {
+	AdminName string
+	LockedAt  string
+	Failures  int
+	ClientIP  string
+	Duration  string
+	Link      string
+}
+
+func (ctx CtxAccountLocked) Render() (string, error) {
+	return Render(keyAccountLocked, ctx)
+}
@@ -4,7 +4,9 @@ import (
 	"github.com/FTChinese/ftacademy/internal/mock"
 	"github.com/FTChinese/ftacademy/internal/pkg/admin"
 	"github.com/FTChinese/ftacademy/internal/pkg/checkout"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
 	"github.com/brianvoe/gofakeit/v5"
+	"github.com/guregu/null"
 	"testing"
 )
 
@@ -227,3 +229,135 @@ func TestCtxOrderCreated_Render(t *testing.T) {
 		})
 	}
 }
+
+func TestCtxLicencesRevoked_Render(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     CtxLicencesRevoked
+		wantErr bool
+	}{
+		{
+			name: "All succeeded",
+			ctx: CtxLicencesRevoked{
+				AdminName: gofakeit.Username(),
+				BulkRevokeResult: licence.NewBulkRevokeResult([]licence.RevokeOutcome{
+					{LicenceID: "lic_a", Ok: true},
+					{LicenceID: "lic_b", Ok: true},
+				}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Partially failed",
+			ctx: CtxLicencesRevoked{
+				AdminName: gofakeit.Username(),
+				BulkRevokeResult: licence.NewBulkRevokeResult([]licence.RevokeOutcome{
+					{LicenceID: "lic_a", Ok: true},
+					{LicenceID: "lic_b", Ok: false, Error: null.StringFrom("not found")},
+				}),
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.ctx.Render()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Render() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			t.Logf("%s", got)
+		})
+	}
+}
+
+func TestCtxReaderReserved_Render(t *testing.T) {
+	ctx := CtxReaderReserved{
+		ReaderName:        gofakeit.Username(),
+		AdminEmail:        gofakeit.Email(),
+		TeamName:          gofakeit.Company(),
+		Tier:              "标准会员",
+		CurrentExpiration: "2021-12-31",
+	}
+
+	got, err := ctx.Render()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Logf("%s", got)
+}
+
+func TestCtxLicenceRequestVrf_Render(t *testing.T) {
+	ctx := CtxLicenceRequestVrf{
+		ReaderName: gofakeit.Username(),
+		Email:      gofakeit.Email(),
+		TeamName:   gofakeit.Company(),
+		Link:       gofakeit.URL(),
+		Duration:   "3天",
+	}
+
+	got, err := ctx.Render()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Logf("%s", got)
+}
+
+func TestCtxSeatsAvailable_Render(t *testing.T) {
+	ctx := CtxSeatsAvailable{
+		AdminName: gofakeit.Username(),
+		Available: 2,
+		Waiting:   5,
+	}
+
+	got, err := ctx.Render()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Logf("%s", got)
+}
+
+func TestCtxAdminInvitation_Render(t *testing.T) {
+	ctx := CtxAdminInvitation{
+		InviterName: gofakeit.Username(),
+		TeamName:    gofakeit.Company(),
+		Role:        "只读成员",
+		Email:       gofakeit.Email(),
+		Link:        "https://next.ftacademy.cn/corporate/join-team/abc",
+		Duration:    "7天",
+	}
+
+	got, err := ctx.Render()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Logf("%s", got)
+}
+
+func TestCtxAccountLocked_Render(t *testing.T) {
+	ctx := CtxAccountLocked{
+		AdminName: gofakeit.Username(),
+		LockedAt:  "2022-01-01 12:00:00 UTC",
+		Failures:  5,
+		ClientIP:  gofakeit.IPv4Address(),
+		Duration:  "30分钟",
+		Link:      "https://next.ftacademy.cn/corporate/unlock/abc",
+	}
+
+	got, err := ctx.Render()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Logf("%s", got)
+}
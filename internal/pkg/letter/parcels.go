@@ -8,6 +8,7 @@ import (
 	"github.com/FTChinese/ftacademy/pkg/postman"
 	"github.com/FTChinese/go-rest/chrono"
 	"github.com/guregu/null"
+	"time"
 )
 
 const (
@@ -140,3 +141,211 @@ func LicenceGrantedParcel(lic licence.Licence, a licence.Assignee, adminAccount
 		Body:        body,
 	}, nil
 }
+
+// LicencesRevokedParcel composes a single summary letter
+// to admin after licences are revoked in bulk.
+func LicencesRevokedParcel(a admin.Profile, result licence.BulkRevokeResult) (postman.Parcel, error) {
+	name := a.NormalizeName()
+
+	body, err := CtxLicencesRevoked{
+		AdminName:        name,
+		BulkRevokeResult: result,
+	}.Render()
+
+	if err != nil {
+		return postman.Parcel{}, err
+	}
+
+	return postman.Parcel{
+		FromAddress: fromAddress,
+		FromName:    fromName,
+		ToAddress:   a.Email,
+		ToName:      name,
+		Subject:     subjectName + "批量撤销许可",
+		Body:        body,
+	}, nil
+}
+
+// LicenceReservedParcel tells admin a licence is reserved
+// for a reader until the reader's auto-renewal subscription ended.
+func LicenceReservedParcel(result licence.GrantResult, a licence.Assignee, adminProfile admin.Profile) (postman.Parcel, error) {
+	name := adminProfile.NormalizeName()
+
+	body, err := CtxLicenceReserved{
+		AdminName:         name,
+		AssigneeEmail:     a.Email.String,
+		Tier:              result.LicenceVersion.PostChange.Tier.StringCN(),
+		CurrentExpiration: result.BlockingMember.ExpireDate.String(),
+	}.Render()
+
+	if err != nil {
+		return postman.Parcel{}, err
+	}
+
+	return postman.Parcel{
+		FromAddress: fromAddress,
+		FromName:    fromName,
+		ToAddress:   adminProfile.Email,
+		ToName:      name,
+		Subject:     subjectName + "团队成员许可待生效",
+		Body:        body,
+	}, nil
+}
+
+// ReaderReservedParcel tells reader the licence accepted
+// is pending on the end of current auto-renewal subscription.
+func ReaderReservedParcel(result licence.GrantResult, a licence.Assignee, adminProfile admin.Profile) (postman.Parcel, error) {
+	body, err := CtxReaderReserved{
+		ReaderName:        a.NormalizeName(),
+		AdminEmail:        adminProfile.Email,
+		TeamName:          adminProfile.OrgName,
+		Tier:              result.LicenceVersion.PostChange.Tier.StringCN(),
+		CurrentExpiration: result.BlockingMember.ExpireDate.String(),
+	}.Render()
+
+	if err != nil {
+		return postman.Parcel{}, err
+	}
+
+	return postman.Parcel{
+		FromAddress: fromAddress,
+		FromName:    fromName,
+		ToAddress:   a.Email.String,
+		ToName:      a.NormalizeName(),
+		Subject:     subjectName + "会员许可待生效",
+		Body:        body,
+	}, nil
+}
+
+// LicenceRequestVrfParcel sends the link to verify the
+// company email used in a licence request.
+func LicenceRequestVrfParcel(r licence.LicenceRequest, a licence.Assignee, team admin.Team) (postman.Parcel, error) {
+	body, err := CtxLicenceRequestVrf{
+		ReaderName: a.NormalizeName(),
+		Email:      r.Email,
+		TeamName:   team.OrgName,
+		Link:       r.BuildURL(),
+		Duration:   r.FormatDuration(),
+	}.Render()
+
+	if err != nil {
+		return postman.Parcel{}, err
+	}
+
+	return postman.Parcel{
+		FromAddress: fromAddress,
+		FromName:    fromName,
+		ToAddress:   r.Email,
+		ToName:      a.NormalizeName(),
+		Subject:     subjectName + "验证企业邮箱",
+		Body:        body,
+	}, nil
+}
+
+// LicenceRequestedParcel tells admin a verified licence
+// request is waiting for approval.
+func LicenceRequestedParcel(r licence.LicenceRequest, adminProfile admin.Profile) (postman.Parcel, error) {
+	name := adminProfile.NormalizeName()
+
+	body, err := CtxLicenceRequested{
+		AdminName: name,
+		Email:     r.Email,
+	}.Render()
+
+	if err != nil {
+		return postman.Parcel{}, err
+	}
+
+	return postman.Parcel{
+		FromAddress: fromAddress,
+		FromName:    fromName,
+		ToAddress:   adminProfile.Email,
+		ToName:      name,
+		Subject:     subjectName + "新的许可申请",
+		Body:        body,
+	}, nil
+}
+
+// SeatsAvailableParcel tells admin to invite people on
+// waitlist since licences become available.
+func SeatsAvailableParcel(f licence.WaitlistFilled, adminProfile admin.Profile) (postman.Parcel, error) {
+	name := adminProfile.NormalizeName()
+
+	body, err := CtxSeatsAvailable{
+		AdminName: name,
+		Available: f.Available,
+		Waiting:   f.Waiting,
+	}.Render()
+
+	if err != nil {
+		return postman.Parcel{}, err
+	}
+
+	return postman.Parcel{
+		FromAddress: fromAddress,
+		FromName:    fromName,
+		ToAddress:   adminProfile.Email,
+		ToName:      name,
+		Subject:     subjectName + "候补成员等待分配许可",
+		Body:        body,
+	}, nil
+}
+
+var roleCN = map[admin.Role]string{
+	admin.RoleOwner:          "所有者",
+	admin.RoleBilling:        "财务（管理订单）",
+	admin.RoleLicenceManager: "许可管理员（管理邀请和许可）",
+	admin.RoleViewer:         "只读成员",
+}
+
+// AdminInvitationParcel invites an email to become co-admin
+// of a team.
+func AdminInvitationParcel(inv admin.AdminInvitation, inviter admin.Profile) (postman.Parcel, error) {
+	body, err := CtxAdminInvitation{
+		InviterName: inviter.NormalizeName(),
+		TeamName:    inviter.OrgName,
+		Role:        roleCN[inv.Role],
+		Email:       inv.Email,
+		Link:        inv.BuildURL(),
+		Duration:    "7天",
+	}.Render()
+
+	if err != nil {
+		return postman.Parcel{}, err
+	}
+
+	return postman.Parcel{
+		FromAddress: fromAddress,
+		FromName:    fromName,
+		ToAddress:   inv.Email,
+		ToName:      inv.Email,
+		Subject:     subjectName + inviter.OrgName + "邀请您成为管理员",
+		Body:        body,
+	}, nil
+}
+
+// AccountLockedParcel sends an admin the link to unlock
+// account.
+func AccountLockedParcel(a admin.BaseAccount, l admin.Lockout) (postman.Parcel, error) {
+	body, err := CtxAccountLocked{
+		AdminName: a.NormalizeName(),
+		LockedAt:  l.LockedUTC.In(time.UTC).Format("2006-01-02 15:04:05 UTC"),
+		Failures:  l.Failures,
+		ClientIP:  l.ClientIP,
+		Duration:  l.FormatDuration(),
+		Link:      l.BuildURL(),
+	}.Render()
+
+	if err != nil {
+		return postman.Parcel{}, err
+	}
+
+	return postman.Parcel{
+		FromAddress: fromAddress,
+		FromName:    fromName,
+		ToAddress:   a.Email,
+		ToName:      a.NormalizeName(),
+		Subject:     subjectName + "账号已临时锁定",
+		Body:        body,
+	}, nil
+}
@@ -7,6 +7,14 @@ const (
 	keyOrderCreated      = "order_created"
 	keyLicenceInvitation = "licence_invitation"
 	keyLicenceGranted    = "licence_granted"
+	keyLicencesRevoked   = "licences_revoked"
+	keyLicenceReserved   = "licence_reserved"
+	keyReaderReserved    = "reader_licence_reserved"
+	keyLicenceRequestVrf = "licence_request_verification"
+	keyLicenceRequested  = "licence_requested"
+	keySeatsAvailable    = "waitlist_seats_available"
+	keyAdminInvitation   = "admin_invitation"
+	keyAccountLocked     = "account_locked"
 )
 
 const customerService = `
@@ -94,5 +102,117 @@ FT中文网B2B管理员 {{.AdminName}}，你好！
 
 本邮件由系统自动生成，请勿回复。
 
+FT中文网`,
+
+	keyLicencesRevoked: `
+FT中文网B2B管理员 {{.AdminName}}，你好！
+
+您在B2B管理系统中批量撤销了{{.Total}}份会员许可，其中成功{{.Succeeded}}份，失败{{.Failed}}份。
+{{if .Failed}}
+以下许可未能撤销，请稍后重试：
+{{range .Items}}{{if not .Ok}}
+	{{.LicenceID}}{{end}}{{end}}
+{{end}}
+被撤销许可的团队成员将失去对应的FT中文网会员身份，这些许可可以重新分配给其他成员。
+
+本邮件由系统自动生成，请勿回复。
+
+FT中文网`,
+
+	keyLicenceReserved: `
+FT中文网B2B管理员 {{.AdminName}}，你好！
+
+团队成员{{.AssigneeEmail}}已经接受了您的邀请，但该用户目前持有自动续订的FT中文网会员，到期日期为{{.CurrentExpiration}}。
+
+为避免覆盖该用户已付费的订阅，订阅方案{{.Tier}}的许可已为其保留，待其自动续订结束后系统将自动授予该许可。
+
+在此之前，您可以在B2B管理系统中撤销该邀请以释放许可。
+
+本邮件由系统自动生成，请勿回复。
+
+FT中文网`,
+
+	keyReaderReserved: `
+FT中文网读者 {{.ReaderName}}，你好！
+
+您已接受{{.TeamName}}的FT中文网会员 {{.Tier}} 邀请。
+
+由于您当前持有自动续订的会员，到期日期为{{.CurrentExpiration}}，该许可将暂时为您保留。自动续订取消或到期后，系统会自动将许可授予您，届时无需再次操作。
+
+如有疑问，请联系您所属机构的管理员 {{.AdminEmail}}。
+
+本邮件由系统自动生成，请勿回复。
+
+FT中文网`,
+
+	keyLicenceRequestVrf: `
+FT中文网读者 {{.ReaderName}}，你好！
+
+您使用企业邮箱 {{.Email}} 申请了{{.TeamName}}的FT中文网会员许可，请点击以下链接验证该邮箱：
+
+{{.Link}}
+
+验证通过后，申请将提交给{{.TeamName}}的管理员审核。本链接{{.Duration}}内有效。
+
+如果您没有提交过申请，请忽略本邮件。
+
+本邮件由系统自动生成，请勿回复。
+
+FT中文网`,
+
+	keyLicenceRequested: `
+FT中文网B2B管理员 {{.AdminName}}，你好！
+
+团队成员使用企业邮箱 {{.Email}} 申请了FT中文网会员许可，该邮箱已通过验证。
+
+请登录B2B管理系统，在待审核的许可申请中批准或拒绝该申请。批准后系统将自动分配一份可用的许可，无需再发送邀请邮件。
+
+本邮件由系统自动生成，请勿回复。
+
+FT中文网`,
+
+	keySeatsAvailable: `
+FT中文网B2B管理员 {{.AdminName}}，你好！
+
+您的团队目前有{{.Available}}份会员许可可供分配，候补名单中有{{.Waiting}}人正在等待。
+
+请登录B2B管理系统，向候补名单中的成员发送邀请。您也可以开启自动分配，系统将按优先级自动向候补成员发送邀请。
+
+本邮件由系统自动生成，请勿回复。
+
+FT中文网`,
+
+	keyAdminInvitation: `
+你好！
+
+{{.InviterName}}邀请您成为{{.TeamName}}在FT中文网企业订阅服务的管理员，您的角色是：{{.Role}}。
+
+请点击以下链接接受邀请。如果您还没有企业订阅账号，请先使用本邮箱 {{.Email}} 注册，然后再接受邀请：
+
+{{.Link}}
+
+如果上述链接无法点击，可以复制粘贴到浏览器地址栏。本链接{{.Duration}}内有效。
+
+如果您不认识邀请人，请忽略本邮件。
+
+本邮件由系统自动生成，请勿回复。
+
+FT中文网`,
+
+	keyAccountLocked: `
+FT中文网企业订阅管理员 {{.AdminName}}，你好！
+
+您的账号在{{.LockedAt}}连续{{.Failures}}次登录失败，来源IP为 {{.ClientIP}}，为保护账号安全，已被临时锁定{{.Duration}}。
+
+如果是您本人操作，可以点击以下链接立即解锁：
+
+{{.Link}}
+
+如果上述链接无法点击，可以复制粘贴到浏览器地址栏。
+
+如果不是您本人操作，建议解锁后尽快修改密码。
+
+本邮件由系统自动生成，请勿回复。
+
 FT中文网`,
 }
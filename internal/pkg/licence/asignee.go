@@ -37,6 +37,7 @@ func (a Assignee) TeamMember(teamID string) Staffer {
 		Email:  a.Email.String,
 		TeamID: teamID,
 		FtcID:  a.FtcID,
+		Active: true,
 	}
 }
 
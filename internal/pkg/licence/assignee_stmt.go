@@ -23,3 +23,13 @@ LIMIT 1`
 const StmtAssigneeByEmail = selectAssignee + `
 WHERE email = ?
 LIMIT 1`
+
+// StmtIsEmailVerified checks whether a reader owns the email
+// of the account before it is trusted for domain match.
+const StmtIsEmailVerified = `
+SELECT IFNULL(p.email_verified, FALSE) AS is_verified
+FROM cmstmp01.userinfo AS u
+	LEFT JOIN user_db.profile AS p
+	ON u.user_id = p.user_id
+WHERE u.user_id = ?
+LIMIT 1`
@@ -0,0 +1,45 @@
+package licence
+
+import "github.com/FTChinese/go-rest/chrono"
+
+// IsReserved checks whether a licence is reserved for a
+// reader waiting for the deferred grant.
+func (l Licence) IsReserved() bool {
+	return l.Status == LicStatusReserved && l.AssigneeID.Valid
+}
+
+// WithReserved keeps a licence for a reader who accepted the
+// invitation while still having a valid auto-renewal
+// subscription. The licence will be granted once the
+// subscription ended.
+func (l Licence) WithReserved(to Assignee, inv Invitation) Licence {
+	l.HintGrantMismatch = false
+	l.Status = LicStatusReserved
+	l.LatestInvitation = InvitationJSON{inv.AcceptedPending()}
+	l.AssigneeID = to.FtcID
+	l.UpdatedUTC = chrono.TimeUTCNow()
+
+	return l
+}
+
+// ReserveLicence reserves a licence for a reader who cannot
+// be granted immediately due to auto-renewal subscription.
+func ReserveLicence(params GrantParams) GrantResult {
+	return GrantResult{
+		LicenceVersion: params.CurLic.
+			WithReserved(params.To, params.CurInv).
+			Versioned(VersionActionReserve).
+			WithPriorVersion(params.CurLic),
+		MemberModified: MemberModified{},
+		Deferred:       true,
+		BlockingMember: params.CurMember,
+	}
+}
+
+// DeferredGrant is a reserved licence whose assignee's
+// membership should be checked periodically.
+type DeferredGrant struct {
+	LicenceID  string `db:"licence_id"`
+	TeamID     string `db:"team_id"`
+	AssigneeID string `db:"assignee_id"`
+}
@@ -0,0 +1,143 @@
+package licence
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/reader"
+	"github.com/FTChinese/ftacademy/pkg/price"
+	"github.com/FTChinese/ftacademy/pkg/subs"
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/FTChinese/go-rest/enum"
+	"github.com/guregu/null"
+	"testing"
+	"time"
+)
+
+func autoRenewMember(method enum.PayMethod, expire time.Time, autoRenew bool) reader.Membership {
+	return reader.Membership{
+		UserIDs: reader.UserIDs{
+			CompoundID: "reader-1",
+			FtcID:      null.StringFrom("reader-1"),
+		},
+		Edition: price.Edition{
+			Tier:  enum.TierStandard,
+			Cycle: enum.CycleYear,
+		},
+		ExpireDate:    chrono.DateFrom(expire),
+		PaymentMethod: method,
+		StripeSubsID:  null.StringFrom("sub_1"),
+		AutoRenewal:   autoRenew,
+	}
+}
+
+func TestMembership_IsAutoRenewEnded(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		m    reader.Membership
+		want bool
+	}{
+		{
+			name: "Active stripe",
+			m:    autoRenewMember(enum.PayMethodStripe, now.AddDate(0, 1, 0), true),
+			want: false,
+		},
+		{
+			name: "Stripe past due but still renewing",
+			m:    autoRenewMember(enum.PayMethodStripe, now.AddDate(0, 0, -3), true),
+			want: false,
+		},
+		{
+			name: "Cancelled stripe before expiry",
+			m:    autoRenewMember(enum.PayMethodStripe, now.AddDate(0, 0, 3), false),
+			want: false,
+		},
+		{
+			name: "Cancelled stripe expired",
+			m:    autoRenewMember(enum.PayMethodStripe, now.AddDate(0, 0, -3), false),
+			want: true,
+		},
+		{
+			name: "Switched to one-time purchase",
+			m:    autoRenewMember(enum.PayMethodAli, now.AddDate(0, 1, 0), false),
+			want: true,
+		},
+		{
+			name: "No membership",
+			m:    reader.Membership{},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.IsAutoRenewEnded(); got != tt.want {
+				t.Errorf("IsAutoRenewEnded() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReserveOrGrant(t *testing.T) {
+	lic := Licence{
+		ID:     "lic-1",
+		Status: LicStatusInvited,
+	}
+	inv := Invitation{
+		ID:     "inv-1",
+		Status: InvitationStatusCreated,
+	}
+	to := Assignee{
+		FtcID: null.StringFrom("reader-1"),
+	}
+
+	t.Run("Auto-renewal is reserved", func(t *testing.T) {
+		params := GrantParams{
+			CurLic:    lic,
+			CurInv:    inv,
+			To:        to,
+			CurMember: autoRenewMember(enum.PayMethodStripe, time.Now().AddDate(0, 1, 0), true),
+		}
+
+		_, err := GrantLicence(params)
+		if err != subs.ErrOverrideAutoRenewForbidden {
+			t.Fatalf("GrantLicence() error = %v, want %v", err, subs.ErrOverrideAutoRenewForbidden)
+		}
+
+		got := ReserveLicence(params)
+		l := got.LicenceVersion.PostChange.Licence
+
+		if !got.Deferred || got.BlockingMember.IsZero() {
+			t.Error("result should be deferred with the blocking membership")
+		}
+		if !l.IsReserved() || l.AssigneeID.String != "reader-1" {
+			t.Errorf("licence should be reserved for reader-1, got %s %s", l.Status, l.AssigneeID.String)
+		}
+		if !l.LatestInvitation.IsPending() {
+			t.Errorf("invitation status %s, want pending", l.LatestInvitation.Status)
+		}
+		if !got.MembershipVersion.PostChange.IsZero() {
+			t.Error("membership should not be touched")
+		}
+		if !l.IsInvitationRevocable() {
+			t.Error("reserved licence should be revocable")
+		}
+	})
+
+	t.Run("Ended auto-renewal is granted", func(t *testing.T) {
+		got, err := GrantLicence(GrantParams{
+			CurLic:    lic,
+			CurInv:    inv,
+			To:        to,
+			CurMember: autoRenewMember(enum.PayMethodStripe, time.Now().AddDate(0, 0, -3), false),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.Deferred {
+			t.Error("grant should not be deferred")
+		}
+		if got.LicenceVersion.PostChange.Status != LicStatusGranted {
+			t.Errorf("licence status %s, want granted", got.LicenceVersion.PostChange.Status)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package licence
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/export"
+)
+
+// LicenceExportHeader is the header row of ExpandedLicence.ExportRow.
+var LicenceExportHeader = export.Header{
+	{En: "Licence ID", Zh: "许可ID"},
+	{En: "Tier", Zh: "会员类型"},
+	{En: "Cycle", Zh: "周期"},
+	{En: "Status", Zh: "状态"},
+	{En: "Assignee Email", Zh: "使用者邮箱"},
+	{En: "Assignee Name", Zh: "使用者名称"},
+	{En: "Period Start (UTC)", Zh: "当前周期开始 (UTC)"},
+	{En: "Expires (UTC)", Zh: "到期时间 (UTC)"},
+	{En: "Unit ID", Zh: "部门ID"},
+	{En: "Created (UTC)", Zh: "创建时间 (UTC)"},
+}
+
+// ExportRow is used when admin downloads licences as a
+// spreadsheet.
+func (l ExpandedLicence) ExportRow(lang export.Lang) []string {
+	return []string{
+		l.ID,
+		export.Tier(l.Tier, lang),
+		export.Cycle(l.Cycle, lang),
+		l.Status.String(),
+		l.Assignee.Email.String,
+		l.Assignee.UserName.String,
+		export.Time(l.CurrentPeriodStartUTC),
+		export.Time(l.CurrentPeriodEndUTC),
+		l.UnitID.String,
+		export.Time(l.CreatedUTC),
+	}
+}
+
+// InvitationExportHeader is the header row of Invitation.ExportRow.
+var InvitationExportHeader = export.Header{
+	{En: "Invitation ID", Zh: "邀请ID"},
+	{En: "Email", Zh: "受邀邮箱"},
+	{En: "Status", Zh: "状态"},
+	{En: "Expired", Zh: "已过期"},
+	{En: "Licence ID", Zh: "许可ID"},
+	{En: "Description", Zh: "备注"},
+	{En: "Created (UTC)", Zh: "创建时间 (UTC)"},
+}
+
+func (i Invitation) ExportRow(lang export.Lang) []string {
+	var expired string
+	switch {
+	case i.Status != InvitationStatusCreated:
+		expired = ""
+	case i.IsExpired() && lang == export.LangEn:
+		expired = "yes"
+	case i.IsExpired():
+		expired = "是"
+	case lang == export.LangEn:
+		expired = "no"
+	default:
+		expired = "否"
+	}
+
+	return []string{
+		i.ID,
+		i.Email,
+		i.Status.String(),
+		expired,
+		i.LicenceID,
+		i.Description.String,
+		export.Time(i.CreatedUTC),
+	}
+}
@@ -0,0 +1,46 @@
+package licence
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/export"
+	"github.com/FTChinese/go-rest/chrono"
+	"testing"
+	"time"
+)
+
+func TestInvitation_ExportRow(t *testing.T) {
+	inv := Invitation{
+		ID:             "inv_1",
+		Status:         InvitationStatusCreated,
+		ExpirationDays: 7,
+		Email:          "a@example.org",
+		LicenceID:      "lic_1",
+		RowTime: admin.RowTime{
+			CreatedUTC: chrono.TimeFrom(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)),
+		},
+	}
+
+	row := inv.ExportRow(export.LangEn)
+	if len(row) != len(InvitationExportHeader) {
+		t.Fatalf("ExportRow() has %d columns, header has %d", len(row), len(InvitationExportHeader))
+	}
+
+	if row[3] != "yes" {
+		t.Errorf("Expired column = %s, want yes", row[3])
+	}
+
+	if row[6] != "2026-01-02 03:04:05" {
+		t.Errorf("Created column = %s", row[6])
+	}
+
+	if got := inv.ExportRow(export.LangZh)[3]; got != "是" {
+		t.Errorf("Expired column in zh = %s", got)
+	}
+}
+
+func TestExpandedLicence_ExportRow(t *testing.T) {
+	row := ExpandedLicence{}.ExportRow(export.LangZh)
+	if len(row) != len(LicenceExportHeader) {
+		t.Errorf("ExportRow() has %d columns, header has %d", len(row), len(LicenceExportHeader))
+	}
+}
@@ -84,6 +84,8 @@ func (l Licence) WithGrantMismatch(mismatched bool) Licence {
 type GrantResult struct {
 	LicenceVersion Versioned `json:"licenceVersion"`
 	MemberModified
+	Deferred       bool              `json:"deferred"`       // Licence is only reserved and will be granted after reader's auto-renewal subscription ended.
+	BlockingMember reader.Membership `json:"blockingMember"` // The auto-renewal membership deferring the grant.
 }
 
 type GrantParams struct {
@@ -30,6 +30,7 @@ type Invitation struct {
 	Email          string           `json:"email" db:"invite_email"`
 	LicenceID      string           `json:"licenceId" db:"licence_id"`
 	Token          string           `json:"-" db:"invite_token"` // This field is used only when inserting data. Retrieval does not include this field. However, it is included when saving to the JSON column in licence.
+	admin.Operator
 	admin.RowTime
 }
 
@@ -51,6 +52,7 @@ func NewInvitation(params input.InvitationParams, p admin.PassportClaims) (Invit
 		LicenceID:      params.LicenceID,
 		Status:         InvitationStatusCreated,
 		Token:          token,
+		Operator:       p.Operator,
 		RowTime:        admin.NewRowTime(),
 	}, nil
 }
@@ -81,8 +83,27 @@ func (i Invitation) Accepted() Invitation {
 	return i
 }
 
+// AcceptedPending marks an invitation as accepted while the
+// licence could only be granted after reader's auto-renewal
+// subscription ended.
+func (i Invitation) AcceptedPending() Invitation {
+	i.Status = InvitationStatusPending
+	i.UpdatedUTC = chrono.TimeNow()
+
+	return i
+}
+
+// IsPending checks whether the invitation is accepted
+// but the licence is not granted yet.
+func (i Invitation) IsPending() bool {
+	return i.Status == InvitationStatusPending
+}
+
+// IsRevocable checks whether admin could revoke an invitation.
+// A pending invitation could be revoked so that the reserved
+// licence is released.
 func (i Invitation) IsRevocable() bool {
-	return i.Status == InvitationStatusCreated
+	return i.Status == InvitationStatusCreated || i.Status == InvitationStatusPending
 }
 
 // Revoked invalidates an invitation by admin.
@@ -93,6 +114,15 @@ func (i Invitation) Revoked() Invitation {
 	return i
 }
 
+// Expired invalidates an invitation not accepted within
+// its valid period.
+func (i Invitation) Expired() Invitation {
+	i.Status = InvitationStatusExpired
+	i.UpdatedUTC = chrono.TimeNow()
+
+	return i
+}
+
 func (i Invitation) FormatDuration() string {
 	return fmt.Sprintf("%d天", i.ExpirationDays)
 }
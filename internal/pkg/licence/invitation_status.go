@@ -15,6 +15,8 @@ const (
 	InvitationStatusCreated
 	InvitationStatusAccepted
 	InvitationStatusRevoked
+	InvitationStatusPending // Accepted but waiting for reader's auto-renewal subscription to end.
+	InvitationStatusExpired // Not accepted within valid period and the licence is released.
 )
 
 var _invitationStatusNames = [...]string{
@@ -22,6 +24,8 @@ var _invitationStatusNames = [...]string{
 	"created",
 	"accepted",
 	"revoked",
+	"accepted_pending",
+	"expired",
 }
 
 // String representation of OrderKind
@@ -29,6 +33,8 @@ var _invitationStatusMap = map[InvitationStatus]string{
 	1: _invitationStatusNames[1],
 	2: _invitationStatusNames[2],
 	3: _invitationStatusNames[3],
+	4: _invitationStatusNames[4],
+	5: _invitationStatusNames[5],
 }
 
 // Used to get OrderKind from a string.
@@ -36,6 +42,8 @@ var _invitationStatusValue = map[string]InvitationStatus{
 	_invitationStatusNames[1]: 1,
 	_invitationStatusNames[2]: 2,
 	_invitationStatusNames[3]: 3,
+	_invitationStatusNames[4]: 4,
+	_invitationStatusNames[5]: 5,
 }
 
 // ParseInvitationStatus creates OrderKind from a string.
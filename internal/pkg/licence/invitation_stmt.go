@@ -1,5 +1,20 @@
 package licence
 
+// andInvitationInUnit limits invitations to those sent for
+// licences of a unit. It takes the unit id twice.
+const andInvitationInUnit = `
+	AND (? IS NULL OR i.licence_id IN (
+		SELECT id
+		FROM b2b.licence
+		WHERE unit_id = ?
+	))`
+
+// StmtCreateInvitation saves an invitation. The operator
+// columns are only set when CMS invites on behalf of a team:
+//
+//	ALTER TABLE b2b.invitation
+//		ADD COLUMN operator_name VARCHAR(64) NULL,
+//		ADD COLUMN operator_reason VARCHAR(512) NULL;
 const StmtCreateInvitation = `
 INSERT INTO b2b.invitation
 SET id = :invite_id,
@@ -11,6 +26,8 @@ SET id = :invite_id,
 	licence_id = :licence_id,
 	current_status = :invite_status,
 	token = UNHEX(:invite_token),
+	operator_name = :operator_name,
+	operator_reason = :operator_reason,
 	created_utc = :created_utc,
 	updated_utc = :updated_utc`
 
@@ -27,13 +44,15 @@ SELECT i.id AS invite_id,
 	i.invitee_email AS invite_email,
 	i.licence_id AS licence_id,
 	LOWER(HEX(i.token)) AS invite_token,
+	i.operator_name AS operator_name,
+	i.operator_reason AS operator_reason,
 	i.created_utc AS created_utc,
 	i.updated_utc AS updated_utc
 FROM b2b.invitation AS i
 `
 
 const StmtInvitationByID = colInvitation + `
-WHERE i.id = ? AND i.team_id = ?
+WHERE i.id = ? AND i.team_id = ?` + andInvitationInUnit + `
 LIMIT 1
 `
 
@@ -53,14 +72,14 @@ LIMIT 1`
 // of invitations with its assignee attached.
 // This is used by admin to views invitations it sent.
 const StmtListInvitation = colInvitation + `
-WHERE i.team_id = ?
+WHERE i.team_id = ?` + andInvitationInUnit + `
 ORDER BY i.created_utc DESC
 LIMIT ? OFFSET ?`
 
 const StmtCountInvitation = `
 SELECT COUNT(*)
-FROM b2b.invitation
-WHERE team_id = ?`
+FROM b2b.invitation AS i
+WHERE i.team_id = ?` + andInvitationInUnit
 
 const StmtUpdateInvitationStatus = `
 UPDATE b2b.invitation
@@ -69,3 +88,9 @@ SET current_status = :invite_status,
 WHERE id = :invite_id
 	AND team_id = :team_id
 LIMIT 1`
+
+// StmtExportInvitations retrieves all invitations of a team
+// without pagination. Rows should be streamed.
+const StmtExportInvitations = colInvitation + `
+WHERE i.team_id = ?` + andInvitationInUnit + `
+ORDER BY i.created_utc DESC`
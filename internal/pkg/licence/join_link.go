@@ -0,0 +1,200 @@
+package licence
+
+import (
+	"database/sql/driver"
+	"errors"
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/ids"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/pkg/price"
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/FTChinese/go-rest/rand"
+	"github.com/guregu/null"
+	"strings"
+	"time"
+)
+
+// DomainList is saved as comma separated string.
+type DomainList []string
+
+func (l DomainList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+
+	return strings.Join(l, ","), nil
+}
+
+func (l *DomainList) Scan(src interface{}) error {
+	if src == nil {
+		*l = DomainList{}
+		return nil
+	}
+
+	switch s := src.(type) {
+	case []byte:
+		*l = strings.Split(string(s), ",")
+		return nil
+
+	default:
+		return errors.New("incompatible type to scan")
+	}
+}
+
+// Contains checks whether the domain of email is in the list.
+func (l DomainList) Contains(email string) bool {
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[i+1:])
+
+	for _, d := range l {
+		if d == domain {
+			return true
+		}
+	}
+
+	return false
+}
+
+type JoinLinkStatus string
+
+const (
+	JoinLinkStatusActive  JoinLinkStatus = "active"
+	JoinLinkStatusRevoked JoinLinkStatus = "revoked"
+)
+
+// JoinLink is a shareable link granting up to SeatCap
+// licences of an edition to whoever opens it with an email
+// under the allowed domains.
+// The link shares the url of invitation, with a query
+// parameter to tell them apart.
+// Each grant still creates a normal Invitation.
+type JoinLink struct {
+	ID string `json:"id" db:"link_id"`
+	admin.Creator
+	price.Edition
+	Token       string         `json:"-" db:"token"`
+	SeatCap     int64          `json:"seatCap" db:"seat_cap"`
+	UsedCount   int64          `json:"usedCount" db:"used_count"`
+	Domains     DomainList     `json:"domains" db:"allowed_domains"`
+	Description null.String    `json:"description" db:"link_desc"`
+	Status      JoinLinkStatus `json:"status" db:"link_status"`
+	ExpiresUTC  chrono.Time    `json:"expiresUtc" db:"expires_utc"`
+	URL         string         `json:"url" db:"-"`
+	admin.RowTime
+}
+
+func NewJoinLink(params input.JoinLinkParams, by admin.Creator) (JoinLink, error) {
+	token, err := rand.Hex(32)
+	if err != nil {
+		return JoinLink{}, err
+	}
+
+	return JoinLink{
+		ID:          ids.JoinLinkID(),
+		Creator:     by,
+		Edition:     params.Edition,
+		Token:       token,
+		SeatCap:     params.SeatCap,
+		UsedCount:   0,
+		Domains:     params.Domains,
+		Description: params.Description,
+		Status:      JoinLinkStatusActive,
+		ExpiresUTC:  chrono.TimeUTCFrom(time.Now().AddDate(0, 0, int(params.ExpirationDays))),
+		RowTime:     admin.NewRowTime(),
+	}.WithURL(), nil
+}
+
+// WithURL populates the URL field to be shared.
+// Token is never sent to client except in this url.
+func (l JoinLink) WithURL() JoinLink {
+	l.URL = pkg.B2BVerifyInvitationURL(l.Token) + "?join=true"
+	return l
+}
+
+func (l JoinLink) IsExpired() bool {
+	return l.ExpiresUTC.Before(time.Now())
+}
+
+func (l JoinLink) SeatsLeft() int64 {
+	if l.UsedCount >= l.SeatCap {
+		return 0
+	}
+
+	return l.SeatCap - l.UsedCount
+}
+
+// IsJoinable checks whether the link could still grant a licence.
+func (l JoinLink) IsJoinable() bool {
+	return l.Status == JoinLinkStatusActive &&
+		!l.IsExpired() &&
+		l.SeatsLeft() > 0
+}
+
+// SeatTaken increases the used count when a reader joins.
+func (l JoinLink) SeatTaken() JoinLink {
+	l.UsedCount++
+	l.UpdatedUTC = chrono.TimeNow()
+
+	return l
+}
+
+func (l JoinLink) Revoked() JoinLink {
+	l.Status = JoinLinkStatusRevoked
+	l.UpdatedUTC = chrono.TimeNow()
+
+	return l
+}
+
+type JoinLinkList struct {
+	pkg.PagedList
+	Data []JoinLink `json:"data"`
+}
+
+// JoinLinkUse records a reader who joined a team via a link.
+type JoinLinkUse struct {
+	LinkID       string      `json:"linkId" db:"link_id"`
+	TeamID       string      `json:"teamId" db:"team_id"`
+	FtcID        string      `json:"ftcId" db:"ftc_id"`
+	Email        string      `json:"email" db:"email"`
+	InvitationID null.String `json:"invitationId" db:"invitation_id"`
+	LicenceID    null.String `json:"licenceId" db:"licence_id"`
+	CreatedUTC   chrono.Time `json:"createdUtc" db:"created_utc"`
+}
+
+func NewJoinLinkUse(l JoinLink, to Assignee) JoinLinkUse {
+	return JoinLinkUse{
+		LinkID:       l.ID,
+		TeamID:       l.TeamID,
+		FtcID:        to.FtcID.String,
+		Email:        to.Email.String,
+		InvitationID: null.String{},
+		LicenceID:    null.String{},
+		CreatedUTC:   chrono.TimeNow(),
+	}
+}
+
+// WithInvitation links the use to the invitation created
+// for it after licence granted.
+func (u JoinLinkUse) WithInvitation(inv Invitation) JoinLinkUse {
+	u.InvitationID = null.StringFrom(inv.ID)
+	u.LicenceID = null.StringFrom(inv.LicenceID)
+
+	return u
+}
+
+// JoinLinkVerified is returned when a reader opens a link
+// so that client could show what is offered.
+type JoinLinkVerified struct {
+	Link     JoinLink `json:"link"`
+	TeamName string   `json:"teamName"`
+}
+
+// TeamJoined is the result of a reader joining via link.
+type TeamJoined struct {
+	Use JoinLinkUse `json:"use"`
+	GrantResult
+}
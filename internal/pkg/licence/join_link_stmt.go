@@ -0,0 +1,106 @@
+package licence
+
+const StmtCreateJoinLink = `
+INSERT INTO b2b.join_link
+SET id = :link_id,
+	admin_id = :admin_id,
+	team_id = :team_id,
+	tier = :tier,
+	cycle = :cycle,
+	token = UNHEX(:token),
+	seat_cap = :seat_cap,
+	used_count = :used_count,
+	allowed_domains = :allowed_domains,
+	link_desc = :link_desc,
+	link_status = :link_status,
+	expires_utc = :expires_utc,
+	created_utc = :created_utc,
+	updated_utc = :updated_utc`
+
+const colJoinLink = `
+SELECT id AS link_id,
+	admin_id,
+	team_id,
+	tier,
+	cycle,
+	LOWER(HEX(token)) AS token,
+	seat_cap,
+	used_count,
+	allowed_domains,
+	link_desc,
+	link_status,
+	expires_utc,
+	created_utc,
+	updated_utc
+FROM b2b.join_link
+`
+
+const StmtJoinLinkByToken = colJoinLink + `
+WHERE token = UNHEX(?)
+LIMIT 1`
+
+// StmtLockJoinLinkByToken locks a link when a reader
+// is taking a seat from it.
+const StmtLockJoinLinkByToken = StmtJoinLinkByToken + `
+FOR UPDATE`
+
+const StmtLockJoinLink = colJoinLink + `
+WHERE id = ?
+	AND team_id = ?
+LIMIT 1
+FOR UPDATE`
+
+const StmtUpdateJoinLink = `
+UPDATE b2b.join_link
+SET used_count = :used_count,
+	link_status = :link_status,
+	updated_utc = :updated_utc
+WHERE id = :link_id
+LIMIT 1`
+
+const StmtListJoinLinks = colJoinLink + `
+WHERE team_id = ?
+ORDER BY created_utc DESC
+LIMIT ? OFFSET ?`
+
+const StmtCountJoinLinks = `
+SELECT COUNT(*) AS row_count
+FROM b2b.join_link
+WHERE team_id = ?`
+
+const StmtCreateJoinLinkUse = `
+INSERT INTO b2b.join_link_use
+SET link_id = :link_id,
+	team_id = :team_id,
+	ftc_id = :ftc_id,
+	email = :email,
+	invitation_id = :invitation_id,
+	licence_id = :licence_id,
+	created_utc = :created_utc`
+
+// StmtJoinLinkUseExists prevents a reader from taking
+// multiple seats via the same link.
+const StmtJoinLinkUseExists = `
+SELECT EXISTS (
+	SELECT *
+	FROM b2b.join_link_use
+	WHERE link_id = ?
+		AND ftc_id = ?
+) AS already_exists`
+
+// StmtLockAvailableEditionLicence locks a licence of the
+// edition offered by a join link. Only licences kept at team
+// level are used so that join links never eat into the budget
+// allocated to a unit. Licences locked by concurrent joins
+// are skipped.
+const StmtLockAvailableEditionLicence = colLicence + `
+FROM b2b.licence AS l
+WHERE l.team_id = ?
+	AND l.unit_id IS NULL
+	AND l.tier = ?
+	AND l.cycle = ?
+	AND l.current_status = 'available'
+	AND l.assignee_id IS NULL
+ORDER BY l.current_period_end_utc DESC
+LIMIT 1
+FOR UPDATE SKIP LOCKED`
@@ -0,0 +1,129 @@
+package licence
+
+import (
+	"github.com/FTChinese/go-rest/chrono"
+	"testing"
+	"time"
+)
+
+func TestDomainList_Contains(t *testing.T) {
+	l := DomainList{"example.com", "example.org"}
+
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{email: "alice@example.com", want: true},
+		{email: "Bob@EXAMPLE.ORG", want: true},
+		{email: "eve@sub.example.com", want: false},
+		{email: "eve@example.com.evil.net", want: false},
+		{email: "eve@evil.net", want: false},
+		{email: "example.com", want: false},
+		{email: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			if got := l.Contains(tt.email); got != tt.want {
+				t.Errorf("Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if (DomainList{}).Contains("alice@example.com") {
+		t.Errorf("empty list should not allow any email")
+	}
+}
+
+func TestJoinLink_IsJoinable(t *testing.T) {
+	future := chrono.TimeFrom(time.Now().AddDate(0, 0, 1))
+	past := chrono.TimeFrom(time.Now().AddDate(0, 0, -1))
+
+	tests := []struct {
+		name          string
+		link          JoinLink
+		wantSeatsLeft int64
+		want          bool
+	}{
+		{
+			name: "Active with seats",
+			link: JoinLink{
+				SeatCap:    3,
+				UsedCount:  2,
+				Status:     JoinLinkStatusActive,
+				ExpiresUTC: future,
+			},
+			wantSeatsLeft: 1,
+			want:          true,
+		},
+		{
+			name: "Seats used up",
+			link: JoinLink{
+				SeatCap:    3,
+				UsedCount:  3,
+				Status:     JoinLinkStatusActive,
+				ExpiresUTC: future,
+			},
+			wantSeatsLeft: 0,
+			want:          false,
+		},
+		{
+			name: "Used count over cap",
+			link: JoinLink{
+				SeatCap:    3,
+				UsedCount:  5,
+				Status:     JoinLinkStatusActive,
+				ExpiresUTC: future,
+			},
+			wantSeatsLeft: 0,
+			want:          false,
+		},
+		{
+			name: "Expired",
+			link: JoinLink{
+				SeatCap:    3,
+				Status:     JoinLinkStatusActive,
+				ExpiresUTC: past,
+			},
+			wantSeatsLeft: 3,
+			want:          false,
+		},
+		{
+			name: "Revoked",
+			link: JoinLink{
+				SeatCap:    3,
+				Status:     JoinLinkStatusActive,
+				ExpiresUTC: future,
+			}.Revoked(),
+			wantSeatsLeft: 3,
+			want:          false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.link.SeatsLeft(); got != tt.wantSeatsLeft {
+				t.Errorf("SeatsLeft() = %v, want %v", got, tt.wantSeatsLeft)
+			}
+			if got := tt.link.IsJoinable(); got != tt.want {
+				t.Errorf("IsJoinable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoinLink_SeatTaken(t *testing.T) {
+	l := JoinLink{
+		SeatCap:    2,
+		Status:     JoinLinkStatusActive,
+		ExpiresUTC: chrono.TimeFrom(time.Now().AddDate(0, 0, 1)),
+	}
+
+	l = l.SeatTaken()
+	if !l.IsJoinable() {
+		t.Errorf("link should be joinable with 1 seat left")
+	}
+
+	l = l.SeatTaken()
+	if l.IsJoinable() {
+		t.Errorf("link should not be joinable after cap reached")
+	}
+}
@@ -38,6 +38,7 @@ type Licence struct {
 	LatestPrice           price.Price    `json:"latestPrice" db:"latest_price"`
 	LatestInvitation      InvitationJSON `json:"latestInvitation" db:"latest_invitation"` // A redundant field that should be synced with the invitation row. I created this field since I don't know how to retrieve both licence and invitation row in SQL's flat structure, specially used when retrieve a list of such rows.
 	AssigneeID            null.String    `json:"assigneeId" db:"assignee_id"`
+	UnitID                null.String    `json:"unitId" db:"unit_id"` // The department this licence is allocated to. Null if it belongs to the whole team.
 	admin.RowTime
 }
 
@@ -105,8 +106,12 @@ func (l Licence) RenewalStartTime() time.Time {
 // generate multiple invitations.
 // However, only the latest invitation could be accepted.
 // All previous invitations will be invalidated in such case.
+// A licence held by a redemption code is not available until
+// the code is redeemed, revoked or expired.
 func (l Licence) IsAvailable() bool {
-	return l.Status != LicStatusGranted && l.AssigneeID.IsZero()
+	return l.Status != LicStatusGranted &&
+		l.Status != LicStatusCoded &&
+		l.AssigneeID.IsZero()
 }
 
 // CreateInvitation builds a new invitation for this licence.
@@ -128,9 +133,10 @@ func (l Licence) CreateInvitation(params input.InvitationParams, claims admin.Pa
 // IsInvitationRevocable ensures that the licence could
 // have its invitation revoked.
 // A licence could only have its invitation revoked when
-// an invitation is sent but not accepted.
+// an invitation is sent but not accepted, or when it is
+// reserved pending the end of reader's auto-renewal.
 func (l Licence) IsInvitationRevocable() bool {
-	return l.Status == LicStatusInvited && l.AssigneeID.IsZero()
+	return (l.Status == LicStatusInvited && l.AssigneeID.IsZero()) || l.IsReserved()
 }
 
 // WithInvitationRevoked syncs the licence's invitation when
@@ -161,3 +167,20 @@ type PagedLicenceList struct {
 	pkg.PagedList
 	Data []ExpandedLicence `json:"data"`
 }
+
+// Allocation moves a licence into a unit.
+type Allocation struct {
+	LicenceID  string      `db:"licence_id"`
+	TeamID     string      `db:"team_id"`
+	UnitID     null.String `db:"unit_id"`
+	UpdatedUTC chrono.Time `db:"updated_utc"`
+}
+
+// LicencesAllocated is the result of allocating licences
+// to a unit. Ids not belonging to the team are listed in
+// NotFound.
+type LicencesAllocated struct {
+	UnitID    null.String `json:"unitId"`
+	Allocated int64       `json:"allocated"`
+	NotFound  []string    `json:"notFound"`
+}
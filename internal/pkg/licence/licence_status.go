@@ -14,6 +14,8 @@ const (
 	LicStatusAvailable
 	LicStatusInvited
 	LicStatusGranted
+	LicStatusReserved // Reserved for a reader whose auto-renewal subscription is not ended yet.
+	LicStatusCoded    // Held by a redemption code.
 )
 
 var _licenceStatusNames = [...]string{
@@ -21,6 +23,8 @@ var _licenceStatusNames = [...]string{
 	"available",
 	"invited",
 	"granted",
+	"reserved",
+	"coded",
 }
 
 // String representation of OrderKind
@@ -28,6 +32,8 @@ var _licenceStatusMap = map[Status]string{
 	1: _licenceStatusNames[1],
 	2: _licenceStatusNames[2],
 	3: _licenceStatusNames[3],
+	4: _licenceStatusNames[4],
+	5: _licenceStatusNames[5],
 }
 
 // Used to get OrderKind from a string.
@@ -35,6 +41,8 @@ var _licenceStatusValue = map[string]Status{
 	_licenceStatusNames[1]: 1,
 	_licenceStatusNames[2]: 2,
 	_licenceStatusNames[3]: 3,
+	_licenceStatusNames[4]: 4,
+	_licenceStatusNames[5]: 5,
 }
 
 // ParseLicenceStatus creates OrderKind from a string.
@@ -1,5 +1,10 @@
 package licence
 
+// andLicenceInUnit limits licences to a unit. It takes the
+// unit id twice and has no effect if unit id is NULL.
+const andLicenceInUnit = `
+	AND (? IS NULL OR l.unit_id = ?)`
+
 const colLicence = `
 SELECT l.id AS licence_id,
 	l.tier AS tier,
@@ -17,6 +22,7 @@ SELECT l.id AS licence_id,
 	l.latest_price AS latest_price,
 	l.latest_invitation AS latest_invitation,
 	l.assignee_id AS assignee_id,
+	l.unit_id AS unit_id,
 	l.created_utc AS created_utc,
 	l.updated_utc AS updated_utc
 `
@@ -28,26 +34,28 @@ FROM b2b.licence AS l
 	ON l.assignee_id = a.user_id
 `
 
+// StmtLicence retrieves a licence of a team. If unit id is
+// not null, the licence must belong to that unit.
 const StmtLicence = selectLicence + `
-WHERE l.id = ? AND l.team_id = ?
+WHERE l.id = ? AND l.team_id = ?` + andLicenceInUnit + `
 LIMIT 1`
 
 const StmtListLicences = selectLicence + `
-WHERE l.team_id = ?
+WHERE l.team_id = ?` + andLicenceInUnit + `
 ORDER BY l.created_utc DESC
 LIMIT ? OFFSET ?`
 
 // StmtCountLicence is used to support pagination.
 const StmtCountLicence = `
 SELECT COUNT(*) AS row_count
-FROM b2b.licence
-WHERE team_id = ?`
+FROM b2b.licence AS l
+WHERE l.team_id = ?` + andLicenceInUnit
 
 // StmtLockLicence locks a row from licence table when
 // admin updates it.
 const StmtLockLicence = colLicence + `
 FROM b2b.licence AS l
-WHERE l.id = ? AND l.team_id = ?
+WHERE l.id = ? AND l.team_id = ?` + andLicenceInUnit + `
 LIMIT 1
 FOR UPDATE
 `
@@ -72,3 +80,47 @@ SET current_status = :lic_status,
 WHERE id = :licence_id 
 	AND team_id = :team_id
 LIMIT 1`
+
+// StmtGrantedLicenceIDsByDomain finds licences of a team,
+// or a unit of it, granted to readers whose email is under
+// the specified domain.
+// The domain must be escaped by pkg.EscapeLike.
+// Client should fetch one row more than the limit to tell
+// whether the result is truncated.
+const StmtGrantedLicenceIDsByDomain = `
+SELECT l.id
+FROM b2b.licence AS l
+	JOIN cmstmp01.userinfo AS a
+	ON l.assignee_id = a.user_id
+WHERE l.team_id = ?` + andLicenceInUnit + `
+	AND l.current_status = 'granted'
+	AND a.email LIKE CONCAT('%@', ?)
+ORDER BY l.created_utc ASC
+LIMIT ?`
+
+// StmtListReservedLicences retrieves licences reserved for
+// readers waiting for their auto-renewal subscription to end.
+const StmtListReservedLicences = `
+SELECT id AS licence_id,
+	team_id,
+	assignee_id
+FROM b2b.licence
+WHERE current_status = 'reserved'
+	AND assignee_id IS NOT NULL
+ORDER BY updated_utc ASC`
+
+// StmtAllocateLicence moves a licence into a unit, or back
+// to team level if unit_id is NULL.
+const StmtAllocateLicence = `
+UPDATE b2b.licence
+SET unit_id = :unit_id,
+	updated_utc = :updated_utc
+WHERE id = :licence_id
+	AND team_id = :team_id
+LIMIT 1`
+
+// StmtExportLicences retrieves all licences of a team without
+// pagination. Rows should be streamed.
+const StmtExportLicences = selectLicence + `
+WHERE l.team_id = ?` + andLicenceInUnit + `
+ORDER BY l.created_utc DESC`
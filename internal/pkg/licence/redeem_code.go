@@ -0,0 +1,215 @@
+package licence
+
+import (
+	"crypto/rand"
+	"fmt"
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/ids"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/guregu/null"
+	"strings"
+	"time"
+)
+
+type RedeemStatus string
+
+const (
+	RedeemStatusActive   RedeemStatus = "active"
+	RedeemStatusRedeemed RedeemStatus = "redeemed"
+	RedeemStatusRevoked  RedeemStatus = "revoked"
+	RedeemStatusExpired  RedeemStatus = "expired"
+)
+
+// Ambiguous characters like 0/O, 1/I are excluded so that
+// codes could be typed by hand.
+const redeemCodeChars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const redeemCodeLen = 16
+
+// newRedeemCode generates a random code of 16 characters,
+// which is about 80 bits of entropy.
+func newRedeemCode() (string, error) {
+	b := make([]byte, redeemCodeLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	for i := range b {
+		b[i] = redeemCodeChars[int(b[i])%len(redeemCodeChars)]
+	}
+
+	return string(b), nil
+}
+
+// RedeemCode holds a licence so that it could be claimed by
+// whoever has the code, without admin knowing the email.
+// A code could in these phases:
+// active -> redeemed | revoked | expired.
+type RedeemCode struct {
+	ID string `json:"id" db:"code_id"`
+	admin.Creator
+	Code        string       `json:"code" db:"redeem_code"` // Without hyphens.
+	LicenceID   string       `json:"licenceId" db:"licence_id"`
+	Description null.String  `json:"description" db:"code_desc"`
+	Status      RedeemStatus `json:"status" db:"code_status"`
+	ExpiresUTC  chrono.Time  `json:"expiresUtc" db:"expires_utc"`
+	RedeemedBy  null.String  `json:"redeemedBy" db:"redeemed_by"` // Ftc id of the reader.
+	admin.RowTime
+}
+
+func NewRedeemCode(licID string, params input.RedeemCodeParams, by admin.Creator) (RedeemCode, error) {
+	code, err := newRedeemCode()
+	if err != nil {
+		return RedeemCode{}, err
+	}
+
+	return RedeemCode{
+		ID:          ids.RedeemCodeID(),
+		Creator:     by,
+		Code:        code,
+		LicenceID:   licID,
+		Description: params.Description,
+		Status:      RedeemStatusActive,
+		ExpiresUTC:  chrono.TimeUTCFrom(time.Now().AddDate(0, 0, int(params.ExpirationDays))),
+		RedeemedBy:  null.String{},
+		RowTime:     admin.NewRowTime(),
+	}, nil
+}
+
+// Formatted splits the code into groups of 4 characters.
+func (r RedeemCode) Formatted() string {
+	var groups []string
+	for i := 0; i < len(r.Code); i += 4 {
+		end := i + 4
+		if end > len(r.Code) {
+			end = len(r.Code)
+		}
+		groups = append(groups, r.Code[i:end])
+	}
+
+	return strings.Join(groups, "-")
+}
+
+// BuildURL creates the link encoded in QR image.
+func (r RedeemCode) BuildURL() string {
+	return pkg.ReaderRedeemURL(r.Formatted())
+}
+
+func (r RedeemCode) IsExpired() bool {
+	return r.ExpiresUTC.Before(time.Now())
+}
+
+// IsRedeemable checks whether a reader could use the code.
+func (r RedeemCode) IsRedeemable() bool {
+	return r.Status == RedeemStatusActive && !r.IsExpired()
+}
+
+// IsRevocable checks whether admin could take back the
+// licence held by this code.
+func (r RedeemCode) IsRevocable() bool {
+	return r.Status == RedeemStatusActive
+}
+
+func (r RedeemCode) Redeemed(ftcID string) RedeemCode {
+	r.Status = RedeemStatusRedeemed
+	r.RedeemedBy = null.StringFrom(ftcID)
+	r.UpdatedUTC = chrono.TimeNow()
+
+	return r
+}
+
+func (r RedeemCode) Revoked() RedeemCode {
+	r.Status = RedeemStatusRevoked
+	r.UpdatedUTC = chrono.TimeNow()
+
+	return r
+}
+
+func (r RedeemCode) Expired() RedeemCode {
+	r.Status = RedeemStatusExpired
+	r.UpdatedUTC = chrono.TimeNow()
+
+	return r
+}
+
+// CSVRow is used when admin downloads codes as a csv file.
+func (r RedeemCode) CSVRow() []string {
+	return []string{
+		r.Formatted(),
+		r.BuildURL(),
+		r.ExpiresUTC.Format(time.RFC3339),
+		r.Description.String,
+	}
+}
+
+// RedeemCodeCSVHeader is the header row of CSVRow.
+var RedeemCodeCSVHeader = []string{
+	"code",
+	"url",
+	"expires_utc",
+	"description",
+}
+
+// FileName of the QR image or csv file.
+func (r RedeemCode) FileName(ext string) string {
+	return fmt.Sprintf("%s.%s", r.Formatted(), ext)
+}
+
+type RedeemCodeList struct {
+	pkg.PagedList
+	Data []RedeemCode `json:"data"`
+}
+
+// WithCoded puts an available licence on hold for
+// a redemption code.
+func (l Licence) WithCoded() Licence {
+	l.HintGrantMismatch = false
+	l.Status = LicStatusCoded
+	l.LatestInvitation = InvitationJSON{}
+	l.AssigneeID = null.String{}
+	l.UpdatedUTC = chrono.TimeUTCNow()
+
+	return l
+}
+
+// IsCoded checks whether the licence is held by a code.
+func (l Licence) IsCoded() bool {
+	return l.Status == LicStatusCoded
+}
+
+// WithCodeReleased makes the licence available again
+// after the code is revoked, expired, or about to be
+// redeemed through invitation.
+func (l Licence) WithCodeReleased() Licence {
+	l.Status = LicStatusAvailable
+	l.UpdatedUTC = chrono.TimeUTCNow()
+
+	return l
+}
+
+// RedeemCodeRevoked is the result of revoking or expiring a code.
+type RedeemCodeRevoked struct {
+	Code    RedeemCode `json:"code"`
+	Licence Licence    `json:"licence"`
+}
+
+// CodeRedeemed is the result of a reader redeeming a code.
+type CodeRedeemed struct {
+	Code RedeemCode `json:"code"`
+	GrantResult
+}
+
+// ParseRedeemStatus converts a string to RedeemStatus.
+// Empty string defaults to active.
+func ParseRedeemStatus(s string) (RedeemStatus, error) {
+	switch RedeemStatus(s) {
+	case "":
+		return RedeemStatusActive, nil
+	case RedeemStatusActive, RedeemStatusRedeemed, RedeemStatusRevoked, RedeemStatusExpired:
+		return RedeemStatus(s), nil
+	default:
+		return "", fmt.Errorf("%s is not valid RedeemStatus", s)
+	}
+}
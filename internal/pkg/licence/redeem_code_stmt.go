@@ -0,0 +1,93 @@
+package licence
+
+const StmtCreateRedeemCode = `
+INSERT INTO b2b.redeem_code
+SET id = :code_id,
+	admin_id = :admin_id,
+	team_id = :team_id,
+	redeem_code = :redeem_code,
+	licence_id = :licence_id,
+	code_desc = :code_desc,
+	code_status = :code_status,
+	expires_utc = :expires_utc,
+	redeemed_by = :redeemed_by,
+	created_utc = :created_utc,
+	updated_utc = :updated_utc`
+
+const colRedeemCode = `
+SELECT id AS code_id,
+	admin_id,
+	team_id,
+	redeem_code,
+	licence_id,
+	code_desc,
+	code_status,
+	expires_utc,
+	redeemed_by,
+	created_utc,
+	updated_utc
+FROM b2b.redeem_code
+`
+
+const StmtRedeemCode = colRedeemCode + `
+WHERE id = ?
+	AND team_id = ?
+LIMIT 1`
+
+// StmtLockRedeemCode locks a code when admin is revoking it.
+const StmtLockRedeemCode = StmtRedeemCode + `
+FOR UPDATE`
+
+// StmtLockRedeemCodeByCode locks a code when reader
+// is redeeming it.
+const StmtLockRedeemCodeByCode = colRedeemCode + `
+WHERE redeem_code = ?
+LIMIT 1
+FOR UPDATE`
+
+const StmtUpdateRedeemCode = `
+UPDATE b2b.redeem_code
+SET code_status = :code_status,
+	redeemed_by = :redeemed_by,
+	updated_utc = :updated_utc
+WHERE id = :code_id
+LIMIT 1`
+
+const StmtListRedeemCodes = colRedeemCode + `
+WHERE team_id = ?
+	AND code_status = ?
+ORDER BY created_utc DESC
+LIMIT ? OFFSET ?`
+
+const StmtCountRedeemCodes = `
+SELECT COUNT(*) AS row_count
+FROM b2b.redeem_code
+WHERE team_id = ?
+	AND code_status = ?`
+
+// StmtListActiveRedeemCodes retrieves all codes that could
+// be downloaded for distribution.
+const StmtListActiveRedeemCodes = colRedeemCode + `
+WHERE team_id = ?
+	AND code_status = 'active'
+	AND expires_utc > UTC_TIMESTAMP()
+ORDER BY created_utc ASC`
+
+// StmtListExpiredRedeemCodes finds codes whose licence
+// should be released.
+const StmtListExpiredRedeemCodes = colRedeemCode + `
+WHERE code_status = 'active'
+	AND expires_utc <= UTC_TIMESTAMP()
+LIMIT 500`
+
+// StmtLockAvailableLicenceIDs picks licences to be
+// converted into codes.
+const StmtLockAvailableLicenceIDs = `
+SELECT id
+FROM b2b.licence
+WHERE team_id = ?
+	AND current_status = 'available'
+	AND assignee_id IS NULL
+ORDER BY current_period_end_utc DESC
+LIMIT ?
+FOR UPDATE`
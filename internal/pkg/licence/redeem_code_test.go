@@ -0,0 +1,144 @@
+package licence
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/go-rest/chrono"
+	"testing"
+	"time"
+)
+
+func TestRedeemCode_Status(t *testing.T) {
+	rc, err := NewRedeemCode("lic-1", input.RedeemCodeParams{
+		Count:          1,
+		ExpirationDays: 30,
+	}, admin.Creator{
+		AdminID: "admin-1",
+		TeamID:  "team-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expired := rc
+	expired.ExpiresUTC = chrono.TimeFrom(time.Now().Add(-time.Minute))
+
+	tests := []struct {
+		name           string
+		code           RedeemCode
+		wantStatus     RedeemStatus
+		wantExpired    bool
+		wantRedeemable bool
+		wantRevocable  bool
+	}{
+		{
+			name:           "New code",
+			code:           rc,
+			wantStatus:     RedeemStatusActive,
+			wantRedeemable: true,
+			wantRevocable:  true,
+		},
+		{
+			name:           "Active but passed expiry",
+			code:           expired,
+			wantStatus:     RedeemStatusActive,
+			wantExpired:    true,
+			wantRedeemable: false,
+			wantRevocable:  true,
+		},
+		{
+			name:       "Redeemed",
+			code:       rc.Redeemed("reader-1"),
+			wantStatus: RedeemStatusRedeemed,
+		},
+		{
+			name:       "Revoked",
+			code:       rc.Revoked(),
+			wantStatus: RedeemStatusRevoked,
+		},
+		{
+			name:        "Expired",
+			code:        expired.Expired(),
+			wantStatus:  RedeemStatusExpired,
+			wantExpired: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", tt.code.Status, tt.wantStatus)
+			}
+			if got := tt.code.IsExpired(); got != tt.wantExpired {
+				t.Errorf("IsExpired() = %v, want %v", got, tt.wantExpired)
+			}
+			if got := tt.code.IsRedeemable(); got != tt.wantRedeemable {
+				t.Errorf("IsRedeemable() = %v, want %v", got, tt.wantRedeemable)
+			}
+			if got := tt.code.IsRevocable(); got != tt.wantRevocable {
+				t.Errorf("IsRevocable() = %v, want %v", got, tt.wantRevocable)
+			}
+		})
+	}
+}
+
+func TestRedeemCode_Redeemed(t *testing.T) {
+	rc := RedeemCode{Status: RedeemStatusActive}.Redeemed("reader-1")
+
+	if rc.RedeemedBy.String != "reader-1" {
+		t.Errorf("RedeemedBy = %v, want reader-1", rc.RedeemedBy)
+	}
+}
+
+func TestRedeemCode_Formatted(t *testing.T) {
+	rc := RedeemCode{Code: "ABCDEFGHJKLMNPQR"}
+
+	if got := rc.Formatted(); got != "ABCD-EFGH-JKLM-NPQR" {
+		t.Errorf("Formatted() = %s", got)
+	}
+}
+
+func TestLicence_CodeHold(t *testing.T) {
+	lic := Licence{}
+	lic.Status = LicStatusAvailable
+
+	coded := lic.WithCoded()
+	if !coded.IsCoded() {
+		t.Errorf("licence should be held by code")
+	}
+	if coded.IsAvailable() {
+		t.Errorf("coded licence should not be available")
+	}
+
+	released := coded.WithCodeReleased()
+	if released.IsCoded() {
+		t.Errorf("licence should be released from code")
+	}
+	if !released.IsAvailable() {
+		t.Errorf("released licence should be available")
+	}
+}
+
+func TestParseRedeemStatus(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    RedeemStatus
+		wantErr bool
+	}{
+		{in: "", want: RedeemStatusActive},
+		{in: "redeemed", want: RedeemStatusRedeemed},
+		{in: "expired", want: RedeemStatusExpired},
+		{in: "unknown", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseRedeemStatus(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseRedeemStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseRedeemStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package licence
+
+import (
+	"fmt"
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/ids"
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/FTChinese/go-rest/rand"
+	"github.com/guregu/null"
+	"time"
+)
+
+type RequestStatus string
+
+const (
+	RequestStatusUnverified RequestStatus = "unverified" // Company email not verified yet.
+	RequestStatusPending    RequestStatus = "pending"    // Waiting for admin's approval.
+	RequestStatusApproved   RequestStatus = "approved"
+	RequestStatusRejected   RequestStatus = "rejected"
+)
+
+// ParseRequestStatus converts a string to RequestStatus.
+// Empty string defaults to pending so that admin sees the
+// approval queue.
+func ParseRequestStatus(s string) (RequestStatus, error) {
+	switch RequestStatus(s) {
+	case "":
+		return RequestStatusPending, nil
+	case RequestStatusUnverified, RequestStatusPending, RequestStatusApproved, RequestStatusRejected:
+		return RequestStatus(s), nil
+	default:
+		return "", fmt.Errorf("%s is not valid RequestStatus", s)
+	}
+}
+
+// requestVrfDays is the valid period of the verification link.
+const requestVrfDays = 3
+
+// LicenceRequest is created when a reader asks for a licence
+// using company email. The team is found by the email's domain
+// registered in CMS.
+// The request goes through these phases:
+// unverified -> pending -> approved | rejected.
+type LicenceRequest struct {
+	ID        string        `json:"id" db:"request_id"`
+	TeamID    string        `json:"teamId" db:"team_id"`
+	FtcID     string        `json:"ftcId" db:"ftc_id"`
+	Email     string        `json:"email" db:"email"`
+	Token     string        `json:"-" db:"token"`
+	Status    RequestStatus `json:"status" db:"request_status"`
+	LicenceID null.String   `json:"licenceId" db:"licence_id"` // The licence granted after approved.
+	admin.RowTime
+}
+
+func NewLicenceRequest(ftcID, email, teamID string) (LicenceRequest, error) {
+	token, err := rand.Hex(32)
+	if err != nil {
+		return LicenceRequest{}, err
+	}
+
+	return LicenceRequest{
+		ID:        ids.LicenceRequestID(),
+		TeamID:    teamID,
+		FtcID:     ftcID,
+		Email:     email,
+		Token:     token,
+		Status:    RequestStatusUnverified,
+		LicenceID: null.String{},
+		RowTime:   admin.NewRowTime(),
+	}, nil
+}
+
+// IsVerifiable checks whether the verification link is
+// still usable.
+func (r LicenceRequest) IsVerifiable() bool {
+	return r.Status == RequestStatusUnverified &&
+		r.CreatedUTC.AddDate(0, 0, requestVrfDays).After(time.Now())
+}
+
+// Verified puts the request into admin's approval queue.
+func (r LicenceRequest) Verified() LicenceRequest {
+	r.Status = RequestStatusPending
+	r.UpdatedUTC = chrono.TimeNow()
+
+	return r
+}
+
+func (r LicenceRequest) IsPending() bool {
+	return r.Status == RequestStatusPending
+}
+
+func (r LicenceRequest) Approved(licenceID string) LicenceRequest {
+	r.Status = RequestStatusApproved
+	r.LicenceID = null.StringFrom(licenceID)
+	r.UpdatedUTC = chrono.TimeNow()
+
+	return r
+}
+
+func (r LicenceRequest) Rejected() LicenceRequest {
+	r.Status = RequestStatusRejected
+	r.UpdatedUTC = chrono.TimeNow()
+
+	return r
+}
+
+// BuildURL creates the link to verify company email.
+func (r LicenceRequest) BuildURL() string {
+	return pkg.ReaderLicenceRequestURL(r.Token)
+}
+
+func (r LicenceRequest) FormatDuration() string {
+	return fmt.Sprintf("%d天", requestVrfDays)
+}
+
+type LicenceRequestList struct {
+	pkg.PagedList
+	Data []LicenceRequest `json:"data"`
+}
+
+// RequestApproved is the result of approving a licence request.
+type RequestApproved struct {
+	Request LicenceRequest `json:"request"`
+	GrantResult
+}
@@ -0,0 +1,85 @@
+package licence
+
+const StmtCreateLicenceRequest = `
+INSERT INTO b2b.licence_request
+SET id = :request_id,
+	team_id = :team_id,
+	ftc_id = :ftc_id,
+	email = :email,
+	token = UNHEX(:token),
+	request_status = :request_status,
+	licence_id = :licence_id,
+	created_utc = :created_utc,
+	updated_utc = :updated_utc`
+
+const colLicenceRequest = `
+SELECT id AS request_id,
+	team_id,
+	ftc_id,
+	email,
+	LOWER(HEX(token)) AS token,
+	request_status,
+	licence_id,
+	created_utc,
+	updated_utc
+FROM b2b.licence_request
+`
+
+const StmtLicenceRequestByToken = colLicenceRequest + `
+WHERE token = UNHEX(?)
+LIMIT 1`
+
+// StmtLockLicenceRequest locks a request when admin
+// is approving or rejecting it.
+const StmtLockLicenceRequest = colLicenceRequest + `
+WHERE id = ? AND team_id = ?
+LIMIT 1
+FOR UPDATE`
+
+const StmtUpdateLicenceRequest = `
+UPDATE b2b.licence_request
+SET request_status = :request_status,
+	licence_id = :licence_id,
+	updated_utc = :updated_utc
+WHERE id = :request_id
+LIMIT 1`
+
+const StmtListTeamLicenceRequests = colLicenceRequest + `
+WHERE team_id = ?
+	AND request_status = ?
+ORDER BY created_utc ASC
+LIMIT ? OFFSET ?`
+
+const StmtCountTeamLicenceRequests = `
+SELECT COUNT(*) AS row_count
+FROM b2b.licence_request
+WHERE team_id = ?
+	AND request_status = ?`
+
+const StmtListReaderLicenceRequests = colLicenceRequest + `
+WHERE ftc_id = ?
+ORDER BY created_utc DESC
+LIMIT 20`
+
+// StmtOpenRequestExists checks whether a reader already has
+// a request in a team waiting for verification or approval.
+const StmtOpenRequestExists = `
+SELECT EXISTS (
+	SELECT *
+	FROM b2b.licence_request
+	WHERE ftc_id = ?
+		AND team_id = ?
+		AND request_status IN ('unverified', 'pending')
+) AS already_exists`
+
+// StmtLockAvailableLicence locks a licence that could be
+// invited, preferring the one lasting longest. Licences
+// locked by concurrent requests are skipped.
+const StmtLockAvailableLicence = colLicence + `
+FROM b2b.licence AS l
+WHERE l.team_id = ?` + andLicenceInUnit + `
+	AND l.current_status = 'available'
+	AND l.assignee_id IS NULL
+ORDER BY l.current_period_end_utc DESC
+LIMIT 1
+FOR UPDATE SKIP LOCKED`
@@ -5,6 +5,7 @@ import (
 	"github.com/FTChinese/ftacademy/internal/pkg/reader"
 	"github.com/FTChinese/go-rest/chrono"
 	"github.com/guregu/null"
+	"time"
 )
 
 func (l Licence) IsRevocable() bool {
@@ -25,15 +26,46 @@ func (l Licence) Revoked() Licence {
 type RevokeResult struct {
 	LicenceVersion      Versioned                  `json:"licenceVersion"`
 	MembershipVersioned reader.MembershipVersioned `json:"membershipVersioned"`
+	ConsumedInvoice     reader.Invoice             `json:"consumedInvoice"` // Optional carry-over invoice used to restore prior membership.
 }
 
-func RevokeLicence(lic Licence, mmb reader.Membership) (RevokeResult, error) {
+// restorableDays calculates how many days of the carry-over
+// invoice could be used to restore prior membership.
+// It cannot exceed the addon balance of the same tier since
+// the addon might be partially used elsewhere.
+func restorableDays(mmb reader.Membership, carryOver reader.Invoice) int64 {
+	if carryOver.IsZero() {
+		return 0
+	}
+
+	days := carryOver.TotalDays()
+	if balance := mmb.AddOn.Days(carryOver.Tier); balance < days {
+		return balance
+	}
+
+	return days
+}
+
+// RevokeLicence unlinks a licence from its assignee.
+// If the assignee had a one-time purchase membership frozen
+// into a carry-over invoice upon granting, the prior edition
+// is re-activated immediately for the banked days and the
+// invoice is marked as consumed.
+func RevokeLicence(lic Licence, mmb reader.Membership, carryOver reader.Invoice) (RevokeResult, error) {
 	if !lic.IsGrantedTo(mmb) {
 		return RevokeResult{}, errors.New("error revoking licence: membership not generated from this licence")
 	}
 
-	mv := mmb.LicenceRevoked().
-		LicenceRevoked().
+	var inv reader.Invoice
+	var revoked reader.Membership
+	if days := restorableDays(mmb, carryOver); days > 0 {
+		inv = carryOver.Consumed(time.Now(), days)
+		revoked = mmb.CarryOverRestored(inv, days)
+	} else {
+		revoked = mmb.LicenceRevoked()
+	}
+
+	mv := revoked.
 		Version(reader.B2BArchiver(reader.ArchiveActionRevoke)).
 		WithPriorVersion(mmb).
 		WithB2BTxnID(lic.LatestTransactionID.String)
@@ -44,5 +76,42 @@ func RevokeLicence(lic Licence, mmb reader.Membership) (RevokeResult, error) {
 			WithPriorVersion(lic).
 			WithMembershipVersioned(mv.ID),
 		MembershipVersioned: mv,
+		ConsumedInvoice:     inv,
 	}, nil
 }
+
+// RevokeOutcome is the result of revoking a single licence
+// in a bulk operation.
+type RevokeOutcome struct {
+	LicenceID string      `json:"licenceId"`
+	Ok        bool        `json:"ok"`
+	Error     null.String `json:"error"`
+	RevokeResult
+}
+
+// BulkRevokeResult collects the outcome of every licence
+// revoked in one request.
+type BulkRevokeResult struct {
+	Total     int             `json:"total"`
+	Succeeded int             `json:"succeeded"`
+	Failed    int             `json:"failed"`
+	Items     []RevokeOutcome `json:"items"`
+}
+
+// NewBulkRevokeResult collects items into a summary.
+func NewBulkRevokeResult(items []RevokeOutcome) BulkRevokeResult {
+	r := BulkRevokeResult{
+		Total: len(items),
+		Items: items,
+	}
+
+	for _, v := range items {
+		if v.Ok {
+			r.Succeeded++
+		} else {
+			r.Failed++
+		}
+	}
+
+	return r
+}
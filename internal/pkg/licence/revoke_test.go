@@ -0,0 +1,147 @@
+package licence
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/reader"
+	"github.com/FTChinese/ftacademy/pkg/addon"
+	"github.com/FTChinese/ftacademy/pkg/dt"
+	"github.com/FTChinese/ftacademy/pkg/price"
+	"github.com/FTChinese/go-rest/enum"
+	"github.com/guregu/null"
+	"testing"
+	"time"
+)
+
+func grantedMember(licID string, a addon.AddOn) reader.Membership {
+	return reader.Membership{
+		UserIDs: reader.UserIDs{
+			CompoundID: "reader-1",
+			FtcID:      null.StringFrom("reader-1"),
+		},
+		Edition: price.Edition{
+			Tier:  enum.TierPremium,
+			Cycle: enum.CycleYear,
+		},
+		PaymentMethod: enum.PayMethodB2B,
+		B2BLicenceID:  null.StringFrom(licID),
+		AddOn:         a,
+	}
+}
+
+func carryOverOf(tier enum.Tier, days int64) reader.Invoice {
+	return reader.Invoice{
+		ID:         "inv-1",
+		CompoundID: "reader-1",
+		Edition: price.Edition{
+			Tier:  tier,
+			Cycle: enum.CycleYear,
+		},
+		YearMonthDay:  dt.YearMonthDay{Days: days},
+		AddOnSource:   addon.SourceCarryOver,
+		LicenceTxID:   null.StringFrom("txn-1"),
+		PaymentMethod: enum.PayMethodWx,
+	}
+}
+
+func TestRestorableDays(t *testing.T) {
+	tests := []struct {
+		name      string
+		addOn     addon.AddOn
+		carryOver reader.Invoice
+		want      int64
+	}{
+		{
+			name:      "No carry-over",
+			addOn:     addon.AddOn{Standard: 100},
+			carryOver: reader.Invoice{},
+			want:      0,
+		},
+		{
+			name:      "Full balance",
+			addOn:     addon.AddOn{Standard: 100},
+			carryOver: carryOverOf(enum.TierStandard, 60),
+			want:      60,
+		},
+		{
+			name:      "Partially used elsewhere",
+			addOn:     addon.AddOn{Standard: 20},
+			carryOver: carryOverOf(enum.TierStandard, 60),
+			want:      20,
+		},
+		{
+			name:      "Balance of another tier",
+			addOn:     addon.AddOn{Premium: 100},
+			carryOver: carryOverOf(enum.TierStandard, 60),
+			want:      0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := restorableDays(grantedMember("lic-1", tt.addOn), tt.carryOver)
+			if got != tt.want {
+				t.Errorf("restorableDays() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRevokeLicence(t *testing.T) {
+	lic := Licence{
+		ID:                  "lic-1",
+		LatestTransactionID: null.StringFrom("txn-1"),
+		Status:              LicStatusGranted,
+		AssigneeID:          null.StringFrom("reader-1"),
+	}
+
+	t.Run("Restore carry-over", func(t *testing.T) {
+		mmb := grantedMember(lic.ID, addon.AddOn{Standard: 60})
+
+		got, err := RevokeLicence(lic, mmb, carryOverOf(enum.TierStandard, 60))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m := got.MembershipVersioned.PostChange.Membership
+		if m.Tier != enum.TierStandard || m.PaymentMethod != enum.PayMethodWx {
+			t.Errorf("prior edition not restored: %s via %s", m.Tier, m.PaymentMethod)
+		}
+		if m.B2BLicenceID.Valid {
+			t.Error("membership should be unlinked from licence")
+		}
+		if m.AddOn.Standard != 0 {
+			t.Errorf("restored days not deducted from addon: %d", m.AddOn.Standard)
+		}
+		if got.ConsumedInvoice.IsZero() || got.ConsumedInvoice.ConsumedUTC.IsZero() {
+			t.Error("carry-over should be consumed")
+		}
+		if !m.ExpireDate.After(time.Now().AddDate(0, 0, 58)) {
+			t.Errorf("expire date %s should be about 60 days later", m.ExpireDate)
+		}
+	})
+
+	t.Run("Without carry-over", func(t *testing.T) {
+		mmb := grantedMember(lic.ID, addon.AddOn{})
+
+		got, err := RevokeLicence(lic, mmb, reader.Invoice{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m := got.MembershipVersioned.PostChange.Membership
+		if !m.IsExpired() {
+			t.Error("membership should expire after revoked")
+		}
+		if !got.ConsumedInvoice.IsZero() {
+			t.Error("no invoice should be consumed")
+		}
+		if got.LicenceVersion.PostChange.Status != LicStatusAvailable {
+			t.Errorf("licence status %s, want available", got.LicenceVersion.PostChange.Status)
+		}
+	})
+
+	t.Run("Not granted to member", func(t *testing.T) {
+		_, err := RevokeLicence(lic, grantedMember("lic-2", addon.AddOn{}), reader.Invoice{})
+		if err == nil {
+			t.Error("expected error revoking a licence not granted to the member")
+		}
+	})
+}
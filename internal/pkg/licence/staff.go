@@ -1,8 +1,16 @@
 package licence
 
 import (
+	"encoding/csv"
+	"fmt"
 	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/reader"
+	"github.com/FTChinese/go-rest/chrono"
 	"github.com/guregu/null"
+	"io"
+	"strings"
 )
 
 // Staffer is a member belong to a team under admin's
@@ -10,12 +18,184 @@ import (
 type Staffer struct {
 	ID     int64       `json:"id" db:"id"`
 	Email  string      `json:"email" db:"email"`
+	Name   null.String `json:"name" db:"staff_name"`
 	TeamID string      `json:"teamId" db:"team_id"`
 	FtcID  null.String `json:"ftcId" db:"ftc_id"`
+	UnitID null.String `json:"unitId" db:"unit_id"`
+	Active bool        `json:"active" db:"is_active"` // False if deactivated by identity provider.
+	admin.RowTime
+}
+
+// NewStaffer creates a staffer. The assignee is found by email
+// and might be empty if the email is not registered yet.
+func NewStaffer(params input.StafferParams, teamID string, a Assignee) Staffer {
+	return Staffer{
+		Email:   params.Email,
+		Name:    params.Name,
+		TeamID:  teamID,
+		FtcID:   a.FtcID,
+		UnitID:  params.UnitID,
+		Active:  true,
+		RowTime: admin.NewRowTime(),
+	}
+}
+
+// Update changes email, name and unit. FtcID should be
+// found again by the new email.
+func (s Staffer) Update(params input.StafferParams, a Assignee) Staffer {
+	s.Email = params.Email
+	s.Name = params.Name
+	s.FtcID = a.FtcID
+	s.UnitID = params.UnitID
+	s.UpdatedUTC = chrono.TimeNow()
+
+	return s
+}
+
+// WithActive marks a staffer as active or deactivated.
+func (s Staffer) WithActive(on bool) Staffer {
+	s.Active = on
+	s.UpdatedUTC = chrono.TimeNow()
+
+	return s
+}
+
+// ExpandedStaffer shows a staffer together with the
+// licence held or invited and membership.
+type ExpandedStaffer struct {
+	Staffer
+	Licence    *Licence          `json:"licence"`
+	Membership reader.Membership `json:"membership"`
+}
+
+// StafferLicence is a licence found for a staffer in a page
+// of staff.
+type StafferLicence struct {
+	StafferID int64 `db:"staffer_id"`
+	Licence
+}
+
+// ExpandStaff attaches licences and memberships loaded for a
+// page of staff to each staffer.
+// Licences should be ordered by the latest first so that a
+// staffer gets the latest one when several are matched.
+func ExpandStaff(list []Staffer, lics []StafferLicence, members []reader.Membership) []ExpandedStaffer {
+	licOf := make(map[int64]*Licence, len(lics))
+	for i := range lics {
+		if _, ok := licOf[lics[i].StafferID]; ok {
+			continue
+		}
+		licOf[lics[i].StafferID] = &lics[i].Licence
+	}
+
+	memberOf := make(map[string]reader.Membership, len(members))
+	for _, m := range members {
+		m = m.Sync()
+		memberOf[m.CompoundID] = m
+		if m.UnionID.Valid {
+			memberOf[m.UnionID.String] = m
+		}
+	}
+
+	expanded := make([]ExpandedStaffer, 0, len(list))
+	for _, s := range list {
+		es := ExpandedStaffer{
+			Staffer: s,
+			Licence: licOf[s.ID],
+		}
+		if s.FtcID.Valid {
+			es.Membership = memberOf[s.FtcID.String]
+		}
+		expanded = append(expanded, es)
+	}
+
+	return expanded
 }
 
 // StaffList contains a list of assignee rows and the total number of rows for current team.
 type StaffList struct {
 	pkg.PagedList
-	Data []Staffer `json:"data"`
+	Data []ExpandedStaffer `json:"data"`
+}
+
+// StafferRemoved is the result of removing a staffer.
+// If the staffer holds a licence and admin did not ask
+// to revoke it, Licence is populated with Revoked false so
+// that client could offer to revoke it.
+type StafferRemoved struct {
+	Staffer      Staffer  `json:"staffer"`
+	Licence      *Licence `json:"licence"`
+	Revoked      bool     `json:"revoked"`
+	GrantRevoked bool     `json:"grantRevoked"` // The licence was granted rather than only invited.
+	PriorLicence *Licence `json:"-"`            // The licence before revoked. Nil if not revoked.
+}
+
+// StaffImportError describes a row skipped when importing csv.
+type StaffImportError struct {
+	Row    int    `json:"row"`
+	Email  string `json:"email"`
+	Reason string `json:"reason"`
+}
+
+// StaffImported is the result of importing a csv file.
+type StaffImported struct {
+	Imported int                `json:"imported"`
+	Skipped  int                `json:"skipped"` // Already exists.
+	Invalid  []StaffImportError `json:"invalid"`
+}
+
+// ParseStaffCSV reads rows of email and optional name.
+// A header row starting with `email` is skipped.
+// Invalid rows are returned separately with row number
+// counted from 1.
+func ParseStaffCSV(r io.Reader) ([]input.StafferParams, []StaffImportError, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var params = make([]input.StafferParams, 0)
+	var invalid = make([]StaffImportError, 0)
+
+	row := 0
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		row++
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(rec) == 0 {
+			continue
+		}
+		if row == 1 && strings.EqualFold(strings.TrimSpace(rec[0]), "email") {
+			continue
+		}
+
+		if len(params)+len(invalid) >= input.MaxStaffImport {
+			return nil, nil, fmt.Errorf("at most %d rows could be imported", input.MaxStaffImport)
+		}
+
+		p := input.StafferParams{
+			Email: rec[0],
+		}
+		if len(rec) > 1 {
+			p.Name = null.StringFrom(rec[1])
+		}
+
+		if ve := p.Validate(); ve != nil {
+			invalid = append(invalid, StaffImportError{
+				Row:    row,
+				Email:  p.Email,
+				Reason: ve.Message,
+			})
+			continue
+		}
+
+		params = append(params, p)
+	}
+
+	return params, invalid, nil
 }
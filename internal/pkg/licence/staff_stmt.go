@@ -1,5 +1,7 @@
 package licence
 
+import "github.com/FTChinese/ftacademy/internal/pkg/reader"
+
 // DO not change the order the team_id in the WHERE clause
 // because the defined the PRIMARY KEY as (team_id, email)
 // for this table.
@@ -8,8 +10,11 @@ package licence
 const InsertStaffer = `
 INSERT IGNORE INTO b2b.staff
 SET email = :email,
+	staff_name = :staff_name,
 	ftc_id = :ftc_id,
 	team_id = :team_id,
+	unit_id = :unit_id,
+	is_active = :is_active,
 	created_utc = UTC_TIMESTAMP(),
 	updated_utc = UTC_TIMESTAMP()`
 
@@ -24,22 +29,110 @@ UPDATE b2b.staff
 SET ftc_id = :ftc_id
 WHERE team_id = :team_id
 	AND email = :email
-	AND ftc_id = NULL
+	AND ftc_id IS NULL
+LIMIT 1`
+
+const UpdateStaffer = `
+UPDATE b2b.staff
+SET email = :email,
+	staff_name = :staff_name,
+	ftc_id = :ftc_id,
+	unit_id = :unit_id,
+	is_active = :is_active,
+	updated_utc = UTC_TIMESTAMP()
+WHERE team_id = :team_id
+	AND id = :id
 LIMIT 1`
 
-const ListStaff = `
+const colStaffer = `
 SELECT id,
 	email,
+	staff_name,
 	ftc_id,
 	team_id,
+	unit_id,
+	is_active,
 	created_utc,
 	updated_utc
 FROM b2b.staff
+`
+
+const staffPageFilter = `
 WHERE team_id = ?
+	AND (? IS NULL OR unit_id = ?)
 ORDER BY email ASC
 LIMIT ? OFFSET ?`
 
+// ListStaff shows a team's roster, or only staff of a unit
+// if unit id is not NULL. Unit id is passed twice.
+const ListStaff = colStaffer + staffPageFilter
+
+// StmtStaffPageLicences finds licences granted to, or invited
+// for, the same page of staff as ListStaff, so that a page
+// is expanded in one query.
+// It takes the same arguments as ListStaff, followed by unit
+// id twice to limit licences to a unit.
+const StmtStaffPageLicences = colLicence + `,
+	s.id AS staffer_id
+FROM (
+	SELECT id, team_id, ftc_id, email
+	FROM b2b.staff` + staffPageFilter + `
+) AS s
+	JOIN b2b.licence AS l
+	ON l.team_id = s.team_id
+	AND (l.assignee_id = s.ftc_id
+		OR (l.current_status = 'invited'
+			AND JSON_UNQUOTE(JSON_EXTRACT(l.latest_invitation, '$.email')) = s.email))` + andLicenceInUnit + `
+ORDER BY l.updated_utc DESC`
+
+// StmtStaffPageMembers finds membership of the same page of
+// staff as ListStaff.
+// It takes the same arguments as ListStaff.
+const StmtStaffPageMembers = reader.StmtSelectMembers + `
+	JOIN (
+		SELECT ftc_id
+		FROM b2b.staff` + staffPageFilter + `
+	) AS s
+	ON s.ftc_id IN (vip_id, vip_id_alias)`
+
 const CountStaff = `
 SELECT COUNT(*)
 FROM b2b.staff
-WHERE team_id = ?`
+WHERE team_id = ?
+	AND (? IS NULL OR unit_id = ?)`
+
+const StafferByID = colStaffer + `
+WHERE team_id = ?
+	AND id = ?
+LIMIT 1`
+
+const StafferByEmail = colStaffer + `
+WHERE team_id = ?
+	AND email = ?
+LIMIT 1`
+
+// ListStaffFrom lists staff by offset, used by SCIM.
+const ListStaffFrom = colStaffer + `
+WHERE team_id = ?
+ORDER BY id ASC
+LIMIT ? OFFSET ?`
+
+const StafferExists = `
+SELECT EXISTS (
+	SELECT *
+	FROM b2b.staff
+	WHERE team_id = ?
+		AND email = ?
+) AS already_exists`
+
+// StmtStafferLicence finds the licence granted to a staffer,
+// or the one invited with the staffer's email. If unit id
+// is not null, only licences of the unit are searched.
+const StmtStafferLicence = colLicence + `
+FROM b2b.licence AS l
+WHERE l.team_id = ?` + andLicenceInUnit + `
+	AND (l.assignee_id = ?
+		OR (l.current_status = 'invited'
+			AND JSON_UNQUOTE(JSON_EXTRACT(l.latest_invitation, '$.email')) = ?))
+ORDER BY l.updated_utc DESC
+LIMIT 1`
@@ -0,0 +1,71 @@
+package licence
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/reader"
+	"github.com/guregu/null"
+	"strings"
+	"testing"
+)
+
+func TestParseStaffCSV(t *testing.T) {
+	data := `email,name
+Alice@Example.com, Alice
+bob@example.com
+not-an-email,Nobody
+`
+
+	params, invalid, err := ParseStaffCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(params) != 2 {
+		t.Errorf("got %d valid rows, want 2", len(params))
+	}
+
+	if params[0].Email != "alice@example.com" || params[0].Name.String != "Alice" {
+		t.Errorf("first row not normalized: %+v", params[0])
+	}
+
+	if params[1].Name.Valid {
+		t.Errorf("name should be null if missing")
+	}
+
+	if len(invalid) != 1 || invalid[0].Row != 4 {
+		t.Errorf("got invalid rows %+v, want row 4", invalid)
+	}
+}
+
+func TestExpandStaff(t *testing.T) {
+	list := []Staffer{
+		{ID: 1, Email: "a@example.com", FtcID: null.StringFrom("ftc-a")},
+		{ID: 2, Email: "b@example.com"},
+		{ID: 3, Email: "c@example.com", FtcID: null.StringFrom("ftc-c")},
+	}
+	lics := []StafferLicence{
+		{StafferID: 1, Licence: Licence{ID: "lic-new"}},
+		{StafferID: 1, Licence: Licence{ID: "lic-old"}},
+		{StafferID: 2, Licence: Licence{ID: "lic-invited"}},
+	}
+	members := []reader.Membership{
+		{UserIDs: reader.UserIDs{CompoundID: "ftc-a", FtcID: null.StringFrom("ftc-a")}},
+	}
+
+	got := ExpandStaff(list, lics, members)
+
+	if len(got) != 3 {
+		t.Fatalf("got %d staff, want 3", len(got))
+	}
+	if got[0].Licence == nil || got[0].Licence.ID != "lic-new" {
+		t.Errorf("staffer 1 should have the latest licence, got %+v", got[0].Licence)
+	}
+	if got[0].Membership.CompoundID != "ftc-a" {
+		t.Errorf("staffer 1 membership not attached")
+	}
+	if got[1].Licence == nil || got[1].Licence.ID != "lic-invited" {
+		t.Errorf("staffer 2 should have the invited licence, got %+v", got[1].Licence)
+	}
+	if got[2].Licence != nil || got[2].Membership.CompoundID != "" {
+		t.Errorf("staffer 3 should have nothing attached, got %+v", got[2])
+	}
+}
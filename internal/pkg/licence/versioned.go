@@ -10,11 +10,12 @@ import (
 type VersionAction string
 
 const (
-	VersionActionNull   VersionAction = ""
-	VersionActionCreate VersionAction = "create"
-	VersionActionRenew  VersionAction = "renew"
-	VersionActionGrant  VersionAction = "grant"
-	VersionActionRevoke VersionAction = "revoke"
+	VersionActionNull    VersionAction = ""
+	VersionActionCreate  VersionAction = "create"
+	VersionActionRenew   VersionAction = "renew"
+	VersionActionGrant   VersionAction = "grant"
+	VersionActionRevoke  VersionAction = "revoke"
+	VersionActionReserve VersionAction = "reserve"
 )
 
 func VersionActionFromOrderKind(k enum.OrderKind) VersionAction {
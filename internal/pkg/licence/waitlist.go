@@ -0,0 +1,116 @@
+package licence
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/ids"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/guregu/null"
+)
+
+type WaitlistStatus string
+
+const (
+	WaitlistStatusWaiting WaitlistStatus = "waiting"
+	WaitlistStatusInvited WaitlistStatus = "invited" // An invitation is sent to the email.
+	WaitlistStatusRemoved WaitlistStatus = "removed"
+	WaitlistStatusFailed  WaitlistStatus = "failed" // Invitation could not be created for the email.
+)
+
+// WaitlistEntry is an email waiting for a seat when all
+// licences of a team are taken.
+// When a licence becomes available, entries with higher
+// priority, then the earliest, are invited first.
+type WaitlistEntry struct {
+	ID string `json:"id" db:"entry_id"`
+	admin.Creator
+	Email        string         `json:"email" db:"email"`
+	Priority     int64          `json:"priority" db:"priority"`
+	Note         null.String    `json:"note" db:"note"`
+	Status       WaitlistStatus `json:"status" db:"entry_status"`
+	InvitationID null.String    `json:"invitationId" db:"invitation_id"` // The invitation sent when a licence is available.
+	admin.RowTime
+}
+
+func NewWaitlistEntry(params input.WaitlistParams, by admin.Creator) WaitlistEntry {
+	return WaitlistEntry{
+		ID:           ids.WaitlistID(),
+		Creator:      by,
+		Email:        params.Email,
+		Priority:     params.Priority,
+		Note:         params.Note,
+		Status:       WaitlistStatusWaiting,
+		InvitationID: null.String{},
+		RowTime:      admin.NewRowTime(),
+	}
+}
+
+func (w WaitlistEntry) IsWaiting() bool {
+	return w.Status == WaitlistStatusWaiting
+}
+
+// Update changes priority and note. Email is not changeable.
+func (w WaitlistEntry) Update(params input.WaitlistParams) WaitlistEntry {
+	w.Priority = params.Priority
+	w.Note = params.Note
+	w.UpdatedUTC = chrono.TimeNow()
+
+	return w
+}
+
+func (w WaitlistEntry) Invited(inv Invitation) WaitlistEntry {
+	w.Status = WaitlistStatusInvited
+	w.InvitationID = null.StringFrom(inv.ID)
+	w.UpdatedUTC = chrono.TimeNow()
+
+	return w
+}
+
+// Failed takes an entry out of the queue after invitation
+// could not be created for it.
+func (w WaitlistEntry) Failed() WaitlistEntry {
+	w.Status = WaitlistStatusFailed
+	w.UpdatedUTC = chrono.TimeNow()
+
+	return w
+}
+
+func (w WaitlistEntry) Removed() WaitlistEntry {
+	w.Status = WaitlistStatusRemoved
+	w.UpdatedUTC = chrono.TimeNow()
+
+	return w
+}
+
+type WaitlistList struct {
+	pkg.PagedList
+	Data []WaitlistEntry `json:"data"`
+}
+
+// WaitlistFilled is the result after licences become available.
+// If team enabled auto-fill, Invited contains the licences
+// invited to waiting entries; otherwise admin should be told
+// how many are waiting.
+type WaitlistFilled struct {
+	TeamID    string    `json:"teamId"`
+	AutoFill  bool      `json:"autoFill"`
+	Available int       `json:"available"`
+	Waiting   int       `json:"waiting"`
+	Failed    int       `json:"failed"` // Entries failed to be invited.
+	Invited   []Licence `json:"invited"`
+}
+
+// ShouldNotifyAdmin tells whether admin should be told
+// that seats are available while people are waiting.
+func (f WaitlistFilled) ShouldNotifyAdmin() bool {
+	return !f.AutoFill && f.Available > 0 && f.Waiting > 0
+}
+
+// InvitationExpired is an invitation whose licence is not
+// accepted within the valid period.
+type InvitationExpired struct {
+	InvitationID string `db:"invite_id"`
+	LicenceID    string `db:"licence_id"`
+	TeamID       string `db:"team_id"`
+}
@@ -0,0 +1,94 @@
+package licence
+
+const StmtCreateWaitlistEntry = `
+INSERT INTO b2b.waitlist
+SET id = :entry_id,
+	admin_id = :admin_id,
+	team_id = :team_id,
+	email = :email,
+	priority = :priority,
+	note = :note,
+	entry_status = :entry_status,
+	invitation_id = :invitation_id,
+	created_utc = :created_utc,
+	updated_utc = :updated_utc`
+
+const colWaitlist = `
+SELECT id AS entry_id,
+	admin_id,
+	team_id,
+	email,
+	priority,
+	note,
+	entry_status,
+	invitation_id,
+	created_utc,
+	updated_utc
+FROM b2b.waitlist
+`
+
+const StmtWaitlistEntry = colWaitlist + `
+WHERE id = ? AND team_id = ?
+LIMIT 1`
+
+// StmtListWaitlist shows the entries still waiting
+// in the order they will be invited.
+const StmtListWaitlist = colWaitlist + `
+WHERE team_id = ?
+	AND entry_status = 'waiting'
+ORDER BY priority DESC, created_utc ASC
+LIMIT ? OFFSET ?`
+
+const StmtCountWaitlist = `
+SELECT COUNT(*) AS row_count
+FROM b2b.waitlist
+WHERE team_id = ?
+	AND entry_status = 'waiting'`
+
+// StmtLockNextWaiting locks the first n waiting entries.
+const StmtLockNextWaiting = colWaitlist + `
+WHERE team_id = ?
+	AND entry_status = 'waiting'
+ORDER BY priority DESC, created_utc ASC
+LIMIT ?
+FOR UPDATE`
+
+const StmtUpdateWaitlistEntry = `
+UPDATE b2b.waitlist
+SET priority = :priority,
+	note = :note,
+	entry_status = :entry_status,
+	invitation_id = :invitation_id,
+	updated_utc = :updated_utc
+WHERE id = :entry_id
+	AND team_id = :team_id
+LIMIT 1`
+
+// StmtListAvailableLicenceIDs finds licences that could be
+// invited to waiting entries, or to staff of a unit if unit
+// id is not NULL. Unit id is passed twice.
+const StmtListAvailableLicenceIDs = `
+SELECT id
+FROM b2b.licence
+WHERE team_id = ?
+	AND (? IS NULL OR unit_id = ?)
+	AND current_status = 'available'
+	AND assignee_id IS NULL
+ORDER BY current_period_end_utc DESC`
+
+// StmtListExpiredInvitations finds invitations that are not
+// accepted within the valid period while the licence is
+// still waiting for it.
+// Invitations superseded by a newer one are ignored.
+const StmtListExpiredInvitations = `
+SELECT i.id AS invite_id,
+	i.licence_id AS licence_id,
+	i.team_id AS team_id
+FROM b2b.invitation AS i
+	JOIN b2b.licence AS l
+	ON i.licence_id = l.id
+WHERE i.current_status = 'created'
+	AND l.current_status = 'invited'
+	AND l.assignee_id IS NULL
+	AND JSON_UNQUOTE(JSON_EXTRACT(l.latest_invitation, '$.id')) = i.id
+	AND DATE_ADD(i.created_utc, INTERVAL i.expiration_days DAY) < UTC_TIMESTAMP()`
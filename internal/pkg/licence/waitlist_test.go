@@ -0,0 +1,113 @@
+package licence
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/guregu/null"
+	"testing"
+)
+
+func TestWaitlistEntry_Transitions(t *testing.T) {
+	entry := NewWaitlistEntry(input.WaitlistParams{
+		Email:    "alice@example.com",
+		Priority: 1,
+	}, admin.Creator{
+		AdminID: "admin-1",
+		TeamID:  "team-1",
+	})
+
+	tests := []struct {
+		name        string
+		got         WaitlistEntry
+		wantStatus  WaitlistStatus
+		wantWaiting bool
+		wantInvite  null.String
+	}{
+		{
+			name:        "New entry",
+			got:         entry,
+			wantStatus:  WaitlistStatusWaiting,
+			wantWaiting: true,
+		},
+		{
+			name:        "Invited",
+			got:         entry.Invited(Invitation{ID: "inv-1"}),
+			wantStatus:  WaitlistStatusInvited,
+			wantWaiting: false,
+			wantInvite:  null.StringFrom("inv-1"),
+		},
+		{
+			name:        "Failed",
+			got:         entry.Failed(),
+			wantStatus:  WaitlistStatusFailed,
+			wantWaiting: false,
+		},
+		{
+			name:        "Removed",
+			got:         entry.Removed(),
+			wantStatus:  WaitlistStatusRemoved,
+			wantWaiting: false,
+		},
+		{
+			name: "Updated",
+			got: entry.Update(input.WaitlistParams{
+				Email:    "bob@example.com",
+				Priority: 5,
+			}),
+			wantStatus:  WaitlistStatusWaiting,
+			wantWaiting: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", tt.got.Status, tt.wantStatus)
+			}
+			if got := tt.got.IsWaiting(); got != tt.wantWaiting {
+				t.Errorf("IsWaiting() = %v, want %v", got, tt.wantWaiting)
+			}
+			if tt.got.InvitationID != tt.wantInvite {
+				t.Errorf("InvitationID = %v, want %v", tt.got.InvitationID, tt.wantInvite)
+			}
+			if tt.got.Email != "alice@example.com" {
+				t.Errorf("Email should not be changed, got %s", tt.got.Email)
+			}
+		})
+	}
+}
+
+func TestWaitlistFilled_ShouldNotifyAdmin(t *testing.T) {
+	tests := []struct {
+		name   string
+		filled WaitlistFilled
+		want   bool
+	}{
+		{
+			name:   "Auto-fill enabled",
+			filled: WaitlistFilled{AutoFill: true, Available: 2, Waiting: 3},
+			want:   false,
+		},
+		{
+			name:   "Seats available while people waiting",
+			filled: WaitlistFilled{Available: 2, Waiting: 3},
+			want:   true,
+		},
+		{
+			name:   "No seats available",
+			filled: WaitlistFilled{Available: 0, Waiting: 3},
+			want:   false,
+		},
+		{
+			name:   "Nobody waiting",
+			filled: WaitlistFilled{Available: 2, Waiting: 0},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filled.ShouldNotifyAdmin(); got != tt.want {
+				t.Errorf("ShouldNotifyAdmin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package outbox
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/ids"
+	"github.com/FTChinese/ftacademy/pkg/postman"
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/guregu/null"
+	"time"
+)
+
+// MaxAttempts is the number of tries before a letter is
+// moved to dead letters.
+const MaxAttempts = 10
+
+// Lease is how long a message is hidden from the worker
+// after claimed, so that it is not sent twice while
+// an attempt is in progress.
+const Lease = 5 * time.Minute
+
+const (
+	backoffBase = time.Minute
+	backoffMax  = 2 * time.Hour
+)
+
+// Backoff calculates how long to wait after the nth
+// failed attempt: 1m, 2m, 4m... capped at 2h.
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+
+	d := backoffBase
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= backoffMax {
+			return backoffMax
+		}
+	}
+
+	return d
+}
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusSent    Status = "sent"
+	StatusDead    Status = "dead"
+)
+
+// Message is a letter saved before sending. It is retried
+// until sent or MaxAttempts reached.
+type Message struct {
+	ID             string      `json:"id" db:"message_id"`
+	FromAddress    string      `json:"fromAddress" db:"from_address"`
+	FromName       string      `json:"fromName" db:"from_name"`
+	ToAddress      string      `json:"toAddress" db:"to_address"`
+	ToName         string      `json:"toName" db:"to_name"`
+	Subject        string      `json:"subject" db:"subject"`
+	Body           string      `json:"-" db:"body"` // Holds live links; never shown and cleared once sent.
+	Status         Status      `json:"status" db:"mail_status"`
+	Attempts       int         `json:"attempts" db:"attempts"`
+	LastError      null.String `json:"lastError" db:"last_error"`
+	NextAttemptUTC chrono.Time `json:"nextAttemptUtc" db:"next_attempt_utc"`
+	CreatedUTC     chrono.Time `json:"createdUtc" db:"created_utc"`
+	UpdatedUTC     chrono.Time `json:"updatedUtc" db:"updated_utc"`
+}
+
+// NewMessage queues a parcel. It is leased right away
+// since the caller attempts it immediately; the worker
+// picks it up only if that attempt never finishes.
+func NewMessage(p postman.Parcel, now time.Time) Message {
+	return Message{
+		ID:             ids.OutboxID(),
+		FromAddress:    p.FromAddress,
+		FromName:       p.FromName,
+		ToAddress:      p.ToAddress,
+		ToName:         p.ToName,
+		Subject:        p.Subject,
+		Body:           p.Body,
+		Status:         StatusPending,
+		Attempts:       0,
+		NextAttemptUTC: chrono.TimeUTCFrom(now.Add(Lease)),
+		CreatedUTC:     chrono.TimeUTCFrom(now),
+		UpdatedUTC:     chrono.TimeUTCFrom(now),
+	}
+}
+
+func (m Message) Parcel() postman.Parcel {
+	return postman.Parcel{
+		FromAddress: m.FromAddress,
+		FromName:    m.FromName,
+		ToAddress:   m.ToAddress,
+		ToName:      m.ToName,
+		Subject:     m.Subject,
+		Body:        m.Body,
+	}
+}
+
+// Attempted records the outcome of a try and schedules
+// the next one if it failed.
+func (m Message) Attempted(err error, now time.Time) Message {
+	m.Attempts++
+	m.UpdatedUTC = chrono.TimeUTCFrom(now)
+
+	switch {
+	case err == nil:
+		m.Status = StatusSent
+		m.Body = ""
+		m.LastError = null.String{}
+		m.NextAttemptUTC = chrono.Time{}
+
+	case m.Attempts >= MaxAttempts:
+		m.Status = StatusDead
+		m.LastError = null.StringFrom(err.Error())
+		m.NextAttemptUTC = chrono.Time{}
+
+	default:
+		m.Status = StatusPending
+		m.LastError = null.StringFrom(err.Error())
+		m.NextAttemptUTC = chrono.TimeUTCFrom(now.Add(Backoff(m.Attempts)))
+	}
+
+	return m
+}
+
+// Leased hides a claimed message from other workers
+// until the attempt finishes or the lease expires.
+func (m Message) Leased(now time.Time) Message {
+	m.NextAttemptUTC = chrono.TimeUTCFrom(now.Add(Lease))
+	m.UpdatedUTC = chrono.TimeUTCFrom(now)
+
+	return m
+}
+
+// Resend puts a dead letter back to queue with attempts
+// counted from zero. Like NewMessage it is leased to the
+// caller who sends it immediately.
+func (m Message) Resend(now time.Time) Message {
+	m.Status = StatusPending
+	m.Attempts = 0
+
+	return m.Leased(now)
+}
+
+type MessageList struct {
+	pkg.PagedList
+	Data []Message `json:"data"`
+}
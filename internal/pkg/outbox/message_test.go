@@ -0,0 +1,94 @@
+package outbox
+
+import (
+	"errors"
+	"github.com/FTChinese/ftacademy/pkg/postman"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, time.Minute},
+		{3, 4 * time.Minute},
+		{20, 2 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestMessage_Attempted(t *testing.T) {
+	now := time.Now()
+	m := NewMessage(postman.Parcel{ToAddress: "a@example.org", Body: "Hello"}, now)
+
+	if !m.NextAttemptUTC.After(now) {
+		t.Error("new message should be leased to the caller")
+	}
+
+	tests := []struct {
+		name     string
+		attempts int
+		err      error
+		want     Status
+	}{
+		{
+			name:     "Sent",
+			attempts: 0,
+			err:      nil,
+			want:     StatusSent,
+		},
+		{
+			name:     "Retried",
+			attempts: 2,
+			err:      errors.New("connection reset"),
+			want:     StatusPending,
+		},
+		{
+			name:     "Dead",
+			attempts: MaxAttempts - 1,
+			err:      errors.New("mailbox unavailable"),
+			want:     StatusDead,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.Attempts = tt.attempts
+			got := m.Attempted(tt.err, now)
+
+			if got.Status != tt.want {
+				t.Errorf("Status = %s, want %s", got.Status, tt.want)
+			}
+			if got.Attempts != tt.attempts+1 {
+				t.Errorf("Attempts = %d, want %d", got.Attempts, tt.attempts+1)
+			}
+			if (got.Body == "") != (tt.want == StatusSent) {
+				t.Errorf("Body should only be cleared when sent")
+			}
+			if got.LastError.Valid != (tt.err != nil) {
+				t.Errorf("LastError = %v", got.LastError)
+			}
+			if got.NextAttemptUTC.IsZero() != (tt.want != StatusPending) {
+				t.Errorf("NextAttemptUTC = %v", got.NextAttemptUTC)
+			}
+		})
+	}
+}
+
+func TestMessage_Resend(t *testing.T) {
+	now := time.Now()
+	m := NewMessage(postman.Parcel{}, now)
+	m.Attempts = MaxAttempts
+	m.Status = StatusDead
+
+	got := m.Resend(now)
+	if got.Status != StatusPending || got.Attempts != 0 {
+		t.Errorf("got %s after %d attempts", got.Status, got.Attempts)
+	}
+}
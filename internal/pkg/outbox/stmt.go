@@ -0,0 +1,76 @@
+package outbox
+
+const StmtCreateMessage = `
+INSERT INTO b2b.email_outbox
+SET message_id = :message_id,
+	from_address = :from_address,
+	from_name = :from_name,
+	to_address = :to_address,
+	to_name = :to_name,
+	subject = :subject,
+	body = :body,
+	mail_status = :mail_status,
+	attempts = :attempts,
+	next_attempt_utc = :next_attempt_utc,
+	created_utc = :created_utc,
+	updated_utc = :updated_utc`
+
+// StmtUpdateMessage also writes body so that it is cleared
+// once a letter is sent.
+const StmtUpdateMessage = `
+UPDATE b2b.email_outbox
+SET body = :body,
+	mail_status = :mail_status,
+	attempts = :attempts,
+	last_error = :last_error,
+	next_attempt_utc = :next_attempt_utc,
+	updated_utc = :updated_utc
+WHERE message_id = :message_id
+LIMIT 1`
+
+const colMessage = `
+SELECT message_id,
+	from_address,
+	from_name,
+	to_address,
+	to_name,
+	subject,
+	body,
+	mail_status,
+	attempts,
+	last_error,
+	next_attempt_utc,
+	created_utc,
+	updated_utc
+FROM b2b.email_outbox
+`
+
+const StmtMessage = colMessage + `
+WHERE message_id = ?
+LIMIT 1`
+
+// StmtLockMessage locks a message so that concurrent
+// resend requests do not send it twice.
+const StmtLockMessage = colMessage + `
+WHERE message_id = ?
+LIMIT 1
+FOR UPDATE`
+
+// StmtDueMessages locks pending messages whose retry time
+// is reached. Rows locked by another instance are skipped.
+const StmtDueMessages = colMessage + `
+WHERE mail_status = 'pending'
+	AND next_attempt_utc <= UTC_TIMESTAMP()
+ORDER BY next_attempt_utc ASC
+LIMIT ?
+FOR UPDATE SKIP LOCKED`
+
+const StmtListMessages = colMessage + `
+WHERE mail_status = ?
+ORDER BY updated_utc DESC
+LIMIT ? OFFSET ?`
+
+const StmtCountMessages = `
+SELECT COUNT(*) AS row_count
+FROM b2b.email_outbox
+WHERE mail_status = ?`
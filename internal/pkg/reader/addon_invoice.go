@@ -7,6 +7,7 @@ import (
 	"github.com/FTChinese/go-rest/chrono"
 	"github.com/FTChinese/go-rest/enum"
 	"github.com/guregu/null"
+	"time"
 )
 
 // Invoice is the result of a successfully paid order.
@@ -52,3 +53,16 @@ func (i Invoice) WithLicTxID(id null.String) Invoice {
 func (i Invoice) IsZero() bool {
 	return i.ID == ""
 }
+
+// Consumed transfers an addon invoice to membership,
+// starting from the specified moment and lasting for days.
+// The days might be less than the invoice's total days
+// if part of the addon is already used somewhere else.
+func (i Invoice) Consumed(start time.Time, days int64) Invoice {
+	i.ConsumedUTC = chrono.TimeNow()
+	i.DateTimePeriod = dt.NewTimeRange(start).
+		AddDays(int(days)).
+		ToDateTimePeriod()
+
+	return i
+}
@@ -24,3 +24,64 @@ SET id = :id,
 	end_utc = :end_utc,
 	carried_over_utc = :carried_over_utc
 `
+
+const colInvoice = `
+SELECT id,
+	user_compound_id AS compound_id,
+	tier,
+	cycle,
+	years,
+	months,
+	extra_days AS days,
+	addon_source,
+	apple_tx_id,
+	licence_tx_id,
+	order_id,
+	order_kind,
+	paid_amount,
+	payment_method,
+	price_id,
+	stripe_subs_id,
+	created_utc,
+	consumed_utc,
+	start_utc,
+	end_utc,
+	carried_over_utc
+FROM premium.ftc_invoice
+`
+
+// StmtLockLicenceCarryOver locks the carry-over invoice
+// generated when a licence is granted to a one-time purchase
+// membership, which is not consumed yet.
+// It is matched by the licence transaction at the time of
+// granting, which is moved forward when the licence is renewed.
+const StmtLockLicenceCarryOver = colInvoice + `
+WHERE user_compound_id = ?
+	AND licence_tx_id = ?
+	AND addon_source = 'carry_over'
+	AND consumed_utc IS NULL
+ORDER BY created_utc DESC
+LIMIT 1
+FOR UPDATE`
+
+// StmtMoveLicenceCarryOver links unconsumed carry-over of
+// a granted licence to its latest transaction after renewal.
+const StmtMoveLicenceCarryOver = `
+UPDATE premium.ftc_invoice
+SET licence_tx_id = ?
+WHERE user_compound_id = ?
+	AND licence_tx_id = ?
+	AND addon_source = 'carry_over'
+	AND consumed_utc IS NULL
+`
+
+// StmtConsumeInvoice sets the period an invoice is
+// transferred to membership.
+const StmtConsumeInvoice = `
+UPDATE premium.ftc_invoice
+SET consumed_utc = :consumed_utc,
+	start_utc = :start_utc,
+	end_utc = :end_utc
+WHERE id = :id
+LIMIT 1
+`
@@ -2,27 +2,30 @@ package reader
 
 import (
 	"errors"
+	"github.com/FTChinese/ftacademy/pkg/keyring"
 	"github.com/FTChinese/ftacademy/pkg/xhttp"
 	"log"
 	"net/http"
 )
 
 type JWTGuard struct {
-	signingKey []byte
+	keyring keyring.Keyring
 }
 
-func NewJWTGuard(key []byte) JWTGuard {
+func NewJWTGuard(kr keyring.Keyring) JWTGuard {
 	return JWTGuard{
-		signingKey: key,
+		keyring: kr,
 	}
 }
 
-func (g JWTGuard) GetKey() []byte {
-	return g.signingKey
+// JWKS publishes public keys for other services to verify
+// tokens.
+func (g JWTGuard) JWKS() keyring.JWKSet {
+	return g.keyring.JWKS()
 }
 
 func (g JWTGuard) CreatePassport(a Account) (Passport, error) {
-	return NewPassport(a, g.signingKey)
+	return NewPassport(a, g.keyring)
 }
 
 func (g JWTGuard) RetrievePassportClaims(req *http.Request) (PassportClaims, error) {
@@ -32,7 +35,7 @@ func (g JWTGuard) RetrievePassportClaims(req *http.Request) (PassportClaims, err
 		return PassportClaims{}, err
 	}
 
-	claims, err := ParsePassportClaims(ss, g.signingKey)
+	claims, err := ParsePassportClaims(ss, g.keyring)
 	if err != nil {
 		log.Printf("Error parsing JWT %v", err)
 		return PassportClaims{}, err
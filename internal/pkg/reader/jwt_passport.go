@@ -2,6 +2,7 @@ package reader
 
 import (
 	"errors"
+	"github.com/FTChinese/ftacademy/pkg/keyring"
 	"github.com/FTChinese/go-rest/enum"
 	"github.com/golang-jwt/jwt"
 	"github.com/guregu/null"
@@ -56,19 +57,20 @@ type Passport struct {
 	Token     string `json:"token"`
 }
 
-func NewPassport(a Account, signingKey []byte) (Passport, error) {
+// PassportTTL is the lifetime of JWT.
+const PassportTTL = 7 * 24 * time.Hour
+
+func NewPassport(a Account, kr keyring.Keyring) (Passport, error) {
 	claims := PassportClaims{
 		FtcID:          a.FtcID,
 		UnionID:        a.UnionID,
 		LoginMethod:    a.LoginMethod,
 		Live:           !a.IsTest(),
 		Version:        passportVersion,
-		StandardClaims: NewStandardClaims(time.Now().Unix() + 86400*7),
+		StandardClaims: NewStandardClaims(time.Now().Add(PassportTTL).Unix()),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	ss, err := token.SignedString(signingKey)
+	ss, err := kr.Sign(claims)
 
 	if err != nil {
 		return Passport{}, err
@@ -83,13 +85,8 @@ func NewPassport(a Account, signingKey []byte) (Passport, error) {
 	}, nil
 }
 
-func ParsePassportClaims(ss string, key []byte) (PassportClaims, error) {
-	token, err := jwt.ParseWithClaims(
-		ss,
-		&PassportClaims{},
-		func(token *jwt.Token) (i interface{}, err error) {
-			return key, nil
-		})
+func ParsePassportClaims(ss string, kr keyring.Keyring) (PassportClaims, error) {
+	token, err := kr.ParseWithClaims(ss, &PassportClaims{})
 
 	if err != nil {
 		log.Printf("Parsing JWT error: %v", err)
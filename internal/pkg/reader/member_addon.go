@@ -49,3 +49,26 @@ func (m Membership) CarryOverInvoice() Invoice {
 		CarriedOverUtc: chrono.Time{},
 	}
 }
+
+// CarryOverRestored re-activates the one-time purchase membership
+// which was frozen into a carry-over invoice when a licence
+// is granted. The invoice should be consumed for the same days
+// before calling this so that its period is known.
+// The days restored are deducted from addon.
+func (m Membership) CarryOverRestored(inv Invoice, days int64) Membership {
+	m.Edition = inv.Edition
+	m.ExpireDate = chrono.DateFrom(inv.EndUTC.Time)
+	m.LegacyTier = null.IntFrom(GetTierCode(inv.Tier))
+	m.LegacyExpire = null.IntFrom(m.ExpireDate.Unix())
+	m.PaymentMethod = inv.PaymentMethod
+	// Carry-over is only generated from alipay or wxpay.
+	if m.PaymentMethod != enum.PayMethodAli && m.PaymentMethod != enum.PayMethodWx {
+		m.PaymentMethod = enum.PayMethodAli
+	}
+	m.FtcPlanID = inv.PriceID
+	m.AutoRenewal = false
+	m.B2BLicenceID = null.String{}
+	m.AddOn = m.AddOn.Minus(addon.New(inv.Tier, days))
+
+	return m
+}
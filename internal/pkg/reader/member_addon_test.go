@@ -0,0 +1,76 @@
+package reader
+
+import (
+	"github.com/FTChinese/ftacademy/pkg/addon"
+	"github.com/FTChinese/ftacademy/pkg/dt"
+	"github.com/FTChinese/ftacademy/pkg/price"
+	"github.com/FTChinese/go-rest/enum"
+	"github.com/guregu/null"
+	"testing"
+	"time"
+)
+
+func TestMembership_CarryOverRestored(t *testing.T) {
+	now := time.Now()
+
+	m := Membership{
+		Edition: price.Edition{
+			Tier:  enum.TierPremium,
+			Cycle: enum.CycleYear,
+		},
+		PaymentMethod: enum.PayMethodB2B,
+		B2BLicenceID:  null.StringFrom("lic-1"),
+		AddOn:         addon.AddOn{Standard: 90, Premium: 10},
+	}
+
+	tests := []struct {
+		name       string
+		payMethod  enum.PayMethod
+		wantMethod enum.PayMethod
+	}{
+		{
+			name:       "Wxpay kept",
+			payMethod:  enum.PayMethodWx,
+			wantMethod: enum.PayMethodWx,
+		},
+		{
+			name:       "Fallback to alipay",
+			payMethod:  enum.PayMethodStripe,
+			wantMethod: enum.PayMethodAli,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := Invoice{
+				Edition: price.Edition{
+					Tier:  enum.TierStandard,
+					Cycle: enum.CycleYear,
+				},
+				YearMonthDay:  dt.YearMonthDay{Days: 60},
+				PaymentMethod: tt.payMethod,
+				PriceID:       null.StringFrom("plan-1"),
+			}.Consumed(now, 30)
+
+			got := m.CarryOverRestored(inv, 30)
+
+			if got.Tier != enum.TierStandard {
+				t.Errorf("Tier = %s, want standard", got.Tier)
+			}
+			if got.PaymentMethod != tt.wantMethod {
+				t.Errorf("PaymentMethod = %s, want %s", got.PaymentMethod, tt.wantMethod)
+			}
+			if got.B2BLicenceID.Valid || got.AutoRenewal {
+				t.Error("restored membership should be a plain one-time purchase")
+			}
+			if got.AddOn != (addon.AddOn{Standard: 60, Premium: 10}) {
+				t.Errorf("AddOn = %+v, want 30 standard days deducted", got.AddOn)
+			}
+			if got.ExpireDate.Format("2006-01-02") != inv.EndUTC.Format("2006-01-02") {
+				t.Errorf("ExpireDate = %s, want %s", got.ExpireDate, inv.EndUTC)
+			}
+			if got.LegacyTier.Int64 != GetTierCode(enum.TierStandard) {
+				t.Errorf("LegacyTier = %d", got.LegacyTier.Int64)
+			}
+		})
+	}
+}
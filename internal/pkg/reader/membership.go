@@ -180,3 +180,12 @@ func (m Membership) LicenceRevoked() Membership {
 	m.PaymentMethod = enum.PayMethodAli
 	return m
 }
+
+// IsAutoRenewEnded checks whether an auto-renewal subscription
+// is already expired or switched to other payment methods,
+// so that a licence reserved for it could be granted.
+// Auto renewal that is cancelled is treated as expired after
+// the expiration date.
+func (m Membership) IsAutoRenewEnded() bool {
+	return !m.IsAutoRenew() || m.IsExpired()
+}
@@ -21,6 +21,10 @@ SELECT vip_id AS compound_id,
 	premium_addon
 FROM premium.ftc_vip`
 
+// StmtSelectMembers retrieves memberships without any
+// condition. Callers append their own JOIN or WHERE clause.
+const StmtSelectMembers = colMembership
+
 const StmtSelectMember = colMembership + `
 WHERE ? IN (vip_id, vip_id_alias)
 LIMIT 1
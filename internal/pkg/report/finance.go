@@ -0,0 +1,182 @@
+package report
+
+import (
+	"strconv"
+)
+
+func formatAmount(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+func formatInt(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+// RevenueRow is revenue of a tier and cycle in a month,
+// aggregated from payment offers of confirmed orders.
+type RevenueRow struct {
+	Month    string  `json:"month" db:"month"` // YYYY-MM
+	Tier     string  `json:"tier" db:"tier"`
+	Cycle    string  `json:"cycle" db:"cycle"`
+	Orders   int64   `json:"orders" db:"order_count"`
+	Copies   int64   `json:"copies" db:"copy_count"`
+	Gross    float64 `json:"gross" db:"gross_amount"`       // Copies multiplied by unit price.
+	Discount float64 `json:"discount" db:"discount_amount"` // Copies multiplied by price off per copy.
+}
+
+// Net is what the customer paid.
+func (r RevenueRow) Net() float64 {
+	return r.Gross - r.Discount
+}
+
+var RevenueCSVHeader = []string{
+	"month",
+	"tier",
+	"cycle",
+	"orders",
+	"copies",
+	"gross",
+	"discount",
+	"net",
+}
+
+func (r RevenueRow) CSVRow() []string {
+	return []string{
+		r.Month,
+		r.Tier,
+		r.Cycle,
+		formatInt(r.Orders),
+		formatInt(r.Copies),
+		formatAmount(r.Gross),
+		formatAmount(r.Discount),
+		formatAmount(r.Net()),
+	}
+}
+
+// RevenueTotal sums up all rows in a revenue report.
+type RevenueTotal struct {
+	Copies   int64   `json:"copies"`
+	Gross    float64 `json:"gross"`
+	Discount float64 `json:"discount"`
+	Net      float64 `json:"net"`
+}
+
+// RevenueReport is revenue by month, tier and cycle.
+type RevenueReport struct {
+	Total RevenueTotal `json:"total"`
+	Data  []RevenueRow `json:"data"`
+}
+
+func NewRevenueReport(rows []RevenueRow) RevenueReport {
+	var t RevenueTotal
+	for _, r := range rows {
+		t.Copies += r.Copies
+		t.Gross += r.Gross
+		t.Discount += r.Discount
+	}
+	t.Net = t.Gross - t.Discount
+
+	return RevenueReport{
+		Total: t,
+		Data:  rows,
+	}
+}
+
+// SeatRow counts licences created or renewed in a month,
+// from finalized licence transactions.
+type SeatRow struct {
+	Month   string `json:"month" db:"month"`
+	New     int64  `json:"new" db:"new_count"`
+	Renewal int64  `json:"renewal" db:"renewal_count"`
+}
+
+var SeatCSVHeader = []string{
+	"month",
+	"new",
+	"renewal",
+}
+
+func (r SeatRow) CSVRow() []string {
+	return []string{
+		r.Month,
+		formatInt(r.New),
+		formatInt(r.Renewal),
+	}
+}
+
+// GrantChange counts grant and revoke actions in licence
+// versions of a month.
+type GrantChange struct {
+	Month   string `db:"month"`
+	Granted int64  `db:"granted_count"`
+	Revoked int64  `db:"revoked_count"`
+}
+
+// ActiveSeatRow is the number of granted licences at the end
+// of a month.
+type ActiveSeatRow struct {
+	Month   string `json:"month"`
+	Granted int64  `json:"granted"`
+	Revoked int64  `json:"revoked"`
+	Active  int64  `json:"active"`
+}
+
+var ActiveSeatCSVHeader = []string{
+	"month",
+	"granted",
+	"revoked",
+	"active",
+}
+
+func (r ActiveSeatRow) CSVRow() []string {
+	return []string{
+		r.Month,
+		formatInt(r.Granted),
+		formatInt(r.Revoked),
+		formatInt(r.Active),
+	}
+}
+
+// NewActiveSeats accumulates monthly changes on top of the
+// number of seats active before the period.
+// changes must be sorted by month.
+func NewActiveSeats(baseline int64, changes []GrantChange) []ActiveSeatRow {
+	var rows = make([]ActiveSeatRow, 0, len(changes))
+
+	active := baseline
+	for _, c := range changes {
+		active += c.Granted - c.Revoked
+		rows = append(rows, ActiveSeatRow{
+			Month:   c.Month,
+			Granted: c.Granted,
+			Revoked: c.Revoked,
+			Active:  active,
+		})
+	}
+
+	return rows
+}
+
+// TopTeamRow is a team ranked by amount paid.
+type TopTeamRow struct {
+	TeamID  string  `json:"teamId" db:"team_id"`
+	OrgName string  `json:"orgName" db:"org_name"`
+	Orders  int64   `json:"orders" db:"order_count"`
+	Spend   float64 `json:"spend" db:"amount_paid"`
+}
+
+var TopTeamCSVHeader = []string{
+	"team_id",
+	"org_name",
+	"orders",
+	"spend",
+}
+
+func (r TopTeamRow) CSVRow() []string {
+	return []string{
+		r.TeamID,
+		r.OrgName,
+		formatInt(r.Orders),
+		formatAmount(r.Spend),
+	}
+}
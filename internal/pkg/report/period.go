@@ -0,0 +1,75 @@
+package report
+
+import (
+	"errors"
+	"github.com/FTChinese/go-rest/chrono"
+	"time"
+)
+
+const layoutDate = "2006-01-02"
+
+// maxPeriod prevents a single report from scanning
+// too many rows.
+const maxPeriod = 3 * 366 * 24 * time.Hour
+
+// Period is the date range of a report. Start is inclusive
+// and End is exclusive, both in UTC.
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// ParsePeriod parses dates in the format of 2006-01-02.
+// end is inclusive when provided by client.
+// If start is empty, it defaults to the 1st day of the month
+// 11 months ago so that a whole year is included; if end is
+// empty, it defaults to today.
+func ParsePeriod(start, end string, now time.Time) (Period, error) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var p Period
+	var err error
+
+	if end == "" {
+		p.End = today.AddDate(0, 0, 1)
+	} else {
+		p.End, err = time.Parse(layoutDate, end)
+		if err != nil {
+			return Period{}, errors.New("end date must be in format YYYY-MM-DD")
+		}
+		p.End = p.End.AddDate(0, 0, 1)
+	}
+
+	if start == "" {
+		p.Start = time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -11, 0)
+	} else {
+		p.Start, err = time.Parse(layoutDate, start)
+		if err != nil {
+			return Period{}, errors.New("start date must be in format YYYY-MM-DD")
+		}
+	}
+
+	if !p.Start.Before(p.End) {
+		return Period{}, errors.New("start date must be earlier than end date")
+	}
+
+	if p.End.Sub(p.Start) > maxPeriod {
+		return Period{}, errors.New("date range must be within 3 years")
+	}
+
+	return p, nil
+}
+
+// Args is used in SQL WHERE clause `col >= ? AND col < ?`.
+func (p Period) Args() []interface{} {
+	return []interface{}{
+		p.Start.Format(chrono.SQLDateTime),
+		p.End.Format(chrono.SQLDateTime),
+	}
+}
+
+// FileName of a csv file for a report.
+func (p Period) FileName(name string) string {
+	return name + "_" + p.Start.Format(layoutDate) + "_" + p.End.AddDate(0, 0, -1).Format(layoutDate) + ".csv"
+}
@@ -0,0 +1,107 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePeriod(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		start   string
+		end     string
+		want    Period
+		wantErr bool
+	}{
+		{
+			name: "Default to last 12 months",
+			want: Period{
+				Start: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:  "End date is inclusive",
+			start: "2026-01-01",
+			end:   "2026-03-31",
+			want: Period{
+				Start: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "Start after end",
+			start:   "2026-05-01",
+			end:     "2026-04-01",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid format",
+			start:   "2026/05/01",
+			wantErr: true,
+		},
+		{
+			name:    "Too long",
+			start:   "2020-01-01",
+			end:     "2026-01-01",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePeriod(tt.start, tt.end, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePeriod() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePeriod() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriod_FileName(t *testing.T) {
+	p := Period{
+		Start: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	if got := p.FileName("revenue"); got != "revenue_2026-01-01_2026-03-31.csv" {
+		t.Errorf("FileName() = %s", got)
+	}
+}
+
+func TestNewActiveSeats(t *testing.T) {
+	got := NewActiveSeats(10, []GrantChange{
+		{Month: "2026-01", Granted: 5, Revoked: 2},
+		{Month: "2026-02", Granted: 0, Revoked: 4},
+	})
+
+	want := []ActiveSeatRow{
+		{Month: "2026-01", Granted: 5, Revoked: 2, Active: 13},
+		{Month: "2026-02", Granted: 0, Revoked: 4, Active: 9},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewActiveSeats() = %v, want %v", got, want)
+	}
+}
+
+func TestNewRevenueReport(t *testing.T) {
+	r := NewRevenueReport([]RevenueRow{
+		{Copies: 10, Gross: 2980, Discount: 300},
+		{Copies: 2, Gross: 596, Discount: 0},
+	})
+
+	if r.Total.Copies != 12 || r.Total.Net != 3276 {
+		t.Errorf("NewRevenueReport() total = %+v", r.Total)
+	}
+
+	if got := r.Data[0].CSVRow()[7]; got != "2680.00" {
+		t.Errorf("CSVRow() net = %s", got)
+	}
+}
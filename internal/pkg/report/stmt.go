@@ -0,0 +1,73 @@
+package report
+
+// All statements take Period.Args() for the date range.
+
+// StmtRevenue aggregates payment offers by the month payment
+// is approved.
+const StmtRevenue = `
+SELECT DATE_FORMAT(p.approved_utc, '%Y-%m') AS month,
+	IFNULL(JSON_UNQUOTE(JSON_EXTRACT(po.price, '$.tier')), '') AS tier,
+	IFNULL(JSON_UNQUOTE(JSON_EXTRACT(po.price, '$.cycle')), '') AS cycle,
+	COUNT(DISTINCT po.order_id) AS order_count,
+	SUM(po.copy_count) AS copy_count,
+	SUM(po.copy_count * JSON_EXTRACT(po.price, '$.unitAmount')) AS gross_amount,
+	SUM(po.copy_count * po.price_off_per_copy) AS discount_amount
+FROM b2b.payment_offer AS po
+	JOIN b2b.payment AS p
+	ON po.order_id = p.order_id
+WHERE p.approved_utc >= ? AND p.approved_utc < ?
+GROUP BY month, tier, cycle
+ORDER BY month ASC, tier ASC, cycle ASC`
+
+// StmtSeats counts licence transactions by the month they
+// are finalized, i.e., licences created or renewed.
+const StmtSeats = `
+SELECT DATE_FORMAT(finalized_utc, '%Y-%m') AS month,
+	SUM(kind = 'create') AS new_count,
+	SUM(kind = 'renew') AS renewal_count
+FROM b2b.licence_transaction
+WHERE finalized_utc >= ? AND finalized_utc < ?
+GROUP BY month
+ORDER BY month ASC`
+
+// seatTaken tells whether a licence version takes a seat.
+// A reserved licence is already held by its reader, so the
+// reserve counts while the deferred grant that follows it does
+// not. Otherwise revoking a reserved licence would subtract a
+// seat that was never added.
+const seatTaken = `(action_kind = 'reserve'
+	OR (action_kind = 'grant'
+		AND IFNULL(JSON_UNQUOTE(JSON_EXTRACT(ante_change, '$.status')), '') <> 'reserved'))`
+
+// StmtActiveSeatsBefore counts seats taken prior to the
+// period. It takes the start of period only.
+const StmtActiveSeatsBefore = `
+SELECT IFNULL(SUM(` + seatTaken + `) - SUM(action_kind = 'revoke'), 0)
+FROM b2b.licence_version
+WHERE created_utc < ?`
+
+const StmtGrantChanges = `
+SELECT DATE_FORMAT(created_utc, '%Y-%m') AS month,
+	SUM(` + seatTaken + `) AS granted_count,
+	SUM(action_kind = 'revoke') AS revoked_count
+FROM b2b.licence_version
+WHERE created_utc >= ? AND created_utc < ?
+GROUP BY month
+ORDER BY month ASC`
+
+// StmtTopTeams ranks teams by amount paid. It takes the
+// date range and the limit.
+const StmtTopTeams = `
+SELECT o.team_id AS team_id,
+	IFNULL(t.org_name, '') AS org_name,
+	COUNT(*) AS order_count,
+	SUM(p.amount_paid) AS amount_paid
+FROM b2b.payment AS p
+	JOIN b2b.order AS o
+	ON p.order_id = o.id
+	LEFT JOIN b2b.team AS t
+	ON o.team_id = t.id
+WHERE p.approved_utc >= ? AND p.approved_utc < ?
+GROUP BY o.team_id, t.org_name
+ORDER BY amount_paid DESC
+LIMIT ?`
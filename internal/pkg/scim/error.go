@@ -0,0 +1,27 @@
+package scim
+
+import "strconv"
+
+const (
+	ErrTypeInvalidFilter = "invalidFilter"
+	ErrTypeInvalidValue  = "invalidValue"
+	ErrTypeUniqueness    = "uniqueness"
+	ErrTypeNoTarget      = "noTarget"
+)
+
+// Error is the error response defined by RFC 7644 section 3.12.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+func NewError(status int, scimType string, detail string) Error {
+	return Error{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	}
+}
@@ -0,0 +1,137 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultCount = 100
+	maxCount     = 200
+)
+
+// Paging converts SCIM's 1-based startIndex and count
+// query parameters to SQL offset and limit.
+type Paging struct {
+	StartIndex int64
+	Count      int64
+}
+
+func ParsePaging(startIndex, count string) Paging {
+	p := Paging{
+		StartIndex: 1,
+		Count:      defaultCount,
+	}
+
+	if n, err := strconv.ParseInt(startIndex, 10, 64); err == nil && n > 1 {
+		p.StartIndex = n
+	}
+
+	if n, err := strconv.ParseInt(count, 10, 64); err == nil && n >= 0 {
+		p.Count = n
+	}
+	if p.Count > maxCount {
+		p.Count = maxCount
+	}
+
+	return p
+}
+
+func (p Paging) Offset() int64 {
+	return p.StartIndex - 1
+}
+
+// ParseFilter supports the only filter used by identity
+// providers to look up an existing user:
+// userName eq "someone@example.org"
+// Returns the userName.
+func ParseFilter(f string) (string, error) {
+	parts := strings.SplitN(strings.TrimSpace(f), " ", 3)
+	if len(parts) != 3 ||
+		!strings.EqualFold(parts[0], "userName") ||
+		!strings.EqualFold(parts[1], "eq") {
+		return "", fmt.Errorf("unsupported filter: %s", f)
+	}
+
+	v, err := strconv.Unquote(strings.TrimSpace(parts[2]))
+	if err != nil {
+		return "", fmt.Errorf("invalid filter value: %s", parts[2])
+	}
+
+	return strings.ToLower(v), nil
+}
+
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// PatchOp is the body of a PATCH request.
+type PatchOp struct {
+	Schemas    []string    `json:"schemas"`
+	Operations []Operation `json:"Operations"`
+}
+
+// parseBool accepts both JSON boolean and string like "False"
+// which is sent by some identity providers.
+func parseBool(raw json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return false, errors.New("active must be a boolean")
+	}
+
+	return strconv.ParseBool(s)
+}
+
+// Active finds the value of the active attribute in the
+// operations, either set by path or contained in value
+// object without path.
+// The last one wins if set multiple times.
+func (p PatchOp) Active() (active bool, found bool, err error) {
+	for _, op := range p.Operations {
+		switch strings.ToLower(op.Op) {
+		case "replace", "add":
+		default:
+			continue
+		}
+
+		if strings.EqualFold(op.Path, "active") {
+			active, err = parseBool(op.Value)
+			if err != nil {
+				return false, false, err
+			}
+			found = true
+			continue
+		}
+
+		if op.Path != "" {
+			continue
+		}
+
+		var attrs map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attrs); err != nil {
+			continue
+		}
+		for k, v := range attrs {
+			if !strings.EqualFold(k, "active") {
+				continue
+			}
+			active, err = parseBool(v)
+			if err != nil {
+				return false, false, err
+			}
+			found = true
+		}
+	}
+
+	return active, found, nil
+}
@@ -0,0 +1,132 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "userName eq",
+			filter: `userName eq "Alice@Example.com"`,
+			want:   "alice@example.com",
+		},
+		{
+			name:   "Case insensitive attribute",
+			filter: `username EQ "bob@example.com"`,
+			want:   "bob@example.com",
+		},
+		{
+			name:    "Unsupported operator",
+			filter:  `userName co "alice"`,
+			wantErr: true,
+		},
+		{
+			name:    "Unquoted value",
+			filter:  `userName eq alice`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFilter(tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFilter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseFilter() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchOp_Active(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantActive bool
+		wantFound  bool
+		wantErr    bool
+	}{
+		{
+			name:       "Path active",
+			body:       `{"Operations":[{"op":"replace","path":"active","value":false}]}`,
+			wantActive: false,
+			wantFound:  true,
+		},
+		{
+			name:       "Value object",
+			body:       `{"Operations":[{"op":"Replace","value":{"active":true}}]}`,
+			wantActive: true,
+			wantFound:  true,
+		},
+		{
+			name:       "String boolean",
+			body:       `{"Operations":[{"op":"Replace","path":"active","value":"False"}]}`,
+			wantActive: false,
+			wantFound:  true,
+		},
+		{
+			name:      "Other attributes",
+			body:      `{"Operations":[{"op":"replace","path":"displayName","value":"Alice"}]}`,
+			wantFound: false,
+		},
+		{
+			name:    "Invalid value",
+			body:    `{"Operations":[{"op":"replace","path":"active","value":"maybe"}]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p PatchOp
+			if err := json.Unmarshal([]byte(tt.body), &p); err != nil {
+				t.Fatal(err)
+			}
+
+			active, found, err := p.Active()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Active() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if active != tt.wantActive || found != tt.wantFound {
+				t.Errorf("Active() got = %v, %v, want %v, %v", active, found, tt.wantActive, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestUser_StafferParams(t *testing.T) {
+	var u User
+	err := json.Unmarshal([]byte(`{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"userName": "alice",
+		"name": {"givenName": "Alice", "familyName": "Liddell"},
+		"emails": [{"value": "bob@example.com"}, {"value": "Alice@Example.com", "primary": true}]
+	}`), &u)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := u.StafferParams()
+	if ve := p.Validate(); ve != nil {
+		t.Fatal(ve)
+	}
+
+	if p.Email != "alice@example.com" {
+		t.Errorf("got email %s", p.Email)
+	}
+	if p.Name.String != "Alice Liddell" {
+		t.Errorf("got name %s", p.Name.String)
+	}
+	if !u.IsActive() {
+		t.Error("should be active when omitted")
+	}
+}
@@ -0,0 +1,163 @@
+package scim
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/guregu/null"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaPatchOp      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+)
+
+// ContentType is used by all responses.
+const ContentType = "application/scim+json"
+
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary"`
+}
+
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Location     string `json:"location,omitempty"`
+}
+
+// User is the SCIM representation of a Staffer.
+// Only the attributes we could map to a Staffer are supported.
+type User struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	ExternalID  string   `json:"externalId,omitempty"`
+	UserName    string   `json:"userName"`
+	Name        *Name    `json:"name,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Emails      []Email  `json:"emails,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+	Meta        *Meta    `json:"meta,omitempty"`
+}
+
+// NewUser converts a staffer. baseURL is the url of the
+// Users endpoint, used to build meta.location.
+func NewUser(s licence.Staffer, baseURL string) User {
+	id := strconv.FormatInt(s.ID, 10)
+	active := s.Active
+
+	u := User{
+		Schemas:     []string{SchemaUser},
+		ID:          id,
+		UserName:    s.Email,
+		DisplayName: s.Name.String,
+		Emails: []Email{
+			{
+				Value:   s.Email,
+				Type:    "work",
+				Primary: true,
+			},
+		},
+		Active: &active,
+		Meta: &Meta{
+			ResourceType: "User",
+			Created:      s.CreatedUTC.Format(time.RFC3339),
+			Location:     strings.TrimSuffix(baseURL, "/") + "/" + id,
+		},
+	}
+
+	if s.Name.Valid {
+		u.Name = &Name{
+			Formatted: s.Name.String,
+		}
+	}
+
+	if !s.UpdatedUTC.IsZero() {
+		u.Meta.LastModified = s.UpdatedUTC.Format(time.RFC3339)
+	}
+
+	return u
+}
+
+// Email picks the primary email, or the first one,
+// falling back to userName which is usually an email.
+func (u User) Email() string {
+	for _, e := range u.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+
+	return u.UserName
+}
+
+// IsActive defaults to true if identity provider omitted it.
+func (u User) IsActive() bool {
+	return u.Active == nil || *u.Active
+}
+
+func (u User) displayName() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+
+	if u.Name == nil {
+		return ""
+	}
+
+	if u.Name.Formatted != "" {
+		return u.Name.Formatted
+	}
+
+	return strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+}
+
+// StafferParams converts a user to the input of a staffer.
+// Call Validate on the result.
+func (u User) StafferParams() input.StafferParams {
+	name := u.displayName()
+
+	return input.StafferParams{
+		Email: u.Email(),
+		Name:  null.NewString(name, name != ""),
+	}
+}
+
+// ListResponse wraps a page of users.
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int64    `json:"totalResults"`
+	StartIndex   int64    `json:"startIndex"`
+	ItemsPerPage int64    `json:"itemsPerPage"`
+	Resources    []User   `json:"Resources"`
+}
+
+func NewListResponse(users []User, total int64, startIndex int64) ListResponse {
+	if users == nil {
+		users = []User{}
+	}
+
+	return ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: int64(len(users)),
+		Resources:    users,
+	}
+}
@@ -19,3 +19,26 @@ func B2BVerifyAdminURL(token string) string {
 func B2BVerifyInvitationURL(token string) string {
 	return B2BBaseURL + "/grant-licence/" + token
 }
+
+// ReaderLicenceRequestURL is used to verify the company
+// email a reader used to request a licence.
+func ReaderLicenceRequestURL(token string) string {
+	return ReaderBaseURL + "/licence-request/" + token
+}
+
+// ReaderRedeemURL is encoded into QR image of a redemption code.
+func ReaderRedeemURL(code string) string {
+	return ReaderBaseURL + "/redeem/" + code
+}
+
+// B2BAdminInvitationURL is sent to an email invited to
+// become a team's co-admin.
+func B2BAdminInvitationURL(token string) string {
+	return B2BBaseURL + "/join-team/" + token
+}
+
+// B2BUnlockAccountURL is sent to an admin whose account is
+// locked after repeated failed logins.
+func B2BUnlockAccountURL(token string) string {
+	return B2BBaseURL + "/unlock/" + token
+}
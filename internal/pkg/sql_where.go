@@ -1,5 +1,7 @@
 package pkg
 
+import "strings"
+
 type SQLWhere struct {
 	Clause string
 	Values []interface{}
@@ -9,3 +11,11 @@ func (w SQLWhere) AddValues(v ...interface{}) SQLWhere {
 	w.Values = append(w.Values, v...)
 	return w
 }
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike prevents user input from being treated as
+// wildcards in a LIKE pattern.
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
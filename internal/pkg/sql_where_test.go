@@ -0,0 +1,20 @@
+package pkg
+
+import "testing"
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"ftchinese.com", "ftchinese.com"},
+		{"ft_chinese.com", `ft\_chinese.com`},
+		{"100%", `100\%`},
+		{`a\b`, `a\\b`},
+	}
+	for _, tt := range tests {
+		if got := EscapeLike(tt.in); got != tt.want {
+			t.Errorf("EscapeLike(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/FTChinese/ftacademy/pkg/xhttp"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderSignature = "X-FTA-Signature"
+	HeaderEvent     = "X-FTA-Event"
+	HeaderDelivery  = "X-FTA-Delivery"
+)
+
+// maxResponseBody limits how much of the response is logged.
+const maxResponseBody = 1024
+
+// Sign computes HMAC-SHA256 of timestamp and body so that
+// receivers could both verify the sender and reject replay.
+// The header value has the form t=<unix>,v1=<hex>.
+func Sign(secret string, ts int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(ts, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return fmt.Sprintf("t=%d,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+}
+
+type AttemptResult struct {
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+func (r AttemptResult) Ok() bool {
+	return r.Err == nil && r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// Client posts deliveries to endpoints.
+type Client struct {
+	http *http.Client
+}
+
+// NewClient creates a client that never connects to
+// non-public addresses, even if an endpoint's host is
+// changed to resolve to one after registered.
+func NewClient(timeout time.Duration) Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: xhttp.PublicDialControl,
+	}
+
+	return Client{
+		http: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+			},
+		},
+	}
+}
+
+func (c Client) Send(ep Endpoint, d Delivery) AttemptResult {
+	body := []byte(d.Payload)
+
+	req, err := http.NewRequest(http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		return AttemptResult{Err: err}
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(d.EventType))
+	req.Header.Set(HeaderDelivery, d.ID)
+	req.Header.Set(HeaderSignature, Sign(ep.Secret, time.Now().Unix(), body))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return AttemptResult{Err: err}
+	}
+	defer resp.Body.Close()
+
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+
+	return AttemptResult{
+		StatusCode: resp.StatusCode,
+		Body:       string(b),
+	}
+}
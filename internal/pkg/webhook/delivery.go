@@ -0,0 +1,156 @@
+package webhook
+
+import (
+	"encoding/json"
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/ids"
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/guregu/null"
+	"time"
+)
+
+// MaxAttempts is the number of tries before a delivery
+// is given up.
+const MaxAttempts = 8
+
+// Lease is how long a delivery is hidden from the retry
+// worker after claimed, so that it is not sent twice while
+// an attempt is in progress.
+const Lease = 5 * time.Minute
+
+const (
+	backoffBase = time.Minute
+	backoffMax  = 12 * time.Hour
+)
+
+// Backoff calculates how long to wait after the nth
+// failed attempt: 1m, 2m, 4m... capped at 12h.
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+
+	d := backoffBase
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= backoffMax {
+			return backoffMax
+		}
+	}
+
+	return d
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Delivery logs sending an event to an endpoint.
+// It is retried until succeeded or MaxAttempts reached.
+type Delivery struct {
+	ID             string         `json:"id" db:"delivery_id"`
+	EndpointID     string         `json:"endpointId" db:"endpoint_id"`
+	EventID        string         `json:"eventId" db:"event_id"`
+	EventType      EventType      `json:"eventType" db:"event_type"`
+	TeamID         string         `json:"teamId" db:"team_id"`
+	Payload        string         `json:"payload" db:"payload"`
+	Status         DeliveryStatus `json:"status" db:"delivery_status"`
+	Attempts       int            `json:"attempts" db:"attempts"`
+	ResponseCode   null.Int       `json:"responseCode" db:"response_code"`
+	ResponseBody   null.String    `json:"responseBody" db:"response_body"`
+	LastError      null.String    `json:"lastError" db:"last_error"`
+	NextAttemptUTC chrono.Time    `json:"nextAttemptUtc" db:"next_attempt_utc"`
+	CreatedUTC     chrono.Time    `json:"createdUtc" db:"created_utc"`
+	UpdatedUTC     chrono.Time    `json:"updatedUtc" db:"updated_utc"`
+}
+
+// NewDelivery serializes an event for an endpoint.
+// It is leased right away since the caller attempts it
+// immediately; the worker picks it up only if that attempt
+// never finishes.
+func NewDelivery(ep Endpoint, evt Event) (Delivery, error) {
+	b, err := json.Marshal(evt)
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	now := time.Now()
+
+	return Delivery{
+		ID:             ids.WebhookDeliveryID(),
+		EndpointID:     ep.ID,
+		EventID:        evt.ID,
+		EventType:      evt.Type,
+		TeamID:         evt.TeamID,
+		Payload:        string(b),
+		Status:         DeliveryPending,
+		Attempts:       0,
+		NextAttemptUTC: chrono.TimeUTCFrom(now.Add(Lease)),
+		CreatedUTC:     chrono.TimeUTCFrom(now),
+		UpdatedUTC:     chrono.TimeUTCFrom(now),
+	}, nil
+}
+
+// Attempted records the outcome of a try and schedules
+// the next one if it failed.
+func (d Delivery) Attempted(r AttemptResult, now time.Time) Delivery {
+	d.Attempts++
+	d.UpdatedUTC = chrono.TimeUTCFrom(now)
+
+	if r.StatusCode > 0 {
+		d.ResponseCode = null.IntFrom(int64(r.StatusCode))
+	} else {
+		d.ResponseCode = null.Int{}
+	}
+	d.ResponseBody = null.NewString(r.Body, r.Body != "")
+
+	if r.Err != nil {
+		d.LastError = null.StringFrom(r.Err.Error())
+	} else {
+		d.LastError = null.String{}
+	}
+
+	switch {
+	case r.Ok():
+		d.Status = DeliverySucceeded
+		d.NextAttemptUTC = chrono.Time{}
+
+	case d.Attempts >= MaxAttempts:
+		d.Status = DeliveryFailed
+		d.NextAttemptUTC = chrono.Time{}
+
+	default:
+		d.Status = DeliveryPending
+		d.NextAttemptUTC = chrono.TimeUTCFrom(now.Add(Backoff(d.Attempts)))
+	}
+
+	return d
+}
+
+// Leased hides a claimed delivery from other workers
+// until the attempt finishes or the lease expires.
+func (d Delivery) Leased(now time.Time) Delivery {
+	d.NextAttemptUTC = chrono.TimeUTCFrom(now.Add(Lease))
+	d.UpdatedUTC = chrono.TimeUTCFrom(now)
+
+	return d
+}
+
+// Abandoned stops retrying a delivery.
+func (d Delivery) Abandoned(reason string, now time.Time) Delivery {
+	d.Status = DeliveryFailed
+	d.LastError = null.StringFrom(reason)
+	d.NextAttemptUTC = chrono.Time{}
+	d.UpdatedUTC = chrono.TimeUTCFrom(now)
+
+	return d
+}
+
+type DeliveryList struct {
+	pkg.PagedList
+	Data []Delivery `json:"data"`
+}
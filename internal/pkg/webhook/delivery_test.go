@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{4, 8 * time.Minute},
+		{20, 12 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestDelivery_Attempted(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		attempts int
+		result   AttemptResult
+		want     DeliveryStatus
+	}{
+		{
+			name:     "Succeeded",
+			attempts: 0,
+			result:   AttemptResult{StatusCode: 204},
+			want:     DeliverySucceeded,
+		},
+		{
+			name:     "Server error retried",
+			attempts: 0,
+			result:   AttemptResult{StatusCode: 500},
+			want:     DeliveryPending,
+		},
+		{
+			name:     "Network error retried",
+			attempts: 2,
+			result:   AttemptResult{Err: errors.New("timeout")},
+			want:     DeliveryPending,
+		},
+		{
+			name:     "Given up",
+			attempts: MaxAttempts - 1,
+			result:   AttemptResult{StatusCode: 502},
+			want:     DeliveryFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Delivery{Attempts: tt.attempts}.Attempted(tt.result, now)
+
+			if d.Status != tt.want {
+				t.Errorf("Attempted() status = %s, want %s", d.Status, tt.want)
+			}
+
+			if d.Attempts != tt.attempts+1 {
+				t.Errorf("Attempted() attempts = %d, want %d", d.Attempts, tt.attempts+1)
+			}
+
+			if d.Status == DeliveryPending && d.NextAttemptUTC.Unix() != now.Add(Backoff(d.Attempts)).Unix() {
+				t.Errorf("Attempted() next attempt = %v", d.NextAttemptUTC)
+			}
+		})
+	}
+}
+
+func TestNewDelivery_Leased(t *testing.T) {
+	d, err := NewDelivery(Endpoint{ID: "ep_1"}, Event{ID: "evt_1", Type: EventOrderPaid})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Not due for the retry worker while Emit is attempting it.
+	if !d.NextAttemptUTC.After(time.Now().Add(Lease - time.Minute)) {
+		t.Errorf("NewDelivery() next attempt = %v, should be leased", d.NextAttemptUTC)
+	}
+
+	now := time.Now()
+	d = d.Leased(now)
+	if d.NextAttemptUTC.Unix() != now.Add(Lease).Unix() {
+		t.Errorf("Leased() next attempt = %v", d.NextAttemptUTC)
+	}
+}
+
+func TestSign(t *testing.T) {
+	a := Sign("secret", 1600000000, []byte(`{"id":"evt_1"}`))
+	b := Sign("secret", 1600000000, []byte(`{"id":"evt_2"}`))
+
+	if a == b {
+		t.Error("signature should differ for different body")
+	}
+
+	want := "t=1600000000,v1="
+	if a[:len(want)] != want {
+		t.Errorf("Sign() = %s, want prefix %s", a, want)
+	}
+}
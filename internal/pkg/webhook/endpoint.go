@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/ids"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/FTChinese/go-rest/rand"
+	"github.com/guregu/null"
+)
+
+// Endpoint is a url registered to receive events.
+// TeamID is null for endpoints registered in CMS,
+// which receive events of all teams.
+type Endpoint struct {
+	ID          string      `json:"id" db:"endpoint_id"`
+	TeamID      null.String `json:"teamId" db:"team_id"`
+	AdminID     null.String `json:"adminId" db:"admin_id"`
+	URL         string      `json:"url" db:"endpoint_url"`
+	Secret      string      `json:"-" db:"signing_secret"`
+	Events      EventList   `json:"events" db:"events"`
+	Description string      `json:"description" db:"endpoint_desc"`
+	Active      bool        `json:"active" db:"is_active"`
+	admin.RowTime
+}
+
+// NewEndpoint creates an endpoint together with the secret
+// used to sign payload.
+func NewEndpoint(params input.WebhookParams, teamID, adminID null.String) (Endpoint, error) {
+	secret, err := rand.Hex(24)
+	if err != nil {
+		return Endpoint{}, err
+	}
+
+	return Endpoint{
+		ID:          ids.WebhookEndpointID(),
+		TeamID:      teamID,
+		AdminID:     adminID,
+		URL:         params.URL,
+		Secret:      "whsec_" + secret,
+		Events:      NewEventList(params.Events),
+		Description: params.Description,
+		Active:      true,
+		RowTime:     admin.NewRowTime(),
+	}, nil
+}
+
+func (e Endpoint) Update(params input.WebhookParams) Endpoint {
+	e.URL = params.URL
+	e.Events = NewEventList(params.Events)
+	e.Description = params.Description
+	e.Active = params.Active
+	e.UpdatedUTC = chrono.TimeNow()
+
+	return e
+}
+
+// EndpointCreated shows the signing secret only once.
+type EndpointCreated struct {
+	Endpoint
+	Secret string `json:"secret"`
+}
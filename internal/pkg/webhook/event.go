@@ -0,0 +1,104 @@
+package webhook
+
+import (
+	"database/sql/driver"
+	"errors"
+	"github.com/FTChinese/ftacademy/internal/pkg/ids"
+	"github.com/FTChinese/go-rest/chrono"
+	"strings"
+)
+
+type EventType string
+
+const (
+	EventOrderCreated      EventType = "order.created"
+	EventOrderPaid         EventType = "order.paid"
+	EventInvitationCreated EventType = "invitation.created"
+	EventLicenceGranted    EventType = "licence.granted"
+	EventLicenceRevoked    EventType = "licence.revoked"
+	EventLicenceRenewed    EventType = "licence.renewed"
+)
+
+var eventTypes = map[EventType]bool{
+	EventOrderCreated:      true,
+	EventOrderPaid:         true,
+	EventInvitationCreated: true,
+	EventLicenceGranted:    true,
+	EventLicenceRevoked:    true,
+	EventLicenceRenewed:    true,
+}
+
+func IsValidEvent(s string) bool {
+	return eventTypes[EventType(s)]
+}
+
+// EventList is saved as comma separated string so that
+// FIND_IN_SET could be used to find subscribers.
+type EventList []EventType
+
+func NewEventList(s []string) EventList {
+	var l = make(EventList, 0, len(s))
+	for _, v := range s {
+		l = append(l, EventType(v))
+	}
+
+	return l
+}
+
+func (l EventList) Has(t EventType) bool {
+	for _, v := range l {
+		if v == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l EventList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+
+	var s = make([]string, 0, len(l))
+	for _, v := range l {
+		s = append(s, string(v))
+	}
+
+	return strings.Join(s, ","), nil
+}
+
+func (l *EventList) Scan(src interface{}) error {
+	if src == nil {
+		*l = EventList{}
+		return nil
+	}
+
+	switch s := src.(type) {
+	case []byte:
+		*l = NewEventList(strings.Split(string(s), ","))
+		return nil
+
+	default:
+		return errors.New("incompatible type to scan")
+	}
+}
+
+// Event is the body posted to endpoints.
+type Event struct {
+	ID         string      `json:"id"`
+	Type       EventType   `json:"type"`
+	TeamID     string      `json:"teamId"`
+	CreatedUTC chrono.Time `json:"createdUtc"`
+	Data       interface{} `json:"data"`
+}
+
+func NewEvent(t EventType, teamID string, data interface{}) Event {
+	return Event{
+		ID:         ids.WebhookEventID(),
+		Type:       t,
+		TeamID:     teamID,
+		CreatedUTC: chrono.TimeNow(),
+		Data:       data,
+	}
+}
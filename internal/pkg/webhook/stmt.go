@@ -0,0 +1,129 @@
+package webhook
+
+const StmtCreateEndpoint = `
+INSERT INTO b2b.webhook_endpoint
+SET endpoint_id = :endpoint_id,
+	team_id = :team_id,
+	admin_id = :admin_id,
+	endpoint_url = :endpoint_url,
+	signing_secret = :signing_secret,
+	events = :events,
+	endpoint_desc = :endpoint_desc,
+	is_active = :is_active,
+	created_utc = :created_utc,
+	updated_utc = :updated_utc`
+
+const colEndpoint = `
+SELECT endpoint_id,
+	team_id,
+	admin_id,
+	endpoint_url,
+	signing_secret,
+	events,
+	endpoint_desc,
+	is_active,
+	created_utc,
+	updated_utc
+FROM b2b.webhook_endpoint
+`
+
+// Team id is null for endpoints managed in CMS.
+// The null-safe equal operator matches both cases.
+const StmtListEndpoints = colEndpoint + `
+WHERE team_id <=> ?
+ORDER BY created_utc DESC`
+
+const StmtEndpoint = colEndpoint + `
+WHERE endpoint_id = ?
+	AND team_id <=> ?
+LIMIT 1`
+
+const StmtRetrieveEndpoint = colEndpoint + `
+WHERE endpoint_id = ?
+LIMIT 1`
+
+// StmtSubscribedEndpoints finds active endpoints of a team
+// and those of CMS subscribing to an event.
+const StmtSubscribedEndpoints = colEndpoint + `
+WHERE is_active = TRUE
+	AND (team_id = ? OR team_id IS NULL)
+	AND FIND_IN_SET(?, events) > 0`
+
+const StmtUpdateEndpoint = `
+UPDATE b2b.webhook_endpoint
+SET endpoint_url = :endpoint_url,
+	events = :events,
+	endpoint_desc = :endpoint_desc,
+	is_active = :is_active,
+	updated_utc = :updated_utc
+WHERE endpoint_id = :endpoint_id
+LIMIT 1`
+
+const StmtDeleteEndpoint = `
+DELETE FROM b2b.webhook_endpoint
+WHERE endpoint_id = ?
+	AND team_id <=> ?
+LIMIT 1`
+
+const StmtCreateDelivery = `
+INSERT INTO b2b.webhook_delivery
+SET delivery_id = :delivery_id,
+	endpoint_id = :endpoint_id,
+	event_id = :event_id,
+	event_type = :event_type,
+	team_id = :team_id,
+	payload = :payload,
+	delivery_status = :delivery_status,
+	attempts = :attempts,
+	next_attempt_utc = :next_attempt_utc,
+	created_utc = :created_utc,
+	updated_utc = :updated_utc`
+
+const StmtUpdateDelivery = `
+UPDATE b2b.webhook_delivery
+SET delivery_status = :delivery_status,
+	attempts = :attempts,
+	response_code = :response_code,
+	response_body = :response_body,
+	last_error = :last_error,
+	next_attempt_utc = :next_attempt_utc,
+	updated_utc = :updated_utc
+WHERE delivery_id = :delivery_id
+LIMIT 1`
+
+const colDelivery = `
+SELECT delivery_id,
+	endpoint_id,
+	event_id,
+	event_type,
+	team_id,
+	payload,
+	delivery_status,
+	attempts,
+	response_code,
+	response_body,
+	last_error,
+	next_attempt_utc,
+	created_utc,
+	updated_utc
+FROM b2b.webhook_delivery
+`
+
+const StmtListDeliveries = colDelivery + `
+WHERE endpoint_id = ?
+ORDER BY created_utc DESC
+LIMIT ? OFFSET ?`
+
+const StmtCountDeliveries = `
+SELECT COUNT(*) AS row_count
+FROM b2b.webhook_delivery
+WHERE endpoint_id = ?`
+
+// StmtDueDeliveries locks pending deliveries whose retry
+// time is reached. Rows locked by another instance are skipped.
+const StmtDueDeliveries = colDelivery + `
+WHERE delivery_status = 'pending'
+	AND next_attempt_utc <= UTC_TIMESTAMP()
+ORDER BY next_attempt_utc ASC
+LIMIT ?
+FOR UPDATE SKIP LOCKED`
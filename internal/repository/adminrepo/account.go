@@ -39,9 +39,12 @@ func (env Env) UpdateName(account admin.BaseAccount) error {
 // UpdatePassword updates reader's password.
 // This is used both by resetting password if forgotten and updating password after logged in.
 func (env Env) UpdatePassword(p input.PasswordUpdateParams) error {
+	h, err := admin.NewPasswordHashed(p.ID, p.New)
+	if err != nil {
+		return err
+	}
 
-	_, err := env.DBs.Write.NamedExec(admin.StmtUpdatePassword,
-		p)
+	_, err = env.DBs.Write.NamedExec(admin.StmtUpdatePassword, h)
 
 	if err != nil {
 		return err
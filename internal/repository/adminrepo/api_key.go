@@ -0,0 +1,67 @@
+package adminrepo
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+)
+
+func (env Env) CreateAPIKey(k admin.APIKey) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtCreateAPIKey, k)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListAPIKeys shows all keys of a team, including revoked ones.
+func (env Env) ListAPIKeys(teamID string) ([]admin.APIKey, error) {
+	var keys = make([]admin.APIKey, 0)
+	err := env.DBs.Read.Select(&keys, admin.StmtListAPIKeys, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (env Env) LoadAPIKey(id, teamID string) (admin.APIKey, error) {
+	var k admin.APIKey
+	err := env.DBs.Read.Get(&k, admin.StmtAPIKey, id, teamID)
+	if err != nil {
+		return admin.APIKey{}, err
+	}
+
+	return k, nil
+}
+
+// APIKeyByPlain finds a key by its plain text value sent in
+// Authorization header.
+func (env Env) APIKeyByPlain(key string) (admin.APIKey, error) {
+	var k admin.APIKey
+	err := env.DBs.Read.Get(&k, admin.StmtAPIKeyByHash, admin.HashToken(key))
+	if err != nil {
+		return admin.APIKey{}, err
+	}
+
+	return k, nil
+}
+
+// UpdateAPIKey saves changed name, scopes or revocation.
+func (env Env) UpdateAPIKey(k admin.APIKey) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtUpdateAPIKey, k)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// TouchAPIKey records the last time a key is used.
+func (env Env) TouchAPIKey(id string) error {
+	_, err := env.DBs.Write.Exec(admin.StmtTouchAPIKey, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
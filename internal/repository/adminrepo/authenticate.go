@@ -10,32 +10,60 @@ import (
 // email does not exists.
 // If no error returned, the AuthResult.PasswordMatched
 // field indicates whether the password is correct.
+// A legacy SHA-256 hash is replaced by argon2id upon
+// successful login.
 func (env Env) Authenticate(params input.Credentials) (admin.AuthResult, error) {
 	var r admin.AuthResult
 	err := env.DBs.Read.Get(&r,
 		admin.StmtVerifyPasswordByEmail,
-		params.Password,
 		params.Email)
 
 	if err != nil {
 		return r, err
 	}
 
+	r, err = r.Verify(params.Password)
+	if err != nil {
+		return r, err
+	}
+
+	if r.NeedsUpgrade {
+		env.upgradePasswordHash(r.AdminID, params.Password)
+	}
+
 	return r, nil
 }
 
+// upgradePasswordHash re-hashes a password already verified.
+// Failure is only logged since user could still log in with
+// the old hash.
+func (env Env) upgradePasswordHash(id, password string) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	h, err := admin.NewPasswordHashed(id, password)
+	if err != nil {
+		sugar.Error(err)
+		return
+	}
+
+	_, err = env.DBs.Write.NamedExec(admin.StmtUpdatePassword, h)
+	if err != nil {
+		sugar.Error(err)
+	}
+}
+
 // VerifyPassword when user is trying to change it.
 func (env Env) VerifyPassword(params input.PasswordUpdateParams) (admin.AuthResult, error) {
 	var r admin.AuthResult
 	err := env.DBs.Read.Get(
 		&r,
 		admin.StmtVerifyPasswordByID,
-		params.Old,
 		params.ID)
 
 	if err != nil {
 		return admin.AuthResult{}, err
 	}
 
-	return r, nil
+	return r.Verify(params.Old)
 }
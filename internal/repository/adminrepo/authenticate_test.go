@@ -6,13 +6,13 @@ import (
 	"github.com/FTChinese/ftacademy/internal/pkg/input"
 	"github.com/FTChinese/ftacademy/pkg/db"
 	"go.uber.org/zap/zaptest"
-	"reflect"
 	"testing"
 )
 
 func TestEnv_Authenticate(t *testing.T) {
 	env := NewEnv(db.MockMySQL(), zaptest.NewLogger(t))
-	account := mock.NewAdmin().Account
+	a := mock.NewAdmin()
+	account := a.Account
 	_ = env.SignUp(account)
 
 	type args struct {
@@ -29,7 +29,7 @@ func TestEnv_Authenticate(t *testing.T) {
 			args: args{
 				params: input.Credentials{
 					Email:    account.Email,
-					Password: account.Password,
+					Password: a.Password,
 				},
 			},
 			want: admin.AuthResult{
@@ -47,7 +47,7 @@ func TestEnv_Authenticate(t *testing.T) {
 				t.Errorf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
+			if got.AdminID != tt.want.AdminID || got.PasswordMatched != tt.want.PasswordMatched {
 				t.Errorf("Authenticate() got = %v, want %v", got, tt.want)
 			}
 		})
@@ -56,7 +56,8 @@ func TestEnv_Authenticate(t *testing.T) {
 
 func TestEnv_VerifyPassword(t *testing.T) {
 	env := NewEnv(db.MockMySQL(), zaptest.NewLogger(t))
-	account := mock.NewAdmin().Account
+	a := mock.NewAdmin()
+	account := a.Account
 	_ = env.SignUp(account)
 
 	type args struct {
@@ -73,7 +74,7 @@ func TestEnv_VerifyPassword(t *testing.T) {
 			args: args{
 				params: input.PasswordUpdateParams{
 					ID:  account.ID,
-					Old: account.Password,
+					Old: a.Password,
 					New: "",
 				},
 			},
@@ -92,7 +93,7 @@ func TestEnv_VerifyPassword(t *testing.T) {
 				t.Errorf("VerifyPassword() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
+			if got.AdminID != tt.want.AdminID || got.PasswordMatched != tt.want.PasswordMatched {
 				t.Errorf("VerifyPassword() got = %v, want %v", got, tt.want)
 			}
 		})
@@ -1,18 +1,19 @@
 package adminrepo
 
 import (
+	"github.com/FTChinese/ftacademy/internal/repository"
 	"github.com/FTChinese/ftacademy/pkg/db"
 	"go.uber.org/zap"
 )
 
 type Env struct {
-	DBs    db.ReadWriteMyDBs
+	repository.SharedRepo
 	logger *zap.Logger
 }
 
 func NewEnv(dbs db.ReadWriteMyDBs, logger *zap.Logger) Env {
 	return Env{
-		DBs:    dbs,
-		logger: logger,
+		SharedRepo: repository.NewSharedRepo(dbs),
+		logger:     logger,
 	}
 }
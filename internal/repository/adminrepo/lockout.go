@@ -0,0 +1,44 @@
+package adminrepo
+
+import "github.com/FTChinese/ftacademy/internal/pkg/admin"
+
+// CreateLockout locks an account and records the event.
+func (env Env) CreateLockout(l admin.Lockout) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtCreateLockout, l)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ActiveLockout finds the lock still in effect for an admin.
+// sql.ErrNoRows indicates the account is not locked.
+func (env Env) ActiveLockout(adminID string) (admin.Lockout, error) {
+	var l admin.Lockout
+	err := env.DBs.Read.Get(&l, admin.StmtActiveLockout, adminID)
+	if err != nil {
+		return admin.Lockout{}, err
+	}
+
+	return l, nil
+}
+
+func (env Env) LockoutByToken(token string) (admin.Lockout, error) {
+	var l admin.Lockout
+	err := env.DBs.Read.Get(&l, admin.StmtLockoutByToken, token)
+	if err != nil {
+		return admin.Lockout{}, err
+	}
+
+	return l, nil
+}
+
+func (env Env) UnlockAccount(l admin.Lockout) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtUnlockAccount, l)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
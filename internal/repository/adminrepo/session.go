@@ -0,0 +1,135 @@
+package adminrepo
+
+import (
+	"database/sql"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/patrickmn/go-cache"
+	"time"
+)
+
+func (env Env) CreateSession(s admin.Session) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtCreateSession, s)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (env Env) LoadSession(id string) (admin.Session, error) {
+	var s admin.Session
+	err := env.DBs.Read.Get(&s, admin.StmtSession, id)
+	if err != nil {
+		return admin.Session{}, err
+	}
+
+	return s, nil
+}
+
+// ListActiveSessions lists sessions an admin is logged in.
+func (env Env) ListActiveSessions(adminID string) ([]admin.Session, error) {
+	var list = make([]admin.Session, 0)
+	err := env.DBs.Read.Select(&list, admin.StmtActiveSessions, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// RotateSession saves the new refresh token.
+// Returns sql.ErrNoRows if the previous token is no longer
+// current, i.e., it is used twice.
+func (env Env) RotateSession(s admin.Session) error {
+	result, err := env.DBs.Write.NamedExec(admin.StmtRotateSession, s)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RevokeSession returns sql.ErrNoRows if the session does
+// not belong to the admin or is already revoked.
+func (env Env) RevokeSession(adminID, sessionID string) error {
+	result, err := env.DBs.Write.Exec(admin.StmtRevokeSession, sessionID, adminID)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// IsSessionRevoked treats a session not found as revoked.
+func (env Env) IsSessionRevoked(id string) (bool, error) {
+	var revoked bool
+	err := env.DBs.Read.Get(&revoked, admin.StmtSessionRevoked, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return true, nil
+		}
+		return false, err
+	}
+
+	return revoked, nil
+}
+
+// RevocationList checks sessions against db for JWTGuard.
+// Since every authenticated request hits it, results are
+// cached shortly. Sessions revoked by this instance take
+// effect immediately while other instances follow within
+// the cache duration.
+type RevocationList struct {
+	env   Env
+	cache *cache.Cache
+}
+
+func NewRevocationList(env Env) RevocationList {
+	return RevocationList{
+		env:   env,
+		cache: cache.New(30*time.Second, time.Minute),
+	}
+}
+
+func (l RevocationList) IsRevoked(sessionID string) bool {
+	if x, ok := l.cache.Get(sessionID); ok {
+		return x.(bool)
+	}
+
+	revoked, err := l.env.IsSessionRevoked(sessionID)
+	// Access tokens are short-lived so db error should not
+	// lock out everyone.
+	if err != nil {
+		defer l.env.logger.Sync()
+		l.env.logger.Sugar().Error(err)
+		return false
+	}
+
+	l.cache.Set(sessionID, revoked, cache.DefaultExpiration)
+
+	return revoked
+}
+
+// Revoked marks sessions revoked locally.
+func (l RevocationList) Revoked(ids ...string) {
+	for _, id := range ids {
+		// Kept until any access token of it expires.
+		l.cache.Set(id, true, 20*time.Minute)
+	}
+}
@@ -17,13 +17,15 @@ func (env Env) CreateTeam(t admin.Team) error {
 	return nil
 }
 
-// LoadTeam loads a team by id belong to an admin.
+// LoadTeam loads a team by id belong to an admin,
+// either as owner or co-admin.
 func (env Env) LoadTeam(teamID, adminID string) (admin.Team, error) {
 	var t admin.Team
 	err := env.DBs.Read.Get(
 		&t,
 		admin.BuildStmtLoadTeam(true),
 		teamID,
+		adminID,
 		adminID)
 	if err != nil {
 		return admin.Team{}, err
@@ -42,3 +44,15 @@ func (env Env) UpdateTeam(t admin.Team) error {
 
 	return nil
 }
+
+// RetrieveTeam loads a team without checking ownership.
+// Used to show team name to people invited.
+func (env Env) RetrieveTeam(teamID string) (admin.Team, error) {
+	var t admin.Team
+	err := env.DBs.Read.Get(&t, admin.BuildStmtLoadTeam(false), teamID)
+	if err != nil {
+		return admin.Team{}, err
+	}
+
+	return t, nil
+}
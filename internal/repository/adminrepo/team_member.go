@@ -0,0 +1,161 @@
+package adminrepo
+
+import (
+	"errors"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+)
+
+var (
+	ErrInvitationInvalid = errors.New("invitation is not found, expired or already used")
+	ErrNotInvitee        = errors.New("the invitation is not sent to this account")
+	ErrAlreadyInTeam     = errors.New("account already belongs to a team")
+)
+
+// ListTeamMembers retrieves owner and co-admins of a team.
+func (env Env) ListTeamMembers(teamID string) ([]admin.TeamMember, error) {
+	var list = make([]admin.TeamMember, 0)
+	err := env.DBs.Read.Select(&list, admin.StmtListTeamMembers, teamID, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// LoadTeamMember retrieves a co-admin. Owner is not included.
+func (env Env) LoadTeamMember(teamID, adminID string) (admin.TeamMember, error) {
+	var m admin.TeamMember
+	err := env.DBs.Read.Get(&m, admin.StmtTeamMember, teamID, adminID)
+	if err != nil {
+		return admin.TeamMember{}, err
+	}
+
+	return m, nil
+}
+
+func (env Env) UpdateMemberRole(m admin.TeamMember) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtUpdateMemberRole, m)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (env Env) RemoveTeamMember(m admin.TeamMember) error {
+	_, err := env.DBs.Write.Exec(admin.StmtDeleteTeamMember, m.TeamID, m.AdminID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (env Env) CreateAdminInvitation(inv admin.AdminInvitation) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtCreateAdminInvitation, inv)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListAdminInvitations retrieves pending invitations of a team.
+func (env Env) ListAdminInvitations(teamID string) ([]admin.AdminInvitation, error) {
+	var list = make([]admin.AdminInvitation, 0)
+	err := env.DBs.Read.Select(&list, admin.StmtListAdminInvitations, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (env Env) LoadAdminInvitation(id, teamID string) (admin.AdminInvitation, error) {
+	var inv admin.AdminInvitation
+	err := env.DBs.Read.Get(&inv, admin.StmtAdminInvitation, id, teamID)
+	if err != nil {
+		return admin.AdminInvitation{}, err
+	}
+
+	return inv, nil
+}
+
+func (env Env) AdminInvitationByToken(token string) (admin.AdminInvitation, error) {
+	var inv admin.AdminInvitation
+	err := env.DBs.Read.Get(&inv, admin.StmtAdminInvitationByToken, token)
+	if err != nil {
+		return admin.AdminInvitation{}, err
+	}
+
+	return inv, nil
+}
+
+func (env Env) UpdateAdminInvitation(inv admin.AdminInvitation) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtUpdateAdminInvitation, inv)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AcceptAdminInvitation adds an account to the inviting team.
+// The account must be the invitee and should not belong to
+// any team.
+func (env Env) AcceptAdminInvitation(inv admin.AdminInvitation, a admin.BaseAccount) (admin.TeamMember, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	if !inv.IsInvitee(a) {
+		return admin.TeamMember{}, ErrNotInvitee
+	}
+
+	if a.TeamID.Valid {
+		return admin.TeamMember{}, ErrAlreadyInTeam
+	}
+
+	tx, err := env.DBs.Write.Beginx()
+	if err != nil {
+		sugar.Error(err)
+		return admin.TeamMember{}, err
+	}
+
+	// Lock it so that the same invitation won't be used twice.
+	var locked admin.AdminInvitation
+	err = tx.Get(&locked, admin.StmtLockAdminInvitation, inv.ID)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return admin.TeamMember{}, err
+	}
+
+	if !locked.IsAcceptable() {
+		_ = tx.Rollback()
+		return admin.TeamMember{}, ErrInvitationInvalid
+	}
+
+	accepted, member := locked.Accepted(a)
+
+	// Unique key on admin_id prevents joining multiple teams.
+	_, err = tx.NamedExec(admin.StmtCreateTeamMember, member)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return admin.TeamMember{}, err
+	}
+
+	_, err = tx.NamedExec(admin.StmtUpdateAdminInvitation, accepted)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return admin.TeamMember{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return admin.TeamMember{}, err
+	}
+
+	return member, nil
+}
@@ -0,0 +1,219 @@
+package adminrepo
+
+import (
+	"errors"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+)
+
+// ErrTwoFactorStepUsed means a TOTP code is accepted by a
+// concurrent request.
+var ErrTwoFactorStepUsed = errors.New("verification code is already used")
+
+func (env Env) LoadTwoFactor(adminID string) (admin.TwoFactor, error) {
+	var f admin.TwoFactor
+	err := env.DBs.Read.Get(&f, admin.StmtTwoFactor, adminID)
+	if err != nil {
+		return admin.TwoFactor{}, err
+	}
+
+	return f, nil
+}
+
+// TwoFactorStatus returns sql.ErrNoRows if admin never
+// started enrollment.
+func (env Env) TwoFactorStatus(adminID string) (admin.TwoFactorStatus, error) {
+	var s admin.TwoFactorStatus
+	err := env.DBs.Read.Get(&s, admin.StmtTwoFactorStatus, adminID)
+	if err != nil {
+		return admin.TwoFactorStatus{}, err
+	}
+
+	return s, nil
+}
+
+// SaveTwoFactor saves a new secret which is not enabled yet.
+func (env Env) SaveTwoFactor(f admin.TwoFactor) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtSaveTwoFactor, f)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateTwoFactor saves the last step used.
+// ErrTwoFactorStepUsed is returned if the step is used.
+func (env Env) UpdateTwoFactor(f admin.TwoFactor) error {
+	r, err := env.DBs.Write.NamedExec(admin.StmtUpdateTwoFactor, f)
+	if err != nil {
+		return err
+	}
+
+	n, err := r.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrTwoFactorStepUsed
+	}
+
+	return nil
+}
+
+// EnableTwoFactor turns on 2FA and replaces recovery codes.
+func (env Env) EnableTwoFactor(f admin.TwoFactor, codes []admin.RecoveryCode) error {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	tx, err := env.DBs.Write.Beginx()
+	if err != nil {
+		sugar.Error(err)
+		return err
+	}
+
+	r, err := tx.NamedExec(admin.StmtUpdateTwoFactor, f)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return err
+	}
+	n, err := r.RowsAffected()
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return err
+	}
+	if n == 0 {
+		_ = tx.Rollback()
+		return ErrTwoFactorStepUsed
+	}
+
+	_, err = tx.Exec(admin.StmtDeleteRecoveryCodes, f.AdminID)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return err
+	}
+
+	for _, c := range codes {
+		_, err = tx.NamedExec(admin.StmtInsertRecoveryCode, c)
+		if err != nil {
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// DisableTwoFactor removes secret and recovery codes.
+func (env Env) DisableTwoFactor(adminID string) error {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	tx, err := env.DBs.Write.Beginx()
+	if err != nil {
+		sugar.Error(err)
+		return err
+	}
+
+	_, err = tx.Exec(admin.StmtDeleteRecoveryCodes, adminID)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(admin.StmtDeleteTwoFactor, adminID)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// UseRecoveryCode consumes a recovery code. Returns false if
+// the code is wrong or already used.
+func (env Env) UseRecoveryCode(adminID, code string) (bool, error) {
+	r, err := env.DBs.Write.Exec(
+		admin.StmtUseRecoveryCode,
+		adminID,
+		admin.HashToken(admin.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return false, err
+	}
+
+	n, err := r.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+func (env Env) CreateLoginChallenge(c admin.LoginChallenge) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtCreateLoginChallenge, c)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (env Env) LoginChallenge(token string) (admin.LoginChallenge, error) {
+	var c admin.LoginChallenge
+	err := env.DBs.Read.Get(&c, admin.StmtLoginChallenge, token)
+	if err != nil {
+		return admin.LoginChallenge{}, err
+	}
+
+	return c, nil
+}
+
+// TakeChallengeAttempt counts an attempt before a code is
+// checked. Returns false if no attempt is left.
+func (env Env) TakeChallengeAttempt(token string) (bool, error) {
+	r, err := env.DBs.Write.Exec(admin.StmtIncrChallengeAttempts, token, admin.MaxChallengeAttempts)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := r.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// DeleteLoginChallenge after it is used.
+func (env Env) DeleteLoginChallenge(token string) error {
+	_, err := env.DBs.Write.Exec(admin.StmtDeleteLoginChallenge, token)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (env Env) UpdateTwoFactorPolicy(t admin.Team) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtUpdateTwoFactorPolicy, t)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package adminrepo
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+)
+
+func (env Env) CreateUnit(u admin.Unit) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtCreateUnit, u)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (env Env) ListUnits(teamID string) ([]admin.Unit, error) {
+	var list = make([]admin.Unit, 0)
+	err := env.DBs.Read.Select(&list, admin.StmtListUnits, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (env Env) UpdateUnit(u admin.Unit) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtUpdateUnit, u)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteUnit removes a unit. Its licences, staff and
+// co-admins are moved back to team level.
+func (env Env) DeleteUnit(u admin.Unit) error {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	tx, err := env.DBs.Write.Beginx()
+	if err != nil {
+		sugar.Error(err)
+		return err
+	}
+
+	for _, stmt := range []string{
+		admin.StmtReleaseUnitLicences,
+		admin.StmtReleaseUnitStaff,
+		admin.StmtReleaseUnitMembers,
+	} {
+		_, err = tx.Exec(stmt, u.TeamID, u.ID)
+		if err != nil {
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	_, err = tx.Exec(admin.StmtDeleteUnit, u.ID, u.TeamID)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// UnitReport breaks down licence utilisation and spend of a
+// team by unit.
+func (env Env) UnitReport(teamID string) (admin.UnitReport, error) {
+	units, err := env.ListUnits(teamID)
+	if err != nil {
+		return admin.UnitReport{}, err
+	}
+
+	var usages = make([]admin.UnitUsage, 0)
+	err = env.DBs.Read.Select(&usages, admin.StmtUnitUsage, teamID)
+	if err != nil {
+		return admin.UnitReport{}, err
+	}
+
+	return admin.NewUnitReport(units, usages), nil
+}
@@ -0,0 +1,82 @@
+package auditrepo
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/audit"
+	gorest "github.com/FTChinese/go-rest"
+)
+
+func (env Env) CreateEntry(e audit.Entry) error {
+	_, err := env.DBs.Write.NamedExec(audit.StmtCreateEntry, e)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (env Env) countEntries(f audit.Filter) (int64, error) {
+	var total int64
+	err := env.DBs.Read.Get(&total, audit.StmtCountEntries, f.Args()...)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (env Env) listEntries(f audit.Filter, page gorest.Pagination) ([]audit.Entry, error) {
+	var list = make([]audit.Entry, 0)
+	args := append(f.Args(), page.Limit, page.Offset())
+	err := env.DBs.Read.Select(&list, audit.StmtListEntries, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ListEntries retrieves the audit log, newest first.
+func (env Env) ListEntries(f audit.Filter, page gorest.Pagination) (audit.List, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	countCh := make(chan int64)
+	listCh := make(chan audit.List)
+
+	go func() {
+		defer close(countCh)
+		n, err := env.countEntries(f)
+		if err != nil {
+			sugar.Error(err)
+		}
+
+		countCh <- n
+	}()
+
+	go func() {
+		defer close(listCh)
+		list, err := env.listEntries(f, page)
+
+		listCh <- audit.List{
+			PagedList: pkg.PagedList{
+				Err: err,
+			},
+			Data: list,
+		}
+	}()
+
+	count, listResult := <-countCh, <-listCh
+	if listResult.Err != nil {
+		return audit.List{}, listResult.Err
+	}
+
+	return audit.List{
+		PagedList: pkg.PagedList{
+			Total:      count,
+			Pagination: page,
+			Err:        nil,
+		},
+		Data: listResult.Data,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package cmsrepo
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	gorest "github.com/FTChinese/go-rest"
+)
+
+func (env Env) countLegacyPassword() (int64, error) {
+	var total int64
+	err := env.DBs.Read.Get(&total, admin.StmtCountLegacyPassword)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (env Env) listLegacyPassword(page gorest.Pagination) ([]admin.LegacyPasswordAccount, error) {
+	var list = make([]admin.LegacyPasswordAccount, 0)
+	err := env.DBs.Read.Select(&list, admin.StmtListLegacyPassword, page.Limit, page.Offset())
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ListLegacyPassword shows admins whose password is still
+// hashed with SHA-256 since they have not logged in after
+// migrating to argon2id.
+func (env Env) ListLegacyPassword(page gorest.Pagination) (admin.LegacyPasswordList, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	countCh := make(chan int64)
+	listCh := make(chan admin.LegacyPasswordList)
+
+	go func() {
+		defer close(countCh)
+		n, err := env.countLegacyPassword()
+		if err != nil {
+			sugar.Error(err)
+		}
+
+		countCh <- n
+	}()
+
+	go func() {
+		defer close(listCh)
+
+		list, err := env.listLegacyPassword(page)
+
+		listCh <- admin.LegacyPasswordList{
+			PagedList: pkg.PagedList{
+				Total:      0,
+				Pagination: gorest.Pagination{},
+				Err:        err,
+			},
+			Data: list,
+		}
+	}()
+
+	count, listResult := <-countCh, <-listCh
+	if listResult.Err != nil {
+		return admin.LegacyPasswordList{}, listResult.Err
+	}
+
+	return admin.LegacyPasswordList{
+		PagedList: pkg.PagedList{
+			Total:      count,
+			Pagination: page,
+			Err:        nil,
+		},
+		Data: listResult.Data,
+	}, nil
+}
@@ -168,6 +168,22 @@ func (env Env) buildLicence(txnPrice checkout.PriceOfLicenceTxn) (checkout.Licen
 		return checkout.LicenceGenerated{}, err
 	}
 
+	// Carry-over banked when the licence was granted follows
+	// it to the new transaction so that it could still be
+	// found upon revoking.
+	postLic := result.LicenceVersion.PostChange.Licence
+	if currLic.IsGrantedTo(curMmb) && currLic.LatestTransactionID != postLic.LatestTransactionID {
+		err = tx.MoveLicenceCarryOver(
+			curMmb.CompoundID,
+			currLic.LatestTransactionID.String,
+			postLic.LatestTransactionID.String)
+		if err != nil {
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return checkout.LicenceGenerated{}, err
+		}
+	}
+
 	// This row in the queue table is finalized.
 	sugar.Infof("Finalize licence transaction %s", result.Transaction.ID)
 	err = tx.FinalizeLicenceTxn(result.Transaction)
@@ -224,10 +240,12 @@ func (env Env) buildLicence(txnPrice checkout.PriceOfLicenceTxn) (checkout.Licen
 
 // ConfirmPayment creates/renew licences under an order
 // one by one.
+// onGenerated, if not nil, is called for every licence
+// created or renewed successfully.
 // After the job is finished:
 // * Tell admin the job is done;
 // * Tell cms the job is done;
-func (env Env) ConfirmPayment(order checkout.Order) error {
+func (env Env) ConfirmPayment(order checkout.Order, onGenerated func(checkout.LicenceGenerated)) error {
 	defer env.logger.Sync()
 	sugar := env.logger.Sugar()
 	ctx := context.Background()
@@ -249,7 +267,7 @@ func (env Env) ConfirmPayment(order checkout.Order) error {
 			sugar.Infof("----- Start processing transaction %s -----", p.TxnID)
 
 			queLog.IncTotal()
-			_, err := env.buildLicence(p)
+			result, err := env.buildLicence(p)
 			if err != nil {
 				sugar.Errorf("Error after processing %s, %s", p.TxnID, err)
 				queLog.IncFailure()
@@ -262,6 +280,10 @@ func (env Env) ConfirmPayment(order checkout.Order) error {
 				sugar.Infof("Transaction %s processed successfully", p.TxnID)
 				queLog.IncSuccess()
 				// TODO: send email to renewed membership.
+				// Zero value is returned for already finalized transaction.
+				if onGenerated != nil && result.Transaction.ID != "" {
+					onGenerated(result)
+				}
 			}
 			sugar.Infof("----- Finished processing transaction %s -----", p.TxnID)
 			sem.Release(1)
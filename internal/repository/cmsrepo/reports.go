@@ -0,0 +1,58 @@
+package cmsrepo
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/report"
+)
+
+// Reports are read from replica since they scan many rows.
+
+func (env Env) RevenueReport(p report.Period) (report.RevenueReport, error) {
+	var rows = make([]report.RevenueRow, 0)
+	err := env.DBs.Read.Select(&rows, report.StmtRevenue, p.Args()...)
+	if err != nil {
+		return report.RevenueReport{}, err
+	}
+
+	return report.NewRevenueReport(rows), nil
+}
+
+func (env Env) SeatReport(p report.Period) ([]report.SeatRow, error) {
+	var rows = make([]report.SeatRow, 0)
+	err := env.DBs.Read.Select(&rows, report.StmtSeats, p.Args()...)
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// ActiveSeatReport shows granted seats at the end of each
+// month in the period.
+func (env Env) ActiveSeatReport(p report.Period) ([]report.ActiveSeatRow, error) {
+	var baseline int64
+	err := env.DBs.Read.Get(&baseline, report.StmtActiveSeatsBefore, p.Args()[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var changes = make([]report.GrantChange, 0)
+	err = env.DBs.Read.Select(&changes, report.StmtGrantChanges, p.Args()...)
+	if err != nil {
+		return nil, err
+	}
+
+	return report.NewActiveSeats(baseline, changes), nil
+}
+
+func (env Env) TopTeamsReport(p report.Period, limit int64) ([]report.TopTeamRow, error) {
+	var rows = make([]report.TopTeamRow, 0)
+	err := env.DBs.Read.Select(
+		&rows,
+		report.StmtTopTeams,
+		append(p.Args(), limit)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
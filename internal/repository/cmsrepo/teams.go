@@ -1,6 +1,10 @@
 package cmsrepo
 
-import "github.com/FTChinese/ftacademy/internal/pkg/admin"
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	gorest "github.com/FTChinese/go-rest"
+)
 
 func (env Env) LoadTeam(teamID string) (admin.Team, error) {
 	var t admin.Team
@@ -14,3 +18,146 @@ func (env Env) LoadTeam(teamID string) (admin.Team, error) {
 
 	return t, nil
 }
+
+// UpdateTeam changes team details on behalf of its owner.
+func (env Env) UpdateTeam(t admin.Team) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtUpdateTeam, t)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListTeamDomains shows the email domains registered for a team.
+func (env Env) ListTeamDomains(teamID string) ([]admin.TeamDomain, error) {
+	var list = make([]admin.TeamDomain, 0)
+	err := env.DBs.Read.Select(&list, admin.StmtListTeamDomains, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// CreateTeamDomain registers an email domain for a team.
+// A domain could only be registered once.
+func (env Env) CreateTeamDomain(d admin.TeamDomain) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtCreateTeamDomain, d)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteTeamDomain removes an email domain from a team.
+func (env Env) DeleteTeamDomain(teamID, domain string) error {
+	_, err := env.DBs.Write.Exec(admin.StmtDeleteTeamDomain, domain, teamID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (env Env) countTeamSummary(w pkg.SQLWhere) (int64, error) {
+	var total int64
+	err := env.DBs.Read.Get(
+		&total,
+		admin.BuildStmtCountTeamSummary(w.Clause),
+		w.Values...)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (env Env) listTeamSummary(filter admin.TeamDirectoryFilter, w pkg.SQLWhere, page gorest.Pagination) ([]admin.TeamSummary, error) {
+	var list = make([]admin.TeamSummary, 0)
+
+	w = w.AddValues(page.Limit, page.Offset())
+
+	err := env.DBs.Read.Select(
+		&list,
+		admin.BuildStmtListTeamSummary(w.Clause, filter.Sort),
+		w.Values...)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ListTeams shows the team directory with aggregated
+// orders and licences of each team.
+func (env Env) ListTeams(filter admin.TeamDirectoryFilter, page gorest.Pagination) (admin.TeamSummaryList, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	where := filter.SQLWhere()
+	countCh := make(chan int64)
+	listCh := make(chan admin.TeamSummaryList)
+
+	go func() {
+		defer close(countCh)
+		n, err := env.countTeamSummary(where)
+		if err != nil {
+			sugar.Error(err)
+		}
+
+		countCh <- n
+	}()
+
+	go func() {
+		defer close(listCh)
+
+		list, err := env.listTeamSummary(filter, where, page)
+
+		listCh <- admin.TeamSummaryList{
+			PagedList: pkg.PagedList{
+				Total:      0,
+				Pagination: gorest.Pagination{},
+				Err:        err,
+			},
+			Data: list,
+		}
+	}()
+
+	count, listResult := <-countCh, <-listCh
+	if listResult.Err != nil {
+		return admin.TeamSummaryList{}, listResult.Err
+	}
+
+	return admin.TeamSummaryList{
+		PagedList: pkg.PagedList{
+			Total:      count,
+			Pagination: page,
+			Err:        nil,
+		},
+		Data: listResult.Data,
+	}, nil
+}
+
+// LoadTeamSummary retrieves a team with aggregated stats.
+func (env Env) LoadTeamSummary(teamID string) (admin.TeamSummary, error) {
+	var t admin.TeamSummary
+	err := env.DBs.Read.Get(&t, admin.StmtTeamSummary, teamID)
+	if err != nil {
+		return admin.TeamSummary{}, err
+	}
+
+	return t, nil
+}
+
+// LoadAdmin retrieves the account of a team's owner.
+func (env Env) LoadAdmin(adminID string) (admin.BaseAccount, error) {
+	var a admin.BaseAccount
+	err := env.DBs.Read.Get(&a, admin.StmtBaseAccountByID, adminID)
+	if err != nil {
+		return admin.BaseAccount{}, err
+	}
+
+	return a, nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/FTChinese/ftacademy/internal/pkg/admin"
 	"github.com/FTChinese/ftacademy/pkg/db"
 	"github.com/FTChinese/ftacademy/pkg/faker"
+	gorest "github.com/FTChinese/go-rest"
 	"go.uber.org/zap/zaptest"
 	"testing"
 )
@@ -50,3 +51,42 @@ func TestEnv_LoadTeam(t *testing.T) {
 		})
 	}
 }
+
+func TestEnv_ListTeams(t *testing.T) {
+	env := NewEnv(db.MockMySQL(), zaptest.NewLogger(t))
+
+	team := mock.NewAdmin().Team()
+
+	mock.NewRepo().InsertTeam(team)
+
+	tests := []struct {
+		name    string
+		filter  admin.TeamDirectoryFilter
+		wantErr bool
+	}{
+		{
+			name:    "Default directory",
+			filter:  admin.TeamDirectoryFilter{},
+			wantErr: false,
+		},
+		{
+			name: "Search by team id sorted by spend",
+			filter: admin.TeamDirectoryFilter{
+				Query: team.ID,
+				Sort:  admin.TeamSortSpend,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := env.ListTeams(tt.filter, gorest.NewPagination(1, 10))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ListTeams() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			t.Logf("%s", faker.MustMarshalIndent(got))
+		})
+	}
+}
@@ -0,0 +1,5 @@
+package outboxrepo
+
+import "errors"
+
+var ErrNotDead = errors.New("only dead letters could be resent")
@@ -0,0 +1,188 @@
+package outboxrepo
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/outbox"
+	gorest "github.com/FTChinese/go-rest"
+	"time"
+)
+
+func (env Env) CreateMessage(m outbox.Message) error {
+	_, err := env.DBs.Write.NamedExec(outbox.StmtCreateMessage, m)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateMessage saves the outcome of an attempt.
+func (env Env) UpdateMessage(m outbox.Message) error {
+	_, err := env.DBs.Write.NamedExec(outbox.StmtUpdateMessage, m)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (env Env) RetrieveMessage(id string) (outbox.Message, error) {
+	var m outbox.Message
+	err := env.DBs.Read.Get(&m, outbox.StmtMessage, id)
+	if err != nil {
+		return outbox.Message{}, err
+	}
+
+	return m, nil
+}
+
+// ClaimDueMessages retrieves pending messages ready to
+// retry and leases them so that other instances skip them.
+func (env Env) ClaimDueMessages(limit int) ([]outbox.Message, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	tx, err := env.DBs.Write.Beginx()
+	if err != nil {
+		sugar.Error(err)
+		return nil, err
+	}
+
+	var list = make([]outbox.Message, 0)
+	err = tx.Select(&list, outbox.StmtDueMessages, limit)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now()
+	for i, m := range list {
+		list[i] = m.Leased(now)
+		_, err = tx.NamedExec(outbox.StmtUpdateMessage, list[i])
+		if err != nil {
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ResendMessage puts a dead letter back to queue.
+// ErrNotDead is returned if it is still pending or
+// already sent.
+func (env Env) ResendMessage(id string) (outbox.Message, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	tx, err := env.DBs.Write.Beginx()
+	if err != nil {
+		sugar.Error(err)
+		return outbox.Message{}, err
+	}
+
+	var m outbox.Message
+	err = tx.Get(&m, outbox.StmtLockMessage, id)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return outbox.Message{}, err
+	}
+
+	if m.Status != outbox.StatusDead {
+		_ = tx.Rollback()
+		return outbox.Message{}, ErrNotDead
+	}
+
+	m = m.Resend(time.Now())
+	_, err = tx.NamedExec(outbox.StmtUpdateMessage, m)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return outbox.Message{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return outbox.Message{}, err
+	}
+
+	return m, nil
+}
+
+func (env Env) countMessages(s outbox.Status) (int64, error) {
+	var total int64
+	err := env.DBs.Read.Get(&total, outbox.StmtCountMessages, s)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (env Env) listMessages(s outbox.Status, page gorest.Pagination) ([]outbox.Message, error) {
+	var list = make([]outbox.Message, 0)
+	err := env.DBs.Read.Select(
+		&list,
+		outbox.StmtListMessages,
+		s,
+		page.Limit,
+		page.Offset())
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ListMessages shows letters of the specified status.
+func (env Env) ListMessages(s outbox.Status, page gorest.Pagination) (outbox.MessageList, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	countCh := make(chan int64)
+	listCh := make(chan outbox.MessageList)
+
+	go func() {
+		defer close(countCh)
+		n, err := env.countMessages(s)
+		if err != nil {
+			sugar.Error(err)
+		}
+
+		countCh <- n
+	}()
+
+	go func() {
+		defer close(listCh)
+		list, err := env.listMessages(s, page)
+
+		listCh <- outbox.MessageList{
+			PagedList: pkg.PagedList{
+				Err: err,
+			},
+			Data: list,
+		}
+	}()
+
+	count, listResult := <-countCh, <-listCh
+	if listResult.Err != nil {
+		return outbox.MessageList{}, listResult.Err
+	}
+
+	return outbox.MessageList{
+		PagedList: pkg.PagedList{
+			Total:      count,
+			Pagination: page,
+			Err:        nil,
+		},
+		Data: listResult.Data,
+	}, nil
+}
@@ -28,6 +28,17 @@ func (r SharedRepo) LoadB2BAdminProfile(id string) (admin.Profile, error) {
 	return p, nil
 }
 
+// LoadUnit retrieves a unit of a team.
+func (r SharedRepo) LoadUnit(id, teamID string) (admin.Unit, error) {
+	var u admin.Unit
+	err := r.DBs.Read.Get(&u, admin.StmtUnit, id, teamID)
+	if err != nil {
+		return admin.Unit{}, err
+	}
+
+	return u, nil
+}
+
 func (r SharedRepo) RetrieveAssignee(id string) (licence.Assignee, error) {
 	var a licence.Assignee
 	err := r.DBs.Read.Get(&a, licence.StmtAssigneeByID, id)
@@ -59,3 +70,15 @@ func (r SharedRepo) SaveVersionedLicence(s licence.Versioned) error {
 
 	return nil
 }
+
+// MemberScope finds the role and unit of an admin in a team.
+// RoleNull is returned if the admin does not belong to it.
+func (r SharedRepo) MemberScope(teamID, adminID string) (admin.MemberScope, error) {
+	var s admin.MemberScope
+	err := r.DBs.Read.Get(&s, admin.StmtMemberScope, adminID, adminID, adminID, adminID, teamID)
+	if err != nil {
+		return admin.MemberScope{}, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,153 @@
+package subsrepo
+
+import (
+	"context"
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/guregu/null"
+	"golang.org/x/sync/semaphore"
+	"runtime"
+	"sync"
+)
+
+var maxWorkers = runtime.GOMAXPROCS(0)
+
+// listLicenceIDsByDomain finds ids of licences granted
+// to emails under a domain, at most limit rows, within the
+// team or unit of claims.
+func (env Env) listLicenceIDsByDomain(claims admin.PassportClaims, domain string, limit int) ([]string, error) {
+	var ids = make([]string, 0)
+
+	err := env.DBs.Read.Select(
+		&ids,
+		licence.StmtGrantedLicenceIDsByDomain,
+		claims.TeamID.String,
+		claims.UnitID,
+		claims.UnitID,
+		pkg.EscapeLike(domain),
+		limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// collectRevokeTargets merges licence ids specified by
+// client and those found by email domain.
+// ErrTooManyRevokes is returned if the merged ids exceed
+// input.MaxBulkRevoke.
+func (env Env) collectRevokeTargets(claims admin.PassportClaims, params input.BulkRevokeParams) ([]string, error) {
+	if params.EmailDomain == "" {
+		return params.LicenceIDs, nil
+	}
+
+	// One more than the cap so that we know it is exceeded.
+	found, err := env.listLicenceIDsByDomain(claims, params.EmailDomain, input.MaxBulkRevoke+1)
+	if err != nil {
+		return nil, err
+	}
+
+	var seen = map[string]bool{}
+	var ids = make([]string, 0)
+	for _, v := range append(params.LicenceIDs, found...) {
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		ids = append(ids, v)
+	}
+
+	if len(ids) > input.MaxBulkRevoke {
+		return nil, ErrTooManyRevokes
+	}
+
+	return ids, nil
+}
+
+// archiveRevoke revokes a single licence in its own
+// transaction and archives the changes.
+func (env Env) archiveRevoke(r admin.AccessRight) (licence.RevokeResult, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	result, err := env.RevokeLicence(r)
+	if err != nil {
+		return licence.RevokeResult{}, err
+	}
+
+	err = env.SaveVersionedLicence(result.LicenceVersion)
+	if err != nil {
+		sugar.Error(err)
+	}
+
+	err = env.ArchiveMembership(result.MembershipVersioned)
+	if err != nil {
+		sugar.Error(err)
+	}
+
+	return result, nil
+}
+
+// revokeAndArchive is the same as archiveRevoke except the
+// error is put into the outcome.
+func (env Env) revokeAndArchive(r admin.AccessRight) licence.RevokeOutcome {
+	result, err := env.archiveRevoke(r)
+	if err != nil {
+		return licence.RevokeOutcome{
+			LicenceID: r.RowID,
+			Ok:        false,
+			Error:     null.StringFrom(err.Error()),
+		}
+	}
+
+	return licence.RevokeOutcome{
+		LicenceID:    r.RowID,
+		Ok:           true,
+		RevokeResult: result,
+	}
+}
+
+// BulkRevokeLicence revokes multiple licences of a team, or
+// only those of the unit managed by claims.
+// Each licence is revoked in its own transaction through
+// RevokeLicence so that a failure does not affect others.
+// The number of licences processed concurrently is
+// bounded by the number of CPUs.
+func (env Env) BulkRevokeLicence(claims admin.PassportClaims, params input.BulkRevokeParams) (licence.BulkRevokeResult, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+	ctx := context.Background()
+
+	ids, err := env.collectRevokeTargets(claims, params)
+	if err != nil {
+		sugar.Error(err)
+		return licence.BulkRevokeResult{}, err
+	}
+
+	sem := semaphore.NewWeighted(int64(maxWorkers))
+	var items = make([]licence.RevokeOutcome, len(ids))
+	var wg sync.WaitGroup
+
+	for i, id := range ids {
+		if err := sem.Acquire(ctx, 1); err != nil {
+			sugar.Errorf("Failed to acquire semaphore: %v", err)
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			defer sem.Release(1)
+
+			sugar.Infof("Start revoking licence %s", id)
+			items[i] = env.revokeAndArchive(claims.AccessRight(id))
+		}(i, id)
+	}
+
+	wg.Wait()
+
+	return licence.NewBulkRevokeResult(items), nil
+}
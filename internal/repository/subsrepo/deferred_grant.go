@@ -0,0 +1,195 @@
+package subsrepo
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+)
+
+// ListDeferredGrants retrieves all licences reserved for
+// readers with auto-renewal subscription.
+func (env Env) ListDeferredGrants() ([]licence.DeferredGrant, error) {
+	var list = make([]licence.DeferredGrant, 0)
+
+	err := env.DBs.Read.Select(&list, licence.StmtListReservedLicences)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ApplyDeferredGrant grants a reserved licence to its assignee
+// if the assignee's auto-renewal subscription is already ended.
+// Returns ErrGrantNotReady if the subscription is still valid,
+// or ErrSwitchingLicence if the reader got another licence in
+// the meantime, in which case the reservation should be
+// released by ReleaseReservation.
+func (env Env) ApplyDeferredGrant(d licence.DeferredGrant) (licence.GrantResult, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	to, err := env.RetrieveAssignee(d.AssigneeID)
+	if err != nil {
+		sugar.Error(err)
+		return licence.GrantResult{}, err
+	}
+
+	tx, err := env.beginTx()
+	if err != nil {
+		sugar.Error(err)
+		return licence.GrantResult{}, err
+	}
+
+	lic, err := tx.LockLicence(admin.AccessRight{
+		RowID:  d.LicenceID,
+		TeamID: d.TeamID,
+	})
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.GrantResult{}, err
+	}
+	if !lic.IsReserved() || lic.AssigneeID.String != d.AssigneeID {
+		_ = tx.Rollback()
+		return licence.GrantResult{}, ErrLicenceUnavailable
+	}
+
+	inv, err := tx.RetrieveInvitation(admin.AccessRight{
+		RowID:  lic.LatestInvitation.ID,
+		TeamID: d.TeamID,
+	})
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.GrantResult{}, err
+	}
+	if !inv.IsPending() {
+		_ = tx.Rollback()
+		return licence.GrantResult{}, ErrInvalidInvitation
+	}
+
+	mmb, err := tx.LockMember(d.AssigneeID)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.GrantResult{}, err
+	}
+	if !mmb.IsAutoRenewEnded() {
+		_ = tx.Rollback()
+		return licence.GrantResult{}, ErrGrantNotReady
+	}
+
+	result, err := licence.GrantLicence(licence.GrantParams{
+		CurLic:    lic,
+		CurInv:    inv,
+		To:        to,
+		CurMember: mmb,
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return licence.GrantResult{}, err
+	}
+	if result.IsSwitchingLicence {
+		_ = tx.Rollback()
+		return licence.GrantResult{}, ErrSwitchingLicence
+	}
+
+	err = tx.UpdateInvitationStatus(result.LicenceVersion.PostChange.LatestInvitation.Invitation)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.GrantResult{}, err
+	}
+
+	err = tx.UpdateLicenceStatus(result.LicenceVersion.PostChange.Licence)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.GrantResult{}, err
+	}
+
+	err = tx.UpsertMember(result.MembershipVersion.PostChange.Membership, mmb.IsZero())
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.GrantResult{}, err
+	}
+
+	if !result.CarryOverInvoice.IsZero() {
+		err = tx.SaveInvoice(result.CarryOverInvoice)
+		if err != nil {
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return licence.GrantResult{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return licence.GrantResult{}, err
+	}
+
+	return result, nil
+}
+
+// ReleaseReservation makes a reserved licence available again
+// and revokes the pending invitation. It is used when the
+// deferred grant could never be applied.
+func (env Env) ReleaseReservation(d licence.DeferredGrant) (licence.Versioned, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	tx, err := env.beginTx()
+	if err != nil {
+		sugar.Error(err)
+		return licence.Versioned{}, err
+	}
+
+	lic, err := tx.LockLicence(admin.AccessRight{
+		RowID:  d.LicenceID,
+		TeamID: d.TeamID,
+	})
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.Versioned{}, err
+	}
+	if !lic.IsReserved() || lic.AssigneeID.String != d.AssigneeID {
+		_ = tx.Rollback()
+		return licence.Versioned{}, ErrLicenceUnavailable
+	}
+
+	inv, err := tx.RetrieveInvitation(admin.AccessRight{
+		RowID:  lic.LatestInvitation.ID,
+		TeamID: d.TeamID,
+	})
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.Versioned{}, err
+	}
+
+	err = tx.UpdateInvitationStatus(inv.Revoked())
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.Versioned{}, err
+	}
+
+	released := lic.WithInvitationRevoked()
+	err = tx.UpdateLicenceStatus(released)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.Versioned{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return licence.Versioned{}, err
+	}
+
+	return released.
+		Versioned(licence.VersionActionRevoke).
+		WithPriorVersion(lic), nil
+}
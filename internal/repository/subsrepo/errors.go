@@ -8,4 +8,17 @@ var (
 	ErrInviteeMismatch    = errors.New("an invitation for this licence is already sent to another user")
 	ErrInvalidInvitation  = errors.New("invalid invitation")
 	ErrLicenceTaken       = errors.New("the licence is already taken by another user")
+	ErrNoTeamForDomain    = errors.New("no team registered for the email domain")
+	ErrRequestExists      = errors.New("a licence request is already waiting for verification or approval")
+	ErrNoAvailableLicence = errors.New("no licence available to grant")
+	ErrGrantNotReady      = errors.New("the reader's auto-renewal subscription is not ended yet")
+	ErrSwitchingLicence   = errors.New("the reader is already using another licence")
+	ErrInvalidRedeemCode  = errors.New("the code is invalid, expired or already redeemed")
+	ErrInvalidJoinLink    = errors.New("the join link is invalid, expired or fully used")
+	ErrDomainNotAllowed   = errors.New("your email domain is not allowed by this join link")
+	ErrAlreadyJoined      = errors.New("you already joined via this link")
+	ErrEmailNotVerified   = errors.New("verify your email before joining a team")
+	ErrStafferExists      = errors.New("the email is already in staff roster")
+	ErrTooManyRevokes     = errors.New("too many licences to revoke in one request")
+	ErrNotRevocable       = errors.New("nothing to revoke")
 )
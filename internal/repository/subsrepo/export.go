@@ -0,0 +1,79 @@
+package subsrepo
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/checkout"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/guregu/null"
+)
+
+// Rows to export are passed to a callback one by one so that
+// a large team is never loaded into memory as a whole.
+// Iteration stops at the first error returned by the callback.
+
+// StreamOrders iterates over all orders of a team.
+func (env Env) StreamOrders(teamID string, fn func(checkout.Order) error) error {
+	rows, err := env.DBs.Read.Queryx(checkout.StmtExportOrders, teamID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o checkout.Order
+		if err := rows.StructScan(&o); err != nil {
+			return err
+		}
+
+		if err := fn(o); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// StreamLicences iterates over all licences of a team,
+// or of a unit if unitID is not null.
+func (env Env) StreamLicences(teamID string, unitID null.String, fn func(licence.ExpandedLicence) error) error {
+	rows, err := env.DBs.Read.Queryx(licence.StmtExportLicences, teamID, unitID, unitID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l licence.ExpandedLicence
+		if err := rows.StructScan(&l); err != nil {
+			return err
+		}
+
+		if err := fn(l); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// StreamInvitations iterates over all invitations of a team,
+// or of a unit if unitID is not null.
+func (env Env) StreamInvitations(teamID string, unitID null.String, fn func(licence.Invitation) error) error {
+	rows, err := env.DBs.Read.Queryx(licence.StmtExportInvitations, teamID, unitID, unitID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var i licence.Invitation
+		if err := rows.StructScan(&i); err != nil {
+			return err
+		}
+
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,111 @@
+package subsrepo
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/FTChinese/ftacademy/internal/repository/txrepo"
+	"github.com/FTChinese/ftacademy/pkg/subs"
+)
+
+// The functions in this file work on a licence already
+// locked in tx. They never commit or roll back so that
+// callers could combine them with their own changes.
+
+// inviteTx creates an invitation for a licence.
+// The returned licence contains the newly created invitation.
+func inviteTx(tx txrepo.TxRepo, lic licence.Licence, params input.InvitationParams, p admin.PassportClaims) (licence.Licence, error) {
+	// Ensure that the licence is not granted to anyone,
+	// and it has no assignee attached to it.
+	if !lic.IsAvailable() {
+		return licence.Licence{}, ErrLicenceUnavailable
+	}
+
+	// Create invitation and update existing licence's latest invitation field.
+	invitedLic, err := lic.CreateInvitation(params, p)
+	if err != nil {
+		return licence.Licence{}, err
+	}
+
+	err = tx.CreateInvitation(invitedLic.LatestInvitation.Invitation)
+	if err != nil {
+		return licence.Licence{}, err
+	}
+
+	err = tx.UpdateLicenceStatus(invitedLic)
+	if err != nil {
+		return licence.Licence{}, err
+	}
+
+	return invitedLic, nil
+}
+
+// grantTx grants a licence to the reader accepting inv.
+// Reader with a valid auto-renewal subscription could not
+// be granted now. The licence is reserved and granted
+// after the subscription ended.
+func grantTx(tx txrepo.TxRepo, lic licence.Licence, inv licence.Invitation, to licence.Assignee) (licence.GrantResult, error) {
+	mmb, err := tx.LockMember(to.FtcID.String)
+	if err != nil {
+		return licence.GrantResult{}, err
+	}
+
+	params := licence.GrantParams{
+		CurLic:    lic,
+		CurInv:    inv,
+		To:        to,
+		CurMember: mmb,
+	}
+	result, err := licence.GrantLicence(params)
+	if err == subs.ErrOverrideAutoRenewForbidden {
+		result, err = licence.ReserveLicence(params), nil
+	}
+	if err != nil {
+		return licence.GrantResult{}, err
+	}
+
+	err = tx.UpdateInvitationStatus(result.LicenceVersion.PostChange.LatestInvitation.Invitation)
+	if err != nil {
+		return licence.GrantResult{}, err
+	}
+
+	err = tx.UpdateLicenceStatus(result.LicenceVersion.PostChange.Licence)
+	if err != nil {
+		return licence.GrantResult{}, err
+	}
+
+	if result.Deferred {
+		return result, nil
+	}
+
+	// Is current membership is zero, do insert;
+	// otherwise update.
+	err = tx.UpsertMember(result.MembershipVersion.PostChange.Membership, mmb.IsZero())
+	if err != nil {
+		return licence.GrantResult{}, err
+	}
+
+	// Bank remaining days of one-time purchase so that they
+	// could be restored when the licence is revoked.
+	if !result.CarryOverInvoice.IsZero() {
+		err = tx.SaveInvoice(result.CarryOverInvoice)
+		if err != nil {
+			return licence.GrantResult{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// inviteAndGrantTx creates an invitation on behalf of p and
+// accepts it immediately for the reader, so that history
+// stays the same as per-email invitations while the licence
+// is never seen half-way by others.
+func inviteAndGrantTx(tx txrepo.TxRepo, lic licence.Licence, params input.InvitationParams, p admin.PassportClaims, to licence.Assignee) (licence.GrantResult, error) {
+	invitedLic, err := inviteTx(tx, lic, params, p)
+	if err != nil {
+		return licence.GrantResult{}, err
+	}
+
+	return grantTx(tx, invitedLic, invitedLic.LatestInvitation.Invitation, to)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/FTChinese/ftacademy/internal/pkg/input"
 	"github.com/FTChinese/ftacademy/internal/pkg/licence"
 	gorest "github.com/FTChinese/go-rest"
+	"github.com/guregu/null"
 )
 
 // InvitationByToken tries to find an Invitation by token.
@@ -22,7 +23,7 @@ func (env Env) InvitationByToken(token string) (licence.Invitation, error) {
 
 func (env Env) InvitationByID(r admin.AccessRight) (licence.Invitation, error) {
 	var inv licence.Invitation
-	err := env.DBs.Read.Get(&inv, licence.StmtInvitationByID, r.RowID, r.TeamID)
+	err := env.DBs.Read.Get(&inv, licence.StmtInvitationByID, r.RowID, r.TeamID, r.UnitID, r.UnitID)
 	if err != nil {
 		return licence.Invitation{}, err
 	}
@@ -44,40 +45,14 @@ func (env Env) CreateInvitation(params input.InvitationParams, p admin.PassportC
 	}
 
 	// Retrieve the licence for whom the invitation will be created.
-	lic, err := tx.LockLicence(admin.AccessRight{
-		RowID:  params.LicenceID,
-		TeamID: p.TeamID.String,
-	})
-	if err != nil {
-		sugar.Error(err)
-		_ = tx.Rollback()
-		return licence.Licence{}, err
-	}
-	// Ensure that the licence is not granted to anyone,
-	// and it has no assignee attached to it.
-	if !lic.IsAvailable() {
-		_ = tx.Rollback()
-		return licence.Licence{}, err
-	}
-
-	// Create invitation and update existing licence's latest invitation field.
-	invitedLic, err := lic.CreateInvitation(params, p)
+	lic, err := tx.LockLicence(p.AccessRight(params.LicenceID))
 	if err != nil {
 		sugar.Error(err)
 		_ = tx.Rollback()
 		return licence.Licence{}, err
 	}
 
-	// Save invitation.
-	err = tx.CreateInvitation(invitedLic.LatestInvitation.Invitation)
-	if err != nil {
-		sugar.Error(err)
-		_ = tx.Rollback()
-		return licence.Licence{}, err
-	}
-
-	// Update licence
-	err = tx.UpdateLicenceStatus(invitedLic)
+	invitedLic, err := inviteTx(tx, lic, params, p)
 	if err != nil {
 		sugar.Error(err)
 		_ = tx.Rollback()
@@ -162,13 +137,15 @@ func (env Env) RevokeInvitation(invID, teamID string) (licence.InvitationRevoked
 }
 
 // List invitations shows a list of invitations for a team.
-func (env Env) listInvitations(teamID string, page gorest.Pagination) ([]licence.Invitation, error) {
+func (env Env) listInvitations(teamID string, unitID null.String, page gorest.Pagination) ([]licence.Invitation, error) {
 	var invs = make([]licence.Invitation, 0)
 
 	err := env.DBs.Read.Select(
 		&invs,
 		licence.StmtListInvitation,
 		teamID,
+		unitID,
+		unitID,
 		page.Limit,
 		page.Offset())
 
@@ -179,13 +156,15 @@ func (env Env) listInvitations(teamID string, page gorest.Pagination) ([]licence
 	return invs, nil
 }
 
-func (env Env) countInvitation(teamID string) (int64, error) {
+func (env Env) countInvitation(teamID string, unitID null.String) (int64, error) {
 	var total int64
 
 	err := env.DBs.Read.Get(
 		&total,
 		licence.StmtCountInvitation,
-		teamID)
+		teamID,
+		unitID,
+		unitID)
 
 	if err != nil {
 		return total, err
@@ -194,7 +173,7 @@ func (env Env) countInvitation(teamID string) (int64, error) {
 	return total, nil
 }
 
-func (env Env) ListInvitations(teamID string, page gorest.Pagination) (licence.InvitationList, error) {
+func (env Env) ListInvitations(teamID string, unitID null.String, page gorest.Pagination) (licence.InvitationList, error) {
 	defer env.logger.Sync()
 	sugar := env.logger.Sugar()
 
@@ -203,7 +182,7 @@ func (env Env) ListInvitations(teamID string, page gorest.Pagination) (licence.I
 
 	go func() {
 		defer close(countCh)
-		n, err := env.countInvitation(teamID)
+		n, err := env.countInvitation(teamID, unitID)
 		if err != nil {
 			sugar.Error(err)
 		}
@@ -214,7 +193,7 @@ func (env Env) ListInvitations(teamID string, page gorest.Pagination) (licence.I
 	go func() {
 		defer close(listCh)
 
-		invs, err := env.listInvitations(teamID, page)
+		invs, err := env.listInvitations(teamID, unitID, page)
 
 		listCh <- licence.InvitationList{
 			PagedList: pkg.PagedList{
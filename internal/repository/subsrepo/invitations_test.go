@@ -242,7 +242,7 @@ func TestEnv_countInvitation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			got, err := env.countInvitation(tt.args.teamID)
+			got, err := env.countInvitation(tt.args.teamID, null.String{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("countInvitation() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -288,7 +288,7 @@ func TestEnv_listInvitations(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			got, err := env.listInvitations(tt.args.teamID, tt.args.page)
+			got, err := env.listInvitations(tt.args.teamID, null.String{}, tt.args.page)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("listInvitations() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -334,7 +334,7 @@ func TestEnv_ListInvitations(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			got, err := env.ListInvitations(tt.args.teamID, tt.args.page)
+			got, err := env.ListInvitations(tt.args.teamID, null.String{}, tt.args.page)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ListInvitations() error = %v, wantErr %v", err, tt.wantErr)
 				return
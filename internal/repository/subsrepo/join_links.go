@@ -0,0 +1,256 @@
+package subsrepo
+
+import (
+	"database/sql"
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	gorest "github.com/FTChinese/go-rest"
+	"github.com/guregu/null"
+)
+
+func (env Env) CreateJoinLink(l licence.JoinLink) error {
+	_, err := env.DBs.Write.NamedExec(licence.StmtCreateJoinLink, l)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (env Env) listJoinLinks(teamID string, page gorest.Pagination) ([]licence.JoinLink, error) {
+	var list = make([]licence.JoinLink, 0)
+	err := env.DBs.Read.Select(
+		&list,
+		licence.StmtListJoinLinks,
+		teamID,
+		page.Limit,
+		page.Offset())
+	if err != nil {
+		return nil, err
+	}
+
+	for i, l := range list {
+		list[i] = l.WithURL()
+	}
+
+	return list, nil
+}
+
+func (env Env) countJoinLinks(teamID string) (int64, error) {
+	var total int64
+	err := env.DBs.Read.Get(&total, licence.StmtCountJoinLinks, teamID)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (env Env) ListJoinLinks(teamID string, page gorest.Pagination) (licence.JoinLinkList, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	countCh := make(chan int64)
+	listCh := make(chan licence.JoinLinkList)
+
+	go func() {
+		defer close(countCh)
+		n, err := env.countJoinLinks(teamID)
+		if err != nil {
+			sugar.Error(err)
+		}
+
+		countCh <- n
+	}()
+
+	go func() {
+		defer close(listCh)
+		list, err := env.listJoinLinks(teamID, page)
+
+		listCh <- licence.JoinLinkList{
+			PagedList: pkg.PagedList{
+				Err: err,
+			},
+			Data: list,
+		}
+	}()
+
+	count, listResult := <-countCh, <-listCh
+	if listResult.Err != nil {
+		return licence.JoinLinkList{}, listResult.Err
+	}
+
+	return licence.JoinLinkList{
+		PagedList: pkg.PagedList{
+			Total:      count,
+			Pagination: page,
+			Err:        nil,
+		},
+		Data: listResult.Data,
+	}, nil
+}
+
+// JoinLinkByToken finds a link opened by reader.
+func (env Env) JoinLinkByToken(token string) (licence.JoinLink, error) {
+	var l licence.JoinLink
+	err := env.DBs.Read.Get(&l, licence.StmtJoinLinkByToken, token)
+	if err != nil {
+		return licence.JoinLink{}, err
+	}
+
+	return l, nil
+}
+
+// RevokeJoinLink stops a link from granting more licences.
+// Licences already granted via it are not affected.
+func (env Env) RevokeJoinLink(r admin.AccessRight) (licence.JoinLink, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	tx, err := env.beginTx()
+	if err != nil {
+		sugar.Error(err)
+		return licence.JoinLink{}, err
+	}
+
+	var l licence.JoinLink
+	err = tx.Get(&l, licence.StmtLockJoinLink, r.RowID, r.TeamID)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.JoinLink{}, err
+	}
+
+	l = l.Revoked()
+	_, err = tx.NamedExec(licence.StmtUpdateJoinLink, l)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.JoinLink{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return licence.JoinLink{}, err
+	}
+
+	return l.WithURL(), nil
+}
+
+// JoinTeam grants a licence of the link's edition to a reader.
+// An invitation is created on behalf of the link's creator
+// and accepted immediately so that history stays the same
+// as per-email invitations.
+// Seat, invitation and grant are saved in one transaction
+// so that seat cap is respected under concurrent access.
+func (env Env) JoinTeam(token string, to licence.Assignee) (licence.TeamJoined, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	tx, err := env.beginTx()
+	if err != nil {
+		sugar.Error(err)
+		return licence.TeamJoined{}, err
+	}
+
+	var link licence.JoinLink
+	err = tx.Get(&link, licence.StmtLockJoinLinkByToken, token)
+	if err != nil {
+		_ = tx.Rollback()
+		if err == sql.ErrNoRows {
+			return licence.TeamJoined{}, ErrInvalidJoinLink
+		}
+		sugar.Error(err)
+		return licence.TeamJoined{}, err
+	}
+	if !link.IsJoinable() {
+		_ = tx.Rollback()
+		return licence.TeamJoined{}, ErrInvalidJoinLink
+	}
+	// Email of an account could be set to anything. Only a
+	// verified one proves that reader belongs to the domain.
+	var verified bool
+	err = tx.Get(&verified, licence.StmtIsEmailVerified, to.FtcID.String)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.TeamJoined{}, err
+	}
+	if !verified {
+		_ = tx.Rollback()
+		return licence.TeamJoined{}, ErrEmailNotVerified
+	}
+	if !link.Domains.Contains(to.Email.String) {
+		_ = tx.Rollback()
+		return licence.TeamJoined{}, ErrDomainNotAllowed
+	}
+
+	var joined bool
+	err = tx.Get(&joined, licence.StmtJoinLinkUseExists, link.ID, to.FtcID.String)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.TeamJoined{}, err
+	}
+	if joined {
+		_ = tx.Rollback()
+		return licence.TeamJoined{}, ErrAlreadyJoined
+	}
+
+	lic, err := tx.LockAvailableEditionLicence(link.TeamID, link.Edition)
+	if err != nil {
+		_ = tx.Rollback()
+		if err == sql.ErrNoRows {
+			return licence.TeamJoined{}, ErrNoAvailableLicence
+		}
+		sugar.Error(err)
+		return licence.TeamJoined{}, err
+	}
+
+	result, err := inviteAndGrantTx(
+		tx,
+		lic,
+		input.InvitationParams{
+			Email:       to.Email.String,
+			Description: null.StringFrom("Join link " + link.ID),
+			LicenceID:   lic.ID,
+		},
+		admin.PassportClaims{
+			AdminID: link.AdminID,
+			TeamID:  null.StringFrom(link.TeamID),
+		},
+		to)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.TeamJoined{}, err
+	}
+
+	use := licence.NewJoinLinkUse(link, to).
+		WithInvitation(result.LicenceVersion.PostChange.LatestInvitation.Invitation)
+	_, err = tx.NamedExec(licence.StmtCreateJoinLinkUse, use)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.TeamJoined{}, err
+	}
+
+	_, err = tx.NamedExec(licence.StmtUpdateJoinLink, link.SeatTaken())
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.TeamJoined{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return licence.TeamJoined{}, err
+	}
+
+	return licence.TeamJoined{
+		Use:         use,
+		GrantResult: result,
+	}, nil
+}
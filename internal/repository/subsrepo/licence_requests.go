@@ -0,0 +1,325 @@
+package subsrepo
+
+import (
+	"database/sql"
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/FTChinese/ftacademy/internal/repository/txrepo"
+	gorest "github.com/FTChinese/go-rest"
+	"github.com/guregu/null"
+)
+
+// TeamByDomain finds the team which registered the email domain.
+// Returns ErrNoTeamForDomain if not found.
+func (env Env) TeamByDomain(domain string) (admin.Team, error) {
+	var t admin.Team
+	err := env.DBs.Read.Get(&t, admin.StmtTeamByDomain, domain)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return admin.Team{}, ErrNoTeamForDomain
+		}
+		return admin.Team{}, err
+	}
+
+	return t, nil
+}
+
+func (env Env) openRequestExists(ftcID, teamID string) (bool, error) {
+	var ok bool
+	err := env.DBs.Read.Get(&ok, licence.StmtOpenRequestExists, ftcID, teamID)
+	if err != nil {
+		return false, err
+	}
+
+	return ok, nil
+}
+
+// CreateLicenceRequest saves a reader's request for licence
+// using company email. The request should be verified
+// by the token sent to the email.
+func (env Env) CreateLicenceRequest(ftcID string, params input.LicenceRequestParams) (licence.LicenceRequest, error) {
+	team, err := env.TeamByDomain(params.EmailDomain())
+	if err != nil {
+		return licence.LicenceRequest{}, err
+	}
+
+	ok, err := env.openRequestExists(ftcID, team.ID)
+	if err != nil {
+		return licence.LicenceRequest{}, err
+	}
+	if ok {
+		return licence.LicenceRequest{}, ErrRequestExists
+	}
+
+	r, err := licence.NewLicenceRequest(ftcID, params.Email, team.ID)
+	if err != nil {
+		return licence.LicenceRequest{}, err
+	}
+
+	_, err = env.DBs.Write.NamedExec(licence.StmtCreateLicenceRequest, r)
+	if err != nil {
+		return licence.LicenceRequest{}, err
+	}
+
+	return r, nil
+}
+
+func (env Env) updateLicenceRequest(r licence.LicenceRequest) error {
+	_, err := env.DBs.Write.NamedExec(licence.StmtUpdateLicenceRequest, r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// VerifyLicenceRequest verifies the company email of a request
+// and puts it into admin's approval queue.
+// The request must be created by the same reader.
+func (env Env) VerifyLicenceRequest(token string, ftcID string) (licence.LicenceRequest, error) {
+	var r licence.LicenceRequest
+	err := env.DBs.Read.Get(&r, licence.StmtLicenceRequestByToken, token)
+	if err != nil {
+		return licence.LicenceRequest{}, err
+	}
+
+	if r.FtcID != ftcID || !r.IsVerifiable() {
+		return licence.LicenceRequest{}, sql.ErrNoRows
+	}
+
+	r = r.Verified()
+	err = env.updateLicenceRequest(r)
+	if err != nil {
+		return licence.LicenceRequest{}, err
+	}
+
+	return r, nil
+}
+
+// ListReaderLicenceRequests shows a reader's recent requests.
+func (env Env) ListReaderLicenceRequests(ftcID string) ([]licence.LicenceRequest, error) {
+	var list = make([]licence.LicenceRequest, 0)
+	err := env.DBs.Read.Select(&list, licence.StmtListReaderLicenceRequests, ftcID)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (env Env) listLicenceRequests(teamID string, status licence.RequestStatus, page gorest.Pagination) ([]licence.LicenceRequest, error) {
+	var list = make([]licence.LicenceRequest, 0)
+	err := env.DBs.Read.Select(
+		&list,
+		licence.StmtListTeamLicenceRequests,
+		teamID,
+		status,
+		page.Limit,
+		page.Offset())
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (env Env) countLicenceRequests(teamID string, status licence.RequestStatus) (int64, error) {
+	var total int64
+	err := env.DBs.Read.Get(
+		&total,
+		licence.StmtCountTeamLicenceRequests,
+		teamID,
+		status)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// ListLicenceRequests shows the requests of a team in the
+// specified status, which is the approval queue for pending ones.
+func (env Env) ListLicenceRequests(teamID string, status licence.RequestStatus, page gorest.Pagination) (licence.LicenceRequestList, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	countCh := make(chan int64)
+	listCh := make(chan licence.LicenceRequestList)
+
+	go func() {
+		defer close(countCh)
+		n, err := env.countLicenceRequests(teamID, status)
+		if err != nil {
+			sugar.Error(err)
+		}
+
+		countCh <- n
+	}()
+
+	go func() {
+		defer close(listCh)
+		list, err := env.listLicenceRequests(teamID, status, page)
+
+		listCh <- licence.LicenceRequestList{
+			PagedList: pkg.PagedList{
+				Err: err,
+			},
+			Data: list,
+		}
+	}()
+
+	count, listResult := <-countCh, <-listCh
+	if listResult.Err != nil {
+		return licence.LicenceRequestList{}, listResult.Err
+	}
+
+	return licence.LicenceRequestList{
+		PagedList: pkg.PagedList{
+			Total:      count,
+			Pagination: page,
+			Err:        nil,
+		},
+		Data: listResult.Data,
+	}, nil
+}
+
+// claimLicenceRequest locks a pending request and changes
+// its status so that it won't be processed twice.
+func (env Env) claimLicenceRequest(r admin.AccessRight, update func(licence.LicenceRequest) licence.LicenceRequest) (licence.LicenceRequest, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	tx, err := env.beginTx()
+	if err != nil {
+		sugar.Error(err)
+		return licence.LicenceRequest{}, err
+	}
+
+	var lr licence.LicenceRequest
+	err = tx.Get(&lr, licence.StmtLockLicenceRequest, r.RowID, r.TeamID)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.LicenceRequest{}, err
+	}
+	if !lr.IsPending() {
+		_ = tx.Rollback()
+		return licence.LicenceRequest{}, sql.ErrNoRows
+	}
+
+	lr = update(lr)
+
+	_, err = tx.NamedExec(licence.StmtUpdateLicenceRequest, lr)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.LicenceRequest{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return licence.LicenceRequest{}, err
+	}
+
+	return lr, nil
+}
+
+// RejectLicenceRequest removes a request from approval queue.
+func (env Env) RejectLicenceRequest(r admin.AccessRight) (licence.LicenceRequest, error) {
+	return env.claimLicenceRequest(r, func(lr licence.LicenceRequest) licence.LicenceRequest {
+		return lr.Rejected()
+	})
+}
+
+// lockRequestedLicence locks the licence chosen by admin,
+// or picks one if r.RowID is empty.
+func lockRequestedLicence(tx txrepo.TxRepo, r admin.AccessRight) (licence.Licence, error) {
+	if r.RowID != "" {
+		return tx.LockLicence(r)
+	}
+
+	lic, err := tx.LockAvailableLicence(r)
+	if err == sql.ErrNoRows {
+		return licence.Licence{}, ErrNoAvailableLicence
+	}
+
+	return lic, err
+}
+
+// ApproveLicenceRequest picks an available licence if not
+// specified, creates an invitation for the request email and
+// accepts it immediately on behalf of the reader, so that
+// reader does not need to open another invitation email.
+// Everything happens in one transaction with the request and
+// licence locked.
+func (env Env) ApproveLicenceRequest(
+	r admin.AccessRight,
+	params input.ApproveRequestParams,
+	claims admin.PassportClaims,
+) (licence.RequestApproved, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	tx, err := env.beginTx()
+	if err != nil {
+		sugar.Error(err)
+		return licence.RequestApproved{}, err
+	}
+
+	var lr licence.LicenceRequest
+	err = tx.Get(&lr, licence.StmtLockLicenceRequest, r.RowID, r.TeamID)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.RequestApproved{}, err
+	}
+	if !lr.IsPending() {
+		_ = tx.Rollback()
+		return licence.RequestApproved{}, sql.ErrNoRows
+	}
+
+	assignee, err := env.RetrieveAssignee(lr.FtcID)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.RequestApproved{}, err
+	}
+
+	lic, err := lockRequestedLicence(tx, claims.AccessRight(params.LicenceID))
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.RequestApproved{}, err
+	}
+
+	result, err := inviteAndGrantTx(tx, lic, input.InvitationParams{
+		Email:       lr.Email,
+		Description: null.StringFrom("Self-service licence request " + lr.ID),
+		LicenceID:   lic.ID,
+	}, claims, assignee)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.RequestApproved{}, err
+	}
+
+	lr = lr.Approved(lic.ID)
+	_, err = tx.NamedExec(licence.StmtUpdateLicenceRequest, lr)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.RequestApproved{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return licence.RequestApproved{}, err
+	}
+
+	return licence.RequestApproved{
+		Request:     lr,
+		GrantResult: result,
+	}, nil
+}
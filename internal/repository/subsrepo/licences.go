@@ -1,12 +1,16 @@
 package subsrepo
 
 import (
+	"database/sql"
 	"errors"
 	"github.com/FTChinese/ftacademy/internal/pkg"
 	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
 	"github.com/FTChinese/ftacademy/internal/pkg/licence"
 	"github.com/FTChinese/ftacademy/internal/pkg/reader"
 	gorest "github.com/FTChinese/go-rest"
+	"github.com/FTChinese/go-rest/chrono"
+	"github.com/guregu/null"
 )
 
 // LoadLicence retrieves a licence, together with its
@@ -14,7 +18,7 @@ import (
 // If the licence is not assigned yet, assignee fields are empty..
 func (env Env) LoadLicence(r admin.AccessRight) (licence.ExpandedLicence, error) {
 	var lic licence.ExpandedLicence
-	err := env.DBs.Read.Get(&lic, licence.StmtLicence, r.RowID, r.TeamID)
+	err := env.DBs.Read.Get(&lic, licence.StmtLicence, r.RowID, r.TeamID, r.UnitID, r.UnitID)
 
 	if err != nil {
 		return licence.ExpandedLicence{}, err
@@ -25,13 +29,15 @@ func (env Env) LoadLicence(r admin.AccessRight) (licence.ExpandedLicence, error)
 
 // listLicences shows a list all licence.
 // Each licence's plan, invitation, assignee are attached.
-func (env Env) listLicences(teamID string, page gorest.Pagination) ([]licence.ExpandedLicence, error) {
+func (env Env) listLicences(teamID string, unitID null.String, page gorest.Pagination) ([]licence.ExpandedLicence, error) {
 	var licences = make([]licence.ExpandedLicence, 0)
 
 	err := env.DBs.Read.Select(
 		&licences,
 		licence.StmtListLicences,
 		teamID,
+		unitID,
+		unitID,
 		page.Limit,
 		page.Offset(),
 	)
@@ -43,16 +49,18 @@ func (env Env) listLicences(teamID string, page gorest.Pagination) ([]licence.Ex
 	return licences, nil
 }
 
-func (env Env) countLicences(teamID string) (int64, error) {
+func (env Env) countLicences(teamID string, unitID null.String) (int64, error) {
 	var total int64
-	if err := env.DBs.Read.Get(&total, licence.StmtCountLicence, teamID); err != nil {
+	if err := env.DBs.Read.Get(&total, licence.StmtCountLicence, teamID, unitID, unitID); err != nil {
 		return total, err
 	}
 
 	return total, nil
 }
 
-func (env Env) ListLicence(teamID string, page gorest.Pagination) (licence.PagedLicenceList, error) {
+// ListLicence shows licences of a team, or only those of a
+// unit if unitID is not null.
+func (env Env) ListLicence(teamID string, unitID null.String, page gorest.Pagination) (licence.PagedLicenceList, error) {
 	defer env.logger.Sync()
 	sugar := env.logger.Sugar()
 
@@ -61,7 +69,7 @@ func (env Env) ListLicence(teamID string, page gorest.Pagination) (licence.Paged
 
 	go func() {
 		defer close(countCh)
-		n, err := env.countLicences(teamID)
+		n, err := env.countLicences(teamID, unitID)
 		if err != nil {
 			sugar.Error(err)
 		}
@@ -71,7 +79,7 @@ func (env Env) ListLicence(teamID string, page gorest.Pagination) (licence.Paged
 
 	go func() {
 		defer close(listCh)
-		licences, err := env.listLicences(teamID, page)
+		licences, err := env.listLicences(teamID, unitID, page)
 
 		listCh <- licence.PagedLicenceList{
 			PagedList: pkg.PagedList{
@@ -136,51 +144,15 @@ func (env Env) GrantLicence(r admin.AccessRight, to licence.Assignee) (licence.G
 		return licence.GrantResult{}, errors.New("invitation is not acceptable")
 	}
 
-	// Retrieve membership for this user.
-	mmb, err := tx.LockMember(to.FtcID.String)
+	result, err := grantTx(tx, lic, inv, to)
 	if err != nil {
 		sugar.Error(err)
 		_ = tx.Rollback()
 		return licence.GrantResult{}, err
 	}
 
-	// TODO: if
-	result, err := licence.GrantLicence(licence.GrantParams{
-		CurLic:    lic,
-		CurInv:    inv,
-		To:        to,
-		CurMember: mmb,
-	})
-
-	if err != nil {
-		_ = tx.Rollback()
-		return licence.GrantResult{}, err
-	}
-
-	// Update invitation
-	err = tx.UpdateInvitationStatus(result.LicenceVersion.PostChange.LatestInvitation.Invitation)
-	if err != nil {
-		sugar.Error(err)
-		_ = tx.Rollback()
-		return licence.GrantResult{}, err
-	}
-
-	// Update licence
-	err = tx.UpdateLicenceStatus(result.LicenceVersion.PostChange.Licence)
-	if err != nil {
-		sugar.Error(err)
-		_ = tx.Rollback()
-		return licence.GrantResult{}, err
-	}
-
-	// Upsert a new row if the current membership is empty.
-	// Is current membership is zero, do insert;
-	// otherwise update.
-	err = tx.UpsertMember(result.MembershipVersion.PostChange.Membership, mmb.IsZero())
-	if err != nil {
-		sugar.Error(err)
-		_ = tx.Rollback()
-		return licence.GrantResult{}, err
+	if result.Deferred {
+		sugar.Infof("Deferring grant of licence %s to %s", lic.ID, to.FtcID.String)
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -259,7 +231,7 @@ func (env Env) RevokeLicence(r admin.AccessRight) (licence.RevokeResult, error)
 	if !lic.IsRevocable() {
 		sugar.Error(err)
 		_ = tx.Rollback()
-		return licence.RevokeResult{}, errors.New("nothing to revoke")
+		return licence.RevokeResult{}, ErrNotRevocable
 	}
 
 	mmb, err := tx.LockMember(lic.AssigneeID.String)
@@ -270,7 +242,16 @@ func (env Env) RevokeLicence(r admin.AccessRight) (licence.RevokeResult, error)
 	}
 	sugar.Infof("AnteChange to revoke: %v", mmb)
 
-	result, err := licence.RevokeLicence(lic, mmb)
+	// Find the carry-over invoice in case the membership
+	// prior to granting should be restored.
+	carryOver, err := tx.LockLicenceCarryOver(mmb.CompoundID, lic.LatestTransactionID.String)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.RevokeResult{}, err
+	}
+
+	result, err := licence.RevokeLicence(lic, mmb, carryOver)
 	if err != nil {
 		_ = tx.Rollback()
 		return licence.RevokeResult{}, err
@@ -280,14 +261,24 @@ func (env Env) RevokeLicence(r admin.AccessRight) (licence.RevokeResult, error)
 	if err != nil {
 		sugar.Error(err)
 		_ = tx.Rollback()
-		return licence.RevokeResult{}, nil
+		return licence.RevokeResult{}, err
 	}
 
 	err = tx.UpdateMember(result.MembershipVersioned.PostChange.Membership)
 	if err != nil {
 		sugar.Error(err)
 		_ = tx.Rollback()
-		return licence.RevokeResult{}, nil
+		return licence.RevokeResult{}, err
+	}
+
+	if !result.ConsumedInvoice.IsZero() {
+		sugar.Infof("Consuming carried over invoice %v", result.ConsumedInvoice)
+		err = tx.ConsumeInvoice(result.ConsumedInvoice)
+		if err != nil {
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return licence.RevokeResult{}, err
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -297,3 +288,58 @@ func (env Env) RevokeLicence(r admin.AccessRight) (licence.RevokeResult, error)
 
 	return result, nil
 }
+
+// AllocateLicences moves licences of a team into a unit, or
+// back to team level if unit id is null.
+func (env Env) AllocateLicences(teamID string, params input.LicenceAllocationParams) (licence.LicencesAllocated, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	result := licence.LicencesAllocated{
+		UnitID:   params.UnitID,
+		NotFound: make([]string, 0),
+	}
+
+	tx, err := env.beginTx()
+	if err != nil {
+		sugar.Error(err)
+		return licence.LicencesAllocated{}, err
+	}
+
+	now := chrono.TimeNow()
+	for _, id := range params.LicenceIDs {
+		_, err := tx.LockLicence(admin.AccessRight{
+			RowID:  id,
+			TeamID: teamID,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				result.NotFound = append(result.NotFound, id)
+				continue
+			}
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return licence.LicencesAllocated{}, err
+		}
+
+		_, err = tx.NamedExec(licence.StmtAllocateLicence, licence.Allocation{
+			LicenceID:  id,
+			TeamID:     teamID,
+			UnitID:     params.UnitID,
+			UpdatedUTC: now,
+		})
+		if err != nil {
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return licence.LicencesAllocated{}, err
+		}
+		result.Allocated++
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return licence.LicencesAllocated{}, err
+	}
+
+	return result, nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/FTChinese/ftacademy/internal/pkg/licence"
 	"github.com/FTChinese/ftacademy/pkg/db"
 	gorest "github.com/FTChinese/go-rest"
+	"github.com/guregu/null"
 	"go.uber.org/zap/zaptest"
 	"reflect"
 	"testing"
@@ -88,7 +89,7 @@ func TestEnv_listLicences(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := env.listLicences(tt.args.teamID, tt.args.page)
+			got, err := env.listLicences(tt.args.teamID, null.String{}, tt.args.page)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("listLicences() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -130,7 +131,7 @@ func TestEnv_countLicences(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			got, err := env.countLicences(tt.args.teamID)
+			got, err := env.countLicences(tt.args.teamID, null.String{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("countLicences() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -0,0 +1,353 @@
+package subsrepo
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	gorest "github.com/FTChinese/go-rest"
+	"github.com/guregu/null"
+)
+
+// CreateRedeemCodes converts available licences of a team
+// into redemption codes.
+// Returns ErrNoAvailableLicence if there are fewer available
+// licences than requested.
+func (env Env) CreateRedeemCodes(params input.RedeemCodeParams, by admin.Creator) ([]licence.RedeemCode, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	tx, err := env.beginTx()
+	if err != nil {
+		sugar.Error(err)
+		return nil, err
+	}
+
+	var licIDs = make([]string, 0)
+	err = tx.Select(&licIDs, licence.StmtLockAvailableLicenceIDs, by.TeamID, params.Count)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if int64(len(licIDs)) < params.Count {
+		_ = tx.Rollback()
+		return nil, ErrNoAvailableLicence
+	}
+
+	var codes = make([]licence.RedeemCode, 0, len(licIDs))
+	for _, id := range licIDs {
+		lic, err := tx.LockLicence(admin.AccessRight{
+			RowID:  id,
+			TeamID: by.TeamID,
+		})
+		if err != nil {
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return nil, err
+		}
+
+		rc, err := licence.NewRedeemCode(lic.ID, params, by)
+		if err != nil {
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return nil, err
+		}
+
+		_, err = tx.NamedExec(licence.StmtCreateRedeemCode, rc)
+		if err != nil {
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return nil, err
+		}
+
+		err = tx.UpdateLicenceStatus(lic.WithCoded())
+		if err != nil {
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return nil, err
+		}
+
+		codes = append(codes, rc)
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (env Env) LoadRedeemCode(r admin.AccessRight) (licence.RedeemCode, error) {
+	var rc licence.RedeemCode
+	err := env.DBs.Read.Get(&rc, licence.StmtRedeemCode, r.RowID, r.TeamID)
+	if err != nil {
+		return licence.RedeemCode{}, err
+	}
+
+	return rc, nil
+}
+
+func (env Env) listRedeemCodes(teamID string, status licence.RedeemStatus, page gorest.Pagination) ([]licence.RedeemCode, error) {
+	var list = make([]licence.RedeemCode, 0)
+	err := env.DBs.Read.Select(
+		&list,
+		licence.StmtListRedeemCodes,
+		teamID,
+		status,
+		page.Limit,
+		page.Offset())
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (env Env) countRedeemCodes(teamID string, status licence.RedeemStatus) (int64, error) {
+	var total int64
+	err := env.DBs.Read.Get(&total, licence.StmtCountRedeemCodes, teamID, status)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// ListRedeemCodes shows codes of a team in the specified status.
+func (env Env) ListRedeemCodes(teamID string, status licence.RedeemStatus, page gorest.Pagination) (licence.RedeemCodeList, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	countCh := make(chan int64)
+	listCh := make(chan licence.RedeemCodeList)
+
+	go func() {
+		defer close(countCh)
+		n, err := env.countRedeemCodes(teamID, status)
+		if err != nil {
+			sugar.Error(err)
+		}
+
+		countCh <- n
+	}()
+
+	go func() {
+		defer close(listCh)
+		list, err := env.listRedeemCodes(teamID, status, page)
+
+		listCh <- licence.RedeemCodeList{
+			PagedList: pkg.PagedList{
+				Err: err,
+			},
+			Data: list,
+		}
+	}()
+
+	count, listResult := <-countCh, <-listCh
+	if listResult.Err != nil {
+		return licence.RedeemCodeList{}, listResult.Err
+	}
+
+	return licence.RedeemCodeList{
+		PagedList: pkg.PagedList{
+			Total:      count,
+			Pagination: page,
+			Err:        nil,
+		},
+		Data: listResult.Data,
+	}, nil
+}
+
+// ListActiveRedeemCodes retrieves all codes of a team that
+// could still be redeemed, used to export csv.
+func (env Env) ListActiveRedeemCodes(teamID string) ([]licence.RedeemCode, error) {
+	var list = make([]licence.RedeemCode, 0)
+	err := env.DBs.Read.Select(&list, licence.StmtListActiveRedeemCodes, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ListExpiredRedeemCodes finds active codes passed expiry.
+func (env Env) ListExpiredRedeemCodes() ([]licence.RedeemCode, error) {
+	var list = make([]licence.RedeemCode, 0)
+	err := env.DBs.Read.Select(&list, licence.StmtListExpiredRedeemCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// releaseRedeemCode invalidates an active code and makes
+// its licence available again.
+func (env Env) releaseRedeemCode(r admin.AccessRight, update func(licence.RedeemCode) licence.RedeemCode) (licence.RedeemCodeRevoked, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	tx, err := env.beginTx()
+	if err != nil {
+		sugar.Error(err)
+		return licence.RedeemCodeRevoked{}, err
+	}
+
+	var rc licence.RedeemCode
+	err = tx.Get(&rc, licence.StmtLockRedeemCode, r.RowID, r.TeamID)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.RedeemCodeRevoked{}, err
+	}
+	if !rc.IsRevocable() {
+		_ = tx.Rollback()
+		return licence.RedeemCodeRevoked{}, errors.New("code is not revocable")
+	}
+
+	lic, err := tx.LockLicence(admin.AccessRight{
+		RowID:  rc.LicenceID,
+		TeamID: rc.TeamID,
+	})
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.RedeemCodeRevoked{}, err
+	}
+
+	rc = update(rc)
+	_, err = tx.NamedExec(licence.StmtUpdateRedeemCode, rc)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.RedeemCodeRevoked{}, err
+	}
+
+	// The licence might be changed by other means,
+	// e.g. revoked by CMS. Only touch it if still held by this code.
+	if lic.IsCoded() {
+		lic = lic.WithCodeReleased()
+		err = tx.UpdateLicenceStatus(lic)
+		if err != nil {
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return licence.RedeemCodeRevoked{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return licence.RedeemCodeRevoked{}, err
+	}
+
+	return licence.RedeemCodeRevoked{
+		Code:    rc,
+		Licence: lic,
+	}, nil
+}
+
+// RevokeRedeemCode is used by admin to take back the licence
+// held by an active code.
+func (env Env) RevokeRedeemCode(r admin.AccessRight) (licence.RedeemCodeRevoked, error) {
+	return env.releaseRedeemCode(r, func(rc licence.RedeemCode) licence.RedeemCode {
+		return rc.Revoked()
+	})
+}
+
+// ExpireRedeemCode releases the licence of an expired code.
+func (env Env) ExpireRedeemCode(rc licence.RedeemCode) (licence.RedeemCodeRevoked, error) {
+	return env.releaseRedeemCode(admin.AccessRight{
+		RowID:  rc.ID,
+		TeamID: rc.TeamID,
+	}, func(rc licence.RedeemCode) licence.RedeemCode {
+		return rc.Expired()
+	})
+}
+
+// RedeemCode grants the licence held by a code to a reader.
+// The code is claimed, an invitation is created on behalf of
+// the code's creator and accepted immediately, all in one
+// transaction while the licence is locked, the same way as
+// ApproveLicenceRequest does.
+func (env Env) RedeemCode(code string, to licence.Assignee) (licence.CodeRedeemed, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	tx, err := env.beginTx()
+	if err != nil {
+		sugar.Error(err)
+		return licence.CodeRedeemed{}, err
+	}
+
+	var rc licence.RedeemCode
+	err = tx.Get(&rc, licence.StmtLockRedeemCodeByCode, code)
+	if err != nil {
+		_ = tx.Rollback()
+		if err == sql.ErrNoRows {
+			return licence.CodeRedeemed{}, ErrInvalidRedeemCode
+		}
+		sugar.Error(err)
+		return licence.CodeRedeemed{}, err
+	}
+	if !rc.IsRedeemable() {
+		_ = tx.Rollback()
+		return licence.CodeRedeemed{}, ErrInvalidRedeemCode
+	}
+
+	lic, err := tx.LockLicence(admin.AccessRight{
+		RowID:  rc.LicenceID,
+		TeamID: rc.TeamID,
+	})
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.CodeRedeemed{}, err
+	}
+	if !lic.IsCoded() {
+		_ = tx.Rollback()
+		return licence.CodeRedeemed{}, ErrInvalidRedeemCode
+	}
+
+	rc = rc.Redeemed(to.FtcID.String)
+	_, err = tx.NamedExec(licence.StmtUpdateRedeemCode, rc)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.CodeRedeemed{}, err
+	}
+
+	// Release the licence from the code so that it goes
+	// through the invitation flow.
+	result, err := inviteAndGrantTx(
+		tx,
+		lic.WithCodeReleased(),
+		input.InvitationParams{
+			Email:       to.Email.String,
+			Description: null.StringFrom("Redemption code " + rc.ID),
+			LicenceID:   rc.LicenceID,
+		},
+		admin.PassportClaims{
+			AdminID: rc.AdminID,
+			TeamID:  null.StringFrom(rc.TeamID),
+		},
+		to)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.CodeRedeemed{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return licence.CodeRedeemed{}, err
+	}
+
+	return licence.CodeRedeemed{
+		Code:        rc,
+		GrantResult: result,
+	}, nil
+}
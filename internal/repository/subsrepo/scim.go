@@ -0,0 +1,56 @@
+package subsrepo
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+)
+
+func (env Env) LoadScimSetting(teamID string) (admin.ScimSetting, error) {
+	var s admin.ScimSetting
+	err := env.DBs.Read.Get(&s, admin.StmtScimSetting, teamID)
+	if err != nil {
+		return admin.ScimSetting{}, err
+	}
+
+	return s, nil
+}
+
+// ScimSettingByToken finds the team of a bearer token.
+func (env Env) ScimSettingByToken(token string) (admin.ScimSetting, error) {
+	var s admin.ScimSetting
+	err := env.DBs.Read.Get(&s, admin.StmtScimSettingByToken, admin.HashToken(token))
+	if err != nil {
+		return admin.ScimSetting{}, err
+	}
+
+	return s, nil
+}
+
+// SaveScimSetting creates a token for a team, replacing the
+// previous one if exists.
+func (env Env) SaveScimSetting(s admin.ScimSetting) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtUpsertScimSetting, s)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (env Env) UpdateScimAutoInvite(s admin.ScimSetting) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtUpdateScimAutoInvite, s)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteScimSetting disables SCIM provisioning of a team.
+func (env Env) DeleteScimSetting(teamID string) error {
+	_, err := env.DBs.Write.Exec(admin.StmtDeleteScimSetting, teamID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
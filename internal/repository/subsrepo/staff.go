@@ -1,9 +1,14 @@
 package subsrepo
 
 import (
+	"database/sql"
 	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
 	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/FTChinese/ftacademy/internal/pkg/reader"
 	gorest "github.com/FTChinese/go-rest"
+	"github.com/guregu/null"
 )
 
 func (env Env) SaveStaffer(m licence.Staffer) error {
@@ -28,20 +33,205 @@ func (env Env) UpdateStaffer(m licence.Staffer) error {
 	return nil
 }
 
-func (env Env) listStaff(teamID string, page gorest.Pagination) ([]licence.Staffer, error) {
+func (env Env) stafferExists(teamID, email string) (bool, error) {
+	var ok bool
+	err := env.DBs.Read.Get(&ok, licence.StafferExists, teamID, email)
+	if err != nil {
+		return false, err
+	}
+
+	return ok, nil
+}
+
+// CreateStaffer adds a member to team roster.
+// Returns ErrStafferExists if the email is already added.
+func (env Env) CreateStaffer(s licence.Staffer) (licence.Staffer, error) {
+	ok, err := env.stafferExists(s.TeamID, s.Email)
+	if err != nil {
+		return licence.Staffer{}, err
+	}
+	if ok {
+		return licence.Staffer{}, ErrStafferExists
+	}
+
+	result, err := env.DBs.Write.NamedExec(licence.InsertStaffer, s)
+	if err != nil {
+		return licence.Staffer{}, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return licence.Staffer{}, err
+	}
+	s.ID = id
+
+	return s, nil
+}
+
+// ImportStaff adds rows parsed from a csv file.
+// Emails already in roster are skipped.
+// All rows are saved in one transaction so that a failed
+// import leaves nothing behind and could be retried.
+func (env Env) ImportStaff(teamID string, params []input.StafferParams) (licence.StaffImported, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	tx, err := env.beginTx()
+	if err != nil {
+		sugar.Error(err)
+		return licence.StaffImported{}, err
+	}
+
+	var imported licence.StaffImported
+	for _, p := range params {
+		a, err := env.FindAssignee(p.Email)
+		if err != nil {
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return licence.StaffImported{}, err
+		}
+
+		ok, err := tx.InsertStaffer(licence.NewStaffer(p, teamID, a))
+		if err != nil {
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return licence.StaffImported{}, err
+		}
+
+		if ok {
+			imported.Imported++
+		} else {
+			imported.Skipped++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return licence.StaffImported{}, err
+	}
+
+	return imported, nil
+}
+
+func (env Env) LoadStaffer(teamID string, id int64) (licence.Staffer, error) {
+	var s licence.Staffer
+	err := env.DBs.Read.Get(&s, licence.StafferByID, teamID, id)
+	if err != nil {
+		return licence.Staffer{}, err
+	}
+
+	return s, nil
+}
+
+// EditStaffer saves the changed email and name.
+// Returns ErrStafferExists if the new email is used by
+// another staffer.
+func (env Env) EditStaffer(current licence.Staffer, params input.StafferParams) (licence.Staffer, error) {
+	if params.Email != current.Email {
+		ok, err := env.stafferExists(current.TeamID, params.Email)
+		if err != nil {
+			return licence.Staffer{}, err
+		}
+		if ok {
+			return licence.Staffer{}, ErrStafferExists
+		}
+	}
+
+	a, err := env.FindAssignee(params.Email)
+	if err != nil {
+		return licence.Staffer{}, err
+	}
+
+	s := current.Update(params, a)
+	_, err = env.DBs.Write.NamedExec(licence.UpdateStaffer, s)
+	if err != nil {
+		return licence.Staffer{}, err
+	}
+
+	return s, nil
+}
+
+// stafferLicence finds the licence granted to, or invited
+// for, a staffer. Returns nil if not found.
+// If unitID is not null, licences outside the unit are
+// not found.
+func (env Env) stafferLicence(s licence.Staffer, unitID null.String) (*licence.Licence, error) {
+	var lic licence.Licence
+	err := env.DBs.Read.Get(
+		&lic,
+		licence.StmtStafferLicence,
+		s.TeamID,
+		unitID,
+		unitID,
+		s.FtcID,
+		s.Email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &lic, nil
+}
+
+// ExpandStaffer attaches licence and membership to a staffer.
+// Only a licence of the unit is attached if unitID is not null.
+func (env Env) ExpandStaffer(s licence.Staffer, unitID null.String) (licence.ExpandedStaffer, error) {
+	lic, err := env.stafferLicence(s, unitID)
+	if err != nil {
+		return licence.ExpandedStaffer{}, err
+	}
+
+	expanded := licence.ExpandedStaffer{
+		Staffer: s,
+		Licence: lic,
+	}
+
+	if s.FtcID.Valid {
+		m, err := env.RetrieveMembership(s.FtcID.String)
+		if err != nil {
+			return licence.ExpandedStaffer{}, err
+		}
+		expanded.Membership = m
+	}
+
+	return expanded, nil
+}
+
+// listStaff loads a page of staff together with their
+// licences and memberships in three queries, regardless of
+// page size.
+func (env Env) listStaff(teamID string, unitID null.String, page gorest.Pagination) ([]licence.ExpandedStaffer, error) {
 	list := make([]licence.Staffer, 0)
 
-	err := env.DBs.Read.Select(&list, licence.ListStaff, teamID, page.Limit, page.Offset())
+	err := env.DBs.Read.Select(&list, licence.ListStaff, teamID, unitID, unitID, page.Limit, page.Offset())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) == 0 {
+		return []licence.ExpandedStaffer{}, nil
+	}
+
+	lics := make([]licence.StafferLicence, 0)
+	err = env.DBs.Read.Select(&lics, licence.StmtStaffPageLicences, teamID, unitID, unitID, page.Limit, page.Offset(), unitID, unitID)
 	if err != nil {
 		return nil, err
 	}
 
-	return list, nil
+	members := make([]reader.Membership, 0)
+	err = env.DBs.Read.Select(&members, licence.StmtStaffPageMembers, teamID, unitID, unitID, page.Limit, page.Offset())
+	if err != nil {
+		return nil, err
+	}
+
+	return licence.ExpandStaff(list, lics, members), nil
 }
 
-func (env Env) countStaff(teamID string) (int64, error) {
+func (env Env) countStaff(teamID string, unitID null.String) (int64, error) {
 	var total int64
-	err := env.DBs.Read.Get(&total, licence.CountStaff, teamID)
+	err := env.DBs.Read.Get(&total, licence.CountStaff, teamID, unitID, unitID)
 	if err != nil {
 		return 0, err
 	}
@@ -49,7 +239,9 @@ func (env Env) countStaff(teamID string) (int64, error) {
 	return total, nil
 }
 
-func (env Env) ListStaff(teamID string, page gorest.Pagination) (licence.StaffList, error) {
+// ListStaff shows roster of a team, or of a unit if unitID
+// is not null.
+func (env Env) ListStaff(teamID string, unitID null.String, page gorest.Pagination) (licence.StaffList, error) {
 	defer env.logger.Sync()
 	sugar := env.logger.Sugar()
 
@@ -59,7 +251,7 @@ func (env Env) ListStaff(teamID string, page gorest.Pagination) (licence.StaffLi
 	go func() {
 		defer close(countCh)
 
-		n, err := env.countStaff(teamID)
+		n, err := env.countStaff(teamID, unitID)
 		if err != nil {
 			sugar.Error(err)
 		}
@@ -69,7 +261,7 @@ func (env Env) ListStaff(teamID string, page gorest.Pagination) (licence.StaffLi
 	go func() {
 		defer close(listCh)
 
-		list, err := env.listStaff(teamID, page)
+		list, err := env.listStaff(teamID, unitID, page)
 
 		listCh <- licence.StaffList{
 			PagedList: pkg.PagedList{
@@ -105,3 +297,225 @@ func (env Env) DeleteStaffer(m licence.Staffer) error {
 
 	return nil
 }
+
+// revokeStafferLicence revokes the licence granted to a
+// staffer, or the invitation sent to the staffer.
+// The licence found is returned with Revoked false if the
+// staffer holds none that could be revoked.
+// A unit manager passes its unitID so that licences of
+// other units are left untouched.
+func (env Env) revokeStafferLicence(s licence.Staffer, unitID null.String) (licence.StafferRemoved, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	result := licence.StafferRemoved{
+		Staffer: s,
+	}
+
+	lic, err := env.stafferLicence(s, unitID)
+	if err != nil || lic == nil {
+		return result, err
+	}
+
+	switch {
+	case lic.IsGranted():
+		revoked, err := env.archiveRevoke(admin.AccessRight{
+			RowID:  lic.ID,
+			TeamID: s.TeamID,
+			UnitID: unitID,
+		})
+		if err != nil {
+			sugar.Error(err)
+			// Changed concurrently since found.
+			if err == ErrNotRevocable {
+				return licence.StafferRemoved{}, ErrLicenceUnavailable
+			}
+			return licence.StafferRemoved{}, err
+		}
+		l := revoked.LicenceVersion.PostChange.Licence
+		result.Licence = &l
+		result.PriorLicence = lic
+		result.Revoked = true
+		result.GrantRevoked = true
+
+	case lic.IsInvitationRevocable():
+		revoked, err := env.RevokeInvitation(lic.LatestInvitation.ID, s.TeamID)
+		if err != nil {
+			sugar.Error(err)
+			return licence.StafferRemoved{}, err
+		}
+		result.Licence = &revoked.Licence.Licence
+		result.PriorLicence = lic
+		result.Revoked = true
+
+	default:
+		result.Licence = lic
+	}
+
+	return result, nil
+}
+
+// RemoveStaffer deletes a staffer from roster.
+// If the staffer holds a licence, it is revoked only when
+// revoke is true; for an invited licence the invitation
+// is revoked.
+// If unitID is not null, only a licence of the unit is
+// revoked or returned.
+func (env Env) RemoveStaffer(s licence.Staffer, revoke bool, unitID null.String) (licence.StafferRemoved, error) {
+	removed := licence.StafferRemoved{
+		Staffer: s,
+	}
+
+	if revoke {
+		var err error
+		removed, err = env.revokeStafferLicence(s, unitID)
+		if err != nil {
+			return licence.StafferRemoved{}, err
+		}
+	} else {
+		lic, err := env.stafferLicence(s, unitID)
+		if err != nil {
+			return licence.StafferRemoved{}, err
+		}
+		removed.Licence = lic
+	}
+
+	err := env.DeleteStaffer(s)
+	if err != nil {
+		return licence.StafferRemoved{}, err
+	}
+
+	return removed, nil
+}
+
+// SetStafferActive activates or deactivates a staffer.
+// Deactivating revokes the licence held by the staffer.
+// It is used by identity provider which manages the whole
+// team, thus licences of any unit are revoked.
+func (env Env) SetStafferActive(s licence.Staffer, active bool) (licence.StafferRemoved, error) {
+	result := licence.StafferRemoved{}
+
+	if !active && s.Active {
+		var err error
+		result, err = env.revokeStafferLicence(s, null.String{})
+		if err != nil {
+			return licence.StafferRemoved{}, err
+		}
+	}
+
+	s = s.WithActive(active)
+	_, err := env.DBs.Write.NamedExec(licence.UpdateStaffer, s)
+	if err != nil {
+		return licence.StafferRemoved{}, err
+	}
+	result.Staffer = s
+
+	return result, nil
+}
+
+// StafferByEmail finds a staffer of a team.
+func (env Env) StafferByEmail(teamID, email string) (licence.Staffer, error) {
+	var s licence.Staffer
+	err := env.DBs.Read.Get(&s, licence.StafferByEmail, teamID, email)
+	if err != nil {
+		return licence.Staffer{}, err
+	}
+
+	return s, nil
+}
+
+// ListStaffFrom lists staff by offset, with total count.
+func (env Env) ListStaffFrom(teamID string, offset, limit int64) ([]licence.Staffer, int64, error) {
+	total, err := env.countStaff(teamID, null.String{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	list := make([]licence.Staffer, 0)
+	if limit == 0 {
+		return list, total, nil
+	}
+
+	err = env.DBs.Read.Select(&list, licence.ListStaffFrom, teamID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return list, total, nil
+}
+
+// InviteStaff creates an invitation with an available
+// licence for each selected staffer.
+// Staffers outside admin's unit, or already holding or
+// invited to a licence, are skipped. It stops when licences
+// run out.
+func (env Env) InviteStaff(params input.StaffInvitationParams, claims admin.PassportClaims) ([]licence.Licence, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	licIDs, err := env.listAvailableLicenceIDs(claims.TeamID.String, claims.UnitID)
+	if err != nil {
+		return nil, err
+	}
+
+	var invited = make([]licence.Licence, 0)
+	for _, id := range params.StaffIDs {
+		if len(licIDs) == 0 {
+			break
+		}
+
+		s, err := env.LoadStaffer(claims.TeamID.String, id)
+		if err != nil {
+			sugar.Error(err)
+			continue
+		}
+		// Unit admin could only invite staff of own unit.
+		if !claims.Covers(s.UnitID) {
+			continue
+		}
+
+		// Licences held outside the unit also count so that
+		// a staffer never gets two.
+		held, err := env.stafferLicence(s, null.String{})
+		if err != nil {
+			return invited, err
+		}
+		if held != nil {
+			continue
+		}
+
+		lic, err := env.CreateInvitation(input.InvitationParams{
+			Email:       s.Email,
+			Description: params.Description,
+			LicenceID:   licIDs[0],
+		}, claims)
+		if err != nil {
+			sugar.Error(err)
+			// The licence is taken concurrently and should not
+			// be tried again. Otherwise it is kept for the next staffer.
+			if err == ErrLicenceUnavailable {
+				licIDs = licIDs[1:]
+			}
+			continue
+		}
+
+		licIDs = licIDs[1:]
+		invited = append(invited, lic)
+	}
+
+	return invited, nil
+}
+
+// AddGrantedStaffer puts a reader granted a licence into
+// roster if not added yet.
+func (env Env) AddGrantedStaffer(a licence.Assignee, teamID string) error {
+	s := a.TeamMember(teamID)
+	s.Name = a.UserName
+
+	err := env.SaveStaffer(s)
+	if err != nil {
+		return err
+	}
+
+	return env.UpdateStaffer(s)
+}
@@ -0,0 +1,332 @@
+package subsrepo
+
+import (
+	"database/sql"
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
+	"github.com/FTChinese/ftacademy/internal/pkg/input"
+	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	gorest "github.com/FTChinese/go-rest"
+	"github.com/guregu/null"
+)
+
+func (env Env) listWaitlist(teamID string, page gorest.Pagination) ([]licence.WaitlistEntry, error) {
+	var list = make([]licence.WaitlistEntry, 0)
+	err := env.DBs.Read.Select(
+		&list,
+		licence.StmtListWaitlist,
+		teamID,
+		page.Limit,
+		page.Offset())
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (env Env) countWaitlist(teamID string) (int64, error) {
+	var total int64
+	err := env.DBs.Read.Get(&total, licence.StmtCountWaitlist, teamID)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// ListWaitlist shows entries still waiting for a licence.
+func (env Env) ListWaitlist(teamID string, page gorest.Pagination) (licence.WaitlistList, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	countCh := make(chan int64)
+	listCh := make(chan licence.WaitlistList)
+
+	go func() {
+		defer close(countCh)
+		n, err := env.countWaitlist(teamID)
+		if err != nil {
+			sugar.Error(err)
+		}
+
+		countCh <- n
+	}()
+
+	go func() {
+		defer close(listCh)
+		list, err := env.listWaitlist(teamID, page)
+
+		listCh <- licence.WaitlistList{
+			PagedList: pkg.PagedList{
+				Err: err,
+			},
+			Data: list,
+		}
+	}()
+
+	count, listResult := <-countCh, <-listCh
+	if listResult.Err != nil {
+		return licence.WaitlistList{}, listResult.Err
+	}
+
+	return licence.WaitlistList{
+		PagedList: pkg.PagedList{
+			Total:      count,
+			Pagination: page,
+			Err:        nil,
+		},
+		Data: listResult.Data,
+	}, nil
+}
+
+func (env Env) CreateWaitlistEntry(e licence.WaitlistEntry) error {
+	_, err := env.DBs.Write.NamedExec(licence.StmtCreateWaitlistEntry, e)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (env Env) LoadWaitlistEntry(r admin.AccessRight) (licence.WaitlistEntry, error) {
+	var e licence.WaitlistEntry
+	err := env.DBs.Read.Get(&e, licence.StmtWaitlistEntry, r.RowID, r.TeamID)
+	if err != nil {
+		return licence.WaitlistEntry{}, err
+	}
+
+	return e, nil
+}
+
+func (env Env) UpdateWaitlistEntry(e licence.WaitlistEntry) error {
+	_, err := env.DBs.Write.NamedExec(licence.StmtUpdateWaitlistEntry, e)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// LoadTeam retrieves a team without checking its admin.
+func (env Env) LoadTeam(teamID string) (admin.Team, error) {
+	var t admin.Team
+	err := env.DBs.Read.Get(&t, admin.BuildStmtLoadTeam(false), teamID)
+	if err != nil {
+		return admin.Team{}, err
+	}
+
+	return t, nil
+}
+
+// UpdateWaitlistAutoFill saves team's waitlist setting.
+func (env Env) UpdateWaitlistAutoFill(t admin.Team) error {
+	_, err := env.DBs.Write.NamedExec(admin.StmtUpdateWaitlistAutoFill, t)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// listAvailableLicenceIDs finds available licences of a team.
+// If unitID is not null, only those of the unit are used.
+func (env Env) listAvailableLicenceIDs(teamID string, unitID null.String) ([]string, error) {
+	var ids = make([]string, 0)
+	err := env.DBs.Read.Select(&ids, licence.StmtListAvailableLicenceIDs, teamID, unitID, unitID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// FillWaitlist is called after licences of a team become
+// available. If the team enabled auto-fill, waiting entries
+// are invited in order of priority; otherwise only the
+// number of available licences and waiting entries are
+// returned so that admin could be notified.
+func (env Env) FillWaitlist(teamID string) (licence.WaitlistFilled, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	team, err := env.LoadTeam(teamID)
+	if err != nil {
+		sugar.Error(err)
+		return licence.WaitlistFilled{}, err
+	}
+
+	licIDs, err := env.listAvailableLicenceIDs(teamID, null.String{})
+	if err != nil {
+		sugar.Error(err)
+		return licence.WaitlistFilled{}, err
+	}
+
+	waiting, err := env.countWaitlist(teamID)
+	if err != nil {
+		sugar.Error(err)
+		return licence.WaitlistFilled{}, err
+	}
+
+	filled := licence.WaitlistFilled{
+		TeamID:    teamID,
+		AutoFill:  team.AutoFillWaitlist,
+		Available: len(licIDs),
+		Waiting:   int(waiting),
+		Invited:   make([]licence.Licence, 0),
+	}
+
+	if !team.AutoFillWaitlist || len(licIDs) == 0 || waiting == 0 {
+		return filled, nil
+	}
+
+	tx, err := env.beginTx()
+	if err != nil {
+		sugar.Error(err)
+		return licence.WaitlistFilled{}, err
+	}
+
+	var entries = make([]licence.WaitlistEntry, 0)
+	err = tx.Select(&entries, licence.StmtLockNextWaiting, teamID, len(licIDs))
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return licence.WaitlistFilled{}, err
+	}
+
+	// Invitations are created on behalf of team's admin.
+	claims := admin.PassportClaims{
+		AdminID: team.AdminID,
+		TeamID:  null.StringFrom(team.ID),
+	}
+
+	for _, entry := range entries {
+		// Skip licences locked by others, e.g., being invited
+		// by admin at the same time.
+		lic, err := tx.LockAvailableLicence(admin.AccessRight{
+			TeamID: teamID,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				break
+			}
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return licence.WaitlistFilled{}, err
+		}
+
+		invitedLic, err := inviteTx(tx, lic, input.InvitationParams{
+			Email:       entry.Email,
+			Description: null.StringFrom("Invited from waitlist"),
+			LicenceID:   lic.ID,
+		}, claims)
+		// Entry which could not be invited is marked failed so
+		// that it won't block those behind it next time.
+		if err != nil {
+			sugar.Error(err)
+			_, err = tx.NamedExec(licence.StmtUpdateWaitlistEntry, entry.Failed())
+			if err != nil {
+				sugar.Error(err)
+				_ = tx.Rollback()
+				return licence.WaitlistFilled{}, err
+			}
+			filled.Failed++
+			continue
+		}
+
+		_, err = tx.NamedExec(
+			licence.StmtUpdateWaitlistEntry,
+			entry.Invited(invitedLic.LatestInvitation.Invitation))
+		if err != nil {
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return licence.WaitlistFilled{}, err
+		}
+
+		filled.Invited = append(filled.Invited, invitedLic)
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return licence.WaitlistFilled{}, err
+	}
+
+	filled.Waiting = filled.Waiting - len(filled.Invited) - filled.Failed
+
+	return filled, nil
+}
+
+// ListExpiredInvitations finds invitations not accepted
+// within valid period.
+func (env Env) ListExpiredInvitations() ([]licence.InvitationExpired, error) {
+	var list = make([]licence.InvitationExpired, 0)
+	err := env.DBs.Read.Select(&list, licence.StmtListExpiredInvitations)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ExpireInvitation marks an invitation as expired and
+// releases its licence so that it could be invited again.
+func (env Env) ExpireInvitation(ie licence.InvitationExpired) error {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	tx, err := env.beginTx()
+	if err != nil {
+		sugar.Error(err)
+		return err
+	}
+
+	inv, err := tx.RetrieveInvitation(admin.AccessRight{
+		RowID:  ie.InvitationID,
+		TeamID: ie.TeamID,
+	})
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return err
+	}
+	if inv.Status != licence.InvitationStatusCreated || !inv.IsExpired() {
+		_ = tx.Rollback()
+		return ErrInvalidInvitation
+	}
+
+	lic, err := tx.LockLicence(admin.AccessRight{
+		RowID:  ie.LicenceID,
+		TeamID: ie.TeamID,
+	})
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return err
+	}
+	// The licence might already be invited to someone else.
+	if !lic.IsInvitationRevocable() || lic.LatestInvitation.ID != inv.ID {
+		_ = tx.Rollback()
+		return ErrInvalidInvitation
+	}
+
+	err = tx.UpdateInvitationStatus(inv.Expired())
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.UpdateLicenceStatus(lic.WithInvitationRevoked())
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return err
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package txrepo
 import (
 	"github.com/FTChinese/ftacademy/internal/pkg/admin"
 	"github.com/FTChinese/ftacademy/internal/pkg/licence"
+	"github.com/FTChinese/ftacademy/pkg/price"
 )
 
 // LockLicence retrieves and locks a licence row when
@@ -13,7 +14,9 @@ func (tx TxRepo) LockLicence(r admin.AccessRight) (licence.Licence, error) {
 		&bl,
 		licence.StmtLockLicence,
 		r.RowID,
-		r.TeamID)
+		r.TeamID,
+		r.UnitID,
+		r.UnitID)
 
 	if err != nil {
 		return licence.Licence{}, err
@@ -22,6 +25,31 @@ func (tx TxRepo) LockLicence(r admin.AccessRight) (licence.Licence, error) {
 	return bl, nil
 }
 
+// LockAvailableLicence picks and locks a licence of a team,
+// or of a unit if r.UnitID is not null, that could be invited.
+// r.RowID is ignored.
+func (tx TxRepo) LockAvailableLicence(r admin.AccessRight) (licence.Licence, error) {
+	var l licence.Licence
+	err := tx.Get(&l, licence.StmtLockAvailableLicence, r.TeamID, r.UnitID, r.UnitID)
+	if err != nil {
+		return licence.Licence{}, err
+	}
+
+	return l, nil
+}
+
+// LockAvailableEditionLicence picks and locks a licence
+// of the edition that could be invited.
+func (tx TxRepo) LockAvailableEditionLicence(teamID string, e price.Edition) (licence.Licence, error) {
+	var l licence.Licence
+	err := tx.Get(&l, licence.StmtLockAvailableEditionLicence, teamID, e.Tier, e.Cycle)
+	if err != nil {
+		return licence.Licence{}, err
+	}
+
+	return l, nil
+}
+
 // UpdateLicenceStatus after its status changed.
 // * when invitation is created;
 // * when it is revoked
@@ -53,7 +81,9 @@ func (tx TxRepo) RetrieveInvitation(r admin.AccessRight) (licence.Invitation, er
 		&inv,
 		licence.StmtLockInvitation,
 		r.RowID,
-		r.TeamID)
+		r.TeamID,
+		r.UnitID,
+		r.UnitID)
 	if err != nil {
 		return licence.Invitation{}, err
 	}
@@ -71,3 +101,19 @@ func (tx TxRepo) UpdateInvitationStatus(inv licence.Invitation) error {
 
 	return nil
 }
+
+// InsertStaffer adds a staffer to roster.
+// Returns false if the email is already in roster.
+func (tx TxRepo) InsertStaffer(s licence.Staffer) (bool, error) {
+	result, err := tx.NamedExec(licence.InsertStaffer, s)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
@@ -71,3 +71,53 @@ func (tx TxRepo) LockMember(compoundID string) (reader.Membership, error) {
 	// Treat a non-existing member as a valid value.
 	return m.Sync(), nil
 }
+
+// LockLicenceCarryOver retrieves the carry-over invoice
+// generated when a licence is granted under the transaction
+// licTxID.
+// Returns zero value if not found.
+func (tx TxRepo) LockLicenceCarryOver(compoundID string, licTxID string) (reader.Invoice, error) {
+	var inv reader.Invoice
+
+	if licTxID == "" {
+		return inv, nil
+	}
+
+	err := tx.Get(
+		&inv,
+		reader.StmtLockLicenceCarryOver,
+		compoundID,
+		licTxID)
+
+	if err != nil && err != sql.ErrNoRows {
+		return inv, err
+	}
+
+	return inv, nil
+}
+
+// MoveLicenceCarryOver keeps carry-over of a granted licence
+// linked to it after renewal changed its transaction id.
+func (tx TxRepo) MoveLicenceCarryOver(compoundID string, fromTxID string, toTxID string) error {
+	_, err := tx.Exec(
+		reader.StmtMoveLicenceCarryOver,
+		toTxID,
+		compoundID,
+		fromTxID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ConsumeInvoice records the period an invoice
+// is transferred to membership.
+func (tx TxRepo) ConsumeInvoice(inv reader.Invoice) error {
+	_, err := tx.NamedExec(reader.StmtConsumeInvoice, inv)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package webhookrepo
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg"
+	"github.com/FTChinese/ftacademy/internal/pkg/webhook"
+	gorest "github.com/FTChinese/go-rest"
+	"time"
+)
+
+func (env Env) CreateDelivery(d webhook.Delivery) error {
+	_, err := env.DBs.Write.NamedExec(webhook.StmtCreateDelivery, d)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateDelivery saves the outcome of an attempt.
+func (env Env) UpdateDelivery(d webhook.Delivery) error {
+	_, err := env.DBs.Write.NamedExec(webhook.StmtUpdateDelivery, d)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ClaimDueDeliveries retrieves pending deliveries ready to
+// retry and leases them so that other instances skip them.
+func (env Env) ClaimDueDeliveries(limit int) ([]webhook.Delivery, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	tx, err := env.DBs.Write.Beginx()
+	if err != nil {
+		sugar.Error(err)
+		return nil, err
+	}
+
+	var list = make([]webhook.Delivery, 0)
+	err = tx.Select(&list, webhook.StmtDueDeliveries, limit)
+	if err != nil {
+		sugar.Error(err)
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now()
+	for i, d := range list {
+		list[i] = d.Leased(now)
+		_, err = tx.NamedExec(webhook.StmtUpdateDelivery, list[i])
+		if err != nil {
+			sugar.Error(err)
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		sugar.Error(err)
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (env Env) countDeliveries(endpointID string) (int64, error) {
+	var total int64
+	err := env.DBs.Read.Get(&total, webhook.StmtCountDeliveries, endpointID)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (env Env) listDeliveries(endpointID string, page gorest.Pagination) ([]webhook.Delivery, error) {
+	var list = make([]webhook.Delivery, 0)
+	err := env.DBs.Read.Select(
+		&list,
+		webhook.StmtListDeliveries,
+		endpointID,
+		page.Limit,
+		page.Offset())
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ListDeliveries shows the delivery log of an endpoint.
+func (env Env) ListDeliveries(endpointID string, page gorest.Pagination) (webhook.DeliveryList, error) {
+	defer env.logger.Sync()
+	sugar := env.logger.Sugar()
+
+	countCh := make(chan int64)
+	listCh := make(chan webhook.DeliveryList)
+
+	go func() {
+		defer close(countCh)
+		n, err := env.countDeliveries(endpointID)
+		if err != nil {
+			sugar.Error(err)
+		}
+
+		countCh <- n
+	}()
+
+	go func() {
+		defer close(listCh)
+		list, err := env.listDeliveries(endpointID, page)
+
+		listCh <- webhook.DeliveryList{
+			PagedList: pkg.PagedList{
+				Err: err,
+			},
+			Data: list,
+		}
+	}()
+
+	count, listResult := <-countCh, <-listCh
+	if listResult.Err != nil {
+		return webhook.DeliveryList{}, listResult.Err
+	}
+
+	return webhook.DeliveryList{
+		PagedList: pkg.PagedList{
+			Total:      count,
+			Pagination: page,
+			Err:        nil,
+		},
+		Data: listResult.Data,
+	}, nil
+}
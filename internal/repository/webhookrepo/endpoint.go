@@ -0,0 +1,80 @@
+package webhookrepo
+
+import (
+	"github.com/FTChinese/ftacademy/internal/pkg/webhook"
+	"github.com/guregu/null"
+)
+
+func (env Env) CreateEndpoint(ep webhook.Endpoint) error {
+	_, err := env.DBs.Write.NamedExec(webhook.StmtCreateEndpoint, ep)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListEndpoints retrieves endpoints of a team, or those
+// of CMS if teamID is null.
+func (env Env) ListEndpoints(teamID null.String) ([]webhook.Endpoint, error) {
+	var list = make([]webhook.Endpoint, 0)
+	err := env.DBs.Read.Select(&list, webhook.StmtListEndpoints, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// LoadEndpoint retrieves an endpoint owned by a team, or
+// by CMS if teamID is null.
+func (env Env) LoadEndpoint(id string, teamID null.String) (webhook.Endpoint, error) {
+	var ep webhook.Endpoint
+	err := env.DBs.Read.Get(&ep, webhook.StmtEndpoint, id, teamID)
+	if err != nil {
+		return webhook.Endpoint{}, err
+	}
+
+	return ep, nil
+}
+
+// RetrieveEndpoint is used by retry without ownership check.
+func (env Env) RetrieveEndpoint(id string) (webhook.Endpoint, error) {
+	var ep webhook.Endpoint
+	err := env.DBs.Read.Get(&ep, webhook.StmtRetrieveEndpoint, id)
+	if err != nil {
+		return webhook.Endpoint{}, err
+	}
+
+	return ep, nil
+}
+
+// SubscribedEndpoints finds where an event of a team should
+// be sent to.
+func (env Env) SubscribedEndpoints(teamID string, t webhook.EventType) ([]webhook.Endpoint, error) {
+	var list = make([]webhook.Endpoint, 0)
+	err := env.DBs.Read.Select(&list, webhook.StmtSubscribedEndpoints, teamID, t)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (env Env) UpdateEndpoint(ep webhook.Endpoint) error {
+	_, err := env.DBs.Write.NamedExec(webhook.StmtUpdateEndpoint, ep)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (env Env) DeleteEndpoint(id string, teamID null.String) error {
+	_, err := env.DBs.Write.Exec(webhook.StmtDeleteEndpoint, id, teamID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -13,9 +13,11 @@ import (
 	"github.com/FTChinese/ftacademy/internal/app/b2b"
 	"github.com/FTChinese/ftacademy/internal/app/content"
 	"github.com/FTChinese/ftacademy/internal/app/reader"
+	"github.com/FTChinese/ftacademy/internal/pkg/admin"
 	"github.com/FTChinese/ftacademy/pkg/config"
 	"github.com/FTChinese/ftacademy/pkg/db"
 	"github.com/FTChinese/ftacademy/pkg/postman"
+	"github.com/FTChinese/ftacademy/pkg/throttle"
 	"github.com/FTChinese/ftacademy/pkg/xhttp"
 	"github.com/FTChinese/ftacademy/web"
 	"github.com/flosch/pongo2/v4"
@@ -72,17 +74,35 @@ func main() {
 
 	myDBs := db.MustNewMyDBs()
 
-	pm := postman.New(config.MustGetHanqiConn())
+	// Letters are saved to outbox before sent via SMTP.
+	pm := b2b.NewMailer(myDBs, postman.New(config.MustGetHanqiConn()), logger)
+	// Retry letters failed to send with backoff.
+	go pm.Watch(time.Minute)
 
 	//b2bGuard := controller.NewJWTGuard(b2bAppKey.GetJWTKey())
 	oauthGuard := access.NewGuard(myDBs)
 
 	apiClients := api.NewClients(production)
 
-	adminRouter := b2b.NewAdminRouter(myDBs, pm, logger)
-	subsRouter := b2b.NewSubsRouter(myDBs, pm, logger)
+	// Throttling of login and redemption is kept in database
+	// in production so that limits survive restarts.
+	var throttleStore throttle.Store = throttle.NewMemoryStore()
+	if production {
+		throttleStore = throttle.NewMySQLStore(myDBs.Write)
+	}
+
+	adminRouter := b2b.NewAdminRouter(myDBs, pm, throttleStore, logger)
+	subsRouter := b2b.NewSubsRouter(myDBs, pm, throttleStore, logger)
+	// Grant licences reserved for readers whose auto-renewal
+	// subscription ended.
+	go subsRouter.WatchDeferredGrants(time.Hour)
+	// Release licences whose invitation or redemption code
+	// expired and fill them from waitlist.
+	go subsRouter.WatchExpiredInvitations(time.Hour)
+	// Retry failed webhook deliveries with backoff.
+	go subsRouter.WatchWebhookDeliveries(time.Minute)
 	productRouter := b2b.NewProductRouter(apiClients, logger)
-	readerRouter := reader.NewReaderRouter(apiClients, version)
+	readerRouter := reader.NewReaderRouter(apiClients, throttleStore, version)
 	stripeRouter := reader.NewStripeRouter(
 		apiClients,
 		production,
@@ -141,6 +161,9 @@ func main() {
 	readerAPIGroup := apiGroup.Group("/reader")
 	readerAuthGroup := readerAPIGroup.Group("/auth")
 
+	// Public keys to verify reader JWT.
+	readerAuthGroup.GET("/jwks/", readerRouter.JWKS)
+
 	emailAuthGroup := readerAuthGroup.Group("/email")
 	{
 		emailAuthGroup.GET("/exists/", readerRouter.EmailExists)
@@ -193,6 +216,22 @@ func main() {
 		memberGroup.POST("/addons/", readerRouter.ClaimAddon)
 	}
 
+	// Reader requests a licence with company email.
+	licenceRequestGroup := readerAPIGroup.Group("/licence-requests", readerRouter.RequireLoggedIn)
+	{
+		licenceRequestGroup.GET("/", subsRouter.ListReaderLicenceRequests)
+		licenceRequestGroup.POST("/", subsRouter.CreateLicenceRequest)
+		licenceRequestGroup.POST("/verification/:token/", subsRouter.VerifyLicenceRequest)
+	}
+
+	// Reader redeems a code to get a licence.
+	readerLicenceGroup := readerAPIGroup.Group("/licence", readerRouter.RequireLoggedIn)
+	{
+		readerLicenceGroup.POST("/redeem/", subsRouter.Redeem)
+		// Join a team via a shareable link.
+		readerLicenceGroup.POST("/join/:token/", subsRouter.JoinTeam)
+	}
+
 	iapGroup := readerAPIGroup.Group("/apple", readerRouter.RequireLoggedIn)
 	{
 		iapGroup.POST("/subs/:id/", readerRouter.RefreshIAP)
@@ -266,8 +305,19 @@ func main() {
 	b2bAuthGroup := b2bAPIGroup.Group("/auth")
 	{
 		b2bAuthGroup.POST("/login/", adminRouter.Login)
+		// Second step of login if 2FA is enabled.
+		b2bAuthGroup.POST("/login/2fa/", adminRouter.LoginTwoFactor)
+		// Exchange refresh token for new tokens.
+		b2bAuthGroup.POST("/refresh/", adminRouter.RefreshSession)
+		b2bAuthGroup.POST("/logout/", adminRouter.Logout, adminRouter.RequireLoggedIn)
+		// Public keys to verify admin JWT.
+		b2bAuthGroup.GET("/jwks/", adminRouter.JWKS)
+		// Unlock account locked after repeated failed logins.
+		b2bAuthGroup.POST("/unlock/:token/", adminRouter.UnlockAccount)
 		b2bAuthGroup.POST("/signup/", adminRouter.SignUp)
 		b2bAuthGroup.GET("/verify/:token/", adminRouter.VerifyEmail)
+		// Show which team is inviting a co-admin.
+		b2bAuthGroup.GET("/team-invitations/:token/", adminRouter.VerifyAdminInvitation)
 
 		pwResetGroup := b2bAuthGroup.Group("/password-reset")
 		{
@@ -289,16 +339,56 @@ func main() {
 	{
 		//b2bAccountGroup.GET("/", accountRouter.Account)
 		b2bAccountGroup.GET("/jwt/", adminRouter.RefreshJWT)
+		// Devices logged in.
+		b2bAccountGroup.GET("/sessions/", adminRouter.ListSessions)
+		b2bAccountGroup.POST("/sessions/revoke-others/", adminRouter.RevokeOtherSessions)
+		b2bAccountGroup.DELETE("/sessions/:id/", adminRouter.RevokeSession)
 		b2bAccountGroup.POST("/request-verification/", adminRouter.RequestVerification)
 		b2bAccountGroup.PATCH("/display-name/", adminRouter.ChangeName)
 		b2bAccountGroup.PATCH("/password/", adminRouter.ChangePassword)
+		// Join a team as co-admin. Returns a new JWT.
+		b2bAccountGroup.POST("/team-invitations/:token/", adminRouter.AcceptAdminInvitation)
+
+		// TOTP two-factor authentication.
+		b2bAccountGroup.GET("/2fa/", adminRouter.TwoFactorStatus)
+		b2bAccountGroup.POST("/2fa/setup/", adminRouter.SetupTwoFactor)
+		b2bAccountGroup.POST("/2fa/enable/", adminRouter.EnableTwoFactor)
+		b2bAccountGroup.POST("/2fa/disable/", adminRouter.DisableTwoFactor)
+		b2bAccountGroup.POST("/2fa/recovery-codes/", adminRouter.RegenerateRecoveryCodes)
+	}
+
+	b2bTeamGroup := b2bAPIGroup.Group("/team")
+	{
+		b2bTeamGroup.GET("/", adminRouter.LoadTeam, adminRouter.RequireLoggedIn)
+		b2bTeamGroup.POST("/", adminRouter.CreateTeam, adminRouter.RequireLoggedIn)
+		b2bTeamGroup.PATCH("/", adminRouter.UpdateTeam, adminRouter.RequireTeamSet, adminRouter.RequireRole(admin.AreaTeam))
+		// Owner requires 2FA for every admin.
+		b2bTeamGroup.PATCH("/2fa/", adminRouter.UpdateTwoFactorPolicy, adminRouter.RequireTeamSet)
+		// Administrative actions on the team. Owner only.
+		b2bTeamGroup.GET("/audit-log/", adminRouter.ListAuditLog, adminRouter.RequireTeamSet)
 	}
 
-	b2bTeamGroup := b2bAPIGroup.Group("/team", adminRouter.RequireLoggedIn)
+	// Co-admins of a team. Only owner could change them.
+	b2bMemberGroup := b2bAPIGroup.Group("/members", adminRouter.RequireTeamSet, adminRouter.RequireRole(admin.AreaTeam))
 	{
-		b2bTeamGroup.GET("/", adminRouter.LoadTeam)
-		b2bTeamGroup.POST("/", adminRouter.CreateTeam)
-		b2bTeamGroup.PATCH("/", adminRouter.UpdateTeam)
+		b2bMemberGroup.GET("/", adminRouter.ListTeamMembers)
+		b2bMemberGroup.PATCH("/:id/", adminRouter.UpdateTeamMember)
+		b2bMemberGroup.DELETE("/:id/", adminRouter.RemoveTeamMember)
+		// Pending invitations to co-admins.
+		b2bMemberGroup.GET("/invitations/", adminRouter.ListAdminInvitations)
+		b2bMemberGroup.POST("/invitations/", adminRouter.InviteTeamMember)
+		b2bMemberGroup.POST("/invitations/:id/revoke/", adminRouter.RevokeAdminInvitation)
+	}
+
+	// Departments of a team.
+	b2bUnitGroup := b2bAPIGroup.Group("/units", adminRouter.RequireTeamSet, adminRouter.RequireRole(admin.AreaTeam))
+	{
+		b2bUnitGroup.GET("/", adminRouter.ListUnits)
+		b2bUnitGroup.POST("/", adminRouter.CreateUnit)
+		// Utilisation and spend by unit.
+		b2bUnitGroup.GET("/report/", adminRouter.UnitReport)
+		b2bUnitGroup.PATCH("/:id/", adminRouter.UpdateUnit)
+		b2bUnitGroup.DELETE("/:id/", adminRouter.DeleteUnit)
 	}
 
 	b2bSearchGroup := b2bAPIGroup.Group("/search", adminRouter.RequireLoggedIn)
@@ -307,35 +397,136 @@ func main() {
 		b2bSearchGroup.GET("/membership/", subsRouter.FindMembership)
 	}
 
-	orderGroup := b2bAPIGroup.Group("/orders", adminRouter.RequireTeamSet)
+	// Routes accepting API keys set guard per route.
+	orderGroup := b2bAPIGroup.Group("/orders")
 	{
 		// List orders
-		orderGroup.GET("/", subsRouter.ListOrders)
+		orderGroup.GET("/", subsRouter.ListOrders, adminRouter.RequireTeamOrKey(admin.ScopeOrdersRead))
+		// Download all orders.
+		// format=csv|xlsx&lang=zh|en
+		orderGroup.GET("/export/", subsRouter.ExportOrders, adminRouter.RequireTeamOrKey(admin.ScopeOrdersRead))
 		// CreateTeam orders, or renew/upgrade in bulk.
-		orderGroup.POST("/", subsRouter.CreateOrders)
-		orderGroup.GET("/:id/", subsRouter.LoadOrder)
+		orderGroup.POST("/", subsRouter.CreateOrders, adminRouter.RequireTeamSet, adminRouter.RequireRole(admin.AreaOrders))
+		orderGroup.GET("/:id/", subsRouter.LoadOrder, adminRouter.RequireTeamOrKey(admin.ScopeOrdersRead))
 	}
 
-	b2bLicenceGroup := b2bAPIGroup.Group("/licences", adminRouter.RequireTeamSet)
+	b2bLicenceGroup := b2bAPIGroup.Group("/licences")
 	{
 		// List licences
-		b2bLicenceGroup.GET("/", subsRouter.ListLicence)
-		b2bLicenceGroup.GET("/:id/", subsRouter.LoadLicence)
+		b2bLicenceGroup.GET("/", subsRouter.ListLicence, adminRouter.RequireTeamOrKey(admin.ScopeLicencesRead))
+		// Download all licences with assignee, tier and expiration.
+		// format=csv|xlsx&lang=zh|en
+		b2bLicenceGroup.GET("/export/", subsRouter.ExportLicences, adminRouter.RequireTeamOrKey(admin.ScopeLicencesRead))
+		b2bLicenceGroup.GET("/:id/", subsRouter.LoadLicence, adminRouter.RequireTeamOrKey(admin.ScopeLicencesRead))
 		// Revoked a licence
-		b2bLicenceGroup.POST("/:id/revoke/", subsRouter.RevokeLicence)
+		b2bLicenceGroup.POST("/:id/revoke/", subsRouter.RevokeLicence, adminRouter.RequireTeamSet, adminRouter.RequireRole(admin.AreaLicences))
+		// Revoke licences in bulk by ids or assignee's email domain.
+		b2bLicenceGroup.POST("/bulk-revoke/", subsRouter.BulkRevokeLicences, adminRouter.RequireTeamSet, adminRouter.RequireRole(admin.AreaLicences), adminRouter.RequireTeamScope)
+		// Move licences into a unit.
+		b2bLicenceGroup.POST("/allocation/", subsRouter.AllocateLicences, adminRouter.RequireTeamSet, adminRouter.RequireRole(admin.AreaLicences), adminRouter.RequireTeamScope)
 	}
 
-	b2bInvitationGroup := b2bAPIGroup.Group("/invitations", adminRouter.RequireTeamSet)
+	b2bInvitationGroup := b2bAPIGroup.Group("/invitations")
 	{
 		// List invitations
-		b2bInvitationGroup.GET("/", subsRouter.ListInvitations)
+		b2bInvitationGroup.GET("/", subsRouter.ListInvitations, adminRouter.RequireTeamOrKey(admin.ScopeInvitationsRead))
+		// Download all invitations.
+		// format=csv|xlsx&lang=zh|en
+		b2bInvitationGroup.GET("/export/", subsRouter.ExportInvitations, adminRouter.RequireTeamOrKey(admin.ScopeInvitationsRead))
 		// Create invitation.
 		// Also update the linked licence's status.
-		b2bInvitationGroup.POST("/", subsRouter.CreateInvitation)
+		b2bInvitationGroup.POST("/", subsRouter.CreateInvitation, adminRouter.RequireTeamOrKey(admin.ScopeInvitationsWrite))
 		// Revoked invitation before licence is accepted.
 		// Also revert the status of a licence from invitation sent
 		// back to available.
-		b2bInvitationGroup.POST("/:id/revoke/", subsRouter.RevokeInvitation)
+		b2bInvitationGroup.POST("/:id/revoke/", subsRouter.RevokeInvitation, adminRouter.RequireTeamSet, adminRouter.RequireRole(admin.AreaLicences))
+	}
+
+	// Keys for team's own systems to call the above api.
+	b2bAPIKeyGroup := b2bAPIGroup.Group("/api-keys", adminRouter.RequireTeamSet, adminRouter.RequireRole(admin.AreaTeam))
+	{
+		b2bAPIKeyGroup.GET("/", adminRouter.ListAPIKeys)
+		b2bAPIKeyGroup.POST("/", adminRouter.CreateAPIKey)
+		b2bAPIKeyGroup.PATCH("/:id/", adminRouter.UpdateAPIKey)
+		b2bAPIKeyGroup.POST("/:id/revoke/", adminRouter.RevokeAPIKey)
+	}
+
+	// Shareable links granting licences to emails under
+	// allowed domains.
+	b2bJoinLinkGroup := b2bAPIGroup.Group("/join-links", adminRouter.RequireTeamSet, adminRouter.RequireRole(admin.AreaLicences), adminRouter.RequireTeamScope)
+	{
+		b2bJoinLinkGroup.GET("/", subsRouter.ListJoinLinks)
+		b2bJoinLinkGroup.POST("/", subsRouter.CreateJoinLink)
+		b2bJoinLinkGroup.POST("/:id/revoke/", subsRouter.RevokeJoinLink)
+	}
+
+	// Licences converted into one-time redemption codes.
+	b2bRedeemCodeGroup := b2bAPIGroup.Group("/redeem-codes", adminRouter.RequireTeamSet, adminRouter.RequireRole(admin.AreaLicences), adminRouter.RequireTeamScope)
+	{
+		// ?status=active | redeemed | revoked | expired
+		b2bRedeemCodeGroup.GET("/", subsRouter.ListRedeemCodes)
+		b2bRedeemCodeGroup.POST("/", subsRouter.CreateRedeemCodes)
+		// Download all active codes as csv.
+		b2bRedeemCodeGroup.GET("/export/", subsRouter.ExportRedeemCodes)
+		b2bRedeemCodeGroup.GET("/:id/qr/", subsRouter.RedeemCodeQR)
+		b2bRedeemCodeGroup.POST("/:id/revoke/", subsRouter.RevokeRedeemCode)
+	}
+
+	// Team roster managed independently of licences.
+	b2bStaffGroup := b2bAPIGroup.Group("/staff", adminRouter.RequireTeamSet, adminRouter.RequireRole(admin.AreaLicences))
+	{
+		b2bStaffGroup.GET("/", subsRouter.ListStaff)
+		b2bStaffGroup.POST("/", subsRouter.CreateStaffer)
+		// Multipart form with a csv file in field `file`.
+		b2bStaffGroup.POST("/import/", subsRouter.ImportStaff)
+		// Invite selected staff with available licences.
+		b2bStaffGroup.POST("/invitations/", subsRouter.InviteStaff)
+		b2bStaffGroup.GET("/:id/", subsRouter.LoadStaffer)
+		b2bStaffGroup.PATCH("/:id/", subsRouter.UpdateStaffer)
+		// ?revoke=true to revoke licence of this staffer.
+		b2bStaffGroup.DELETE("/:id/", subsRouter.RemoveStaffer)
+	}
+
+	// SCIM provisioning settings of a team.
+	b2bScimGroup := b2bAPIGroup.Group("/scim", adminRouter.RequireTeamSet, adminRouter.RequireRole(admin.AreaTeam))
+	{
+		b2bScimGroup.GET("/", subsRouter.LoadScimSetting)
+		b2bScimGroup.PATCH("/", subsRouter.UpdateScimSetting)
+		b2bScimGroup.DELETE("/", subsRouter.DeleteScimSetting)
+		// Generate a new bearer token, replacing the old one.
+		b2bScimGroup.POST("/token/", subsRouter.CreateScimToken)
+	}
+
+	// People waiting for a licence when all are taken.
+	b2bWaitlistGroup := b2bAPIGroup.Group("/waitlist", adminRouter.RequireTeamSet, adminRouter.RequireRole(admin.AreaLicences), adminRouter.RequireTeamScope)
+	{
+		b2bWaitlistGroup.GET("/", subsRouter.ListWaitlist)
+		b2bWaitlistGroup.POST("/", subsRouter.CreateWaitlistEntry)
+		// Turn on/off inviting waitlist automatically.
+		b2bWaitlistGroup.PATCH("/settings/", subsRouter.UpdateWaitlistSetting)
+		b2bWaitlistGroup.PATCH("/:id/", subsRouter.UpdateWaitlistEntry)
+		b2bWaitlistGroup.DELETE("/:id/", subsRouter.RemoveWaitlistEntry)
+	}
+
+	// Endpoints receiving signed events of a team.
+	b2bWebhookGroup := b2bAPIGroup.Group("/webhooks", adminRouter.RequireTeamSet, adminRouter.RequireRole(admin.AreaTeam))
+	{
+		b2bWebhookGroup.GET("/", subsRouter.ListWebhooks)
+		b2bWebhookGroup.POST("/", subsRouter.CreateWebhook)
+		b2bWebhookGroup.PATCH("/:id/", subsRouter.UpdateWebhook)
+		b2bWebhookGroup.DELETE("/:id/", subsRouter.DeleteWebhook)
+		// Delivery log of an endpoint.
+		b2bWebhookGroup.GET("/:id/deliveries/", subsRouter.ListWebhookDeliveries)
+	}
+
+	// Approval queue of readers' self-service licence requests.
+	b2bLicenceRequestGroup := b2bAPIGroup.Group("/licence-requests", adminRouter.RequireTeamSet, adminRouter.RequireRole(admin.AreaLicences), adminRouter.RequireTeamScope)
+	{
+		// ?status=unverified | pending | approved | rejected
+		b2bLicenceRequestGroup.GET("/", subsRouter.ListLicenceRequests)
+		// Pick an available licence and grant it.
+		b2bLicenceRequestGroup.POST("/:id/approve/", subsRouter.ApproveLicenceRequest)
+		b2bLicenceRequestGroup.POST("/:id/reject/", subsRouter.RejectLicenceRequest)
 	}
 
 	// Steps to accept an invitation:
@@ -347,11 +538,29 @@ func main() {
 	{
 		// Verify the invitation is valid.
 		b2bGrantGroup.GET("/invitation/verification/:token/", subsRouter.VerifyInvitation)
+		// Verify a join link, which shares the invitation url
+		// with query parameter ?join=true.
+		b2bGrantGroup.GET("/join/verification/:token/", subsRouter.VerifyJoinLink)
 
 		// Grant licence to user
 		b2bGrantGroup.POST("/grant/", subsRouter.GrantLicence)
 	}
 
+	// -------------------------------------------------
+	// SCIM 2.0 used by identity providers of corporate
+	// to provision staff, authenticated by team's token.
+	// -------------------------------------------------
+	scimGroup := e.Group("/scim/v2", subsRouter.RequireScimToken)
+	{
+		// ?filter=userName eq "email"&startIndex=1&count=100
+		scimGroup.GET("/Users/", subsRouter.ScimListUsers)
+		scimGroup.POST("/Users/", subsRouter.ScimCreateUser)
+		scimGroup.GET("/Users/:id/", subsRouter.ScimGetUser)
+		// Only active attribute is supported.
+		scimGroup.PATCH("/Users/:id/", subsRouter.ScimPatchUser)
+		scimGroup.DELETE("/Users/:id/", subsRouter.ScimDeleteUser)
+	}
+
 	//-------------------------------------------------
 	// The following is used by internal system.
 	// It is not used by any customer-side client.
@@ -362,26 +571,71 @@ func main() {
 	// -----------------------------------------------
 	cmsGroup := apiGroup.Group("/cms", oauthGuard.RequireToken)
 	{
-		cmsGroup.GET("/profile/:id/", cmsRouter.LoadingAdminProfile)
+		cmsGroup.GET("/profile/:id/", cmsRouter.LoadingAdminProfile, oauthGuard.RequireScope(access.ScopeAdminsRead))
+		// Admins still on SHA-256 password hash.
+		cmsGroup.GET("/admins/legacy-passwords/", cmsRouter.ListLegacyPasswords, oauthGuard.RequireScope(access.ScopeAdminsRead))
 		// List teams
-		//cmsGroup.GET("/teams/",)
+		// q=xxx - Search by team id, org name or owner email;
+		// pending_orders=true - Only teams having orders not paid;
+		// expiring=true - Only teams having licences expiring this month;
+		// sort=created|spend|seats
+		cmsGroup.GET("/teams/", cmsRouter.ListTeams, oauthGuard.RequireScope(access.ScopeTeamsRead))
 		// Show team detail
 		// * admin account;
 		// * team name
 		// * orders
 		// * licences
-		cmsGroup.GET("/teams/:id/", cmsRouter.LoadTeam)
+		cmsGroup.GET("/teams/:id/", cmsRouter.LoadTeam, oauthGuard.RequireScope(access.ScopeTeamsRead))
+		// Act on behalf of a team. Request body must contain
+		// operator and reason.
+		cmsGroup.PATCH("/teams/:id/", cmsRouter.UpdateTeamOnBehalf, oauthGuard.RequireScope(access.ScopeTeamsWrite))
+		cmsGroup.POST("/teams/:id/orders/", cmsRouter.CreateOrderOnBehalf, oauthGuard.RequireScope(access.ScopeTeamsWrite))
+		cmsGroup.POST("/teams/:id/invitations/", cmsRouter.CreateInvitationOnBehalf, oauthGuard.RequireScope(access.ScopeTeamsWrite))
+		cmsGroup.POST("/teams/:id/invitations/:invId/revoke/", cmsRouter.RevokeInvitationOnBehalf, oauthGuard.RequireScope(access.ScopeTeamsWrite))
+		cmsGroup.POST("/teams/:id/invitations/:invId/resend/", cmsRouter.ResendInvitationOnBehalf, oauthGuard.RequireScope(access.ScopeTeamsWrite))
+		cmsGroup.POST("/teams/:id/licences/:licId/revoke/", cmsRouter.RevokeLicenceOnBehalf, oauthGuard.RequireScope(access.ScopeTeamsWrite))
+		// Email domains used to match readers' licence requests to a team.
+		cmsGroup.GET("/teams/:id/domains/", cmsRouter.ListTeamDomains, oauthGuard.RequireScope(access.ScopeTeamsRead))
+		cmsGroup.POST("/teams/:id/domains/", cmsRouter.CreateTeamDomain, oauthGuard.RequireScope(access.ScopeTeamsWrite))
+		cmsGroup.DELETE("/teams/:id/domains/:domain/", cmsRouter.DeleteTeamDomain, oauthGuard.RequireScope(access.ScopeTeamsWrite))
 		// List orders
 		// Query parameters used as filters:
 		// team=xxx - List orders of the specified team
 		// status=pending_payment | paid | processing | cancelled - List orders of the specified status
-		cmsGroup.GET("/orders/", cmsRouter.ListOrders)
+		cmsGroup.GET("/orders/", cmsRouter.ListOrders, oauthGuard.RequireScope(access.ScopeOrdersRead))
 		// Details of an order:
 		// * order data
 		// * team details
-		cmsGroup.GET("/orders/:id/", cmsRouter.LoadOrder)
+		cmsGroup.GET("/orders/:id/", cmsRouter.LoadOrder, oauthGuard.RequireScope(access.ScopeOrdersRead))
 		// Order payment confirmed.
-		cmsGroup.POST("/orders/:id/", cmsRouter.ConfirmPayment)
+		cmsGroup.POST("/orders/:id/", cmsRouter.ConfirmPayment, oauthGuard.RequireScope(access.ScopeOrdersConfirm))
+
+		// Endpoints receiving events of all teams.
+		cmsGroup.GET("/webhooks/", cmsRouter.ListWebhooks, oauthGuard.RequireScope(access.ScopeWebhooksRead))
+		cmsGroup.POST("/webhooks/", cmsRouter.CreateWebhook, oauthGuard.RequireScope(access.ScopeWebhooksWrite))
+		cmsGroup.PATCH("/webhooks/:id/", cmsRouter.UpdateWebhook, oauthGuard.RequireScope(access.ScopeWebhooksWrite))
+		cmsGroup.DELETE("/webhooks/:id/", cmsRouter.DeleteWebhook, oauthGuard.RequireScope(access.ScopeWebhooksWrite))
+		cmsGroup.GET("/webhooks/:id/deliveries/", cmsRouter.ListWebhookDeliveries, oauthGuard.RequireScope(access.ScopeWebhooksRead))
+
+		// Administrative actions of all teams.
+		// team_id=xxx - Only show actions on the specified team.
+		cmsGroup.GET("/audit-log/", cmsRouter.ListAuditLog, oauthGuard.RequireScope(access.ScopeAuditRead))
+
+		// Finance reports.
+		// start=YYYY-MM-DD&end=YYYY-MM-DD - Date range, inclusive;
+		// format=csv - Download as csv file.
+		reportGroup := cmsGroup.Group("/reports", oauthGuard.RequireScope(access.ScopeReportsRead))
+		{
+			reportGroup.GET("/revenue/", cmsRouter.RevenueReport)
+			reportGroup.GET("/seats/", cmsRouter.SeatReport)
+			reportGroup.GET("/active-seats/", cmsRouter.ActiveSeatReport)
+			reportGroup.GET("/top-teams/", cmsRouter.TopTeamsReport)
+		}
+
+		// Dead letters failed after all attempts.
+		cmsGroup.GET("/outbox/", cmsRouter.ListOutbox, oauthGuard.RequireScope(access.ScopeOutboxRead))
+		// Queue a dead letter again.
+		cmsGroup.POST("/outbox/:id/resend/", cmsRouter.ResendLetter, oauthGuard.RequireScope(access.ScopeOutboxWrite))
 	}
 
 	e.Logger.Fatal(e.Start(":4000"))
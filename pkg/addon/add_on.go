@@ -45,3 +45,31 @@ func (d AddOn) Clear(tier enum.Tier) AddOn {
 
 	return d
 }
+
+// Minus subtracts other from current instance.
+// Each tier never goes below zero.
+func (d AddOn) Minus(other AddOn) AddOn {
+	d.Standard = d.Standard - other.Standard
+	if d.Standard < 0 {
+		d.Standard = 0
+	}
+
+	d.Premium = d.Premium - other.Premium
+	if d.Premium < 0 {
+		d.Premium = 0
+	}
+
+	return d
+}
+
+// Days returns the addon days of a tier.
+func (d AddOn) Days(tier enum.Tier) int64 {
+	switch tier {
+	case enum.TierStandard:
+		return d.Standard
+	case enum.TierPremium:
+		return d.Premium
+	default:
+		return 0
+	}
+}
@@ -106,3 +106,50 @@ func TestReservedDays_Clear(t *testing.T) {
 		})
 	}
 }
+
+func TestAddOn_Minus(t *testing.T) {
+	tests := []struct {
+		name  string
+		d     AddOn
+		other AddOn
+		want  AddOn
+	}{
+		{
+			name:  "Minus",
+			d:     AddOn{Standard: 100, Premium: 20},
+			other: AddOn{Standard: 30, Premium: 0},
+			want:  AddOn{Standard: 70, Premium: 20},
+		},
+		{
+			name:  "Not below zero",
+			d:     AddOn{Standard: 10, Premium: 20},
+			other: AddOn{Standard: 30, Premium: 0},
+			want:  AddOn{Standard: 0, Premium: 20},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.Minus(tt.other); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Minus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddOn_Days(t *testing.T) {
+	d := AddOn{Standard: 30, Premium: 7}
+
+	tests := []struct {
+		tier enum.Tier
+		want int64
+	}{
+		{enum.TierStandard, 30},
+		{enum.TierPremium, 7},
+		{enum.TierNull, 0},
+	}
+	for _, tt := range tests {
+		if got := d.Days(tt.tier); got != tt.want {
+			t.Errorf("Days(%s) = %d, want %d", tt.tier, got, tt.want)
+		}
+	}
+}
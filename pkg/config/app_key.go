@@ -1,14 +1,18 @@
 package config
 
 import (
+	"github.com/FTChinese/ftacademy/pkg/keyring"
 	"github.com/spf13/viper"
 	"log"
+	"time"
 )
 
 // AppKey contains various signing or access keys for an app.
 type AppKey struct {
-	JWT     string `mapstructure:"jwt_signing_key"`
-	CSRF    string `mapstructure:"csrf_signing_key"`
+	JWT  string `mapstructure:"jwt_signing_key"`
+	CSRF string `mapstructure:"csrf_signing_key"`
+	// JWTKeys supersedes JWT to allow key rotation.
+	JWTKeys []keyring.KeyConfig `mapstructure:"jwt_keys"`
 	jwtKey  []byte
 	csrfKey []byte
 }
@@ -17,6 +21,25 @@ func (a AppKey) GetJWTKey() []byte {
 	return a.jwtKey
 }
 
+// MustGetJWTKeyring builds a keyring from jwt_keys, or from
+// the single jwt_signing_key if no keyring is configured.
+// maxAge is the longest lifetime of tokens signed.
+func (a AppKey) MustGetJWTKeyring(maxAge time.Duration) keyring.Keyring {
+	var kr keyring.Keyring
+	var err error
+	if len(a.JWTKeys) == 0 {
+		kr, err = keyring.NewHMAC(a.JWT, maxAge)
+	} else {
+		kr, err = keyring.New(a.JWTKeys, maxAge)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return kr
+}
+
 func (a AppKey) GetCSRFKey() []byte {
 	return a.csrfKey
 }
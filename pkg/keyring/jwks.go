@@ -0,0 +1,76 @@
+package keyring
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"time"
+)
+
+// JWK is the public part of an asymmetric key as defined
+// in RFC 7517 so that other services could verify tokens.
+type JWK struct {
+	KeyType string `json:"kty"`
+	ID      string `json:"kid"`
+	Alg     string `json:"alg"`
+	Use     string `json:"use"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (k Key) JWK() (JWK, bool) {
+	jwk := JWK{
+		ID:  k.ID,
+		Alg: k.Method.Alg(),
+		Use: "sig",
+	}
+
+	switch pub := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = b64(pub.N.Bytes())
+		jwk.E = b64(big.NewInt(int64(pub.E)).Bytes())
+		return jwk, true
+
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = b64(pub)
+		return jwk, true
+	}
+
+	return JWK{}, false
+}
+
+// JWKS publishes public keys still accepted. HMAC secrets
+// are never included.
+func (r Keyring) JWKS() JWKSet {
+	now := time.Now()
+	set := JWKSet{
+		Keys: make([]JWK, 0),
+	}
+
+	for _, k := range r.keys {
+		if !k.CanVerify(now, r.maxAge) {
+			continue
+		}
+		if jwk, ok := k.JWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return set
+}
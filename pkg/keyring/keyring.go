@@ -0,0 +1,135 @@
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"sort"
+	"time"
+)
+
+var ErrNoSigningKey = errors.New("keyring: no key available for signing")
+
+// Keyring holds JWT keys identified by kid so that keys
+// could be rotated without invalidating issued tokens.
+// The newest key capable of signing issues tokens while
+// any key still accepted verifies them.
+type Keyring struct {
+	keys   []Key // Newest first.
+	maxAge time.Duration
+}
+
+// New parses keys. maxAge is the longest lifetime of tokens
+// signed by this keyring, used to decide how long a retired
+// key is accepted.
+func New(configs []KeyConfig, maxAge time.Duration) (Keyring, error) {
+	if len(configs) == 0 {
+		return Keyring{}, errors.New("keyring: no keys")
+	}
+
+	var keys []Key
+	seen := map[string]bool{}
+	for _, c := range configs {
+		k, err := NewKey(c)
+		if err != nil {
+			return Keyring{}, err
+		}
+		if seen[k.ID] {
+			return Keyring{}, fmt.Errorf("keyring: duplicate kid %s", k.ID)
+		}
+		seen[k.ID] = true
+		keys = append(keys, k)
+	}
+
+	// Newest first. Among keys created at the same time, the
+	// one listed later wins.
+	for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+		keys[i], keys[j] = keys[j], keys[i]
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+
+	return Keyring{
+		keys:   keys,
+		maxAge: maxAge,
+	}, nil
+}
+
+// NewHMAC creates a keyring of a single HS256 secret which
+// also accepts tokens without kid.
+func NewHMAC(secret string, maxAge time.Duration) (Keyring, error) {
+	return New([]KeyConfig{
+		{
+			ID:        "default",
+			Algorithm: AlgHS256,
+			Secret:    secret,
+			Legacy:    true,
+		},
+	}, maxAge)
+}
+
+// Signer returns the newest key capable of signing.
+func (r Keyring) Signer() (Key, error) {
+	now := time.Now()
+	for _, k := range r.keys {
+		if k.CanSign(now) {
+			return k, nil
+		}
+	}
+
+	return Key{}, ErrNoSigningKey
+}
+
+// Sign issues a token with kid in header.
+func (r Keyring) Sign(claims jwt.Claims) (string, error) {
+	k, err := r.Signer()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(k.Method, claims)
+	token.Header["kid"] = k.ID
+
+	return token.SignedString(k.signKey)
+}
+
+// find locates the key to verify a token.
+func (r Keyring) find(kid string) (Key, bool) {
+	for _, k := range r.keys {
+		if kid == "" && k.Legacy {
+			return k, true
+		}
+		if kid != "" && k.ID == kid {
+			return k, true
+		}
+	}
+
+	return Key{}, false
+}
+
+// KeyFunc is used by jwt parser to pick the key by kid.
+// The algorithm in header must match the key's so that a
+// public key cannot be abused as HMAC secret.
+func (r Keyring) KeyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	k, ok := r.find(kid)
+	if !ok {
+		return nil, fmt.Errorf("keyring: unknown kid %q", kid)
+	}
+
+	if token.Method.Alg() != k.Method.Alg() {
+		return nil, fmt.Errorf("keyring: unexpected alg %s for kid %q", token.Method.Alg(), kid)
+	}
+
+	if !k.CanVerify(time.Now(), r.maxAge) {
+		return nil, fmt.Errorf("keyring: kid %q is retired", kid)
+	}
+
+	return k.verifyKey, nil
+}
+
+func (r Keyring) ParseWithClaims(ss string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(ss, claims, r.KeyFunc)
+}
@@ -0,0 +1,166 @@
+package keyring
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt"
+	"testing"
+	"time"
+)
+
+func mustEdPEM(t *testing.T) (string, string) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privDER, _ := x509.MarshalPKCS8PrivateKey(priv)
+	pubDER, _ := x509.MarshalPKIXPublicKey(pub)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+}
+
+func mustRSAPEM(t *testing.T) string {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(priv),
+	}))
+}
+
+func claims() jwt.StandardClaims {
+	return jwt.StandardClaims{
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	edPriv, edPub := mustEdPEM(t)
+
+	old, err := New([]KeyConfig{
+		{ID: "k1", Algorithm: AlgHS256, Secret: "old", CreatedAt: "2022-01-01"},
+	}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ssOld, err := old.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// k1 retired just now; k2 takes over signing.
+	rotated, err := New([]KeyConfig{
+		{ID: "k1", Algorithm: AlgHS256, Secret: "old", CreatedAt: "2022-01-01", RetiredAt: time.Now().Format(time.RFC3339)},
+		{ID: "k2", Algorithm: AlgEdDSA, PrivateKey: edPriv, CreatedAt: "2022-06-01"},
+	}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, _ := rotated.Signer()
+	if signer.ID != "k2" {
+		t.Errorf("Signer() = %s, want k2", signer.ID)
+	}
+
+	if _, err := rotated.ParseWithClaims(ssOld, &jwt.StandardClaims{}); err != nil {
+		t.Errorf("token of retired key should still verify: %v", err)
+	}
+
+	ssNew, err := rotated.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another service with only the public key.
+	verifier, err := New([]KeyConfig{
+		{ID: "k2", Algorithm: AlgEdDSA, PublicKey: edPub},
+	}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.ParseWithClaims(ssNew, &jwt.StandardClaims{}); err != nil {
+		t.Errorf("public key should verify: %v", err)
+	}
+	if _, err := verifier.Sign(claims()); err != ErrNoSigningKey {
+		t.Errorf("verification-only keyring should not sign: %v", err)
+	}
+
+	// After max age the retired key is dropped.
+	expired, _ := New([]KeyConfig{
+		{ID: "k1", Algorithm: AlgHS256, Secret: "old", RetiredAt: time.Now().Add(-2 * time.Hour).Format(time.RFC3339)},
+		{ID: "k2", Algorithm: AlgEdDSA, PrivateKey: edPriv},
+	}, time.Hour)
+	if _, err := expired.ParseWithClaims(ssOld, &jwt.StandardClaims{}); err == nil {
+		t.Error("retired key past max age should be rejected")
+	}
+}
+
+func TestKeyring_LegacyAndAlgConfusion(t *testing.T) {
+	kr, err := NewHMAC("secret", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Token issued without kid.
+	legacy, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims()).SignedString([]byte("secret"))
+	if _, err := kr.ParseWithClaims(legacy, &jwt.StandardClaims{}); err != nil {
+		t.Errorf("legacy token should verify: %v", err)
+	}
+
+	// Same kid but another alg.
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims())
+	token.Header["kid"] = "default"
+	ss, _ := token.SignedString([]byte("secret"))
+	if _, err := kr.ParseWithClaims(ss, &jwt.StandardClaims{}); err == nil {
+		t.Error("alg mismatch should be rejected")
+	}
+
+	token = jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+	token.Header["kid"] = "unknown"
+	ss, _ = token.SignedString([]byte("secret"))
+	if _, err := kr.ParseWithClaims(ss, &jwt.StandardClaims{}); err == nil {
+		t.Error("unknown kid should be rejected")
+	}
+}
+
+func TestKeyring_JWKS(t *testing.T) {
+	edPriv, _ := mustEdPEM(t)
+
+	kr, err := New([]KeyConfig{
+		{ID: "hmac", Algorithm: AlgHS256, Secret: "secret"},
+		{ID: "ed", Algorithm: AlgEdDSA, PrivateKey: edPriv},
+		{ID: "rsa", Algorithm: AlgRS256, PrivateKey: mustRSAPEM(t)},
+	}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := kr.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS() has %d keys, want 2", len(set.Keys))
+	}
+
+	for _, k := range set.Keys {
+		switch k.ID {
+		case "ed":
+			if k.KeyType != "OKP" || k.X == "" {
+				t.Errorf("bad Ed25519 JWK %+v", k)
+			}
+		case "rsa":
+			if k.KeyType != "RSA" || k.N == "" || k.E != "AQAB" {
+				t.Errorf("bad RSA JWK %+v", k)
+			}
+		default:
+			t.Errorf("unexpected key %s", k.ID)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package postman
+
+import "sync"
+
+// Memory keeps parcels in memory instead of sending them.
+// It is used in tests and local development.
+type Memory struct {
+	mu   *sync.Mutex
+	sent *[]Parcel
+	err  *error
+}
+
+func NewMemory() Memory {
+	return Memory{
+		mu:   &sync.Mutex{},
+		sent: &[]Parcel{},
+		err:  new(error),
+	}
+}
+
+// Deliver records the parcel, or returns the error set by Fail.
+func (m Memory) Deliver(p Parcel) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if *m.err != nil {
+		return *m.err
+	}
+
+	*m.sent = append(*m.sent, p)
+
+	return nil
+}
+
+// Fail makes following deliveries return err until
+// called with nil.
+func (m Memory) Fail(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	*m.err = err
+}
+
+// Sent returns a copy of parcels delivered so far.
+func (m Memory) Sent() []Parcel {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]Parcel, len(*m.sent))
+	copy(list, *m.sent)
+
+	return list
+}
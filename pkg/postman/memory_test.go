@@ -0,0 +1,35 @@
+package postman
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMemory_Deliver(t *testing.T) {
+	m := NewMemory()
+
+	var pm Postman = m
+
+	if err := pm.Deliver(Parcel{ToAddress: "a@example.org"}); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Fail(errors.New("connection refused"))
+	if err := pm.Deliver(Parcel{ToAddress: "b@example.org"}); err == nil {
+		t.Error("expected error after Fail")
+	}
+
+	m.Fail(nil)
+	if err := pm.Deliver(Parcel{ToAddress: "c@example.org"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := m.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("sent %d parcels, want 2", len(sent))
+	}
+
+	if sent[1].ToAddress != "c@example.org" {
+		t.Errorf("got %s, want c@example.org", sent[1].ToAddress)
+	}
+}
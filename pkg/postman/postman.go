@@ -5,20 +5,25 @@ import (
 	"github.com/go-mail/mail"
 )
 
-// Postman wraps mail.Dialer.
-type Postman struct {
+// Postman delivers a parcel by whatever transport.
+type Postman interface {
+	Deliver(p Parcel) error
+}
+
+// SMTP wraps mail.Dialer.
+type SMTP struct {
 	dialer *mail.Dialer
 }
 
-// New creates a new instance of Postman
-func New(c config.Connect) Postman {
-	return Postman{
+// New creates a Postman sending parcels via SMTP server.
+func New(c config.Connect) SMTP {
+	return SMTP{
 		dialer: mail.NewDialer(c.Host, c.Port, c.User, c.Pass),
 	}
 }
 
 // Deliver asks the postman to deliver a parcel.
-func (pm Postman) Deliver(p Parcel) error {
+func (pm SMTP) Deliver(p Parcel) error {
 	m := mail.NewMessage()
 
 	m.SetAddressHeader("From", p.FromAddress, p.FromName)
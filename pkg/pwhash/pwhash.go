@@ -0,0 +1,133 @@
+// Package pwhash hashes passwords with argon2id and encodes
+// the result in PHC string format:
+// $argon2id$v=19$m=65536,t=1,p=4$<salt>$<hash>
+// Salt and parameters are carried in the encoded string so
+// that they could be changed later without breaking
+// existing hashes.
+package pwhash
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"strings"
+)
+
+var ErrInvalidHash = errors.New("pwhash: invalid encoded hash")
+
+// Params of argon2id. The defaults follow the second
+// recommended option of RFC 9106.
+type Params struct {
+	Memory  uint32 // KiB
+	Time    uint32
+	Threads uint8
+	SaltLen uint32
+	KeyLen  uint32
+}
+
+var DefaultParams = Params{
+	Memory:  64 * 1024,
+	Time:    1,
+	Threads: 4,
+	SaltLen: 16,
+	KeyLen:  32,
+}
+
+var b64 = base64.RawStdEncoding
+
+// Hash derives a key from password with a random salt.
+func Hash(password string) (string, error) {
+	return hashWith(password, DefaultParams)
+}
+
+func hashWith(password string, p Params) (string, error) {
+	salt := make([]byte, p.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		p.Memory,
+		p.Time,
+		p.Threads,
+		b64.EncodeToString(salt),
+		b64.EncodeToString(key),
+	), nil
+}
+
+func decode(encoded string) (Params, []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Params{}, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return Params{}, nil, nil, ErrInvalidHash
+	}
+	if version != argon2.Version {
+		return Params{}, nil, nil, ErrInvalidHash
+	}
+
+	var p Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads); err != nil {
+		return Params{}, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := b64.DecodeString(parts[4])
+	if err != nil {
+		return Params{}, nil, nil, ErrInvalidHash
+	}
+	key, err := b64.DecodeString(parts[5])
+	if err != nil {
+		return Params{}, nil, nil, ErrInvalidHash
+	}
+
+	p.SaltLen = uint32(len(salt))
+	p.KeyLen = uint32(len(key))
+
+	return p, salt, key, nil
+}
+
+// Verify compares password against an encoded hash.
+func Verify(password, encoded string) (bool, error) {
+	p, salt, key, err := decode(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// NeedsRehash checks whether an encoded hash was created
+// with parameters other than the current defaults.
+func NeedsRehash(encoded string) bool {
+	p, _, _, err := decode(encoded)
+	if err != nil {
+		return true
+	}
+
+	return p != DefaultParams
+}
+
+// VerifyLegacy compares password against the unsalted
+// SHA-256 hash, in hex, used before argon2id.
+func VerifyLegacy(password, sha256Hex string) bool {
+	sum := sha256.Sum256([]byte(password))
+
+	return subtle.ConstantTimeCompare(
+		[]byte(hex.EncodeToString(sum[:])),
+		[]byte(strings.ToLower(sha256Hex)),
+	) == 1
+}
@@ -0,0 +1,73 @@
+package pwhash
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashAndVerify(t *testing.T) {
+	encoded, err := Hash("12345678")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=65536,t=1,p=4$") {
+		t.Errorf("Unexpected encoding %s", encoded)
+	}
+
+	ok, err := Verify("12345678", encoded)
+	if err != nil || !ok {
+		t.Errorf("Verify() = %v, %v; want true", ok, err)
+	}
+
+	ok, _ = Verify("87654321", encoded)
+	if ok {
+		t.Error("Wrong password should not match")
+	}
+
+	other, _ := Hash("12345678")
+	if other == encoded {
+		t.Error("Each hash should have its own salt")
+	}
+
+	if NeedsRehash(encoded) {
+		t.Error("Hash with default params should not need rehash")
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	weak := DefaultParams
+	weak.Memory = 1024
+
+	encoded, err := hashWith("12345678", weak)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !NeedsRehash(encoded) {
+		t.Error("Hash with old params should need rehash")
+	}
+
+	ok, _ := Verify("12345678", encoded)
+	if !ok {
+		t.Error("Hash with old params should still verify")
+	}
+}
+
+func TestVerifyLegacy(t *testing.T) {
+	// SELECT LOWER(HEX(UNHEX(SHA2('12345678', 256))))
+	legacy := "EF797C8118F02DFB649607DD5D3F8C7623048C9C063D532CC95C5ED7A898A64F"
+
+	if !VerifyLegacy("12345678", legacy) {
+		t.Error("Legacy hash should match")
+	}
+	if VerifyLegacy("1234567", legacy) {
+		t.Error("Wrong password should not match legacy hash")
+	}
+}
+
+func TestVerify_Invalid(t *testing.T) {
+	if _, err := Verify("x", "$2a$10$abc"); err != ErrInvalidHash {
+		t.Errorf("Expected ErrInvalidHash, got %v", err)
+	}
+}
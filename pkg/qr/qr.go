@@ -0,0 +1,12 @@
+// Package qr encodes text, usually a url, into QR code images.
+package qr
+
+import "github.com/skip2/go-qrcode"
+
+// size is the width and height of the image in pixels.
+const size = 256
+
+// PNG encodes s into a png image with medium error recovery.
+func PNG(s string) ([]byte, error) {
+	return qrcode.Encode(s, qrcode.Medium, size)
+}
@@ -0,0 +1,17 @@
+package qr
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPNG(t *testing.T) {
+	b, err := PNG("https://next.ftacademy.cn/redeem?code=ABCD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(b, []byte("\x89PNG")) {
+		t.Errorf("PNG() does not produce a png image")
+	}
+}
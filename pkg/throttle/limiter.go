@@ -0,0 +1,190 @@
+package throttle
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Rule defines how many attempts are allowed within a
+// sliding window, and how long a client should wait between
+// attempts once it exceeds the free ones.
+type Rule struct {
+	// Limit is the max number of attempts within Window.
+	Limit  int
+	Window time.Duration
+	// Free is the number of attempts allowed without delay.
+	// After that each attempt doubles the wait, starting from
+	// BaseDelay and capped at MaxDelay.
+	Free      int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// Delay returns the wait required after n attempts.
+func (r Rule) Delay(n int) time.Duration {
+	if r.BaseDelay <= 0 || n < r.Free || n == 0 {
+		return 0
+	}
+
+	exp := n - r.Free
+	// Guard against overflow.
+	if exp > 30 {
+		exp = 30
+	}
+
+	d := time.Duration(float64(r.BaseDelay) * math.Pow(2, float64(exp)))
+	if r.MaxDelay > 0 && d > r.MaxDelay {
+		return r.MaxDelay
+	}
+
+	return d
+}
+
+// Decide tells whether a new attempt is allowed given the
+// attempts already made within the window, oldest first.
+func (r Rule) Decide(attempts []time.Time, now time.Time) Decision {
+	n := len(attempts)
+	if n == 0 {
+		return Decision{Allowed: true}
+	}
+
+	// The window slides: the key is unblocked once the oldest
+	// attempt falls out of it.
+	if r.Limit > 0 && n >= r.Limit {
+		return Decision{
+			Allowed:    false,
+			RetryAfter: attempts[n-r.Limit].Add(r.Window).Sub(now),
+		}
+	}
+
+	next := attempts[n-1].Add(r.Delay(n))
+	if next.After(now) {
+		return Decision{
+			Allowed:    false,
+			RetryAfter: next.Sub(now),
+		}
+	}
+
+	return Decision{Allowed: true}
+}
+
+// Decision is the result of checking a key against a Rule.
+type Decision struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+// RetryAfterSeconds rounds up RetryAfter to be used in the
+// Retry-After header.
+func (d Decision) RetryAfterSeconds() int64 {
+	return int64(math.Ceil(d.RetryAfter.Seconds()))
+}
+
+// Stricter returns the one that should be obeyed.
+func (d Decision) Stricter(other Decision) Decision {
+	if d.Allowed && !other.Allowed {
+		return other
+	}
+	if !d.Allowed && !other.Allowed && other.RetryAfter > d.RetryAfter {
+		return other
+	}
+
+	return d
+}
+
+// Limiter applies a Rule to keys saved in a Store.
+// The name is prefixed to keys so that several limiters
+// could share a Store.
+type Limiter struct {
+	name  string
+	store Store
+	rule  Rule
+}
+
+func NewLimiter(name string, store Store, rule Rule) Limiter {
+	return Limiter{
+		name:  name,
+		store: store,
+		rule:  rule,
+	}
+}
+
+func (l Limiter) key(k string) string {
+	return l.name + ":" + k
+}
+
+// Check tests all keys and returns the strictest decision.
+// A request is allowed only if every key is allowed.
+func (l Limiter) Check(keys ...string) (Decision, error) {
+	now := time.Now()
+	d := Decision{Allowed: true}
+
+	for _, k := range keys {
+		attempts, err := l.store.Attempts(l.key(k), now.Add(-l.rule.Window))
+		if err != nil {
+			return Decision{}, err
+		}
+
+		d = d.Stricter(l.rule.Decide(attempts, now))
+	}
+
+	return d, nil
+}
+
+// Hit records an attempt for all keys.
+func (l Limiter) Hit(keys ...string) error {
+	now := time.Now()
+
+	for _, k := range keys {
+		err := l.store.Record(l.key(k), now, l.rule.Window)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Count returns the number of attempts of key within the
+// window.
+func (l Limiter) Count(key string) (int, error) {
+	attempts, err := l.store.Attempts(l.key(key), time.Now().Add(-l.rule.Window))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(attempts), nil
+}
+
+// Reset clears the attempts of keys, usually after success.
+func (l Limiter) Reset(keys ...string) error {
+	for _, k := range keys {
+		err := l.store.Clear(l.key(k))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// KeyIP builds a key for a client IP.
+func KeyIP(ip string) string {
+	return "ip:" + ip
+}
+
+// KeyFtcID builds a key for a reader's ftc id.
+func KeyFtcID(id string) string {
+	return "ftc:" + id
+}
+
+// KeyEmail builds a key for an email, case-insensitive.
+func KeyEmail(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// KeyMobile builds a key for a mobile number.
+func KeyMobile(mobile string) string {
+	return "mobile:" + strings.TrimSpace(mobile)
+}
@@ -0,0 +1,113 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRule_Delay(t *testing.T) {
+	r := Rule{
+		Free:      3,
+		BaseDelay: time.Second,
+		MaxDelay:  10 * time.Second,
+	}
+
+	tests := []struct {
+		n    int
+		want time.Duration
+	}{
+		{0, 0},
+		{2, 0},
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{5, 4 * time.Second},
+		{8, 10 * time.Second},
+		{100, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := r.Delay(tt.n); got != tt.want {
+			t.Errorf("Delay(%d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestRule_Decide(t *testing.T) {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := Rule{
+		Limit:     3,
+		Window:    time.Minute,
+		Free:      1,
+		BaseDelay: 5 * time.Second,
+	}
+
+	tests := []struct {
+		name     string
+		attempts []time.Time
+		want     Decision
+	}{
+		{
+			name:     "No attempts",
+			attempts: nil,
+			want:     Decision{Allowed: true},
+		},
+		{
+			name:     "Delayed after free attempts",
+			attempts: []time.Time{now.Add(-2 * time.Second)},
+			want:     Decision{Allowed: false, RetryAfter: 3 * time.Second},
+		},
+		{
+			name:     "Delay passed",
+			attempts: []time.Time{now.Add(-6 * time.Second)},
+			want:     Decision{Allowed: true},
+		},
+		{
+			name: "Limit reached",
+			attempts: []time.Time{
+				now.Add(-50 * time.Second),
+				now.Add(-40 * time.Second),
+				now.Add(-20 * time.Second),
+			},
+			want: Decision{Allowed: false, RetryAfter: 10 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Decide(tt.attempts, now); got != tt.want {
+				t.Errorf("Decide() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter("login", NewMemoryStore(), Rule{
+		Limit:  2,
+		Window: 50 * time.Millisecond,
+	})
+
+	_ = l.Hit(KeyIP("127.0.0.1"), KeyEmail("Foo@example.org"))
+	_ = l.Hit(KeyEmail("foo@example.org"))
+
+	d, _ := l.Check(KeyIP("127.0.0.1"), KeyEmail("foo@example.org"))
+	if d.Allowed {
+		t.Error("should be blocked by email key")
+	}
+
+	d, _ = l.Check(KeyIP("127.0.0.1"))
+	if !d.Allowed {
+		t.Error("ip key should still be allowed")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	d, _ = l.Check(KeyEmail("foo@example.org"))
+	if !d.Allowed {
+		t.Error("should be allowed after window slides")
+	}
+
+	_ = l.Hit(KeyEmail("foo@example.org"), KeyEmail("foo@example.org"))
+	_ = l.Reset(KeyEmail("foo@example.org"))
+	if n, _ := l.Count(KeyEmail("foo@example.org")); n != 0 {
+		t.Errorf("Count() after reset = %d", n)
+	}
+}
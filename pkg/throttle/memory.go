@@ -0,0 +1,67 @@
+package throttle
+
+import (
+	"github.com/patrickmn/go-cache"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps attempts in process memory.
+// Limits are lost upon restart and not shared among
+// instances.
+type MemoryStore struct {
+	mu    *sync.Mutex
+	cache *cache.Cache
+}
+
+func NewMemoryStore() MemoryStore {
+	return MemoryStore{
+		mu:    &sync.Mutex{},
+		cache: cache.New(time.Hour, 2*time.Hour),
+	}
+}
+
+func (s MemoryStore) Record(key string, t time.Time, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := after(s.load(key), t.Add(-ttl))
+	attempts = append(attempts, t)
+
+	s.cache.Set(key, attempts, ttl)
+
+	return nil
+}
+
+func (s MemoryStore) Attempts(key string, since time.Time) ([]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return after(s.load(key), since), nil
+}
+
+func (s MemoryStore) Clear(key string) error {
+	s.cache.Delete(key)
+	return nil
+}
+
+func (s MemoryStore) load(key string) []time.Time {
+	x, ok := s.cache.Get(key)
+	if !ok {
+		return nil
+	}
+
+	return x.([]time.Time)
+}
+
+// after returns a copy of attempts made after since.
+func after(attempts []time.Time, since time.Time) []time.Time {
+	var found []time.Time
+	for _, t := range attempts {
+		if t.After(since) {
+			found = append(found, t)
+		}
+	}
+
+	return found
+}
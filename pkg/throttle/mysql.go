@@ -0,0 +1,88 @@
+package throttle
+
+import (
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+// Attempts are saved as unix milliseconds in
+//
+//	CREATE TABLE b2b.throttle_attempt (
+//		throttle_key VARCHAR(191) NOT NULL,
+//		attempted_ms BIGINT NOT NULL,
+//		INDEX (throttle_key, attempted_ms)
+//	)
+const (
+	stmtRecordAttempt = `
+INSERT INTO b2b.throttle_attempt
+SET throttle_key = ?,
+	attempted_ms = ?`
+
+	stmtPurgeAttempts = `
+DELETE FROM b2b.throttle_attempt
+WHERE throttle_key = ?
+	AND attempted_ms <= ?`
+
+	stmtListAttempts = `
+SELECT attempted_ms
+FROM b2b.throttle_attempt
+WHERE throttle_key = ?
+	AND attempted_ms > ?
+ORDER BY attempted_ms ASC`
+
+	stmtClearAttempts = `
+DELETE FROM b2b.throttle_attempt
+WHERE throttle_key = ?`
+)
+
+// MySQLStore keeps attempts in database so that limits
+// survive restarts and are shared among instances.
+type MySQLStore struct {
+	db *sqlx.DB
+}
+
+func NewMySQLStore(db *sqlx.DB) MySQLStore {
+	return MySQLStore{
+		db: db,
+	}
+}
+
+func (s MySQLStore) Record(key string, t time.Time, ttl time.Duration) error {
+	// Expired rows of this key are removed on every write
+	// so that the table does not grow unbounded.
+	_, err := s.db.Exec(stmtPurgeAttempts, key, t.Add(-ttl).UnixMilli())
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(stmtRecordAttempt, key, t.UnixMilli())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s MySQLStore) Attempts(key string, since time.Time) ([]time.Time, error) {
+	var ms []int64
+	err := s.db.Select(&ms, stmtListAttempts, key, since.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := make([]time.Time, 0, len(ms))
+	for _, v := range ms {
+		attempts = append(attempts, time.UnixMilli(v))
+	}
+
+	return attempts, nil
+}
+
+func (s MySQLStore) Clear(key string) error {
+	_, err := s.db.Exec(stmtClearAttempts, key)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+package throttle
+
+import "time"
+
+// Store keeps the time of every attempt under a key so that
+// a Limiter could count them within a sliding window.
+type Store interface {
+	// Record saves an attempt of key made at t. Attempts
+	// older than ttl are no longer needed and could be
+	// discarded by the implementation.
+	Record(key string, t time.Time, ttl time.Duration) error
+	// Attempts returns the attempts of key made after since,
+	// oldest first.
+	Attempts(key string, since time.Time) ([]time.Time, error)
+	// Clear removes all attempts of key.
+	Clear(key string) error
+}
@@ -0,0 +1,69 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test vectors from RFC 6238 Appendix B, SHA1 mode, keeping
+// the last 6 digits.
+func TestCodeAt(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).
+		EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		got, err := CodeAt(secret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("CodeAt(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	code, _ := CodeAt(secret, Step(now.Add(-30*time.Second)))
+
+	step, ok := Validate(secret, code, now)
+	if !ok {
+		t.Fatal("Code of previous step should be accepted")
+	}
+	if step != Step(now)-1 {
+		t.Errorf("Matched step = %d, want %d", step, Step(now)-1)
+	}
+
+	old, _ := CodeAt(secret, Step(now.Add(-2*time.Minute)))
+	if _, ok := Validate(secret, old, now); ok && old != code {
+		t.Error("Expired code should be rejected")
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("JBSWY3DPEHPK3PXP", "FT中文网", "admin@example.org")
+
+	if !strings.HasPrefix(uri, "otpauth://totp/") {
+		t.Errorf("Unexpected uri %s", uri)
+	}
+	if !strings.Contains(uri, "secret=JBSWY3DPEHPK3PXP") {
+		t.Errorf("Secret missing in %s", uri)
+	}
+}
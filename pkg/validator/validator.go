@@ -20,6 +20,7 @@ type Validator struct {
 	max        int
 	isEmail    bool
 	isURL      bool
+	isDomain   bool
 }
 
 func New(name string) *Validator {
@@ -59,6 +60,13 @@ func (v *Validator) URL() *Validator {
 	return v
 }
 
+// Domain requires the value to be a bare host name,
+// like ftchinese.com, without scheme or path.
+func (v *Validator) Domain() *Validator {
+	v.isDomain = true
+	return v
+}
+
 func (v *Validator) Validate(value string) *render.ValidationError {
 	if v.isEmail && v.isURL {
 		log.Fatal("The validated value cannot be both an email and url")
@@ -112,5 +120,13 @@ func (v *Validator) Validate(value string) *render.ValidationError {
 		}
 	}
 
+	if v.isDomain && value != "" && !govalidator.IsDNSName(value) {
+		return &render.ValidationError{
+			Message: "Invalid domain name",
+			Field:   v.fieldName,
+			Code:    render.CodeInvalid,
+		}
+	}
+
 	return nil
 }
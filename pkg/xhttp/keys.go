@@ -2,4 +2,8 @@ package xhttp
 
 const (
 	KeyCtxClaims = "claims"
+	// KeyCtxScim holds the SCIM setting of a team found by bearer token.
+	KeyCtxScim = "scim"
+	// KeyCtxOAuth holds the CMS access token found by bearer token.
+	KeyCtxOAuth = "oauth"
 )
@@ -0,0 +1,65 @@
+package xhttp
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+var ErrNonPublicAddress = errors.New("address is not publicly routable")
+
+// Shared address space used by carrier-grade NAT, which
+// net.IP.IsPrivate does not cover.
+var _, sharedAddrSpace, _ = net.ParseCIDR("100.64.0.0/10")
+
+// IsPublicIP checks whether an ip could be reached from
+// the internet. Loopback, private, link-local and
+// unspecified addresses are rejected so that urls provided
+// by users could not reach internal services.
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddrSpace.Contains(ip)
+}
+
+// ResolvesPublic looks up a host and reports an error
+// unless every address of it is public.
+func ResolvesPublic(host string) error {
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return err
+	}
+
+	for _, ip := range ips {
+		if !IsPublicIP(ip) {
+			return ErrNonPublicAddress
+		}
+	}
+
+	return nil
+}
+
+// PublicDialControl is used as net.Dialer.Control to refuse
+// connecting to non-public addresses, in case a host
+// resolves differently after it is validated.
+func PublicDialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !IsPublicIP(net.ParseIP(host)) {
+		return ErrNonPublicAddress
+	}
+
+	return nil
+}
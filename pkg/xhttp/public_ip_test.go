@@ -0,0 +1,44 @@
+package xhttp
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := IsPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("IsPublicIP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublicDialControl(t *testing.T) {
+	if err := PublicDialControl("tcp", "127.0.0.1:443", nil); err != ErrNonPublicAddress {
+		t.Errorf("loopback should be refused, got %v", err)
+	}
+
+	if err := PublicDialControl("tcp", "8.8.8.8:443", nil); err != nil {
+		t.Errorf("public address should be allowed, got %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package xhttp
+
+import (
+	"github.com/FTChinese/ftacademy/pkg/throttle"
+	"github.com/FTChinese/go-rest/render"
+	"github.com/labstack/echo/v4"
+	"strconv"
+)
+
+// TooManyRequests responds 429 with the Retry-After header
+// telling client how many seconds to wait.
+func TooManyRequests(c echo.Context, d throttle.Decision) error {
+	c.Response().Header().Set("Retry-After", strconv.FormatInt(d.RetryAfterSeconds(), 10))
+
+	return render.NewTooManyRequests("Too many attempts. Please try later")
+}
@@ -0,0 +1,151 @@
+// Package xlsx writes a single-sheet spreadsheet row by row
+// so that large tables could be streamed without holding
+// them in memory. All cells are written as inline strings.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const contentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+
+const rootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const workbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`
+
+const workbookStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="`
+
+const workbookEnd = `" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+const sheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+const sheetEnd = `</sheetData></worksheet>`
+
+// maxRows is the limit of a worksheet.
+const maxRows = 1048576
+
+var ErrTooManyRows = errors.New("xlsx: a sheet could not have more than 1048576 rows")
+
+// Writer streams rows into the only sheet of a workbook.
+// Close must be called to finish the file.
+type Writer struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+}
+
+// NewWriter writes the workbook skeleton to w and opens the
+// sheet named sheetName for rows.
+func NewWriter(w io.Writer, sheetName string) (*Writer, error) {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		body string
+	}{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", rootRels},
+		{"xl/_rels/workbook.xml.rels", workbookRels},
+		{"xl/workbook.xml", workbookStart + escape(sheetName) + workbookEnd},
+	}
+
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(fw, f.body); err != nil {
+			return nil, err
+		}
+	}
+
+	// The sheet must be the last entry since it is written
+	// incrementally.
+	sw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	bw := bufio.NewWriter(sw)
+	if _, err := bw.WriteString(sheetStart); err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		zw:    zw,
+		sheet: bw,
+	}, nil
+}
+
+// Write appends a row.
+func (w *Writer) Write(record []string) error {
+	if w.rows >= maxRows {
+		return ErrTooManyRows
+	}
+	w.rows++
+
+	r := strconv.Itoa(w.rows)
+
+	_, _ = w.sheet.WriteString(`<row r="` + r + `">`)
+	for i, v := range record {
+		_, _ = w.sheet.WriteString(`<c r="` + columnName(i) + r + `" t="inlineStr"><is><t xml:space="preserve">`)
+		_, _ = w.sheet.WriteString(escape(v))
+		_, _ = w.sheet.WriteString(`</t></is></c>`)
+	}
+	_, err := w.sheet.WriteString(`</row>`)
+
+	return err
+}
+
+// Close finishes the sheet and the zip archive. It does not
+// close the underlying writer.
+func (w *Writer) Close() error {
+	if _, err := w.sheet.WriteString(sheetEnd); err != nil {
+		return err
+	}
+
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+
+	return w.zw.Close()
+}
+
+// columnName converts zero-based index to A, B, ..., Z, AA, AB...
+func columnName(i int) string {
+	var name []byte
+	for i >= 0 {
+		name = append([]byte{byte('A' + i%26)}, name...)
+		i = i/26 - 1
+	}
+
+	return string(name)
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
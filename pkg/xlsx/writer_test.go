@@ -0,0 +1,71 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestColumnName(t *testing.T) {
+	tests := map[int]string{
+		0:   "A",
+		25:  "Z",
+		26:  "AA",
+		27:  "AB",
+		701: "ZZ",
+		702: "AAA",
+	}
+	for in, want := range tests {
+		if got := columnName(in); got != want {
+			t.Errorf("columnName(%d) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	w, err := NewWriter(&buf, "许可")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = w.Write([]string{"邮箱", "Tier"})
+	_ = w.Write([]string{"a&b@example.org", "<premium>"})
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		sheet = string(b)
+	}
+
+	if len(zr.File) != 5 {
+		t.Errorf("Expected 5 files, got %d", len(zr.File))
+	}
+
+	for _, want := range []string{
+		`<c r="A1" t="inlineStr"><is><t xml:space="preserve">邮箱</t></is></c>`,
+		`<c r="B2" t="inlineStr"><is><t xml:space="preserve">&lt;premium&gt;</t></is></c>`,
+		`a&amp;b@example.org`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet does not contain %s", want)
+		}
+	}
+}